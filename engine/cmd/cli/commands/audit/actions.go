@@ -0,0 +1,75 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package audit provides commands to review the audit log.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+)
+
+// list runs a request to list audit log entries.
+func list(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	since, err := parseTime(cliCtx.String("since"), now)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+
+	until, err := parseTime(cliCtx.String("until"), now)
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	entries, err := dblabClient.ListAuditEntries(cliCtx.Context, types.AuditListRequest{
+		Since:  since,
+		Until:  until,
+		Actor:  cliCtx.String("actor"),
+		Action: cliCtx.String("action"),
+		Limit:  cliCtx.Int("limit"),
+	})
+	if err != nil {
+		return err
+	}
+
+	commandResponse, err := json.MarshalIndent(entries, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
+
+	return err
+}
+
+// parseTime parses a time in the RFC 3339 format or a duration before now.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return time.Time{}, fmt.Errorf("%q is neither a time in the RFC 3339 format nor a duration, e.g. 24h", value)
+	}
+
+	return now.Add(-duration), nil
+}
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		value    string
+		expected time.Time
+	}{
+		{value: "", expected: time.Time{}},
+		{value: "2026-03-01T10:00:00Z", expected: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{value: "24h", expected: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{value: "90m", expected: time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		parsed, err := parseTime(tc.value, now)
+		require.NoError(t, err, tc.value)
+		assert.True(t, tc.expected.Equal(parsed), tc.value)
+	}
+
+	for _, value := range []string{"yesterday", "-1h", "2026-03-01"} {
+		_, err := parseTime(value, now)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+2026 © Postgres.ai
+*/
+
+package audit
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// CommandList returns available commands for the audit log.
+func CommandList() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "audit",
+			Usage: "review actions performed through the API",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "list",
+					Usage:  "list audit log entries, the most recent first",
+					Action: list,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "since",
+							Usage: "list entries recorded since the time in the RFC 3339 format or the duration ago, e.g. 24h",
+						},
+						&cli.StringFlag{
+							Name:  "until",
+							Usage: "list entries recorded until the time in the RFC 3339 format or the duration ago, e.g. 1h",
+						},
+						&cli.StringFlag{
+							Name:  "actor",
+							Usage: "list entries of the actor, e.g. a user email",
+						},
+						&cli.StringFlag{
+							Name:  "action",
+							Usage: "list entries of the action, e.g. clone.reset, or of all actions on the resource, e.g. clone",
+						},
+						&cli.IntFlag{
+							Name:  "limit",
+							Usage: "maximum number of entries to list",
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -361,6 +361,15 @@ func commit(cliCtx *cli.Context) error {
 		Message: message,
 	}
 
+	if migrationFile := cliCtx.String("migration-file"); migrationFile != "" {
+		migration, err := os.ReadFile(migrationFile)
+		if err != nil {
+			return commands.ToActionError(fmt.Errorf("failed to read migration file: %w", err))
+		}
+
+		snapshotRequest.Migration = string(migration)
+	}
+
 	snapshot, err := dblabClient.CreateSnapshotForBranch(cliCtx.Context, snapshotRequest)
 	if err != nil {
 		return err
@@ -371,6 +380,35 @@ func commit(cliCtx *cli.Context) error {
 	return err
 }
 
+func rebase(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	branchName := cliCtx.Args().First()
+
+	if branchName == "" {
+		branchName = getBaseBranch(cliCtx)
+	}
+
+	rebaseRequest := types.BranchRebaseRequest{
+		Onto:    cliCtx.String("onto"),
+		DBName:  cliCtx.String("db-name"),
+		Message: cliCtx.String("message"),
+	}
+
+	snapshot, err := dblabClient.RebaseBranch(cliCtx.Context, branchName, rebaseRequest)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(cliCtx.App.Writer, "Rebased branch '%s' onto '%s': created snapshot '%s'\n",
+		branchName, rebaseRequest.Onto, snapshot.SnapshotID)
+
+	return err
+}
+
 func history(cliCtx *cli.Context) error {
 	dblabClient, err := commands.ClientByCLIContext(cliCtx)
 	if err != nil {
@@ -55,6 +55,32 @@ func List() []*cli.Command {
 					Usage:   "use the given message as the commit message",
 					Aliases: []string{"m"},
 				},
+				&cli.StringFlag{
+					Name:  "migration-file",
+					Usage: "path to the SQL migration applied in the clone; it is recorded so that the branch can be rebased later",
+				},
+			},
+		},
+		{
+			Name:      "rebase",
+			Usage:     "replay the recorded migrations of the branch on top of the latest state of another branch",
+			Action:    rebase,
+			ArgsUsage: "BRANCH_NAME",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "onto",
+					Usage: "branch to rebase onto",
+					Value: defaultBranch,
+				},
+				&cli.StringFlag{
+					Name:  "db-name",
+					Usage: "database to apply the migrations to; defaults to the database configured for the engine",
+				},
+				&cli.StringFlag{
+					Name:    "message",
+					Usage:   "use the given message as the commit message of the rebased snapshot",
+					Aliases: []string{"m"},
+				},
 			},
 		},
 		{
@@ -6,6 +6,7 @@
 package clone
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,6 +21,7 @@ import (
 
 	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
@@ -44,6 +46,10 @@ func list(cliCtx *cli.Context) error {
 		return err
 	}
 
+	if cliCtx.Bool("mine") {
+		return listOwn(cliCtx, dblabClient)
+	}
+
 	body, err := dblabClient.ListClonesRaw(cliCtx.Context)
 	if err != nil {
 		return err
@@ -61,7 +67,29 @@ func list(cliCtx *cli.Context) error {
 		return err
 	}
 
-	commandResponse, err := json.MarshalIndent(viewCloneList.Cloning.Clones, "", "    ")
+	return printCloneViews(cliCtx, viewCloneList.Cloning.Clones)
+}
+
+// listOwn lists clones bound to the user of the token.
+func listOwn(cliCtx *cli.Context, dblabClient *dblabapi.Client) error {
+	body, err := dblabClient.ListOwnClonesRaw(cliCtx.Context)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = body.Close() }()
+
+	viewClones := make([]*models.CloneView, 0)
+
+	if err := json.NewDecoder(body).Decode(&viewClones); err != nil {
+		return err
+	}
+
+	return printCloneViews(cliCtx, viewClones)
+}
+
+func printCloneViews(cliCtx *cli.Context, clones []*models.CloneView) error {
+	commandResponse, err := json.MarshalIndent(clones, "", "    ")
 	if err != nil {
 		return err
 	}
@@ -123,7 +151,8 @@ func create(cliCtx *cli.Context) error {
 			Restricted: cliCtx.Bool("restricted"),
 			DBName:     cliCtx.String("db-name"),
 		},
-		Branch: cliCtx.String("branch"),
+		Branch:   cliCtx.String("branch"),
+		Template: cliCtx.String("template"),
 	}
 
 	if cliCtx.IsSet("snapshot-id") {
@@ -137,7 +166,7 @@ func create(cliCtx *cli.Context) error {
 	if cliCtx.Bool("async") {
 		clone, err = dblabClient.CreateCloneAsync(cliCtx.Context, cloneRequest)
 	} else {
-		clone, err = dblabClient.CreateClone(cliCtx.Context, cloneRequest)
+		clone, err = createClone(cliCtx.Context, dblabClient, cloneRequest)
 	}
 
 	if err != nil {
@@ -195,6 +224,43 @@ func buildCloneOutput(clone *models.Clone) string {
 	return s.String()
 }
 
+// createClone creates a clone and waits on the event stream of the engine until the clone is ready.
+// If the stream is not available, e.g. the engine is older or the token has no access to it, the clone status is polled.
+func createClone(ctx context.Context, dblabClient *dblabapi.Client, cloneRequest types.CloneCreateRequest) (*models.Clone, error) {
+	sub, err := dblabClient.Subscribe(ctx, 0)
+	if err != nil {
+		log.Dbg("Event stream is not available, polling the clone status:", err)
+
+		return dblabClient.CreateClone(ctx, cloneRequest)
+	}
+
+	defer sub.Close()
+
+	clone, err := dblabClient.CreateCloneAsync(ctx, cloneRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if clone.Status.Code == models.StatusOK {
+		return clone, nil
+	}
+
+	if clone.Status.Code != models.StatusCreating {
+		return nil, errors.Errorf("unexpected clone status given: %v", clone.Status)
+	}
+
+	clone, err = dblabClient.WaitCloneStatus(ctx, sub, clone.ID, clone.Status.Code)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to watch the clone status")
+	}
+
+	if clone.Status.Code != models.StatusOK {
+		return nil, errors.Errorf("failed to create clone, unexpected status given. %v: %s", clone.Status.Code, clone.Status.Message)
+	}
+
+	return clone, nil
+}
+
 // update runs a request to update an existing clone.
 func update(cliCtx *cli.Context) error {
 	dblabClient, err := commands.ClientByCLIContext(cliCtx)
@@ -234,6 +300,43 @@ func update(cliCtx *cli.Context) error {
 	return err
 }
 
+// updateConfig runs a request to change Postgres settings of a clone.
+func updateConfig(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	postgresConfig := make(map[string]*string)
+
+	for name, value := range splitFlags(cliCtx.StringSlice("set")) {
+		postgresConfig[name] = &value
+	}
+
+	for _, name := range cliCtx.StringSlice("reset") {
+		postgresConfig[name] = nil
+	}
+
+	if len(postgresConfig) == 0 {
+		return errors.New("at least one of --set or --reset must be specified")
+	}
+
+	configUpdate, err := dblabClient.UpdateCloneConfig(cliCtx.Context, cliCtx.Args().First(),
+		types.CloneConfigUpdateRequest{PostgresConfig: postgresConfig})
+	if err != nil {
+		return err
+	}
+
+	commandResponse, err := json.MarshalIndent(configUpdate, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(cliCtx.App.Writer, string(commandResponse))
+
+	return err
+}
+
 func convertCloneView(clone *models.Clone) (*models.CloneView, error) {
 	data, err := json.Marshal(clone)
 	if err != nil {
@@ -276,6 +379,42 @@ func reset(cliCtx *cli.Context) error {
 	return err
 }
 
+// hibernate runs a request to hibernate clone.
+func hibernate(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cloneID := cliCtx.Args().First()
+
+	if _, err := dblabClient.HibernateClone(cliCtx.Context, cloneID); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(cliCtx.App.Writer, "The clone has been successfully hibernated: %s\n", cloneID)
+
+	return err
+}
+
+// wake runs a request to wake clone up.
+func wake(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cloneID := cliCtx.Args().First()
+
+	if _, err := dblabClient.WakeClone(cliCtx.Context, cloneID); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(cliCtx.App.Writer, "The clone has been successfully woken up: %s\n", cloneID)
+
+	return err
+}
+
 // destroy runs a request to destroy clone.
 func destroy(cliCtx *cli.Context) error {
 	dblabClient, err := commands.ClientByCLIContext(cliCtx)
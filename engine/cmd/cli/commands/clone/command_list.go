@@ -25,6 +25,12 @@ func CommandList() []*cli.Command {
 				Name:   "list",
 				Usage:  "list all existing clones",
 				Action: list,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "mine",
+						Usage: "list only clones owned by the user of the token",
+					},
+				},
 			},
 			{
 				Name:      "status",
@@ -68,6 +74,10 @@ func CommandList() []*cli.Command {
 						Name:  "branch",
 						Usage: "branch name (optional)",
 					},
+					&cli.StringFlag{
+						Name:  "template",
+						Usage: "name of the clone template defined by the administrator (optional)",
+					},
 					&cli.StringFlag{
 						Name:    "protected",
 						Usage:   "deletion protection: 'true'=default, minutes or 30m/2h/7d, 0=forever",
@@ -98,6 +108,23 @@ func CommandList() []*cli.Command {
 					},
 				},
 			},
+			{
+				Name:      "config",
+				Usage:     "change Postgres settings of a running clone, applying them with a reload or a restart",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    updateConfig,
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "set",
+						Usage: "set a Postgres parameter. An example: --set statement_timeout='1s'",
+					},
+					&cli.StringSliceFlag{
+						Name:  "reset",
+						Usage: "reset a Postgres parameter set earlier. An example: --reset statement_timeout",
+					},
+				},
+			},
 			{
 				Name:      "reset",
 				Usage:     "reset clone's state",
@@ -120,6 +147,20 @@ func CommandList() []*cli.Command {
 					},
 				},
 			},
+			{
+				Name:      "hibernate",
+				Usage:     "stop clone's container, keeping its data and port",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    hibernate,
+			},
+			{
+				Name:      "wake",
+				Usage:     "start the container of a hibernated clone",
+				ArgsUsage: "CLONE_ID",
+				Before:    checkCloneIDBefore,
+				Action:    wake,
+			},
 			{
 				Name:      "destroy",
 				Usage:     "destroy clone",
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/urfave/cli/v2"
 
@@ -77,7 +78,10 @@ func create(cliCtx *cli.Context) error {
 // createOnPool runs a request to create a new snapshot.
 func createOnPool(cliCtx *cli.Context, client *dblabapi.Client) ([]byte, error) {
 	snapshotRequest := types.SnapshotCreateRequest{
-		PoolName: cliCtx.String("pool"),
+		PoolName:           cliCtx.String("pool"),
+		RecoveryTargetTime: cliCtx.String("recovery-target-time"),
+		RecoveryTargetLSN:  cliCtx.String("recovery-target-lsn"),
+		Branch:             cliCtx.String("branch"),
 	}
 
 	snapshot, err := client.CreateSnapshot(cliCtx.Context, snapshotRequest)
@@ -140,6 +144,36 @@ func updateSnapshot(cliCtx *cli.Context) error {
 	return err
 }
 
+// diffSnapshot runs a request to compare a snapshot against another snapshot or clone.
+func diffSnapshot(cliCtx *cli.Context) error {
+	dblabClient, err := commands.ClientByCLIContext(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	format := "sql"
+	if cliCtx.Bool("json") {
+		format = "json"
+	}
+
+	diffRequest := types.SnapshotDiffRequest{
+		Against:     cliCtx.String("against"),
+		DBName:      cliCtx.String("db-name"),
+		ExactCounts: cliCtx.Bool("exact-counts"),
+	}
+
+	body, err := dblabClient.SnapshotDiffRaw(cliCtx.Context, cliCtx.Args().First(), diffRequest, format)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = body.Close() }()
+
+	_, err = io.Copy(cliCtx.App.Writer, body)
+
+	return err
+}
+
 // deleteSnapshot runs a request to delete existing snapshot.
 func deleteSnapshot(cliCtx *cli.Context) error {
 	dblabClient, err := commands.ClientByCLIContext(cliCtx)
@@ -39,6 +39,18 @@ func CommandList() []*cli.Command {
 							Name:  "message",
 							Usage: "optional message for new snapshot created from existing clone",
 						},
+						&cli.StringFlag{
+							Name:  "recovery-target-time",
+							Usage: "create a point-in-time snapshot as of the time in the RFC 3339 format (physical mode only)",
+						},
+						&cli.StringFlag{
+							Name:  "recovery-target-lsn",
+							Usage: "create a point-in-time snapshot as of the WAL location (physical mode only)",
+						},
+						&cli.StringFlag{
+							Name:  "branch",
+							Usage: "branch of the point-in-time snapshot; derived from the recovery target by default",
+						},
 					},
 				},
 				{
@@ -48,6 +60,32 @@ func CommandList() []*cli.Command {
 					ArgsUsage: "SNAPSHOT_ID",
 					Before:    checkSnapshotIDBefore,
 				},
+				{
+					Name:      "diff",
+					Usage:     "compare schema and table statistics of a snapshot against another snapshot or clone",
+					Action:    diffSnapshot,
+					ArgsUsage: "SNAPSHOT_ID",
+					Before:    checkSnapshotIDBefore,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:     "against",
+							Usage:    "ID of the snapshot or clone to compare with",
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:  "db-name",
+							Usage: "database to compare; defaults to the database configured for the engine",
+						},
+						&cli.BoolFlag{
+							Name:  "exact-counts",
+							Usage: "count rows of every table instead of using planner estimates",
+						},
+						&cli.BoolFlag{
+							Name:  "json",
+							Usage: "print the diff as JSON",
+						},
+					},
+				},
 				{
 					Name:      "update",
 					Usage:     "update snapshot deletion protection",
@@ -21,6 +21,7 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
@@ -34,6 +35,9 @@ const (
 	httpIdleTimeout     = 60 * time.Second
 	editionCheckTimeout = 10 * time.Second
 	maxRequestBodySize  = 1 << 20 // 1 MiB
+
+	// webhookSignatureTolerance bounds the age of signed webhook requests accepted to prevent replays.
+	webhookSignatureTolerance = 5 * time.Minute
 )
 
 // Config holds configuration for the teleport sidecar.
@@ -119,7 +123,7 @@ func CommandList() []*cli.Command {
 					},
 					&cli.StringFlag{
 						Name:     "webhook-secret",
-						Usage:    "shared secret that DBLab Engine sends in the DBLab-Webhook-Token header",
+						Usage:    "shared secret that DBLab Engine signs webhook requests with",
 						Required: true,
 						EnvVars:  []string{"WEBHOOK_SECRET"},
 					},
@@ -259,13 +263,19 @@ func (s *service) makeWebhookHandler() http.HandlerFunc {
 			return
 		}
 
-		if subtle.ConstantTimeCompare([]byte(r.Header.Get("DBLab-Webhook-Token")), []byte(s.cfg.WebhookSecret)) != 1 {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize))
+		if err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		if !s.isAuthenticWebhook(r.Header, body) {
 			http.Error(w, "unauthorized", http.StatusUnauthorized)
 			return
 		}
 
 		var payload WebhookPayload
-		if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestBodySize)).Decode(&payload); err != nil {
+		if err := json.Unmarshal(body, &payload); err != nil {
 			log.Errf("webhook: failed to decode payload: %v", err)
 			http.Error(w, "bad request", http.StatusBadRequest)
 
@@ -297,6 +307,17 @@ func (s *service) makeWebhookHandler() http.HandlerFunc {
 	}
 }
 
+// isAuthenticWebhook verifies the request signature, falling back to the plain secret header
+// sent by engines that do not sign webhooks.
+func (s *service) isAuthenticWebhook(header http.Header, body []byte) bool {
+	if signature := header.Get(webhooks.DLEWebhookSignatureHeader); signature != "" {
+		return webhooks.VerifySignature(s.cfg.WebhookSecret, header.Get(webhooks.DLEWebhookTimestampHeader),
+			signature, body, webhookSignatureTolerance)
+	}
+
+	return subtle.ConstantTimeCompare([]byte(header.Get(webhooks.DLEWebhookTokenHeader)), []byte(s.cfg.WebhookSecret)) == 1
+}
+
 func (s *service) handleCloneCreate(ctx context.Context, p *WebhookPayload) error {
 	if p.EntityID == "" || p.Port == 0 {
 		log.Errf("webhook clone_create: missing entity_id or port in payload")
@@ -10,12 +10,15 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
@@ -147,6 +150,27 @@ func TestWebhookHandler_SecretValidation(t *testing.T) {
 		handler(rr, req)
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 	})
+
+	t.Run("valid signature", func(t *testing.T) {
+		timestamp := time.Now().Unix()
+		req := httptest.NewRequest(http.MethodPost, "/teleport-sync", bytes.NewReader(body))
+		req.Header.Set(webhooks.DLEWebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(webhooks.DLEWebhookSignatureHeader, webhooks.Sign("mysecret", timestamp, body))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		timestamp := time.Now().Unix()
+		req := httptest.NewRequest(http.MethodPost, "/teleport-sync", bytes.NewReader(body))
+		req.Header.Set(webhooks.DLEWebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(webhooks.DLEWebhookSignatureHeader, webhooks.Sign("wrongsecret", timestamp, body))
+		req.Header.Set("DBLab-Webhook-Token", "mysecret")
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
 }
 
 func TestDiffDBs(t *testing.T) {
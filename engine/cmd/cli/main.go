@@ -10,6 +10,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands"
+	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands/audit"
 	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands/branch"
 	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands/clone"
 	"gitlab.com/postgres-ai/database-lab/v3/cmd/cli/commands/config"
@@ -43,6 +44,7 @@ func main() {
 			instance.CommandList(),
 			snapshot.CommandList(),
 			teleport.CommandList(),
+			audit.CommandList(),
 
 			// CLI config.
 			config.CommandList(),
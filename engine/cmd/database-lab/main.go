@@ -22,11 +22,15 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/pkg/errors"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/audit"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/billing"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/cloning"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/diagnostic"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/embeddedui"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/events"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/operations"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/pgrouter"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/platform"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/pool"
@@ -37,6 +41,7 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/srv"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/ws"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/telemetry"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/tracing"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/config"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
@@ -85,6 +90,12 @@ func main() {
 	log.Msg("Database Lab Instance ID:", engProps.InstanceID)
 	log.Msg("Database Lab Engine version:", version.GetVersion())
 
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing, engProps.InstanceID)
+	if err != nil {
+		log.Err(errors.WithMessage(err, `error in the "tracing" section of the config`).Error())
+		return
+	}
+
 	// Create a platform service to make requests to Platform.
 	platformSvc, err := platform.New(ctx, cfg.Platform, engProps.InstanceID)
 	if err != nil {
@@ -113,8 +124,9 @@ func main() {
 
 	tm := telemetry.New(platformSvc, engProps.InstanceID)
 
+	eventBroker := events.NewBroker()
 	webhookChan := make(chan webhooks.EventTyper, 1)
-	whs := webhooks.NewService(&cfg.Webhooks, webhookChan)
+	whs := webhooks.NewService(&cfg.Webhooks, eventBroker.Tee(webhookChan))
 
 	go whs.Run(ctx)
 
@@ -124,7 +136,7 @@ func main() {
 	}
 
 	// Create a new retrieval service to prepare a data directory and start snapshotting.
-	retrievalSvc, err := retrieval.New(cfg, &engProps, docker, pm, tm, runner)
+	retrievalSvc, err := retrieval.New(cfg, &engProps, docker, pm, tm, runner, webhookChan)
 	if err != nil {
 		log.Errf(errors.WithMessage(err, `error in the "retrieval" section of config`).Error())
 		return
@@ -161,6 +173,15 @@ func main() {
 		return
 	}
 
+	router := pgrouter.New(cfg.Router, cloningSvc)
+	if err := router.Run(ctx); err != nil {
+		log.Err(errors.WithMessage(err, `error in the "router" section of the config`).Error())
+	}
+
+	if cfg.Cloning.Hibernation.WakeOnConnect && !cfg.Router.Enabled {
+		log.Warn("Hibernated clones are woken up on connection only through the Postgres router, which is disabled")
+	}
+
 	obs := observer.NewObserver(docker, &cfg.Observer, pm)
 	billingSvc := billing.New(platformSvc.Client, &engProps, pm)
 
@@ -185,12 +206,34 @@ func main() {
 			logCleaner,
 			logFilter,
 			whs,
+			router,
 		)
 	}
 
 	server := srv.NewServer(&cfg.Server, &cfg.Global, &engProps, docker, cloningSvc, provisioner, retrievalSvc, platformSvc,
 		billingSvc, obs, pm, tm, tokenHolder, logFilter, embeddedUI, reloadConfigFn, webhookChan)
 	server.SetRetention(cfg.Retention)
+	server.SetWebhooks(whs)
+	server.SetEvents(eventBroker)
+	server.SetRouter(router)
+
+	operationRegistry := operations.NewRegistry(cfg.Operations)
+	server.SetOperations(operationRegistry)
+
+	go operationRegistry.Run(ctx)
+
+	auditLog, err := audit.NewLog(cfg.Audit)
+	if err != nil {
+		log.Err("failed to open audit log, actions will not be recorded:", err)
+	} else {
+		server.SetAuditLog(auditLog)
+
+		defer func() {
+			if err := auditLog.Close(); err != nil {
+				log.Err("failed to close audit log:", err)
+			}
+		}()
+	}
 
 	server.InitHandlers()
 
@@ -238,7 +281,7 @@ func main() {
 	go setReloadListener(ctx, engProps, provisioner, billingSvc,
 		retrievalSvc, pm, cloningSvc, platformSvc,
 		embeddedUI, server,
-		logCleaner, logFilter, whs)
+		logCleaner, logFilter, whs, router)
 
 	go billingSvc.CollectUsage(ctx, systemMetrics)
 
@@ -269,6 +312,10 @@ func main() {
 	cloningSvc.SaveClonesState()
 	logCleaner.StopLogCleanupJob()
 	tm.SendEvent(ctxBackground, telemetry.EngineStoppedEvent, telemetry.EngineStopped{Uptime: server.Uptime()})
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Err("failed to flush traces:", err)
+	}
 }
 
 func getNetworkGateway(docker *client.Client, internalNetworkID string) string {
@@ -321,7 +368,7 @@ func getEngineProperties(ctx context.Context, docker *client.Client, cfg *config
 func reloadConfig(ctx context.Context, engProp global.EngineProps, provisionSvc *provision.Provisioner, billingSvc *billing.Billing,
 	retrievalSvc *retrieval.Retrieval, pm *pool.Manager, cloningSvc *cloning.Base, platformSvc *platform.Service,
 	embeddedUI *embeddedui.UIManager, server *srv.Server, cleaner *diagnostic.Cleaner, filtering *log.Filtering,
-	whs *webhooks.Service) error {
+	whs *webhooks.Service, router *pgrouter.Router) error {
 	cfg, err := config.LoadConfiguration()
 	if err != nil {
 		return err
@@ -334,6 +381,10 @@ func reloadConfig(ctx context.Context, engProp global.EngineProps, provisionSvc
 		return err
 	}
 
+	if err := cfg.Router.Validate(); err != nil {
+		return err
+	}
+
 	newRetrievalConfig, err := retrieval.ValidateConfig(&cfg.Retrieval)
 	if err != nil {
 		return err
@@ -368,15 +419,17 @@ func reloadConfig(ctx context.Context, engProp global.EngineProps, provisionSvc
 	billingSvc.Reload(newPlatformSvc.Client)
 	server.Reload(cfg.Server)
 	server.SetRetention(cfg.Retention)
+	server.ReloadAudit(cfg.Audit)
+	server.ReloadOperations(cfg.Operations)
 	whs.Reload(&cfg.Webhooks)
 
-	return nil
+	return router.Reload(cfg.Router)
 }
 
 func setReloadListener(ctx context.Context, engProp global.EngineProps, provisionSvc *provision.Provisioner, billingSvc *billing.Billing,
 	retrievalSvc *retrieval.Retrieval, pm *pool.Manager, cloningSvc *cloning.Base, platformSvc *platform.Service,
 	embeddedUI *embeddedui.UIManager, server *srv.Server, cleaner *diagnostic.Cleaner, logFilter *log.Filtering,
-	whs *webhooks.Service) {
+	whs *webhooks.Service, router *pgrouter.Router) {
 	reloadCh := make(chan os.Signal, 1)
 	signal.Notify(reloadCh, syscall.SIGHUP)
 
@@ -388,7 +441,7 @@ func setReloadListener(ctx context.Context, engProp global.EngineProps, provisio
 			pm, cloningSvc,
 			platformSvc,
 			embeddedUI, server,
-			cleaner, logFilter, whs); err != nil {
+			cleaner, logFilter, whs, router); err != nil {
 			log.Err("failed to reload configuration:", err)
 
 			continue
@@ -438,5 +491,11 @@ func maskedSecrets(cfg *config.Config) []string {
 		}
 	}
 
+	for _, header := range cfg.Tracing.Headers {
+		if header != "" {
+			maskedSecrets = append(maskedSecrets, header)
+		}
+	}
+
 	return maskedSecrets
 }
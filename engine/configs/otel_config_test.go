@@ -69,6 +69,23 @@ func TestOtelCollectorConfigHasMetricsPipeline(t *testing.T) {
 	require.True(t, ok, "service must have pipelines")
 
 	assert.Contains(t, pipelines, "metrics", "must have metrics pipeline")
+	assert.Contains(t, pipelines, "traces", "must have traces pipeline for dblab traces")
+}
+
+func TestOtelCollectorConfigHasOTLPReceiver(t *testing.T) {
+	data, err := os.ReadFile("otel-collector.example.yml")
+	require.NoError(t, err)
+
+	var config map[string]interface{}
+	err = yaml.Unmarshal(data, &config)
+	require.NoError(t, err)
+
+	receivers := config["receivers"].(map[string]interface{})
+	otlp, ok := receivers["otlp"].(map[string]interface{})
+	require.True(t, ok, "must have otlp receiver for dblab traces")
+
+	protocols := otlp["protocols"].(map[string]interface{})
+	assert.Contains(t, protocols, "http", "dblab exports traces over OTLP/HTTP")
 }
 
 func TestOtelCollectorConfigDBLabScrapeTarget(t *testing.T) {
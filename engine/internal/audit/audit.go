@@ -0,0 +1,349 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package audit keeps a durable record of actions performed through the API: who created, reset, or destroyed
+// a clone, changed a snapshot, reconfigured the engine, and whether the action succeeded.
+//
+// Entries are appended to a JSON-lines file that is rotated when it grows too large.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+const (
+	fileName         = "audit.log"
+	defaultMaxSizeMB = 100
+	defaultMaxFiles  = 5
+	bytesInMB        = 1 << 20
+	maxEntryBytes    = 1 << 20
+
+	// DefaultListLimit is the number of entries returned when the filter sets no limit.
+	DefaultListLimit = 100
+)
+
+// Config defines the rotation of the audit log.
+type Config struct {
+	// MaxSizeMB is the size at which the audit log file is rotated.
+	MaxSizeMB uint `yaml:"maxSizeMB"`
+	// MaxFiles caps the number of rotated files kept in addition to the current one.
+	MaxFiles uint `yaml:"maxFiles"`
+}
+
+func (c Config) maxSize() int64 {
+	if c.MaxSizeMB == 0 {
+		return defaultMaxSizeMB * bytesInMB
+	}
+
+	return int64(c.MaxSizeMB) * bytesInMB
+}
+
+func (c Config) maxFiles() int {
+	if c.MaxFiles == 0 {
+		return defaultMaxFiles
+	}
+
+	return int(c.MaxFiles)
+}
+
+// Filter selects audit entries.
+type Filter struct {
+	Since time.Time
+	Until time.Time
+	Actor string
+	// Action matches the action itself or, for a resource such as "clone", all of its actions.
+	Action string
+	Limit  int
+}
+
+func (f Filter) matches(entry models.AuditEntry) bool {
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+
+	if f.Actor != "" && !strings.EqualFold(entry.Actor, f.Actor) {
+		return false
+	}
+
+	if f.Action != "" && entry.Action != f.Action && !strings.HasPrefix(entry.Action, f.Action+".") {
+		return false
+	}
+
+	return true
+}
+
+// Log is an append-only audit log.
+type Log struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	size     int64
+	maxSize  int64
+	maxFiles int
+}
+
+// NewLog opens the audit log in the metadata directory of the engine.
+func NewLog(cfg Config) (*Log, error) {
+	path, err := util.GetMetaPath(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get path of audit log: %w", err)
+	}
+
+	return Open(path, cfg)
+}
+
+// Open opens the audit log at the given path.
+func Open(path string, cfg Config) (*Log, error) {
+	l := &Log{path: path, maxSize: cfg.maxSize(), maxFiles: cfg.maxFiles()}
+
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Reload applies new rotation settings.
+func (l *Log) Reload(cfg Config) {
+	l.mu.Lock()
+	l.maxSize = cfg.maxSize()
+	l.maxFiles = cfg.maxFiles()
+	l.mu.Unlock()
+}
+
+// Record appends the entry to the log.
+func (l *Log) Record(entry models.AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// The file is missing if it could not be reopened after the last rotation.
+	if l.file == nil {
+		if err := l.open(); err != nil {
+			return err
+		}
+	}
+
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// List returns entries matching the filter, the most recent first.
+func (l *Log) List(filter Filter) ([]models.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultListLimit
+	}
+
+	// Files are opened under the lock, so that a concurrent rotation does not shift them while they are read,
+	// and read without it, so that recording is not blocked.
+	files, err := l.openFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+
+	entries := []models.AuditEntry{}
+
+	for _, file := range files {
+		fileEntries, err := readEntries(file, filter, filter.Limit-len(entries))
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, fileEntries...)
+
+		if len(entries) >= filter.Limit {
+			break
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+
+	return entries, nil
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	err := l.file.Close()
+	l.file = nil
+
+	return err
+}
+
+func (l *Log) open() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory of audit log: %w", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to get size of audit log: %w", err)
+	}
+
+	l.file = file
+	l.size = info.Size()
+
+	return nil
+}
+
+// rotate shifts the rotated files, audit.log.1 being the most recent one, drops the oldest one,
+// and starts a new file.
+func (l *Log) rotate() error {
+	err := l.file.Close()
+	l.file = nil
+
+	if err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+
+	if err := os.Remove(l.rotatedPath(l.maxFiles)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Warn("failed to remove the oldest audit log:", err)
+	}
+
+	for i := l.maxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(l.rotatedPath(i), l.rotatedPath(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Warn("failed to rotate audit log:", err)
+		}
+	}
+
+	if err := os.Rename(l.path, l.rotatedPath(1)); err != nil {
+		log.Warn("failed to rotate audit log:", err)
+	}
+
+	return l.open()
+}
+
+func (l *Log) rotatedPath(n int) string {
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
+
+// openFiles opens the current file and the rotated ones, the most recent first.
+func (l *Log) openFiles() ([]*os.File, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	paths := []string{l.path}
+
+	for i := 1; i <= l.maxFiles; i++ {
+		paths = append(paths, l.rotatedPath(i))
+	}
+
+	files := make([]*os.File, 0, len(paths))
+
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			for _, opened := range files {
+				_ = opened.Close()
+			}
+
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
+		}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// readEntries returns up to limit most recent entries of the file matching the filter, the most recent first.
+func readEntries(file *os.File, filter Filter, limit int) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxEntryBytes)
+
+	for scanner.Scan() {
+		var entry models.AuditEntry
+
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A line may be truncated if the engine stopped while writing it.
+			continue
+		}
+
+		if !filter.matches(entry) {
+			continue
+		}
+
+		entries = append(entries, entry)
+
+		// Entries are appended in time order, so only the last matching ones are kept.
+		if len(entries) >= 2*limit {
+			entries = append(entries[:0], entries[len(entries)-limit:]...)
+		}
+	}
+
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log %s: %w", file.Name(), err)
+	}
+
+	slices.Reverse(entries)
+
+	return entries, nil
+}
@@ -0,0 +1,234 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func newTestLog(t *testing.T, cfg Config) *Log {
+	t.Helper()
+
+	l, err := Open(filepath.Join(t.TempDir(), fileName), cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = l.Close() })
+
+	return l
+}
+
+func entryAt(minute int, actor, action string) models.AuditEntry {
+	return models.AuditEntry{
+		Time:    time.Date(2026, 3, 1, 10, minute, 0, 0, time.UTC),
+		Actor:   actor,
+		Action:  action,
+		Outcome: models.AuditOutcomeSuccess,
+	}
+}
+
+func TestLogRecordAndList(t *testing.T) {
+	l := newTestLog(t, Config{})
+
+	require.NoError(t, l.Record(entryAt(1, "alice@example.com", "clone.create")))
+	require.NoError(t, l.Record(entryAt(2, "bob@example.com", "clone.reset")))
+	require.NoError(t, l.Record(entryAt(3, "alice@example.com", "snapshot.destroy")))
+
+	entries, err := l.List(Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "snapshot.destroy", entries[0].Action)
+	assert.Equal(t, "clone.create", entries[2].Action)
+
+	info, err := os.Stat(l.path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestLogListFilter(t *testing.T) {
+	l := newTestLog(t, Config{})
+
+	require.NoError(t, l.Record(entryAt(1, "alice@example.com", "clone.create")))
+	require.NoError(t, l.Record(entryAt(2, "bob@example.com", "clone.reset")))
+	require.NoError(t, l.Record(entryAt(3, "alice@example.com", "snapshot.destroy")))
+	require.NoError(t, l.Record(entryAt(4, "alice@example.com", "cloneish.create")))
+
+	testCases := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{
+			name:     "actor is case-insensitive",
+			filter:   Filter{Actor: "Alice@Example.com"},
+			expected: []string{"cloneish.create", "snapshot.destroy", "clone.create"},
+		},
+		{
+			name:     "resource matches its actions",
+			filter:   Filter{Action: "clone"},
+			expected: []string{"clone.reset", "clone.create"},
+		},
+		{
+			name:     "exact action",
+			filter:   Filter{Action: "clone.reset"},
+			expected: []string{"clone.reset"},
+		},
+		{
+			name: "time range",
+			filter: Filter{
+				Since: time.Date(2026, 3, 1, 10, 2, 0, 0, time.UTC),
+				Until: time.Date(2026, 3, 1, 10, 3, 0, 0, time.UTC),
+			},
+			expected: []string{"snapshot.destroy", "clone.reset"},
+		},
+		{
+			name:     "limit keeps the most recent entries",
+			filter:   Filter{Limit: 2},
+			expected: []string{"cloneish.create", "snapshot.destroy"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := l.List(tc.filter)
+			require.NoError(t, err)
+
+			actions := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				actions = append(actions, entry.Action)
+			}
+
+			assert.Equal(t, tc.expected, actions)
+		})
+	}
+}
+
+func TestLogRotation(t *testing.T) {
+	l := newTestLog(t, Config{MaxFiles: 2})
+	l.maxSize = 200
+
+	for i := range 12 {
+		require.NoError(t, l.Record(entryAt(i, "alice@example.com", "clone.create")))
+	}
+
+	assert.FileExists(t, l.rotatedPath(1))
+	assert.FileExists(t, l.rotatedPath(2))
+	assert.NoFileExists(t, l.rotatedPath(3))
+
+	info, err := os.Stat(l.path)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(200))
+
+	entries, err := l.List(Filter{})
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	assert.Less(t, len(entries), 12, "the oldest entries must be dropped")
+	assert.Equal(t, 11, entries[0].Time.Minute())
+
+	for i := 1; i < len(entries); i++ {
+		assert.True(t, entries[i-1].Time.After(entries[i].Time))
+	}
+}
+
+func TestLogListLimitAcrossRotatedFiles(t *testing.T) {
+	l := newTestLog(t, Config{MaxFiles: 5})
+	l.maxSize = 200
+
+	for i := range 12 {
+		require.NoError(t, l.Record(entryAt(i, "alice@example.com", "clone.create")))
+	}
+
+	require.FileExists(t, l.rotatedPath(2))
+
+	entries, err := l.List(Filter{Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, 11, entries[0].Time.Minute())
+	assert.Equal(t, 9, entries[2].Time.Minute())
+}
+
+func TestLogRecordReopensMissingFile(t *testing.T) {
+	l := newTestLog(t, Config{})
+
+	// A failed reopening after rotation leaves no file.
+	require.NoError(t, l.file.Close())
+	l.file = nil
+
+	require.NoError(t, l.Record(entryAt(1, "alice@example.com", "clone.create")))
+
+	entries, err := l.List(Filter{})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestLogSkipsTruncatedLines(t *testing.T) {
+	l := newTestLog(t, Config{})
+
+	require.NoError(t, l.Record(entryAt(1, "alice@example.com", "clone.create")))
+
+	_, err := l.file.WriteString(`{"time":"2026-03-01T10:02:00Z","act`)
+	require.NoError(t, err)
+
+	entries, err := l.List(Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "clone.create", entries[0].Action)
+}
+
+func TestLogReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), fileName)
+
+	l, err := Open(path, Config{})
+	require.NoError(t, err)
+	require.NoError(t, l.Record(entryAt(1, "alice@example.com", "clone.create")))
+	require.NoError(t, l.Close())
+
+	l, err = Open(path, Config{})
+	require.NoError(t, err)
+
+	defer func() { _ = l.Close() }()
+
+	require.NoError(t, l.Record(entryAt(2, "alice@example.com", "clone.destroy")))
+
+	entries, err := l.List(Filter{})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestRedact(t *testing.T) {
+	params := map[string]any{
+		"id": "clone1",
+		"db": map[string]any{
+			"username": "john",
+			"password": "secret",
+		},
+		"accessToken": "abc",
+		"extra": []any{
+			map[string]any{"AWS_SECRET_ACCESS_KEY": "xyz", "region": "us-east-1"},
+		},
+	}
+
+	redactedParams := Redact(params)
+
+	assert.Equal(t, "clone1", redactedParams["id"])
+	assert.Equal(t, "[REDACTED]", redactedParams["accessToken"])
+	assert.Equal(t, map[string]any{"username": "john", "password": "[REDACTED]"}, redactedParams["db"])
+	assert.Equal(t, []any{map[string]any{"AWS_SECRET_ACCESS_KEY": "[REDACTED]", "region": "us-east-1"}}, redactedParams["extra"])
+}
+
+func TestAnnotation(t *testing.T) {
+	ctx, annotation := WithAnnotation(t.Context())
+
+	SetTarget(ctx, "clone1")
+	SetAction(ctx, "clone.protect")
+
+	assert.Equal(t, &Annotation{Action: "clone.protect", Target: "clone1"}, annotation)
+
+	// Handlers outside of audited routes are not affected.
+	SetTarget(t.Context(), "clone2")
+}
@@ -0,0 +1,85 @@
+/*
+2026 © Postgres.ai
+*/
+
+package audit
+
+import (
+	"context"
+	"strings"
+)
+
+// redacted replaces values of parameters that may hold credentials.
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are parts of parameter names whose values are never recorded.
+var sensitiveKeys = []string{"password", "secret", "token", "credential", "orgkey", "accesskey", "privatekey"}
+
+type annotationKey struct{}
+
+// Annotation holds details of an audited action that are known only to the handler performing it.
+type Annotation struct {
+	Action string
+	Target string
+}
+
+// WithAnnotation returns a context in which handlers can refine the audited action and its target.
+func WithAnnotation(ctx context.Context) (context.Context, *Annotation) {
+	annotation := &Annotation{}
+
+	return context.WithValue(ctx, annotationKey{}, annotation), annotation
+}
+
+// SetTarget records the object of the audited action, e.g. the ID of a created clone.
+func SetTarget(ctx context.Context, target string) {
+	if annotation, ok := ctx.Value(annotationKey{}).(*Annotation); ok {
+		annotation.Target = target
+	}
+}
+
+// SetAction refines the audited action, e.g. "clone.protect" instead of "clone.update".
+func SetAction(ctx context.Context, action string) {
+	if annotation, ok := ctx.Value(annotationKey{}).(*Annotation); ok {
+		annotation.Action = action
+	}
+}
+
+// Redact replaces values of parameters that may hold credentials, including nested ones.
+func Redact(params map[string]any) map[string]any {
+	for key, value := range params {
+		if isSensitive(key) {
+			params[key] = redacted
+			continue
+		}
+
+		params[key] = redactValue(value)
+	}
+
+	return params
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return Redact(v)
+
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+
+	return value
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
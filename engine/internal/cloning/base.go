@@ -19,11 +19,16 @@ import (
 	_ "github.com/lib/pq" // Register Postgres database driver.
 	"github.com/pkg/errors"
 	"github.com/rs/xid"
+	"go.opentelemetry.io/otel/attribute"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/operations"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/opmetrics"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/db"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/telemetry"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/tracing"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
@@ -41,11 +46,16 @@ const (
 
 // Config contains a cloning configuration.
 type Config struct {
-	MaxIdleMinutes                 uint   `yaml:"maxIdleMinutes"`
-	AccessHost                     string `yaml:"accessHost"`
-	ProtectionLeaseDurationMinutes uint   `yaml:"protectionLeaseDurationMinutes"`
-	ProtectionMaxDurationMinutes   uint   `yaml:"protectionMaxDurationMinutes"`
-	ProtectionExpiryWarningMinutes uint   `yaml:"protectionExpiryWarningMinutes"`
+	MaxIdleMinutes                 uint        `yaml:"maxIdleMinutes"`
+	AccessHost                     string      `yaml:"accessHost"`
+	ProtectionLeaseDurationMinutes uint        `yaml:"protectionLeaseDurationMinutes"`
+	ProtectionMaxDurationMinutes   uint        `yaml:"protectionMaxDurationMinutes"`
+	ProtectionExpiryWarningMinutes uint        `yaml:"protectionExpiryWarningMinutes"`
+	Quotas                         Quotas      `yaml:"quotas"`
+	Hibernation                    Hibernation `yaml:"hibernation"`
+
+	// AllowedPostgresParameters restricts the Postgres parameters users can set for clones; empty allows any.
+	AllowedPostgresParameters []string `yaml:"allowedPostgresParameters"`
 }
 
 // Base provides cloning service.
@@ -59,6 +69,10 @@ type Base struct {
 	tm          *telemetry.Agent
 	observingCh chan string
 	webhookCh   chan webhooks.EventTyper
+	configMu    sync.Mutex
+	hibernateMu sync.Mutex
+	settingsMu  sync.RWMutex
+	settings    map[string]postgres.Setting
 }
 
 // NewBase instances a new Base service.
@@ -82,6 +96,9 @@ func NewBase(cfg *Config, global *global.Config, provision *provision.Provisione
 func (c *Base) Reload(cfg Config, global global.Config) {
 	*c.config = cfg
 	*c.global = global
+
+	// The Docker image may have changed, so pg_settings metadata is read again.
+	c.setCachedSettings(nil)
 }
 
 // Run initializes and runs cloning component.
@@ -110,6 +127,8 @@ func (c *Base) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to revise port pool: %w", err)
 	}
 
+	c.reserveHibernatedPorts()
+
 	go c.runIdleCheck(ctx)
 	go c.runProtectionLeaseCheck(ctx)
 
@@ -146,8 +165,17 @@ func (c *Base) GetLatestSnapshot() (*models.Snapshot, error) {
 	return snapshot, err
 }
 
-// CreateClone creates a new clone.
-func (c *Base) CreateClone(cloneRequest *types.CloneCreateRequest) (*models.Clone, error) {
+// CreateClone creates a new clone. The clone is started in the background,
+// so the operation carried by the context is traced until the clone is ready.
+func (c *Base) CreateClone(ctx context.Context, cloneRequest *types.CloneCreateRequest) (_ *models.Clone, err error) {
+	ctx, span := tracing.Start(context.WithoutCancel(ctx), "cloning.CreateClone")
+
+	defer func() {
+		if err != nil {
+			tracing.End(span, err)
+		}
+	}()
+
 	cloneRequest.ID = strings.TrimSpace(cloneRequest.ID)
 
 	if _, ok := c.findWrapper(cloneRequest.ID); ok {
@@ -158,9 +186,11 @@ func (c *Base) CreateClone(cloneRequest *types.CloneCreateRequest) (*models.Clon
 		cloneRequest.ID = xid.New().String()
 	}
 
+	span.SetAttributes(attribute.String("clone.id", cloneRequest.ID))
+
 	createdAt := time.Now()
 
-	err := c.fetchSnapshots()
+	err = c.fetchSnapshots()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to fetch snapshots")
 	}
@@ -181,18 +211,47 @@ func (c *Base) CreateClone(cloneRequest *types.CloneCreateRequest) (*models.Clon
 		cloneRequest.Branch = snapshot.Branch
 	}
 
+	if len(cloneRequest.ExtraConf) > 0 {
+		settings, err := c.settingsForValidation(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get Postgres settings metadata")
+		}
+
+		if err := c.validatePostgresConfig(cloneRequest.ExtraConf, settings); err != nil {
+			return nil, models.New(models.ErrCodeBadRequest, err.Error())
+		}
+	}
+
+	protected := cloneRequest.Protected
+
+	if cloneRequest.Template != "" {
+		tmpl, ok := c.provision.Template(cloneRequest.Template)
+		if !ok {
+			return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("clone template %q not found", cloneRequest.Template))
+		}
+
+		if !tmpl.AllowsBranch(cloneRequest.Branch) {
+			return nil, models.New(models.ErrCodeBadRequest,
+				fmt.Sprintf("clone template %q is not allowed on branch %q", cloneRequest.Template, cloneRequest.Branch))
+		}
+
+		protected = protected || tmpl.Protected
+	}
+
 	var protectedTill *models.LocalTime
-	if cloneRequest.Protected {
+	if protected {
 		protectedTill = c.calculateProtectionTime(cloneRequest.ProtectionDurationMinutes)
 	}
 
 	clone := &models.Clone{
-		ID:            cloneRequest.ID,
-		Snapshot:      snapshot,
-		Branch:        cloneRequest.Branch,
-		Protected:     cloneRequest.Protected,
-		ProtectedTill: protectedTill,
-		CreatedAt:     models.NewLocalTime(createdAt),
+		ID:             cloneRequest.ID,
+		Snapshot:       snapshot,
+		Branch:         cloneRequest.Branch,
+		Template:       cloneRequest.Template,
+		PostgresConfig: cloneRequest.ExtraConf,
+		Protected:      protected,
+		ProtectedTill:  protectedTill,
+		CreatedAt:      models.NewLocalTime(createdAt),
 		Status: models.Status{
 			Code:    models.StatusCreating,
 			Message: models.CloneMessageCreating,
@@ -208,7 +267,18 @@ func (c *Base) CreateClone(cloneRequest *types.CloneCreateRequest) (*models.Clon
 	w := NewCloneWrapper(clone, createdAt)
 	cloneID := clone.ID
 
-	c.setWrapper(clone.ID, w)
+	// Check quotas and register the clone atomically, so concurrent requests cannot overrun them.
+	c.cloneMutex.Lock()
+
+	if err := c.checkCreateQuotasLocked(clone); err != nil {
+		c.cloneMutex.Unlock()
+		return nil, err
+	}
+
+	c.clones[clone.ID] = w
+	c.cloneMutex.Unlock()
+
+	c.emitStatusChange(cloneID, "", clone.Status)
 
 	ephemeralUser := resources.EphemeralUser{
 		Name:        cloneRequest.DB.Username,
@@ -219,11 +289,20 @@ func (c *Base) CreateClone(cloneRequest *types.CloneCreateRequest) (*models.Clon
 
 	c.IncrementCloneNumber(clone.Snapshot.ID)
 
+	// The operation of the request ends when the clone is ready and can be canceled until then.
+	op := operations.FromContext(ctx)
+	op.SetTarget(cloneID)
+	ctx = op.WithCancel(ctx)
+	op.Detach()
+
 	go func() {
-		session, err := c.provision.StartSession(clone, ephemeralUser, cloneRequest.ExtraConf)
+		session, err := c.provision.StartSession(ctx, clone, ephemeralUser, clone.PostgresConfig)
+		opmetrics.ObserveOperation(opmetrics.ResourceClone, "create", createdAt, err)
+		tracing.End(span, err)
+
 		if err != nil {
 			// TODO(anatoly): Empty room case.
-			log.Errf("failed to start session: %v", err)
+			log.ErrCtx(ctx, "failed to start session:", err)
 
 			if updateErr := c.UpdateCloneStatus(cloneID, models.Status{
 				Code:    models.StatusFatal,
@@ -232,16 +311,25 @@ func (c *Base) CreateClone(cloneRequest *types.CloneCreateRequest) (*models.Clon
 				log.Errf("failed to update clone status: %v", updateErr)
 			}
 
+			op.Finish(err)
+
 			return
 		}
 
 		c.fillCloneSession(cloneID, session)
+		c.emitStatusChange(cloneID, models.StatusCreating, models.Status{
+			Code:    models.StatusOK,
+			Message: models.CloneMessageOK,
+		})
 		c.SaveClonesState()
+		c.loadSettings(session, clone)
+		op.Finish(nil)
 
 		c.webhookCh <- webhooks.CloneEvent{
 			BasicEvent: webhooks.BasicEvent{
 				EventType: webhooks.CloneCreatedEvent,
 				EntityID:  cloneID,
+				TraceID:   tracing.TraceID(ctx),
 			},
 			Host:          c.config.AccessHost,
 			Port:          session.Port,
@@ -285,7 +373,7 @@ func (c *Base) fillCloneSession(cloneID string, session *resources.Session) {
 
 	clone.Metadata = models.CloneMetadata{
 		CloningTime:                    w.TimeStartedAt.Sub(w.TimeCreatedAt).Seconds(),
-		MaxIdleMinutes:                 c.config.MaxIdleMinutes,
+		MaxIdleMinutes:                 c.maxIdleMinutes(clone),
 		ProtectionLeaseDurationMinutes: c.config.ProtectionLeaseDurationMinutes,
 		ProtectionMaxDurationMinutes:   c.config.ProtectionMaxDurationMinutes,
 	}
@@ -316,8 +404,9 @@ func connectionString(host, port, username, dbname string) string {
 		db.EscapeLibpqValue(dbname))
 }
 
-// DestroyClone destroys clone.
-func (c *Base) DestroyClone(cloneID string) error {
+// DestroyClone destroys clone in the background. The operation carried by the context ends
+// when the clone is destroyed.
+func (c *Base) DestroyClone(ctx context.Context, cloneID string) error {
 	w, ok := c.findWrapper(cloneID)
 	if !ok {
 		return models.New(models.ErrCodeNotFound, "clone not found")
@@ -331,7 +420,12 @@ func (c *Base) DestroyClone(cloneID string) error {
 		return err
 	}
 
-	go c.destroyClone(cloneID, w)
+	op := operations.FromContext(ctx)
+	op.Detach()
+
+	go func() {
+		op.Finish(c.destroyClone(cloneID, w))
+	}()
 
 	return nil
 }
@@ -381,13 +475,14 @@ func (c *Base) DestroyCloneSync(cloneID string) error {
 		return err
 	}
 
-	c.destroyClone(cloneID, w)
-
-	return nil
+	return c.destroyClone(cloneID, w)
 }
 
-func (c *Base) destroyClone(cloneID string, w *CloneWrapper) {
+func (c *Base) destroyClone(cloneID string, w *CloneWrapper) error {
+	startedAt := time.Now()
+
 	if err := c.provision.StopSession(w.Session, w.Clone); err != nil {
+		opmetrics.ObserveOperation(opmetrics.ResourceClone, "destroy", startedAt, err)
 		log.Errf("failed to delete clone: %v", err)
 
 		if updateErr := c.UpdateCloneStatus(cloneID, models.Status{
@@ -397,7 +492,7 @@ func (c *Base) destroyClone(cloneID string, w *CloneWrapper) {
 			log.Errf("failed to update clone status: %v", updateErr)
 		}
 
-		return
+		return errors.Wrap(err, "failed to stop session")
 	}
 
 	c.deleteClone(cloneID)
@@ -408,10 +503,13 @@ func (c *Base) destroyClone(cloneID string, w *CloneWrapper) {
 
 	c.observingCh <- cloneID
 
-	if err := c.provision.CleanupCloneDataset(w.Clone, w.Clone.Snapshot.Pool); err != nil {
-		log.Errf("failed to cleanup clone dataset: %v", err)
+	cleanupErr := c.provision.CleanupCloneDataset(w.Clone, w.Clone.Snapshot.Pool)
+	if cleanupErr != nil {
+		log.Errf("failed to cleanup clone dataset: %v", cleanupErr)
 	}
 
+	opmetrics.ObserveOperation(opmetrics.ResourceClone, "destroy", startedAt, cleanupErr)
+
 	c.SaveClonesState()
 
 	c.webhookCh <- webhooks.CloneEvent{
@@ -425,6 +523,8 @@ func (c *Base) destroyClone(cloneID string, w *CloneWrapper) {
 		DBName:        w.Clone.DB.DBName,
 		ContainerName: cloneID,
 	}
+
+	return errors.Wrap(cleanupErr, "failed to cleanup clone dataset")
 }
 
 // GetClone returns clone by ID.
@@ -470,6 +570,13 @@ func (c *Base) UpdateClone(id string, patch types.CloneUpdateRequest) (*models.C
 
 	c.cloneMutex.Lock()
 
+	if patch.Protected && !w.Clone.IsProtected() {
+		if err := c.checkProtectionQuotaLocked(w.Clone.DB.OwnerUser, id); err != nil {
+			c.cloneMutex.Unlock()
+			return nil, err
+		}
+	}
+
 	if patch.Protected {
 		w.Clone.Protected = true
 		w.Clone.ProtectedTill = c.calculateProtectionTime(patch.ProtectionDurationMinutes)
@@ -499,14 +606,18 @@ func (c *Base) calculateProtectionTime(durationMinutes *uint) *models.LocalTime
 // UpdateCloneStatus updates the clone status.
 func (c *Base) UpdateCloneStatus(cloneID string, status models.Status) error {
 	c.cloneMutex.Lock()
-	defer c.cloneMutex.Unlock()
 
 	w, ok := c.clones[cloneID]
 	if !ok {
+		c.cloneMutex.Unlock()
 		return errors.Errorf("clone %q not found", cloneID)
 	}
 
+	previous := w.Clone.Status.Code
 	w.Clone.Status = status
+	c.cloneMutex.Unlock()
+
+	c.emitStatusChange(cloneID, previous, status)
 
 	return nil
 }
@@ -526,8 +637,17 @@ func (c *Base) UpdateCloneSnapshot(cloneID string, snapshot *models.Snapshot) er
 	return nil
 }
 
-// ResetClone resets clone to chosen snapshot.
-func (c *Base) ResetClone(cloneID string, resetOptions types.ResetCloneRequest) error {
+// ResetClone resets clone to chosen snapshot. The clone is reset in the background,
+// so the operation carried by the context is traced until the clone is ready.
+func (c *Base) ResetClone(ctx context.Context, cloneID string, resetOptions types.ResetCloneRequest) (err error) {
+	ctx, span := tracing.Start(context.WithoutCancel(ctx), "cloning.ResetClone", attribute.String("clone.id", cloneID))
+
+	defer func() {
+		if err != nil {
+			tracing.End(span, err)
+		}
+	}()
+
 	w, ok := c.findWrapper(cloneID)
 	if !ok {
 		return models.New(models.ErrCodeNotFound, "the clone not found")
@@ -567,6 +687,11 @@ func (c *Base) ResetClone(cloneID string, resetOptions types.ResetCloneRequest)
 		c.cloneMutex.Unlock()
 	}
 
+	startedAt := time.Now()
+
+	op := operations.FromContext(ctx)
+	op.Detach()
+
 	go func() {
 		var originalSnapshotID string
 
@@ -574,9 +699,12 @@ func (c *Base) ResetClone(cloneID string, resetOptions types.ResetCloneRequest)
 			originalSnapshotID = w.Clone.Snapshot.ID
 		}
 
-		snapshot, err := c.provision.ResetSession(w.Session, w.Clone, snapshotID)
+		snapshot, err := c.provision.ResetSession(ctx, w.Session, w.Clone, snapshotID)
+		opmetrics.ObserveOperation(opmetrics.ResourceClone, "reset", startedAt, err)
+		tracing.End(span, err)
+
 		if err != nil {
-			log.Errf("failed to reset clone: %v", err)
+			log.ErrCtx(ctx, "failed to reset clone:", err)
 
 			if updateErr := c.UpdateCloneStatus(cloneID, models.Status{
 				Code:    models.StatusFatal,
@@ -585,11 +713,15 @@ func (c *Base) ResetClone(cloneID string, resetOptions types.ResetCloneRequest)
 				log.Errf("failed to update clone status: %v", updateErr)
 			}
 
+			op.Finish(err)
+
 			return
 		}
 
 		c.cloneMutex.Lock()
 		w.Clone.Snapshot = snapshot
+		w.Clone.HibernatedAt = nil
+		w.TimeWokenAt = time.Now()
 		c.cloneMutex.Unlock()
 		c.decrementCloneNumber(originalSnapshotID)
 		c.IncrementCloneNumber(snapshot.ID)
@@ -602,11 +734,13 @@ func (c *Base) ResetClone(cloneID string, resetOptions types.ResetCloneRequest)
 		}
 
 		c.SaveClonesState()
+		op.Finish(nil)
 
 		c.webhookCh <- webhooks.CloneEvent{
 			BasicEvent: webhooks.BasicEvent{
 				EventType: webhooks.CloneResetEvent,
 				EntityID:  cloneID,
+				TraceID:   tracing.TraceID(ctx),
 			},
 			Host:          c.config.AccessHost,
 			Port:          w.Session.Port,
@@ -831,16 +965,13 @@ func (c *Base) getExpectedCloningTime() float64 {
 }
 
 func (c *Base) runIdleCheck(ctx context.Context) {
-	if c.config.MaxIdleMinutes == 0 {
-		return
-	}
-
 	idleTimer := time.NewTimer(idleCheckDuration)
 
 	for {
 		select {
 		case <-idleTimer.C:
 			c.destroyIdleClones(ctx)
+			c.hibernateIdleClones(ctx)
 			idleTimer.Reset(idleCheckDuration)
 			c.SaveClonesState()
 
@@ -852,7 +983,7 @@ func (c *Base) runIdleCheck(ctx context.Context) {
 }
 
 func (c *Base) destroyIdleClones(ctx context.Context) {
-	for _, cloneWrapper := range c.clones {
+	for _, cloneWrapper := range c.cloneWrappers() {
 		select {
 		case <-ctx.Done():
 			return
@@ -866,7 +997,7 @@ func (c *Base) destroyIdleClones(ctx context.Context) {
 			if isIdleClone {
 				log.Msg(fmt.Sprintf("Idle clone %q is going to be removed.", cloneWrapper.Clone.ID))
 
-				if err = c.DestroyClone(cloneWrapper.Clone.ID); err != nil {
+				if err = c.DestroyClone(ctx, cloneWrapper.Clone.ID); err != nil {
 					log.Errf("failed to destroy clone: %v", err)
 					continue
 				}
@@ -875,28 +1006,56 @@ func (c *Base) destroyIdleClones(ctx context.Context) {
 	}
 }
 
+// cloneWrappers returns a snapshot of the clone list, so it can be iterated while clones are changed concurrently.
+func (c *Base) cloneWrappers() []*CloneWrapper {
+	c.cloneMutex.RLock()
+	defer c.cloneMutex.RUnlock()
+
+	wrappers := make([]*CloneWrapper, 0, len(c.clones))
+
+	for _, w := range c.clones {
+		wrappers = append(wrappers, w)
+	}
+
+	return wrappers
+}
+
 // isIdleClone checks if clone is idle.
 func (c *Base) isIdleClone(wrapper *CloneWrapper) (bool, error) {
-	currentTime := time.Now()
+	maxIdleMinutes := c.maxIdleMinutes(wrapper.Clone)
+	if maxIdleMinutes == 0 {
+		return false, nil
+	}
 
-	idleDuration := time.Duration(c.config.MaxIdleMinutes) * time.Minute
-	minimumTime := currentTime.Add(-idleDuration)
+	status := c.cloneStatus(wrapper)
 
-	if wrapper.Clone.IsProtected() || wrapper.Clone.Status.Code == models.StatusExporting || wrapper.TimeStartedAt.After(minimumTime) ||
-		c.hasDependentSnapshots(wrapper) {
+	// Hibernated clones are kept parked until they are woken up or destroyed explicitly.
+	if status == models.StatusHibernated || status == models.StatusWaking || status == models.StatusExporting ||
+		wrapper.Clone.IsProtected() || c.hasDependentSnapshots(wrapper) {
 		return false, nil
 	}
 
-	session := wrapper.Session
-
-	if session == nil {
-		if wrapper.Clone.Status.Code == models.StatusFatal {
+	if wrapper.Session == nil {
+		if status == models.StatusFatal {
 			return true, nil
 		}
 
 		return false, errors.New("failed to get clone session")
 	}
 
+	return c.isIdleFor(wrapper, maxIdleMinutes)
+}
+
+// isIdleFor checks if the clone has had no activity for the given number of minutes.
+func (c *Base) isIdleFor(wrapper *CloneWrapper, idleMinutes uint) (bool, error) {
+	minimumTime := time.Now().Add(-time.Duration(idleMinutes) * time.Minute)
+
+	if wrapper.activeSince().After(minimumTime) {
+		return false, nil
+	}
+
+	session := wrapper.Session
+
 	if _, err := c.provision.LastSessionActivity(session, wrapper.Clone.Branch, wrapper.Clone.ID, wrapper.Clone.Revision,
 		minimumTime); err != nil {
 		if err == pglog.ErrNotFound {
@@ -912,6 +1071,17 @@ func (c *Base) isIdleClone(wrapper *CloneWrapper) (bool, error) {
 	return false, nil
 }
 
+// maxIdleMinutes returns the idle timeout of the clone: the one of its template, if set, or the global one.
+func (c *Base) maxIdleMinutes(clone *models.Clone) uint {
+	if clone.Template != "" {
+		if tmpl, ok := c.provision.Template(clone.Template); ok && tmpl.MaxIdleMinutes > 0 {
+			return tmpl.MaxIdleMinutes
+		}
+	}
+
+	return c.config.MaxIdleMinutes
+}
+
 const pgDriverName = "postgres"
 
 // hasNotQueryActivity opens connection and checks if there is no any query running by a user.
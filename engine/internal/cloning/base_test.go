@@ -5,6 +5,7 @@
 package cloning
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -12,6 +13,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
 
@@ -370,3 +373,21 @@ func TestWithBranchDeletionLock(t *testing.T) {
 		assert.Contains(t, err.Error(), "dependent clone")
 	})
 }
+
+func TestMaxIdleMinutes(t *testing.T) {
+	prov, err := provision.New(context.Background(), &provision.Config{
+		PortPool: provision.PortPool{From: 6000, To: 6001},
+		Templates: map[string]provision.CloneTemplate{
+			"long":    {MaxIdleMinutes: 480},
+			"default": {Protected: true},
+		},
+	}, &resources.DB{}, nil, nil, "", "", "")
+	require.NoError(t, err)
+
+	c := &Base{config: &Config{MaxIdleMinutes: 120}, provision: prov}
+
+	assert.Equal(t, uint(120), c.maxIdleMinutes(&models.Clone{}))
+	assert.Equal(t, uint(480), c.maxIdleMinutes(&models.Clone{Template: "long"}))
+	assert.Equal(t, uint(120), c.maxIdleMinutes(&models.Clone{Template: "default"}))
+	assert.Equal(t, uint(120), c.maxIdleMinutes(&models.Clone{Template: "removed"}))
+}
@@ -0,0 +1,70 @@
+/*
+2026 © Postgres.ai
+*/
+
+package cloning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// ConnectClone prepares the clone to accept a new connection routed by the engine.
+// A hibernated clone is woken up if it is enabled, and the connection is recorded as clone activity.
+func (c *Base) ConnectClone(ctx context.Context, cloneID string) (*models.Clone, error) {
+	w, ok := c.findWrapper(cloneID)
+	if !ok {
+		return nil, models.New(models.ErrCodeNotFound, "clone not found")
+	}
+
+	if status := c.cloneStatus(w); status == models.StatusHibernated || status == models.StatusWaking {
+		if !c.config.Hibernation.WakeOnConnect {
+			return nil, models.New(models.ErrCodeBadRequest, "clone is hibernated: wake it up or enable wakeOnConnect")
+		}
+
+		if _, err := c.WakeClone(ctx, cloneID); err != nil {
+			return nil, err
+		}
+	}
+
+	if status := c.cloneStatus(w); status != models.StatusOK || w.Session == nil {
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("clone is not ready: %s", status))
+	}
+
+	if err := c.UpdateCloneActivity(cloneID); err != nil {
+		return nil, err
+	}
+
+	return w.Clone, nil
+}
+
+// UpdateCloneActivity records a connection to the clone, so the clone is not treated as idle.
+func (c *Base) UpdateCloneActivity(cloneID string) error {
+	w, ok := c.findWrapper(cloneID)
+	if !ok {
+		return models.New(models.ErrCodeNotFound, "clone not found")
+	}
+
+	c.cloneMutex.Lock()
+	w.TimeConnectedAt = time.Now()
+	c.cloneMutex.Unlock()
+
+	return nil
+}
+
+// HasClone checks if the clone exists.
+func (c *Base) HasClone(cloneID string) bool {
+	_, ok := c.findWrapper(cloneID)
+
+	return ok
+}
+
+func (c *Base) cloneStatus(w *CloneWrapper) models.StatusCode {
+	c.cloneMutex.RLock()
+	defer c.cloneMutex.RUnlock()
+
+	return w.Clone.Status.Code
+}
@@ -0,0 +1,44 @@
+package cloning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestConnectClone(t *testing.T) {
+	c := &Base{
+		config: &Config{},
+		clones: map[string]*CloneWrapper{
+			"creating":   {Clone: &models.Clone{ID: "creating", Status: models.Status{Code: models.StatusCreating}}},
+			"hibernated": {Clone: &models.Clone{ID: "hibernated", Status: models.Status{Code: models.StatusHibernated}}, Session: &resources.Session{}},
+			"running":    {Clone: &models.Clone{ID: "running", Status: models.Status{Code: models.StatusOK}}, Session: &resources.Session{}},
+		},
+	}
+
+	_, err := c.ConnectClone(context.Background(), "missing")
+	assert.ErrorContains(t, err, "clone not found")
+
+	_, err = c.ConnectClone(context.Background(), "creating")
+	assert.ErrorContains(t, err, "clone is not ready")
+
+	_, err = c.ConnectClone(context.Background(), "hibernated")
+	assert.ErrorContains(t, err, "clone is hibernated")
+
+	before := time.Now()
+
+	clone, err := c.ConnectClone(context.Background(), "running")
+	require.NoError(t, err)
+	assert.Equal(t, "running", clone.ID)
+	assert.False(t, c.clones["running"].TimeConnectedAt.Before(before))
+	assert.Equal(t, c.clones["running"].TimeConnectedAt, c.clones["running"].activeSince())
+
+	assert.True(t, c.HasClone("running"))
+	assert.False(t, c.HasClone("missing"))
+}
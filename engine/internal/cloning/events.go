@@ -0,0 +1,40 @@
+/*
+2026 © Postgres.ai
+*/
+
+package cloning
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// statusEventTimeout bounds waiting for the webhook service so that status updates never stall on it.
+const statusEventTimeout = 10 * time.Second
+
+// emitStatusChange reports a transition of the clone status. Updates that keep the status are not reported.
+func (c *Base) emitStatusChange(cloneID string, previous models.StatusCode, status models.Status) {
+	if c.webhookCh == nil || previous == status.Code {
+		return
+	}
+
+	event := webhooks.CloneStatusEvent{
+		BasicEvent: webhooks.BasicEvent{
+			EventType: webhooks.CloneStatusChangeEvent,
+			EntityID:  cloneID,
+		},
+		Status:         string(status.Code),
+		PreviousStatus: string(previous),
+		Message:        status.Message,
+	}
+
+	select {
+	case c.webhookCh <- event:
+	case <-time.After(statusEventTimeout):
+		log.Warn(fmt.Sprintf("webhook channel is busy, dropped %s event", event.GetType()))
+	}
+}
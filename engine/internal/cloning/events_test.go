@@ -0,0 +1,35 @@
+package cloning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestUpdateCloneStatusEmitsStatusChange(t *testing.T) {
+	webhookCh := make(chan webhooks.EventTyper, 2)
+
+	c := &Base{clones: make(map[string]*CloneWrapper), webhookCh: webhookCh}
+	c.setWrapper("clone1", &CloneWrapper{Clone: &models.Clone{ID: "clone1", Status: models.Status{Code: models.StatusOK}}})
+
+	// Keeping the status is not a transition.
+	require.NoError(t, c.UpdateCloneStatus("clone1", models.Status{Code: models.StatusOK}))
+	require.NoError(t, c.UpdateCloneStatus("clone1", models.Status{
+		Code:    models.StatusResetting,
+		Message: models.CloneMessageResetting,
+	}))
+
+	require.Len(t, webhookCh, 1)
+
+	event := <-webhookCh
+	assert.Equal(t, webhooks.CloneStatusEvent{
+		BasicEvent:     webhooks.BasicEvent{EventType: webhooks.CloneStatusChangeEvent, EntityID: "clone1"},
+		Status:         string(models.StatusResetting),
+		PreviousStatus: string(models.StatusOK),
+		Message:        models.CloneMessageResetting,
+	}, event)
+}
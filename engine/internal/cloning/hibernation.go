@@ -0,0 +1,217 @@
+/*
+2026 © Postgres.ai
+*/
+
+package cloning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/opmetrics"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// Hibernation defines hibernation of idle clones: the container of a clone is stopped,
+// while its dataset and port are kept, so the clone can be woken up later. Hibernated clones are not destroyed as idle.
+type Hibernation struct {
+	// IdleMinutes defines the inactivity period after which a clone is hibernated; 0 disables automatic hibernation.
+	IdleMinutes uint `yaml:"idleMinutes"`
+
+	// WakeOnConnect wakes a hibernated clone up when the Postgres router routes a connection to it.
+	WakeOnConnect bool `yaml:"wakeOnConnect"`
+}
+
+// HibernateClone stops the container of a running clone, keeping its dataset and port.
+func (c *Base) HibernateClone(ctx context.Context, cloneID string) (*models.Clone, error) {
+	w, ok := c.findWrapper(cloneID)
+	if !ok {
+		return nil, models.New(models.ErrCodeNotFound, "clone not found")
+	}
+
+	c.hibernateMu.Lock()
+	defer c.hibernateMu.Unlock()
+
+	c.cloneMutex.RLock()
+	status := w.Clone.Status.Code
+	c.cloneMutex.RUnlock()
+
+	if status == models.StatusHibernated {
+		return w.Clone, nil
+	}
+
+	if status != models.StatusOK || w.Session == nil {
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("clone is not ready: %s", status))
+	}
+
+	startedAt := time.Now()
+	err := c.provision.HibernateSession(ctx, w.Clone)
+
+	opmetrics.ObserveOperation(opmetrics.ResourceClone, "hibernate", startedAt, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.cloneMutex.Lock()
+	w.Clone.Status = models.Status{
+		Code:    models.StatusHibernated,
+		Message: models.CloneMessageHibernated,
+	}
+	w.Clone.HibernatedAt = models.NewLocalTime(time.Now())
+	c.cloneMutex.Unlock()
+
+	c.emitStatusChange(cloneID, models.StatusOK, models.Status{
+		Code:    models.StatusHibernated,
+		Message: models.CloneMessageHibernated,
+	})
+
+	c.SaveClonesState()
+
+	log.Msg(fmt.Sprintf("Clone %s has been hibernated", cloneID))
+
+	return w.Clone, nil
+}
+
+// WakeClone starts the container of a hibernated clone and waits until it accepts connections.
+func (c *Base) WakeClone(ctx context.Context, cloneID string) (*models.Clone, error) {
+	w, ok := c.findWrapper(cloneID)
+	if !ok {
+		return nil, models.New(models.ErrCodeNotFound, "clone not found")
+	}
+
+	c.hibernateMu.Lock()
+	defer c.hibernateMu.Unlock()
+
+	c.cloneMutex.RLock()
+	status := w.Clone.Status.Code
+	c.cloneMutex.RUnlock()
+
+	if status == models.StatusOK {
+		return w.Clone, nil
+	}
+
+	if status != models.StatusHibernated {
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("clone is not hibernated: %s", status))
+	}
+
+	if err := c.UpdateCloneStatus(cloneID, models.Status{
+		Code:    models.StatusWaking,
+		Message: models.CloneMessageWaking,
+	}); err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	err := c.provision.WakeSession(ctx, w.Session, w.Clone)
+
+	opmetrics.ObserveOperation(opmetrics.ResourceClone, "wake", startedAt, err)
+
+	if err != nil {
+		if updateErr := c.UpdateCloneStatus(cloneID, models.Status{
+			Code:    models.StatusHibernated,
+			Message: models.CloneMessageHibernated,
+		}); updateErr != nil {
+			log.Errf("failed to update clone status: %v", updateErr)
+		}
+
+		return nil, fmt.Errorf("failed to wake up clone: %w", err)
+	}
+
+	c.cloneMutex.Lock()
+	w.Clone.Status = models.Status{
+		Code:    models.StatusOK,
+		Message: models.CloneMessageOK,
+	}
+	w.Clone.HibernatedAt = nil
+	w.TimeWokenAt = time.Now()
+	c.cloneMutex.Unlock()
+
+	c.emitStatusChange(cloneID, models.StatusWaking, models.Status{
+		Code:    models.StatusOK,
+		Message: models.CloneMessageOK,
+	})
+	c.SaveClonesState()
+
+	log.Msg(fmt.Sprintf("Clone %s has been woken up", cloneID))
+
+	return w.Clone, nil
+}
+
+// reserveHibernatedPorts keeps the ports of hibernated clones reserved after a restart,
+// so their containers publish the same ports when they are woken up.
+func (c *Base) reserveHibernatedPorts() {
+	for _, w := range c.hibernatedClones() {
+		if err := c.provision.ReservePort(w.Session.Port); err != nil {
+			log.Err(fmt.Sprintf("failed to reserve port of hibernated clone %s: %v", w.Clone.ID, err))
+		}
+	}
+}
+
+func (c *Base) hibernatedClones() []*CloneWrapper {
+	c.cloneMutex.RLock()
+	defer c.cloneMutex.RUnlock()
+
+	hibernated := []*CloneWrapper{}
+
+	for _, w := range c.clones {
+		if isHibernated(w) {
+			hibernated = append(hibernated, w)
+		}
+	}
+
+	return hibernated
+}
+
+func isHibernated(w *CloneWrapper) bool {
+	return w.Clone != nil && w.Session != nil && w.Clone.Status.Code == models.StatusHibernated
+}
+
+// hibernateIdleClones hibernates running clones that have been idle longer than configured.
+// Unlike deletion, hibernation also applies to protected clones.
+func (c *Base) hibernateIdleClones(ctx context.Context) {
+	if c.config.Hibernation.IdleMinutes == 0 {
+		return
+	}
+
+	for _, w := range c.runningClones() {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		isIdle, err := c.isIdleFor(w, c.config.Hibernation.IdleMinutes)
+		if err != nil {
+			log.Errf("failed to check idleness of clone %s: %v", w.Clone.ID, err)
+			continue
+		}
+
+		if !isIdle {
+			continue
+		}
+
+		log.Msg(fmt.Sprintf("Idle clone %q is going to be hibernated.", w.Clone.ID))
+
+		if _, err := c.HibernateClone(ctx, w.Clone.ID); err != nil {
+			log.Errf("failed to hibernate clone: %v", err)
+		}
+	}
+}
+
+func (c *Base) runningClones() []*CloneWrapper {
+	c.cloneMutex.RLock()
+	defer c.cloneMutex.RUnlock()
+
+	running := []*CloneWrapper{}
+
+	for _, w := range c.clones {
+		if w.Clone != nil && w.Session != nil && w.Clone.Status.Code == models.StatusOK {
+			running = append(running, w)
+		}
+	}
+
+	return running
+}
@@ -0,0 +1,83 @@
+package cloning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestHibernationStateChecks(t *testing.T) {
+	c := &Base{
+		config: &Config{},
+		clones: map[string]*CloneWrapper{
+			"creating":   {Clone: &models.Clone{ID: "creating", Status: models.Status{Code: models.StatusCreating}}},
+			"hibernated": {Clone: &models.Clone{ID: "hibernated", Status: models.Status{Code: models.StatusHibernated}}, Session: &resources.Session{}},
+			"running":    {Clone: &models.Clone{ID: "running", Status: models.Status{Code: models.StatusOK}}, Session: &resources.Session{}},
+		},
+	}
+
+	_, err := c.HibernateClone(context.Background(), "creating")
+	assert.ErrorContains(t, err, "clone is not ready")
+
+	_, err = c.WakeClone(context.Background(), "creating")
+	assert.ErrorContains(t, err, "clone is not hibernated")
+
+	_, err = c.WakeClone(context.Background(), "missing")
+	assert.ErrorContains(t, err, "clone not found")
+
+	clone, err := c.HibernateClone(context.Background(), "hibernated")
+	require.NoError(t, err)
+	assert.Equal(t, "hibernated", clone.ID)
+
+	clone, err = c.WakeClone(context.Background(), "running")
+	require.NoError(t, err)
+	assert.Equal(t, "running", clone.ID)
+
+	hibernated := c.hibernatedClones()
+	require.Len(t, hibernated, 1)
+	assert.Equal(t, "hibernated", hibernated[0].Clone.ID)
+
+	running := c.runningClones()
+	require.Len(t, running, 1)
+	assert.Equal(t, "running", running[0].Clone.ID)
+}
+
+func TestActiveSince(t *testing.T) {
+	startedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	w := CloneWrapper{TimeStartedAt: startedAt}
+	assert.Equal(t, startedAt, w.activeSince())
+
+	w.TimeWokenAt = startedAt.Add(time.Hour)
+	assert.Equal(t, startedAt.Add(time.Hour), w.activeSince())
+}
+
+func TestHibernatedCloneSurvivesIdleSweep(t *testing.T) {
+	longAgo := time.Now().Add(-24 * time.Hour)
+
+	c := &Base{
+		config: &Config{MaxIdleMinutes: 1},
+		clones: map[string]*CloneWrapper{
+			"hibernated": {
+				Clone:         &models.Clone{ID: "hibernated", Status: models.Status{Code: models.StatusHibernated}},
+				Session:       &resources.Session{},
+				TimeStartedAt: longAgo,
+			},
+		},
+	}
+
+	isIdle, err := c.isIdleClone(c.clones["hibernated"])
+	require.NoError(t, err)
+	assert.False(t, isIdle)
+
+	c.destroyIdleClones(context.Background())
+
+	require.Contains(t, c.clones, "hibernated")
+	assert.Equal(t, models.StatusHibernated, c.clones["hibernated"].Clone.Status.Code)
+}
@@ -0,0 +1,311 @@
+/*
+2026 © Postgres.ai
+*/
+
+package cloning
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+var (
+	// parameterName matches names of Postgres parameters, including custom ones like "auto_explain.log_min_duration".
+	parameterName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+	// forbiddenValueChars cannot be safely written to a quoted value of a Postgres configuration file.
+	forbiddenValueChars = "'\\\n\r"
+)
+
+// isAllowedParameter checks the parameter against the allow-list of the configuration; an empty list allows any parameter.
+// An entry ending with "*" allows all parameters with the prefix.
+func (c *Base) isAllowedParameter(name string) bool {
+	if len(c.config.AllowedPostgresParameters) == 0 {
+		return true
+	}
+
+	for _, allowed := range c.config.AllowedPostgresParameters {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok && strings.HasPrefix(name, prefix) {
+			return true
+		}
+
+		if allowed == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validatePostgresConfig checks user-defined Postgres parameters. Values are validated against pg_settings metadata
+// when it is available; custom parameters unknown to the server are accepted as is.
+func (c *Base) validatePostgresConfig(conf map[string]string, settings map[string]postgres.Setting) error {
+	for _, name := range sortedKeys(conf) {
+		value := conf[name]
+
+		if err := c.validateParameterName(name, settings); err != nil {
+			return err
+		}
+
+		if strings.ContainsAny(value, forbiddenValueChars) {
+			return fmt.Errorf("value of parameter %q must not contain quotes, backslashes or line breaks", name)
+		}
+
+		if setting, ok := settings[name]; ok {
+			if err := setting.Validate(value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateConfigChanges checks changes of user-defined Postgres parameters. Parameters being reset are checked
+// against the allow-list and pg_settings metadata as well as the ones being set.
+func (c *Base) validateConfigChanges(changes map[string]*string, settings map[string]postgres.Setting) error {
+	setValues := make(map[string]string)
+
+	for name, value := range changes {
+		if value == nil {
+			if err := c.validateParameterName(name, settings); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		setValues[name] = *value
+	}
+
+	return c.validatePostgresConfig(setValues, settings)
+}
+
+// validateParameterName checks that the parameter is allowed to be changed and, if pg_settings metadata is available,
+// that it is known to the server. Custom parameters containing a dot are accepted as is.
+func (c *Base) validateParameterName(name string, settings map[string]postgres.Setting) error {
+	if !parameterName.MatchString(name) {
+		return fmt.Errorf("invalid parameter name %q", name)
+	}
+
+	if !c.isAllowedParameter(name) {
+		return fmt.Errorf("parameter %q is not allowed to be changed", name)
+	}
+
+	if settings == nil || strings.Contains(name, ".") {
+		return nil
+	}
+
+	if _, ok := settings[name]; !ok {
+		return fmt.Errorf("unrecognized configuration parameter %q", name)
+	}
+
+	return nil
+}
+
+// cachedSettings returns pg_settings metadata read from a clone earlier, if any.
+func (c *Base) cachedSettings() map[string]postgres.Setting {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+
+	return c.settings
+}
+
+// settingsForValidation returns pg_settings metadata to validate user-defined parameters of a new clone. If it has not
+// been cached yet, it is read from a running clone or, if there is none, from the Postgres image of clones.
+func (c *Base) settingsForValidation(ctx context.Context) (map[string]postgres.Setting, error) {
+	if settings := c.cachedSettings(); settings != nil {
+		return settings, nil
+	}
+
+	for _, w := range c.readyClones() {
+		settings, err := c.provision.SessionSettings(w.Session, w.Clone)
+		if err != nil {
+			log.Warn(fmt.Sprintf("Failed to read Postgres settings metadata of clone %s: %v", w.Clone.ID, err))
+			continue
+		}
+
+		c.setCachedSettings(settings)
+
+		return settings, nil
+	}
+
+	settings, err := c.provision.ReferenceSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setCachedSettings(settings)
+
+	return settings, nil
+}
+
+// readyClones returns clones accepting connections.
+func (c *Base) readyClones() []*CloneWrapper {
+	c.cloneMutex.RLock()
+	defer c.cloneMutex.RUnlock()
+
+	ready := make([]*CloneWrapper, 0, len(c.clones))
+
+	for _, w := range c.clones {
+		if w.Session != nil && w.Clone != nil && w.Clone.Status.Code == models.StatusOK {
+			ready = append(ready, w)
+		}
+	}
+
+	return ready
+}
+
+func (c *Base) setCachedSettings(settings map[string]postgres.Setting) {
+	c.settingsMu.Lock()
+	c.settings = settings
+	c.settingsMu.Unlock()
+}
+
+// loadSettings caches pg_settings metadata of a running clone, so the settings of new clones can be validated.
+func (c *Base) loadSettings(session *resources.Session, clone *models.Clone) {
+	if c.cachedSettings() != nil {
+		return
+	}
+
+	settings, err := c.provision.SessionSettings(session, clone)
+	if err != nil {
+		log.Warn(fmt.Sprintf("Failed to read Postgres settings metadata of clone %s: %v", clone.ID, err))
+		return
+	}
+
+	c.setCachedSettings(settings)
+}
+
+// UpdateCloneConfig changes user-defined Postgres settings of a running clone. A nil value resets the parameter.
+// The change is applied with a configuration reload, or with a restart if a changed parameter requires it.
+func (c *Base) UpdateCloneConfig(ctx context.Context, cloneID string, changes map[string]*string) (*models.CloneConfigUpdate, error) {
+	w, ok := c.findWrapper(cloneID)
+	if !ok {
+		return nil, models.New(models.ErrCodeNotFound, "clone not found")
+	}
+
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+
+	c.cloneMutex.RLock()
+	status := w.Clone.Status.Code
+	currentConf := copySettings(w.Clone.PostgresConfig)
+	c.cloneMutex.RUnlock()
+
+	if status != models.StatusOK || w.Session == nil {
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("clone is not ready: %s", status))
+	}
+
+	settings, err := c.provision.SessionSettings(w.Session, w.Clone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Postgres settings: %w", err)
+	}
+
+	c.setCachedSettings(settings)
+
+	if err := c.validateConfigChanges(changes, settings); err != nil {
+		return nil, models.New(models.ErrCodeBadRequest, err.Error())
+	}
+
+	newConf := copySettings(currentConf)
+
+	for name, value := range changes {
+		if value == nil {
+			delete(newConf, name)
+			continue
+		}
+
+		newConf[name] = *value
+	}
+
+	update := &models.CloneConfigUpdate{Applied: models.ConfigApplyNone, PostgresConfig: newConf}
+
+	changed := changedParameters(currentConf, newConf)
+	if len(changed) == 0 {
+		return update, nil
+	}
+
+	for _, name := range changed {
+		if settings[name].RequiresRestart() {
+			update.RestartParameters = append(update.RestartParameters, name)
+		}
+	}
+
+	restart := len(update.RestartParameters) > 0
+
+	if err := c.provision.UpdateSessionConfig(ctx, w.Session, w.Clone, newConf, restart); err != nil {
+		if rollbackErr := c.provision.UpdateSessionConfig(ctx, w.Session, w.Clone, currentConf, restart); rollbackErr != nil {
+			log.Err(fmt.Sprintf("failed to restore Postgres settings of clone %s: %v", cloneID, rollbackErr))
+		}
+
+		return nil, fmt.Errorf("failed to apply Postgres settings: %w", err)
+	}
+
+	update.Applied = models.ConfigApplyReload
+	if restart {
+		update.Applied = models.ConfigApplyRestart
+	}
+
+	c.cloneMutex.Lock()
+	w.Clone.PostgresConfig = newConf
+	w.Session.ExtraConfig = newConf
+	c.cloneMutex.Unlock()
+
+	c.SaveClonesState()
+
+	log.Msg(fmt.Sprintf("Postgres settings of clone %s updated with a %s", cloneID, update.Applied))
+
+	return update, nil
+}
+
+// changedParameters returns names of parameters that were added, changed or removed.
+func changedParameters(before, after map[string]string) []string {
+	var changed []string
+
+	for name, value := range after {
+		if previous, ok := before[name]; !ok || previous != value {
+			changed = append(changed, name)
+		}
+	}
+
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+func copySettings(settings map[string]string) map[string]string {
+	copied := make(map[string]string, len(settings))
+
+	for name, value := range settings {
+		copied[name] = value
+	}
+
+	return copied
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
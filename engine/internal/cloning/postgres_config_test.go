@@ -0,0 +1,74 @@
+package cloning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestValidatePostgresConfig(t *testing.T) {
+	settings := map[string]postgres.Setting{
+		"work_mem":          {Name: "work_mem", VarType: "integer", Context: "user", Unit: "kB", MinVal: "64", MaxVal: "2147483647"},
+		"statement_timeout": {Name: "statement_timeout", VarType: "integer", Context: "user", Unit: "ms", MinVal: "0", MaxVal: "2147483647"},
+		"shared_buffers":    {Name: "shared_buffers", VarType: "integer", Context: "postmaster", Unit: "8kB", MinVal: "16", MaxVal: "1073741823"},
+	}
+
+	c := &Base{config: &Config{}}
+
+	assert.NoError(t, c.validatePostgresConfig(map[string]string{"work_mem": "64MB", "auto_explain.log_min_duration": "1s"}, settings))
+	assert.NoError(t, c.validatePostgresConfig(map[string]string{"unknown_param": "1"}, nil), "values are not checked without metadata")
+	assert.Error(t, c.validatePostgresConfig(map[string]string{"unknown_param": "1"}, settings))
+	assert.Error(t, c.validatePostgresConfig(map[string]string{"work_mem": "1kB"}, settings))
+	assert.Error(t, c.validatePostgresConfig(map[string]string{"work_mem = 1; x": "1"}, nil))
+	assert.Error(t, c.validatePostgresConfig(map[string]string{"work_mem": "1MB'\nshared_buffers = '1"}, nil))
+
+	c.config.AllowedPostgresParameters = []string{"work_mem", "auto_explain.*"}
+
+	assert.NoError(t, c.validatePostgresConfig(map[string]string{"work_mem": "64MB", "auto_explain.log_analyze": "on"}, settings))
+	assert.Error(t, c.validatePostgresConfig(map[string]string{"statement_timeout": "1s"}, settings))
+}
+
+func TestValidateConfigChanges(t *testing.T) {
+	settings := map[string]postgres.Setting{
+		"work_mem":          {Name: "work_mem", VarType: "integer", Context: "user", Unit: "kB", MinVal: "64", MaxVal: "2147483647"},
+		"statement_timeout": {Name: "statement_timeout", VarType: "integer", Context: "user", Unit: "ms", MinVal: "0", MaxVal: "2147483647"},
+	}
+
+	value := "64MB"
+
+	c := &Base{config: &Config{AllowedPostgresParameters: []string{"work_mem", "auto_explain.*"}}}
+
+	assert.NoError(t, c.validateConfigChanges(map[string]*string{"work_mem": &value, "auto_explain.log_analyze": nil}, settings))
+	assert.ErrorContains(t, c.validateConfigChanges(map[string]*string{"statement_timeout": nil}, settings), "is not allowed")
+	assert.ErrorContains(t, c.validateConfigChanges(map[string]*string{"work_mem = 1; x": nil}, settings), "invalid parameter name")
+
+	c.config.AllowedPostgresParameters = nil
+
+	assert.ErrorContains(t, c.validateConfigChanges(map[string]*string{"unknown_param": nil}, settings), "unrecognized")
+	assert.NoError(t, c.validateConfigChanges(map[string]*string{"statement_timeout": nil}, settings))
+}
+
+func TestSettingsForValidation(t *testing.T) {
+	c := &Base{config: &Config{}, clones: map[string]*CloneWrapper{
+		"creating": {Clone: &models.Clone{ID: "creating", Status: models.Status{Code: models.StatusCreating}}},
+	}}
+
+	cached := map[string]postgres.Setting{"work_mem": {Name: "work_mem", VarType: "integer"}}
+	c.setCachedSettings(cached)
+
+	settings, err := c.settingsForValidation(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, cached, settings)
+}
+
+func TestChangedParameters(t *testing.T) {
+	before := map[string]string{"work_mem": "4MB", "shared_buffers": "1GB", "jit": "off"}
+	after := map[string]string{"work_mem": "8MB", "jit": "off", "statement_timeout": "1s"}
+
+	assert.Equal(t, []string{"shared_buffers", "statement_timeout", "work_mem"}, changedParameters(before, after))
+	assert.Empty(t, changedParameters(before, before))
+}
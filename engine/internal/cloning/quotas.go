@@ -0,0 +1,99 @@
+/*
+2026 © Postgres.ai
+*/
+
+package cloning
+
+import (
+	"fmt"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// bytesInGiB defines the number of bytes in a gibibyte.
+const bytesInGiB = 1 << 30
+
+// Quotas limits the resources consumed by clones of a single owner. Clones without an owner
+// (created with the shared verification token) are not counted. A zero value disables a limit.
+type Quotas struct {
+	// MaxClones caps the number of concurrent clones per owner.
+	MaxClones uint `yaml:"maxClones"`
+	// MaxProtectedClones caps the number of protected clones per owner.
+	MaxProtectedClones uint `yaml:"maxProtectedClones"`
+	// MaxDiffSizeGiB caps the total diff size of clones per owner; new clones are rejected once it is reached.
+	MaxDiffSizeGiB uint `yaml:"maxDiffSizeGiB"`
+}
+
+// ownerUsage summarizes resources used by clones of one owner.
+type ownerUsage struct {
+	clones    uint
+	protected uint
+	diffSize  uint64
+}
+
+// ownerUsageLocked sums up the usage of the owner's clones, skipping the given clone ID.
+// The caller must hold cloneMutex.
+func (c *Base) ownerUsageLocked(owner, skipCloneID string) ownerUsage {
+	var usage ownerUsage
+
+	for id, w := range c.clones {
+		if id == skipCloneID || w.Clone == nil || w.Clone.DB.OwnerUser != owner {
+			continue
+		}
+
+		usage.clones++
+		usage.diffSize += w.Clone.Metadata.CloneDiffSize
+
+		if w.Clone.IsProtected() {
+			usage.protected++
+		}
+	}
+
+	return usage
+}
+
+// checkCreateQuotasLocked validates that the owner can create one more clone.
+// The caller must hold cloneMutex.
+func (c *Base) checkCreateQuotasLocked(clone *models.Clone) error {
+	owner := clone.DB.OwnerUser
+	if owner == "" {
+		return nil
+	}
+
+	quotas := c.config.Quotas
+	usage := c.ownerUsageLocked(owner, clone.ID)
+
+	if quotas.MaxClones > 0 && usage.clones >= quotas.MaxClones {
+		return quotaError(owner, fmt.Sprintf("%d of %d clones in use", usage.clones, quotas.MaxClones))
+	}
+
+	if quotas.MaxDiffSizeGiB > 0 && usage.diffSize >= uint64(quotas.MaxDiffSizeGiB)*bytesInGiB {
+		return quotaError(owner, fmt.Sprintf("clones use %.1f GiB of %d GiB allowed for clone diffs",
+			float64(usage.diffSize)/bytesInGiB, quotas.MaxDiffSizeGiB))
+	}
+
+	if clone.Protected {
+		return c.checkProtectionQuotaLocked(owner, clone.ID)
+	}
+
+	return nil
+}
+
+// checkProtectionQuotaLocked validates that the owner can protect one more clone.
+// The caller must hold cloneMutex.
+func (c *Base) checkProtectionQuotaLocked(owner, cloneID string) error {
+	maxProtected := c.config.Quotas.MaxProtectedClones
+	if owner == "" || maxProtected == 0 {
+		return nil
+	}
+
+	if usage := c.ownerUsageLocked(owner, cloneID); usage.protected >= maxProtected {
+		return quotaError(owner, fmt.Sprintf("%d of %d protected clones in use", usage.protected, maxProtected))
+	}
+
+	return nil
+}
+
+func quotaError(owner, details string) error {
+	return models.New(models.ErrCodeQuotaExceeded, fmt.Sprintf("quota exceeded for %s: %s", owner, details))
+}
@@ -0,0 +1,107 @@
+/*
+2026 © Postgres.ai
+*/
+
+package cloning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func newQuotaTestBase(quotas Quotas, clones ...*models.Clone) *Base {
+	c := &Base{
+		config: &Config{Quotas: quotas},
+		clones: make(map[string]*CloneWrapper),
+	}
+
+	for _, clone := range clones {
+		c.clones[clone.ID] = &CloneWrapper{Clone: clone}
+	}
+
+	return c
+}
+
+func ownedClone(id, owner string, protected bool, diffSize uint64) *models.Clone {
+	return &models.Clone{
+		ID:        id,
+		Protected: protected,
+		DB:        models.Database{OwnerUser: owner},
+		Metadata:  models.CloneMetadata{CloneDiffSize: diffSize},
+	}
+}
+
+func TestCheckCreateQuotas(t *testing.T) {
+	existing := []*models.Clone{
+		ownedClone("c1", "jsmith@acme.io", true, 3*bytesInGiB),
+		ownedClone("c2", "jsmith@acme.io", false, bytesInGiB),
+		ownedClone("c3", "jdoe@acme.io", true, 10*bytesInGiB),
+		ownedClone("c4", "", true, 10*bytesInGiB),
+	}
+
+	testCases := []struct {
+		name    string
+		quotas  Quotas
+		clone   *models.Clone
+		wantErr string
+	}{
+		{name: "no quotas", clone: ownedClone("new", "jsmith@acme.io", true, 0)},
+		{name: "clone limit reached", quotas: Quotas{MaxClones: 2}, clone: ownedClone("new", "jsmith@acme.io", false, 0),
+			wantErr: "quota exceeded for jsmith@acme.io: 2 of 2 clones in use"},
+		{name: "clone limit of another owner", quotas: Quotas{MaxClones: 2}, clone: ownedClone("new", "jdoe@acme.io", false, 0)},
+		{name: "unowned clones are not limited", quotas: Quotas{MaxClones: 1}, clone: ownedClone("new", "", true, 0)},
+		{name: "protected limit reached", quotas: Quotas{MaxProtectedClones: 1}, clone: ownedClone("new", "jsmith@acme.io", true, 0),
+			wantErr: "quota exceeded for jsmith@acme.io: 1 of 1 protected clones in use"},
+		{name: "unprotected clone ignores protected limit", quotas: Quotas{MaxProtectedClones: 1},
+			clone: ownedClone("new", "jsmith@acme.io", false, 0)},
+		{name: "diff size limit reached", quotas: Quotas{MaxDiffSizeGiB: 4}, clone: ownedClone("new", "jsmith@acme.io", false, 0),
+			wantErr: "quota exceeded for jsmith@acme.io: clones use 4.0 GiB of 4 GiB allowed for clone diffs"},
+		{name: "diff size below limit", quotas: Quotas{MaxDiffSizeGiB: 5}, clone: ownedClone("new", "jsmith@acme.io", false, 0)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newQuotaTestBase(tc.quotas, existing...)
+
+			err := c.checkCreateQuotasLocked(tc.clone)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var reqErr *models.Error
+			require.ErrorAs(t, err, &reqErr)
+			assert.Equal(t, models.ErrCodeQuotaExceeded, reqErr.Code)
+			assert.Equal(t, tc.wantErr, reqErr.Message)
+		})
+	}
+}
+
+func TestUpdateCloneProtectionQuota(t *testing.T) {
+	expired := ownedClone("c2", "jsmith@acme.io", true, 0)
+	expired.ProtectedTill = &models.LocalTime{Time: time.Now().Add(-time.Hour)}
+
+	c := newQuotaTestBase(Quotas{MaxProtectedClones: 1},
+		ownedClone("c1", "jsmith@acme.io", true, 0),
+		expired,
+		ownedClone("c3", "jsmith@acme.io", false, 0),
+	)
+	c.config.ProtectionLeaseDurationMinutes = 60
+
+	_, err := c.UpdateClone("c3", types.CloneUpdateRequest{Protected: true})
+
+	var reqErr *models.Error
+	require.ErrorAs(t, err, &reqErr)
+	assert.Equal(t, models.ErrCodeQuotaExceeded, reqErr.Code)
+	assert.False(t, c.clones["c3"].Clone.Protected)
+
+	// Expired protection does not count, and the checked clone itself is skipped.
+	require.NoError(t, c.checkProtectionQuotaLocked("jsmith@acme.io", "c1"))
+	require.NoError(t, c.checkProtectionQuotaLocked("jdoe@acme.io", "c3"))
+}
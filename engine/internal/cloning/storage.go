@@ -44,7 +44,18 @@ func (c *Base) loadSessionState(sessionsPath string) error {
 		return fmt.Errorf("failed to read sessions data: %w", err)
 	}
 
-	return json.Unmarshal(data, &c.clones)
+	if err := json.Unmarshal(data, &c.clones); err != nil {
+		return err
+	}
+
+	// Sessions saved by earlier versions keep user-defined Postgres settings only in the session.
+	for _, wrapper := range c.clones {
+		if wrapper.Clone != nil && wrapper.Session != nil && wrapper.Clone.PostgresConfig == nil {
+			wrapper.Clone.PostgresConfig = wrapper.Session.ExtraConfig
+		}
+	}
+
+	return nil
 }
 func (c *Base) restartCloneContainers(ctx context.Context) {
 	c.cloneMutex.Lock()
@@ -56,7 +67,7 @@ func (c *Base) restartCloneContainers(ctx context.Context) {
 		}
 
 		cloneName := wrapper.Clone.ID
-		if c.provision.IsCloneRunning(ctx, cloneName) {
+		if isHibernated(wrapper) || c.provision.IsCloneRunning(ctx, cloneName) {
 			continue
 		}
 
@@ -102,7 +113,7 @@ func (c *Base) filterRunningClones(ctx context.Context) {
 			snapshotCache[snapshot.ID] = struct{}{}
 		}
 
-		if !c.provision.IsCloneRunning(ctx, wrapper.Clone.ID) {
+		if !isHibernated(wrapper) && !c.provision.IsCloneRunning(ctx, wrapper.Clone.ID) {
 			delete(c.clones, cloneID)
 		}
 
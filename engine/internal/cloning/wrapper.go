@@ -20,6 +20,10 @@ type CloneWrapper struct {
 
 	TimeCreatedAt time.Time `json:"time_created_at"`
 	TimeStartedAt time.Time `json:"time_started_at"`
+	TimeWokenAt   time.Time `json:"time_woken_at"`
+
+	// TimeConnectedAt is the time of the latest connection routed to the clone by the engine.
+	TimeConnectedAt time.Time `json:"time_connected_at"`
 }
 
 // NewCloneWrapper constructs a new CloneWrapper.
@@ -32,6 +36,20 @@ func NewCloneWrapper(clone *models.Clone, createdAt time.Time) *CloneWrapper {
 	return w
 }
 
+// activeSince returns the time of the latest clone activity known to the engine:
+// the clone start, wake-up, or a routed connection.
+func (cw CloneWrapper) activeSince() time.Time {
+	activeSince := cw.TimeStartedAt
+
+	for _, t := range []time.Time{cw.TimeWokenAt, cw.TimeConnectedAt} {
+		if t.After(activeSince) {
+			activeSince = t
+		}
+	}
+
+	return activeSince
+}
+
 // IsProtected checks if clone is protected.
 func (cw CloneWrapper) IsProtected() bool {
 	return cw.Clone != nil && cw.Clone.Protected
@@ -0,0 +1,203 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package diff compares schema objects and table statistics of two databases.
+package diff
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// Querier runs queries against a database.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// ObjectKey identifies a schema object.
+type ObjectKey struct {
+	Kind string
+	Name string
+}
+
+// TableStats holds the size of a table.
+type TableStats struct {
+	Rows int64
+	Size int64
+}
+
+// Catalog holds the definitions of schema objects and the statistics of tables of a database.
+type Catalog struct {
+	Objects map[ObjectKey]string
+	Tables  map[string]TableStats
+}
+
+// NewCatalog creates an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{
+		Objects: make(map[ObjectKey]string),
+		Tables:  make(map[string]TableStats),
+	}
+}
+
+const (
+	userSchemaFilter = `n.nspname not in ('pg_catalog', 'information_schema')
+  and n.nspname !~ '^pg_toast' and n.nspname !~ '^pg_temp_'`
+
+	// Objects created by extensions belong to the extension and are compared through its version.
+	userRelationFilter = userSchemaFilter + `
+  and c.relkind in ('r', 'p')
+  and not exists (select from pg_depend d where d.classid = 'pg_class'::regclass and d.objid = c.oid and d.deptype = 'e')`
+
+	tablesQuery = `select format('%I.%I', n.nspname, c.relname), format('create table %I.%I', n.nspname, c.relname)
+from pg_class c
+join pg_namespace n on n.oid = c.relnamespace
+where ` + userRelationFilter
+
+	columnsQuery = `select format('%I.%I.%I', n.nspname, c.relname, a.attname),
+  format('alter table %I.%I add column %I %s', n.nspname, c.relname, a.attname, format_type(a.atttypid, a.atttypmod))
+    || case when a.attnotnull then ' not null' else '' end
+    || coalesce(' default ' || pg_get_expr(ad.adbin, ad.adrelid), '')
+from pg_attribute a
+join pg_class c on c.oid = a.attrelid
+join pg_namespace n on n.oid = c.relnamespace
+left join pg_attrdef ad on ad.adrelid = a.attrelid and ad.adnum = a.attnum
+where a.attnum > 0 and not a.attisdropped and ` + userRelationFilter
+
+	indexesQuery = `select format('%I.%I', n.nspname, ic.relname), pg_get_indexdef(i.indexrelid)
+from pg_index i
+join pg_class ic on ic.oid = i.indexrelid
+join pg_class c on c.oid = i.indrelid
+join pg_namespace n on n.oid = c.relnamespace
+where ` + userRelationFilter
+
+	constraintsQuery = `select format('%I.%I.%I', n.nspname, c.relname, con.conname),
+  format('alter table %I.%I add constraint %I %s', n.nspname, c.relname, con.conname, pg_get_constraintdef(con.oid))
+from pg_constraint con
+join pg_class c on c.oid = con.conrelid
+join pg_namespace n on n.oid = c.relnamespace
+where ` + userRelationFilter
+
+	functionsQuery = `select format('%I.%I(%s)', n.nspname, p.proname, pg_get_function_identity_arguments(p.oid)),
+  pg_get_functiondef(p.oid)
+from pg_proc p
+join pg_namespace n on n.oid = p.pronamespace
+where ` + userSchemaFilter + `
+  and not exists (select from pg_aggregate ag where ag.aggfnoid = p.oid)
+  and not exists (select from pg_depend d where d.classid = 'pg_proc'::regclass and d.objid = p.oid and d.deptype = 'e')`
+
+	extensionsQuery = `select quote_ident(extname), format('create extension %I version %L', extname, extversion)
+from pg_extension`
+
+	// reltuples is -1 for tables that have never been vacuumed or analyzed since Postgres 14.
+	tableStatsQuery = `select format('%I.%I', n.nspname, c.relname), greatest(c.reltuples, 0)::bigint, pg_total_relation_size(c.oid)
+from pg_class c
+join pg_namespace n on n.oid = c.relnamespace
+where ` + userRelationFilter
+)
+
+var objectQueries = []struct {
+	kind  string
+	query string
+}{
+	{kind: models.ObjectExtension, query: extensionsQuery},
+	{kind: models.ObjectTable, query: tablesQuery},
+	{kind: models.ObjectColumn, query: columnsQuery},
+	{kind: models.ObjectConstraint, query: constraintsQuery},
+	{kind: models.ObjectIndex, query: indexesQuery},
+	{kind: models.ObjectFunction, query: functionsQuery},
+}
+
+// Collect reads schema objects and table statistics of the connected database.
+// Row counts are planner estimates unless exactCounts is set, which scans every table.
+func Collect(ctx context.Context, db Querier, exactCounts bool) (*Catalog, error) {
+	catalog := NewCatalog()
+
+	for _, q := range objectQueries {
+		if err := collectObjects(ctx, db, catalog, q.kind, q.query); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := collectTableStats(ctx, db, catalog); err != nil {
+		return nil, err
+	}
+
+	if exactCounts {
+		if err := countRows(ctx, db, catalog); err != nil {
+			return nil, err
+		}
+	}
+
+	return catalog, nil
+}
+
+func collectObjects(ctx context.Context, db Querier, catalog *Catalog, kind, query string) error {
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query %s definitions: %w", kind, err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, definition string
+
+		if err := rows.Scan(&name, &definition); err != nil {
+			return fmt.Errorf("failed to scan %s definition: %w", kind, err)
+		}
+
+		catalog.Objects[ObjectKey{Kind: kind, Name: name}] = definition
+	}
+
+	return rows.Err()
+}
+
+func collectTableStats(ctx context.Context, db Querier, catalog *Catalog) error {
+	rows, err := db.Query(ctx, tableStatsQuery)
+	if err != nil {
+		return fmt.Errorf("failed to query table statistics: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			name  string
+			stats TableStats
+		)
+
+		if err := rows.Scan(&name, &stats.Rows, &stats.Size); err != nil {
+			return fmt.Errorf("failed to scan table statistics: %w", err)
+		}
+
+		catalog.Tables[name] = stats
+	}
+
+	return rows.Err()
+}
+
+func countRows(ctx context.Context, db Querier, catalog *Catalog) error {
+	for name, stats := range catalog.Tables {
+		// Table names come from the catalog already quoted with format('%I.%I').
+		rows, err := db.Query(ctx, "select count(*) from "+name)
+		if err != nil {
+			return fmt.Errorf("failed to count rows of %s: %w", name, err)
+		}
+
+		count, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[int64])
+		if err != nil {
+			return fmt.Errorf("failed to count rows of %s: %w", name, err)
+		}
+
+		stats.Rows = count
+		catalog.Tables[name] = stats
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+2026 © Postgres.ai
+*/
+
+package diff
+
+import (
+	"sort"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// kindOrder lists object kinds in the order changes are reported, so dependencies come first.
+var kindOrder = map[string]int{
+	models.ObjectExtension:  0,
+	models.ObjectTable:      1,
+	models.ObjectColumn:     2,
+	models.ObjectConstraint: 3,
+	models.ObjectIndex:      4,
+	models.ObjectFunction:   5,
+}
+
+// Compare reports how the target catalog differs from the base one.
+func Compare(base, target *Catalog) ([]models.SchemaChange, []models.TableStatsDiff) {
+	return compareObjects(base.Objects, target.Objects), compareTables(base.Tables, target.Tables)
+}
+
+func compareObjects(base, target map[ObjectKey]string) []models.SchemaChange {
+	changes := []models.SchemaChange{}
+
+	for key, before := range base {
+		after, ok := target[key]
+
+		switch {
+		case !ok:
+			changes = append(changes, models.SchemaChange{
+				Kind: key.Kind, Name: key.Name, Action: models.ChangeRemoved, Before: before,
+			})
+
+		case after != before:
+			changes = append(changes, models.SchemaChange{
+				Kind: key.Kind, Name: key.Name, Action: models.ChangeChanged, Before: before, After: after,
+			})
+		}
+	}
+
+	for key, after := range target {
+		if _, ok := base[key]; !ok {
+			changes = append(changes, models.SchemaChange{
+				Kind: key.Kind, Name: key.Name, Action: models.ChangeAdded, After: after,
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return kindOrder[changes[i].Kind] < kindOrder[changes[j].Kind]
+		}
+
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+func compareTables(base, target map[string]TableStats) []models.TableStatsDiff {
+	names := make(map[string]struct{}, len(base)+len(target))
+
+	for name := range base {
+		names[name] = struct{}{}
+	}
+
+	for name := range target {
+		names[name] = struct{}{}
+	}
+
+	tables := []models.TableStatsDiff{}
+
+	for name := range names {
+		before, after := base[name], target[name]
+
+		if before == after {
+			continue
+		}
+
+		tables = append(tables, models.TableStatsDiff{
+			Table:      name,
+			RowsBefore: before.Rows,
+			RowsAfter:  after.Rows,
+			RowsDelta:  after.Rows - before.Rows,
+			SizeBefore: before.Size,
+			SizeAfter:  after.Size,
+			SizeDelta:  after.Size - before.Size,
+		})
+	}
+
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Table < tables[j].Table })
+
+	return tables
+}
@@ -0,0 +1,85 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestCompare(t *testing.T) {
+	base := NewCatalog()
+	base.Objects[ObjectKey{Kind: models.ObjectTable, Name: "public.t1"}] = "create table public.t1"
+	base.Objects[ObjectKey{Kind: models.ObjectColumn, Name: "public.t1.c1"}] = "alter table public.t1 add column c1 integer"
+	base.Objects[ObjectKey{Kind: models.ObjectIndex, Name: "public.t1_c1"}] = "CREATE INDEX t1_c1 ON public.t1 USING btree (c1)"
+	base.Tables["public.t1"] = TableStats{Rows: 100, Size: 8192}
+	base.Tables["public.t3"] = TableStats{Rows: 5, Size: 8192}
+
+	target := NewCatalog()
+	target.Objects[ObjectKey{Kind: models.ObjectTable, Name: "public.t1"}] = "create table public.t1"
+	target.Objects[ObjectKey{Kind: models.ObjectColumn, Name: "public.t1.c1"}] = "alter table public.t1 add column c1 bigint"
+	target.Objects[ObjectKey{Kind: models.ObjectTable, Name: "public.t2"}] = "create table public.t2"
+	target.Tables["public.t1"] = TableStats{Rows: 150, Size: 16384}
+	target.Tables["public.t2"] = TableStats{Rows: 0, Size: 0}
+	target.Tables["public.t3"] = TableStats{Rows: 5, Size: 8192}
+
+	changes, tables := Compare(base, target)
+
+	require.Len(t, changes, 3)
+	assert.Equal(t, models.SchemaChange{
+		Kind: models.ObjectTable, Name: "public.t2", Action: models.ChangeAdded, After: "create table public.t2",
+	}, changes[0])
+	assert.Equal(t, models.SchemaChange{
+		Kind: models.ObjectColumn, Name: "public.t1.c1", Action: models.ChangeChanged,
+		Before: "alter table public.t1 add column c1 integer", After: "alter table public.t1 add column c1 bigint",
+	}, changes[1])
+	assert.Equal(t, models.ChangeRemoved, changes[2].Action)
+	assert.Equal(t, models.ObjectIndex, changes[2].Kind)
+
+	assert.Equal(t, []models.TableStatsDiff{{
+		Table: "public.t1", RowsBefore: 100, RowsAfter: 150, RowsDelta: 50, SizeBefore: 8192, SizeAfter: 16384, SizeDelta: 8192,
+	}}, tables)
+}
+
+func TestCompareIdentical(t *testing.T) {
+	catalog := NewCatalog()
+	catalog.Objects[ObjectKey{Kind: models.ObjectExtension, Name: "pg_trgm"}] = "create extension pg_trgm version '1.6'"
+	catalog.Tables["public.t1"] = TableStats{Rows: 1, Size: 1}
+
+	changes, tables := Compare(catalog, catalog)
+	assert.Empty(t, changes)
+	assert.Empty(t, tables)
+}
+
+func TestFormatSQL(t *testing.T) {
+	d := &models.SnapshotDiff{
+		Snapshot: "pool/branch/dev@20260101000000",
+		Against:  "pool@20251201000000",
+		DBName:   "postgres",
+		Changes: []models.SchemaChange{
+			{Kind: models.ObjectTable, Name: "public.t2", Action: models.ChangeAdded, After: "create table public.t2"},
+			{Kind: models.ObjectColumn, Name: "public.t1.c1", Action: models.ChangeChanged,
+				Before: "alter table public.t1 add column c1 integer", After: "alter table public.t1 add column c1 bigint"},
+		},
+		Tables: []models.TableStatsDiff{
+			{Table: "public.t1", RowsBefore: 100, RowsAfter: 150, RowsDelta: 50, SizeBefore: 8192, SizeAfter: 16384, SizeDelta: 8192},
+		},
+	}
+
+	expected := `-- diff of pool/branch/dev@20260101000000 against pool@20251201000000 (database postgres)
+
+-- added table public.t2
++ create table public.t2;
+
+-- changed column public.t1.c1
+- alter table public.t1 add column c1 integer;
++ alter table public.t1 add column c1 bigint;
+
+-- table statistics (estimated rows):
+--   public.t1: rows 100 -> 150 (+50), size 8.0 KiB -> 16 KiB (+8.0 KiB)
+`
+
+	assert.Equal(t, expected, FormatSQL(d))
+}
@@ -0,0 +1,86 @@
+/*
+2026 © Postgres.ai
+*/
+
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// FormatSQL renders the diff in a human-readable form: object definitions prefixed
+// with "+" when added and "-" when removed, both versions of changed objects, and
+// table statistics as SQL comments.
+func FormatSQL(d *models.SnapshotDiff) string {
+	sb := &strings.Builder{}
+
+	fmt.Fprintf(sb, "-- diff of %s against %s (database %s)\n", d.Snapshot, d.Against, d.DBName)
+
+	if len(d.Changes) == 0 {
+		sb.WriteString("-- no schema changes\n")
+	}
+
+	for _, change := range d.Changes {
+		fmt.Fprintf(sb, "\n-- %s %s %s\n", change.Action, change.Kind, change.Name)
+
+		switch change.Action {
+		case models.ChangeAdded:
+			writeDefinition(sb, "+ ", change.After)
+
+		case models.ChangeRemoved:
+			writeDefinition(sb, "- ", change.Before)
+
+		case models.ChangeChanged:
+			writeDefinition(sb, "- ", change.Before)
+			writeDefinition(sb, "+ ", change.After)
+		}
+	}
+
+	if len(d.Tables) == 0 {
+		return sb.String()
+	}
+
+	rowsSource := "estimated"
+	if d.ExactCounts {
+		rowsSource = "exact"
+	}
+
+	fmt.Fprintf(sb, "\n-- table statistics (%s rows):\n", rowsSource)
+
+	for _, table := range d.Tables {
+		fmt.Fprintf(sb, "--   %s: rows %d -> %d (%+d), size %s -> %s (%s)\n", table.Table,
+			table.RowsBefore, table.RowsAfter, table.RowsDelta,
+			formatSize(table.SizeBefore), formatSize(table.SizeAfter), formatSizeDelta(table.SizeDelta))
+	}
+
+	return sb.String()
+}
+
+func writeDefinition(sb *strings.Builder, prefix, definition string) {
+	definition = strings.TrimRight(definition, "\n; ") + ";"
+
+	for _, line := range strings.Split(definition, "\n") {
+		sb.WriteString(prefix + line + "\n")
+	}
+}
+
+func formatSize(size int64) string {
+	if size < 0 {
+		size = 0
+	}
+
+	return humanize.IBytes(uint64(size))
+}
+
+func formatSizeDelta(delta int64) string {
+	if delta < 0 {
+		return "-" + humanize.IBytes(uint64(-delta))
+	}
+
+	return "+" + humanize.IBytes(uint64(delta))
+}
@@ -0,0 +1,152 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package events streams changes of the engine state: clone status transitions, snapshot and branch changes,
+// retrieval stages, and protection warnings.
+//
+// Events are the ones sent to webhooks. The broker keeps the most recent of them, so that a subscriber
+// that reconnects can resume from the last event it has received.
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	historySize          = 1000
+	subscriberBufferSize = 64
+)
+
+type entityEvent interface {
+	GetEntityID() string
+}
+
+// Broker distributes engine events to subscribers.
+type Broker struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     []models.Event
+	historySize int
+	subscribers map[chan models.Event]struct{}
+}
+
+// NewBroker creates a new event broker.
+func NewBroker() *Broker {
+	return newBroker(historySize)
+}
+
+func newBroker(size int) *Broker {
+	return &Broker{
+		// IDs continue from the start time, so that IDs received before a restart are not mistaken for new ones.
+		lastID:      uint64(time.Now().UnixMicro()),
+		historySize: size,
+		subscribers: make(map[chan models.Event]struct{}),
+	}
+}
+
+// Tee publishes events passing from the input channel to the returned one, which is closed when the input is.
+func (b *Broker) Tee(in <-chan webhooks.EventTyper) <-chan webhooks.EventTyper {
+	out := make(chan webhooks.EventTyper, cap(in))
+
+	go func() {
+		defer close(out)
+
+		for event := range in {
+			b.Publish(event)
+			out <- event
+		}
+	}()
+
+	return out
+}
+
+// Publish sends the event to subscribers and keeps it for subscribers that resume later.
+func (b *Broker) Publish(event webhooks.EventTyper) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Err("failed to encode event:", err)
+		return
+	}
+
+	published := models.Event{
+		Type: event.GetType(),
+		Time: time.Now(),
+		Data: data,
+	}
+
+	if entity, ok := event.(entityEvent); ok {
+		published.EntityID = entity.GetEntityID()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	published.ID = b.lastID
+
+	b.history = append(b.history, published)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- published:
+		default:
+			// The subscriber does not keep up; it is disconnected and can resume from the last received event.
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns events published after the one with the given ID and the channel of new events.
+// If lastEventID is zero, only new events are delivered. If the events after lastEventID are no longer kept,
+// a single resync event is returned instead of them. The channel is closed if the subscriber falls behind.
+// The returned function cancels the subscription.
+func (b *Broker) Subscribe(lastEventID uint64) ([]models.Event, <-chan models.Event, func()) {
+	ch := make(chan models.Event, subscriberBufferSize)
+
+	b.mu.Lock()
+	missed := b.missedLocked(lastEventID)
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return missed, ch, unsubscribe
+}
+
+func (b *Broker) missedLocked(lastEventID uint64) []models.Event {
+	if lastEventID == 0 || lastEventID == b.lastID {
+		return nil
+	}
+
+	oldestID := b.lastID + 1
+	if len(b.history) > 0 {
+		oldestID = b.history[0].ID
+	}
+
+	if lastEventID < oldestID-1 || lastEventID > b.lastID {
+		return []models.Event{{ID: b.lastID, Type: models.EventResync, Time: time.Now()}}
+	}
+
+	first := int(lastEventID + 1 - oldestID)
+
+	return append([]models.Event(nil), b.history[first:]...)
+}
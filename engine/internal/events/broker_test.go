@@ -0,0 +1,125 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func cloneStatusEvent(cloneID, status string) webhooks.CloneStatusEvent {
+	return webhooks.CloneStatusEvent{
+		BasicEvent: webhooks.BasicEvent{EventType: webhooks.CloneStatusChangeEvent, EntityID: cloneID},
+		Status:     status,
+	}
+}
+
+func eventIDs(events []models.Event) []uint64 {
+	ids := make([]uint64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+
+	return ids
+}
+
+func TestBrokerPublish(t *testing.T) {
+	b := newBroker(10)
+
+	missed, eventCh, unsubscribe := b.Subscribe(0)
+	defer unsubscribe()
+
+	assert.Empty(t, missed)
+
+	b.Publish(cloneStatusEvent("clone1", "OK"))
+
+	require.Len(t, eventCh, 1)
+
+	event := <-eventCh
+	assert.Equal(t, webhooks.CloneStatusChangeEvent, event.Type)
+	assert.Equal(t, "clone1", event.EntityID)
+	assert.JSONEq(t, `{"event_type":"clone_status_change","entity_id":"clone1","status":"OK"}`, string(event.Data))
+	assert.NotZero(t, event.ID)
+	assert.False(t, event.Time.IsZero())
+}
+
+func TestBrokerResume(t *testing.T) {
+	b := newBroker(3)
+
+	for range 5 {
+		b.Publish(cloneStatusEvent("clone1", "OK"))
+	}
+
+	last := b.lastID
+
+	testCases := []struct {
+		name        string
+		lastEventID uint64
+		expected    []uint64
+	}{
+		{name: "up to date", lastEventID: last, expected: []uint64{}},
+		{name: "kept events", lastEventID: last - 2, expected: []uint64{last - 1, last}},
+		{name: "all kept events", lastEventID: last - 3, expected: []uint64{last - 2, last - 1, last}},
+		{name: "no longer kept events", lastEventID: last - 4, expected: []uint64{last}},
+		{name: "unknown event", lastEventID: last + 10, expected: []uint64{last}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			missed, _, unsubscribe := b.Subscribe(tc.lastEventID)
+			defer unsubscribe()
+
+			assert.Equal(t, tc.expected, eventIDs(missed))
+
+			if tc.lastEventID < last-3 || tc.lastEventID > last {
+				require.Len(t, missed, 1)
+				assert.Equal(t, models.EventResync, missed[0].Type)
+			}
+		})
+	}
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	b := newBroker(historySize)
+
+	_, eventCh, unsubscribe := b.Subscribe(0)
+	defer unsubscribe()
+
+	for range subscriberBufferSize + 1 {
+		b.Publish(cloneStatusEvent("clone1", "OK"))
+	}
+
+	received := 0
+	for range eventCh {
+		received++
+	}
+
+	assert.Equal(t, subscriberBufferSize, received)
+}
+
+func TestBrokerTee(t *testing.T) {
+	b := newBroker(10)
+
+	_, eventCh, unsubscribe := b.Subscribe(0)
+	defer unsubscribe()
+
+	in := make(chan webhooks.EventTyper, 1)
+	out := b.Tee(in)
+
+	in <- webhooks.BasicEvent{EventType: webhooks.SnapshotCreateEvent, EntityID: "snapshot1"}
+	close(in)
+
+	forwarded, ok := <-out
+	require.True(t, ok)
+	assert.Equal(t, webhooks.SnapshotCreateEvent, forwarded.GetType())
+
+	_, ok = <-out
+	assert.False(t, ok)
+
+	event := <-eventCh
+	assert.Equal(t, webhooks.SnapshotCreateEvent, event.Type)
+	assert.Equal(t, "snapshot1", event.EntityID)
+}
@@ -0,0 +1,385 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package operations tracks actions started through the API, such as creating, resetting, or destroying a clone,
+// so that callers can follow them by ID instead of guessing their outcome from the state of the object.
+//
+// An operation is finished by the handler that started it, unless the handler hands it over to code running
+// in the background, which finishes it when the work is done. Finished operations are kept for a configurable time.
+package operations
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	defaultRetention = time.Hour
+	cleanupInterval  = time.Minute
+	// maxFinished caps the number of finished operations kept regardless of the retention time.
+	maxFinished = 10000
+)
+
+var (
+	// ErrNotFound is returned when the operation does not exist or has been removed after the retention time.
+	ErrNotFound = errors.New("operation not found")
+	// ErrNotCancelable is returned when the operation cannot be interrupted.
+	ErrNotCancelable = errors.New("operation cannot be canceled")
+	// ErrFinished is returned when canceling an operation that has already ended.
+	ErrFinished = errors.New("operation has already finished")
+)
+
+// Config defines how long finished operations are kept.
+type Config struct {
+	RetentionMinutes uint `yaml:"retentionMinutes"`
+}
+
+func (c Config) retention() time.Duration {
+	if c.RetentionMinutes == 0 {
+		return defaultRetention
+	}
+
+	return time.Duration(c.RetentionMinutes) * time.Minute
+}
+
+// Registry keeps running and recently finished operations.
+type Registry struct {
+	mu         sync.Mutex
+	operations map[string]*Operation
+	retention  time.Duration
+}
+
+// NewRegistry creates a new registry of operations.
+func NewRegistry(cfg Config) *Registry {
+	return &Registry{
+		operations: make(map[string]*Operation),
+		retention:  cfg.retention(),
+	}
+}
+
+// Reload applies new settings.
+func (r *Registry) Reload(cfg Config) {
+	r.mu.Lock()
+	r.retention = cfg.retention()
+	r.mu.Unlock()
+}
+
+// Start registers a new running operation.
+func (r *Registry) Start(opType, target string) *Operation {
+	now := time.Now()
+
+	op := &Operation{
+		state: models.Operation{
+			ID:        xid.New().String(),
+			Type:      opType,
+			Target:    target,
+			State:     models.OperationRunning,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	r.mu.Lock()
+	r.operations[op.state.ID] = op
+	r.mu.Unlock()
+
+	return op
+}
+
+// Get returns the state of the operation.
+func (r *Registry) Get(id string) (models.Operation, error) {
+	op, ok := r.find(id)
+	if !ok {
+		return models.Operation{}, ErrNotFound
+	}
+
+	return op.State(), nil
+}
+
+// Cancel requests the interruption of the operation and returns its state. The operation is marked as canceled
+// once the interrupted work stops.
+func (r *Registry) Cancel(id string) (models.Operation, error) {
+	op, ok := r.find(id)
+	if !ok {
+		return models.Operation{}, ErrNotFound
+	}
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	if op.state.IsFinished() {
+		return op.state, ErrFinished
+	}
+
+	if op.cancel == nil {
+		return op.state, ErrNotCancelable
+	}
+
+	op.canceled = true
+	op.state.Progress = "canceling"
+	op.state.UpdatedAt = time.Now()
+	op.cancel()
+
+	return op.state, nil
+}
+
+// Run removes finished operations after the retention time until the context is canceled.
+func (r *Registry) Run(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			r.cleanup(time.Now())
+		}
+	}
+}
+
+func (r *Registry) find(id string) (*Operation, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	op, ok := r.operations[id]
+
+	return op, ok
+}
+
+func (r *Registry) cleanup(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	finished := make([]models.Operation, 0)
+
+	for id, op := range r.operations {
+		state := op.State()
+
+		if !state.IsFinished() {
+			continue
+		}
+
+		if now.Sub(*state.FinishedAt) > r.retention {
+			delete(r.operations, id)
+			continue
+		}
+
+		finished = append(finished, state)
+	}
+
+	if len(finished) <= maxFinished {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].FinishedAt.Before(*finished[j].FinishedAt)
+	})
+
+	for _, state := range finished[:len(finished)-maxFinished] {
+		delete(r.operations, state.ID)
+	}
+}
+
+// Operation is a tracked action. All methods are safe to call on a nil operation, so that code shared with
+// untracked callers does not need to check whether an operation is in progress.
+type Operation struct {
+	mu       sync.Mutex
+	state    models.Operation
+	cancel   context.CancelFunc
+	canceled bool
+	detached bool
+	onFinish []func(models.Operation)
+}
+
+// ID returns the ID of the operation.
+func (o *Operation) ID() string {
+	if o == nil {
+		return ""
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.state.ID
+}
+
+// State returns a copy of the operation state.
+func (o *Operation) State() models.Operation {
+	if o == nil {
+		return models.Operation{}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.state
+}
+
+// SetTarget records the object of the operation, e.g. the ID of a created clone.
+func (o *Operation) SetTarget(target string) {
+	o.update(func(state *models.Operation) { state.Target = target })
+}
+
+// SetProgress describes the current step of the operation.
+func (o *Operation) SetProgress(progress string) {
+	o.update(func(state *models.Operation) { state.Progress = progress })
+}
+
+// SetResult records what the operation has produced, e.g. the ID of a created snapshot.
+func (o *Operation) SetResult(result string) {
+	o.update(func(state *models.Operation) { state.Result = result })
+}
+
+// WithCancel returns a context canceled when the operation is canceled and marks the operation as cancelable.
+func (o *Operation) WithCancel(ctx context.Context) context.Context {
+	if o == nil {
+		return ctx
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.state.IsFinished() {
+		cancel()
+		return ctx
+	}
+
+	o.cancel = cancel
+	o.state.Cancelable = true
+
+	return ctx
+}
+
+// Detach hands the operation over to code running in the background, which must finish it.
+func (o *Operation) Detach() {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+	o.detached = true
+	o.mu.Unlock()
+}
+
+// IsDetached reports whether the operation is finished by code running in the background.
+func (o *Operation) IsDetached() bool {
+	if o == nil {
+		return false
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.detached
+}
+
+// OnFinish calls the function with the final state of the operation when it finishes,
+// or right away if it has already finished.
+func (o *Operation) OnFinish(fn func(models.Operation)) {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+
+	if !o.state.IsFinished() {
+		o.onFinish = append(o.onFinish, fn)
+		o.mu.Unlock()
+
+		return
+	}
+
+	state := o.state
+	o.mu.Unlock()
+
+	fn(state)
+}
+
+// Finish records the outcome of the operation. Only the first outcome is recorded.
+func (o *Operation) Finish(err error) {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+
+	if o.state.IsFinished() {
+		o.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+
+	switch {
+	case err == nil:
+		o.state.State = models.OperationSucceeded
+
+	case o.canceled:
+		o.state.State = models.OperationCanceled
+		o.state.Error = err.Error()
+
+	default:
+		o.state.State = models.OperationFailed
+		o.state.Error = err.Error()
+	}
+
+	o.state.Progress = ""
+	o.state.Cancelable = false
+	o.state.UpdatedAt = now
+	o.state.FinishedAt = &now
+
+	if o.cancel != nil {
+		o.cancel()
+		o.cancel = nil
+	}
+
+	state, onFinish := o.state, o.onFinish
+	o.onFinish = nil
+	o.mu.Unlock()
+
+	for _, fn := range onFinish {
+		fn(state)
+	}
+}
+
+func (o *Operation) update(fn func(state *models.Operation)) {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.state.IsFinished() {
+		return
+	}
+
+	fn(&o.state)
+	o.state.UpdatedAt = time.Now()
+}
+
+type operationKey struct{}
+
+// WithOperation returns a context carrying the operation.
+func WithOperation(ctx context.Context, op *Operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// FromContext returns the operation carried by the context or nil if there is none.
+func FromContext(ctx context.Context) *Operation {
+	op, _ := ctx.Value(operationKey{}).(*Operation)
+
+	return op
+}
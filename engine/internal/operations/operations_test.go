@@ -0,0 +1,164 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestOperationLifecycle(t *testing.T) {
+	r := NewRegistry(Config{})
+
+	op := r.Start("clone.create", "")
+	op.SetTarget("clone1")
+	op.SetProgress("starting Postgres")
+
+	state, err := r.Get(op.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "clone.create", state.Type)
+	assert.Equal(t, "clone1", state.Target)
+	assert.Equal(t, models.OperationRunning, state.State)
+	assert.Equal(t, "starting Postgres", state.Progress)
+	assert.False(t, state.Cancelable)
+	assert.Nil(t, state.FinishedAt)
+
+	op.SetResult("snapshot1")
+	op.Finish(nil)
+	op.Finish(errors.New("late failure"))
+	op.SetProgress("ignored")
+	op.SetResult("ignored")
+
+	state, err = r.Get(op.ID())
+	require.NoError(t, err)
+	assert.Equal(t, models.OperationSucceeded, state.State)
+	assert.Equal(t, "snapshot1", state.Result)
+	assert.Empty(t, state.Progress)
+	assert.Empty(t, state.Error)
+	require.NotNil(t, state.FinishedAt)
+}
+
+func TestOperationFailure(t *testing.T) {
+	r := NewRegistry(Config{})
+
+	op := r.Start("clone.reset", "clone1")
+	op.Finish(errors.New("failed to start container"))
+
+	state, err := r.Get(op.ID())
+	require.NoError(t, err)
+	assert.Equal(t, models.OperationFailed, state.State)
+	assert.Equal(t, "failed to start container", state.Error)
+}
+
+func TestOnFinish(t *testing.T) {
+	r := NewRegistry(Config{})
+
+	op := r.Start("clone.create", "clone1")
+
+	var states []models.Operation
+
+	op.OnFinish(func(state models.Operation) { states = append(states, state) })
+	assert.Empty(t, states, "the function is called once the operation finishes")
+
+	op.Finish(errors.New("failed to start container"))
+	require.Len(t, states, 1)
+	assert.Equal(t, models.OperationFailed, states[0].State)
+
+	op.OnFinish(func(state models.Operation) { states = append(states, state) })
+	require.Len(t, states, 2, "the function is called right away for a finished operation")
+	assert.Equal(t, "failed to start container", states[1].Error)
+}
+
+func TestCancel(t *testing.T) {
+	r := NewRegistry(Config{})
+
+	op := r.Start("clone.create", "clone1")
+	ctx := op.WithCancel(context.Background())
+
+	state, err := r.Get(op.ID())
+	require.NoError(t, err)
+	assert.True(t, state.Cancelable)
+
+	state, err = r.Cancel(op.ID())
+	require.NoError(t, err)
+	assert.Equal(t, models.OperationRunning, state.State, "the operation runs until the interrupted work stops")
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	op.Finish(ctx.Err())
+
+	state, err = r.Get(op.ID())
+	require.NoError(t, err)
+	assert.Equal(t, models.OperationCanceled, state.State)
+	assert.False(t, state.Cancelable)
+
+	_, err = r.Cancel(op.ID())
+	assert.ErrorIs(t, err, ErrFinished)
+}
+
+func TestCancelErrors(t *testing.T) {
+	r := NewRegistry(Config{})
+
+	_, err := r.Cancel("unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	op := r.Start("clone.reset", "clone1")
+
+	_, err = r.Cancel(op.ID())
+	assert.ErrorIs(t, err, ErrNotCancelable)
+}
+
+func TestCleanup(t *testing.T) {
+	r := NewRegistry(Config{RetentionMinutes: 10})
+
+	finished := r.Start("clone.destroy", "clone1")
+	finished.Finish(nil)
+
+	running := r.Start("instance.full_refresh", "")
+
+	r.cleanup(time.Now().Add(5 * time.Minute))
+
+	_, err := r.Get(finished.ID())
+	require.NoError(t, err, "finished operations are kept within the retention time")
+
+	r.cleanup(time.Now().Add(11 * time.Minute))
+
+	_, err = r.Get(finished.ID())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = r.Get(running.ID())
+	assert.NoError(t, err, "running operations are never removed")
+}
+
+func TestNilOperation(t *testing.T) {
+	ctx := context.Background()
+
+	op := FromContext(ctx)
+	require.Nil(t, op)
+
+	assert.Empty(t, op.ID())
+	assert.Equal(t, ctx, op.WithCancel(ctx))
+	assert.False(t, op.IsDetached())
+
+	assert.Equal(t, models.Operation{}, op.State())
+
+	op.SetTarget("clone1")
+	op.SetProgress("starting Postgres")
+	op.SetResult("snapshot1")
+	op.Detach()
+	op.Finish(nil)
+}
+
+func TestContext(t *testing.T) {
+	r := NewRegistry(Config{})
+	op := r.Start("clone.create", "")
+
+	ctx := WithOperation(context.Background(), op)
+	assert.Same(t, op, FromContext(ctx))
+
+	assert.Nil(t, FromContext(WithOperation(ctx, nil)), "a nil operation hides the one of the parent context")
+}
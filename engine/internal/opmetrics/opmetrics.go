@@ -0,0 +1,152 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package opmetrics provides Prometheus metrics of engine operations: durations and outcomes of clone,
+// snapshot, and branch operations and retrieval jobs, latency of filesystem commands, and API requests.
+//
+// Unlike the gauges of the metrics package, which are refreshed on scrape, these metrics are updated
+// where the operations happen, so the package has no dependencies on engine services.
+package opmetrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "dblab"
+
+// Outcomes of observed operations.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Resources of observed operations.
+const (
+	ResourceClone    = "clone"
+	ResourceSnapshot = "snapshot"
+	ResourceBranch   = "branch"
+)
+
+var (
+	operationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of clone, snapshot, and branch operations in seconds",
+			Buckets:   prometheus.ExponentialBuckets(0.25, 2, 14),
+		},
+		[]string{"resource", "operation", "outcome"},
+	)
+	operationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "operations_total",
+			Help:      "Total number of clone, snapshot, and branch operations by outcome",
+		},
+		[]string{"resource", "operation", "outcome"},
+	)
+
+	retrievalJobDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "retrieval_job_duration_seconds",
+			Help:      "Duration of retrieval jobs in seconds",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+		},
+		[]string{"job", "outcome"},
+	)
+	retrievalJobsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "retrieval_jobs_total",
+			Help:      "Total number of retrieval jobs by outcome",
+		},
+		[]string{"job", "outcome"},
+	)
+
+	commandDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "fs_command_duration_seconds",
+			Help:      "Latency of filesystem commands (ZFS, LVM, Btrfs) in seconds",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+		},
+		[]string{"command", "outcome"},
+	)
+
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of API requests in seconds",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"route", "method"},
+	)
+	httpRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of API requests by route, method, and status code",
+		},
+		[]string{"route", "method", "code"},
+	)
+)
+
+// Register registers the operation metrics with the Prometheus registry.
+func Register(reg prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{
+		operationDuration,
+		operationsTotal,
+		retrievalJobDuration,
+		retrievalJobsTotal,
+		commandDuration,
+		httpRequestDuration,
+		httpRequestsTotal,
+	} {
+		if err := reg.Register(collector); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ObserveOperation records the duration and the outcome of an operation on a clone, snapshot, or branch.
+func ObserveOperation(resource, operation string, startedAt time.Time, err error) {
+	outcome := outcomeOf(err)
+
+	operationDuration.WithLabelValues(resource, operation, outcome).Observe(time.Since(startedAt).Seconds())
+	operationsTotal.WithLabelValues(resource, operation, outcome).Inc()
+}
+
+// ObserveRetrievalJob records the duration and the outcome of a retrieval job.
+func ObserveRetrievalJob(job string, startedAt time.Time, err error) {
+	outcome := outcomeOf(err)
+
+	retrievalJobDuration.WithLabelValues(job, outcome).Observe(time.Since(startedAt).Seconds())
+	retrievalJobsTotal.WithLabelValues(job, outcome).Inc()
+}
+
+// ObserveCommand records the latency of a filesystem command.
+func ObserveCommand(command string, duration time.Duration, err error) {
+	commandDuration.WithLabelValues(command, outcomeOf(err)).Observe(duration.Seconds())
+}
+
+// ObserveHTTPRequest records an API request. The route is the path template, so IDs do not inflate cardinality.
+func ObserveHTTPRequest(route, method string, code int, duration time.Duration) {
+	httpRequestDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+	httpRequestsTotal.WithLabelValues(route, method, strconv.Itoa(code)).Inc()
+}
+
+func outcomeOf(err error) string {
+	if err != nil {
+		return OutcomeFailure
+	}
+
+	return OutcomeSuccess
+}
@@ -0,0 +1,82 @@
+package opmetrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findMetric returns the metric of the family with the given labels.
+func findMetric(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			matched := 0
+
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] == label.GetValue() {
+					matched++
+				}
+			}
+
+			if matched == len(labels) {
+				return metric
+			}
+		}
+	}
+
+	t.Fatalf("metric %s%v not found", name, labels)
+
+	return nil
+}
+
+func TestObserveMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	require.NoError(t, Register(reg))
+
+	for _, vec := range []interface{ Reset() }{operationDuration, operationsTotal, retrievalJobDuration,
+		retrievalJobsTotal, commandDuration, httpRequestDuration, httpRequestsTotal} {
+		vec.Reset()
+	}
+
+	startedAt := time.Now().Add(-2 * time.Second)
+
+	ObserveOperation(ResourceClone, "reset", startedAt, nil)
+	ObserveOperation(ResourceClone, "reset", startedAt, errors.New("failed"))
+	ObserveRetrievalJob("logicalDump", startedAt, nil)
+	ObserveCommand("zfs snapshot", 10*time.Millisecond, nil)
+	ObserveHTTPRequest("/clone/{id}", "GET", 404, 5*time.Millisecond)
+
+	failures := findMetric(t, reg, "dblab_operations_total",
+		map[string]string{"resource": "clone", "operation": "reset", "outcome": OutcomeFailure})
+	assert.Equal(t, 1.0, failures.GetCounter().GetValue())
+
+	duration := findMetric(t, reg, "dblab_operation_duration_seconds",
+		map[string]string{"resource": "clone", "operation": "reset", "outcome": OutcomeSuccess})
+	assert.Equal(t, uint64(1), duration.GetHistogram().GetSampleCount())
+	assert.GreaterOrEqual(t, duration.GetHistogram().GetSampleSum(), 2.0)
+
+	job := findMetric(t, reg, "dblab_retrieval_jobs_total", map[string]string{"job": "logicalDump", "outcome": OutcomeSuccess})
+	assert.Equal(t, 1.0, job.GetCounter().GetValue())
+
+	command := findMetric(t, reg, "dblab_fs_command_duration_seconds", map[string]string{"command": "zfs snapshot"})
+	assert.Equal(t, uint64(1), command.GetHistogram().GetSampleCount())
+
+	requests := findMetric(t, reg, "dblab_http_requests_total", map[string]string{"route": "/clone/{id}", "method": "GET", "code": "404"})
+	assert.Equal(t, 1.0, requests.GetCounter().GetValue())
+
+	assert.Error(t, Register(reg), "metrics must not be registered twice in the same registry")
+}
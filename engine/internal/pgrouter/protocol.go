@@ -0,0 +1,268 @@
+/*
+2026 © Postgres.ai
+*/
+
+package pgrouter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// protocolVersion3 is the only startup protocol version supported by Postgres.
+	protocolVersion3 = 196608
+
+	// Special request codes sent instead of the protocol version.
+	cancelRequestCode  = 80877102
+	sslRequestCode     = 80877103
+	gssEncRequestCode  = 80877104
+	maxStartupLength   = 10000
+	startupHeaderBytes = 8
+
+	// Backend message types the router has to look at.
+	backendKeyDataType = 'K'
+	readyForQueryType  = 'Z'
+	errorResponseType  = 'E'
+
+	// Responses to SSLRequest and GSSENCRequest.
+	encryptionAccepted = 'S'
+	encryptionRejected = 'N'
+)
+
+// SQLSTATE codes of the errors reported by the router.
+const (
+	codeProtocolViolation = "08P01"
+	codeRejected          = "08004"
+	codeCannotConnectNow  = "57P03"
+	codeUnknownClone      = "3D000"
+)
+
+var errStartupTooLong = errors.New("startup packet is too long")
+
+// startupParam is a parameter of a startup message.
+type startupParam struct {
+	name  string
+	value string
+}
+
+// startupParams keeps startup parameters in the order they have been sent.
+type startupParams []startupParam
+
+func (p startupParams) get(name string) string {
+	for _, param := range p {
+		if param.name == name {
+			return param.value
+		}
+	}
+
+	return ""
+}
+
+func (p startupParams) set(name, value string) startupParams {
+	for i := range p {
+		if p[i].name == name {
+			p[i].value = value
+			return p
+		}
+	}
+
+	return append(p, startupParam{name: name, value: value})
+}
+
+func (p startupParams) remove(name string) startupParams {
+	params := make(startupParams, 0, len(p))
+
+	for _, param := range p {
+		if param.name != name {
+			params = append(params, param)
+		}
+	}
+
+	return params
+}
+
+// readStartupMessage reads a message sent before the protocol is negotiated: a startup message,
+// SSLRequest, GSSENCRequest, or CancelRequest. It returns the request code and the rest of the message.
+func readStartupMessage(r io.Reader) (uint32, []byte, error) {
+	header := make([]byte, startupHeaderBytes)
+
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < startupHeaderBytes || length > maxStartupLength {
+		return 0, nil, errStartupTooLong
+	}
+
+	payload := make([]byte, length-startupHeaderBytes)
+
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	return binary.BigEndian.Uint32(header[4:]), payload, nil
+}
+
+// parseStartupParams parses null-terminated name-value pairs of a startup message.
+func parseStartupParams(payload []byte) (startupParams, error) {
+	params := startupParams{}
+
+	for len(payload) > 0 && payload[0] != 0 {
+		name, rest, ok := bytes.Cut(payload, []byte{0})
+		if !ok {
+			return nil, errors.New("malformed startup parameter name")
+		}
+
+		value, rest, ok := bytes.Cut(rest, []byte{0})
+		if !ok {
+			return nil, fmt.Errorf("malformed value of startup parameter %q", name)
+		}
+
+		params = append(params, startupParam{name: string(name), value: string(value)})
+		payload = rest
+	}
+
+	return params, nil
+}
+
+// encodeStartupMessage builds a protocol 3.0 startup message.
+func encodeStartupMessage(params startupParams) []byte {
+	body := binary.BigEndian.AppendUint32(nil, protocolVersion3)
+
+	for _, param := range params {
+		body = append(body, param.name...)
+		body = append(body, 0)
+		body = append(body, param.value...)
+		body = append(body, 0)
+	}
+
+	body = append(body, 0)
+
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(body)+4)), body...)
+}
+
+// encodeErrorResponse builds a FATAL ErrorResponse message shown to the client.
+func encodeErrorResponse(code, message string) []byte {
+	var body []byte
+
+	for _, field := range []struct {
+		kind  byte
+		value string
+	}{
+		{kind: 'S', value: "FATAL"},
+		{kind: 'V', value: "FATAL"},
+		{kind: 'C', value: code},
+		{kind: 'M', value: message},
+	} {
+		body = append(body, field.kind)
+		body = append(body, field.value...)
+		body = append(body, 0)
+	}
+
+	body = append(body, 0)
+
+	response := append([]byte{errorResponseType}, binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))...)
+
+	return append(response, body...)
+}
+
+// readBackendMessage reads a message sent by Postgres after the startup message.
+func readBackendMessage(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 5)
+
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[1:])
+	if length < 4 {
+		return 0, nil, fmt.Errorf("invalid length of backend message %q", header[0])
+	}
+
+	message := make([]byte, len(header)+int(length)-4)
+	copy(message, header)
+
+	if _, err := io.ReadFull(r, message[len(header):]); err != nil {
+		return 0, nil, err
+	}
+
+	return header[0], message, nil
+}
+
+// splitOptions splits the value of the options parameter into command-line arguments.
+// Escaped spaces are kept as is, so the arguments can be joined back without changes.
+func splitOptions(options string) []string {
+	args := []string{}
+
+	var current strings.Builder
+
+	escaped := false
+
+	for _, r := range options {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if current.Len() > 0 {
+				args = append(args, current.String())
+				current.Reset()
+			}
+
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	if current.Len() > 0 {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+// extractCloneOption finds the clone ID in the options parameter. It accepts "clone=<id>", "-c clone=<id>",
+// and "--clone=<id>" and returns the options without it, as Postgres does not know this setting.
+func extractCloneOption(options string) (string, string) {
+	args := splitOptions(options)
+	rest := make([]string, 0, len(args))
+	cloneID := ""
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "-c" && i+1 < len(args) && strings.HasPrefix(args[i+1], cloneOption) {
+			cloneID = strings.TrimPrefix(args[i+1], cloneOption)
+			i++
+
+			continue
+		}
+
+		if value, ok := cutAnyPrefix(arg, cloneOption, "-c"+cloneOption, "--"+cloneOption); ok {
+			cloneID = value
+			continue
+		}
+
+		rest = append(rest, arg)
+	}
+
+	return cloneID, strings.Join(rest, " ")
+}
+
+func cutAnyPrefix(s string, prefixes ...string) (string, bool) {
+	for _, prefix := range prefixes {
+		if value, ok := strings.CutPrefix(s, prefix); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,568 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package pgrouter provides a listener that speaks the Postgres startup protocol
+// and routes connections to clones over a single port.
+package pgrouter
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	// cloneOption is the pseudo-setting in the options parameter that selects a clone.
+	cloneOption = "clone="
+
+	startupTimeout = 30 * time.Second
+	dialTimeout    = 10 * time.Second
+	defaultPort    = 5432
+)
+
+// Config defines the Postgres router.
+type Config struct {
+	// Enabled starts the router.
+	Enabled bool `yaml:"enabled"`
+
+	// Host is the address to listen on; all interfaces are used if it is empty.
+	Host string `yaml:"host"`
+
+	// Port is the port to listen on.
+	Port uint `yaml:"port"`
+
+	// TLS enables SSL connections to the router. Connections to clones are made over the internal network.
+	TLS TLSConfig `yaml:"tls"`
+}
+
+// TLSConfig defines the certificate of the router.
+type TLSConfig struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
+
+// Clones provides the router with clones to route connections to.
+type Clones interface {
+	// ConnectClone returns a clone ready to accept a new connection, waking it up if needed.
+	ConnectClone(ctx context.Context, cloneID string) (*models.Clone, error)
+
+	// UpdateCloneActivity records activity of a clone, so it is not treated as idle.
+	UpdateCloneActivity(cloneID string) error
+
+	// HasClone checks if the clone exists.
+	HasClone(cloneID string) bool
+}
+
+// Router accepts Postgres connections and routes each of them to the clone selected
+// by the database name or by the "clone" option.
+type Router struct {
+	clones Clones
+
+	// address returns the address the clone accepts connections on.
+	address func(clone *models.Clone) string
+
+	mu       sync.Mutex
+	cfg      Config
+	listener net.Listener
+
+	statsMu    sync.Mutex
+	stats      map[string]*cloneStats
+	cancelKeys map[backendKey]string
+}
+
+type cloneStats struct {
+	active          int
+	total           uint64
+	lastConnectedAt time.Time
+}
+
+// backendKey identifies a Postgres backend in CancelRequest messages.
+type backendKey struct {
+	processID uint32
+	secretKey uint32
+}
+
+// New creates a new Postgres router.
+func New(cfg Config, clones Clones) *Router {
+	return &Router{
+		cfg:        cfg,
+		clones:     clones,
+		address:    containerAddress,
+		stats:      make(map[string]*cloneStats),
+		cancelKeys: make(map[backendKey]string),
+	}
+}
+
+// containerAddress returns the address of the clone container in the internal network of the engine.
+func containerAddress(clone *models.Clone) string {
+	return net.JoinHostPort(clone.ID, clone.DB.Port)
+}
+
+// Validate checks the router configuration.
+func (cfg Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		return errors.New("both certFile and keyFile must be set to enable TLS")
+	}
+
+	return nil
+}
+
+func (cfg Config) listenAddress() string {
+	port := cfg.Port
+	if port == 0 {
+		port = defaultPort
+	}
+
+	return net.JoinHostPort(cfg.Host, strconv.FormatUint(uint64(port), 10))
+}
+
+// Run starts the router if it is enabled. The router stops when the context is canceled.
+func (r *Router) Run(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.start(); err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		r.mu.Lock()
+		r.stop()
+		r.mu.Unlock()
+	}()
+
+	return nil
+}
+
+// Reload applies a new configuration, restarting the listener.
+func (r *Router) Reload(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stop()
+	r.cfg = cfg
+
+	return r.start()
+}
+
+func (r *Router) start() error {
+	if !r.cfg.Enabled {
+		return nil
+	}
+
+	if err := r.cfg.Validate(); err != nil {
+		return err
+	}
+
+	tlsConfig, err := loadTLSConfig(r.cfg.TLS)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", r.cfg.listenAddress())
+	if err != nil {
+		return fmt.Errorf("failed to start Postgres router: %w", err)
+	}
+
+	r.listener = listener
+
+	log.Msg("Postgres router is listening on", listener.Addr().String())
+
+	go r.serve(listener, tlsConfig)
+
+	return nil
+}
+
+func (r *Router) stop() {
+	if r.listener == nil {
+		return
+	}
+
+	if err := r.listener.Close(); err != nil {
+		log.Dbg("failed to close Postgres router listener:", err)
+	}
+
+	r.listener = nil
+}
+
+func loadTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" {
+		return nil, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate of Postgres router: %w", err)
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}, nil
+}
+
+func (r *Router) serve(listener net.Listener, tlsConfig *tls.Config) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Err("Postgres router stopped accepting connections:", err)
+			}
+
+			return
+		}
+
+		go r.handle(conn, tlsConfig)
+	}
+}
+
+// handle negotiates encryption, reads the startup message, and proxies the connection to the clone.
+func (r *Router) handle(conn net.Conn, tlsConfig *tls.Config) {
+	defer func() { _ = conn.Close() }()
+
+	if err := conn.SetDeadline(time.Now().Add(startupTimeout)); err != nil {
+		log.Dbg("failed to set deadline of Postgres router connection:", err)
+		return
+	}
+
+	client, params, err := r.negotiate(conn, tlsConfig)
+	if err != nil {
+		log.Dbg("Postgres router rejected connection:", err)
+		return
+	}
+
+	if client == nil {
+		// A cancel request has been handled.
+		return
+	}
+
+	defer func() { _ = client.Close() }()
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		log.Dbg("failed to reset deadline of Postgres router connection:", err)
+		return
+	}
+
+	cloneID, byDatabase, params, err := routeParams(params)
+	if err != nil {
+		rejectConnection(client, codeRejected, err.Error())
+		return
+	}
+
+	ctx := context.Background()
+
+	clone, err := r.clones.ConnectClone(ctx, cloneID)
+	if err != nil {
+		code := codeCannotConnectNow
+
+		var modelErr *models.Error
+		if errors.As(err, &modelErr) && modelErr.Code == models.ErrCodeNotFound {
+			code = codeUnknownClone
+		}
+
+		rejectConnection(client, code, fmt.Sprintf("cannot route connection to clone %q: %v", cloneID, err))
+
+		return
+	}
+
+	if byDatabase {
+		// The clone ID replaces the database name, so connect to the default database of the clone.
+		params = params.set("database", clone.DB.DBName)
+
+		if clone.DB.DBName == "" {
+			params = params.remove("database")
+		}
+	}
+
+	backend, err := net.DialTimeout("tcp", r.address(clone), dialTimeout)
+	if err != nil {
+		rejectConnection(client, codeCannotConnectNow, fmt.Sprintf("cannot connect to clone %q", cloneID))
+		log.Err(fmt.Sprintf("Postgres router failed to connect to clone %s: %v", cloneID, err))
+
+		return
+	}
+
+	defer func() { _ = backend.Close() }()
+
+	if _, err := backend.Write(encodeStartupMessage(params)); err != nil {
+		log.Err(fmt.Sprintf("Postgres router failed to send startup message to clone %s: %v", cloneID, err))
+		return
+	}
+
+	r.connected(cloneID)
+	defer r.disconnected(cloneID)
+
+	r.proxy(client, backend, clone)
+}
+
+// negotiate handles encryption requests and returns the connection with the startup parameters.
+// A nil connection without an error means the request has been served, e.g. it was a cancel request.
+func (r *Router) negotiate(conn net.Conn, tlsConfig *tls.Config) (net.Conn, startupParams, error) {
+	encrypted := false
+
+	for {
+		code, payload, err := readStartupMessage(conn)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch code {
+		case sslRequestCode:
+			if tlsConfig == nil || encrypted {
+				if _, err := conn.Write([]byte{encryptionRejected}); err != nil {
+					return nil, nil, err
+				}
+
+				continue
+			}
+
+			if _, err := conn.Write([]byte{encryptionAccepted}); err != nil {
+				return nil, nil, err
+			}
+
+			tlsConn := tls.Server(conn, tlsConfig)
+
+			if err := tlsConn.Handshake(); err != nil {
+				return nil, nil, fmt.Errorf("TLS handshake failed: %w", err)
+			}
+
+			conn = tlsConn
+			encrypted = true
+
+		case gssEncRequestCode:
+			if _, err := conn.Write([]byte{encryptionRejected}); err != nil {
+				return nil, nil, err
+			}
+
+		case cancelRequestCode:
+			r.cancel(payload)
+
+			return nil, nil, nil
+
+		case protocolVersion3:
+			params, err := parseStartupParams(payload)
+			if err != nil {
+				rejectConnection(conn, codeProtocolViolation, err.Error())
+				return nil, nil, err
+			}
+
+			return conn, params, nil
+
+		default:
+			message := fmt.Sprintf("unsupported frontend protocol %d.%d", code>>16, code&0xffff)
+			rejectConnection(conn, codeProtocolViolation, message)
+
+			return nil, nil, errors.New(message)
+		}
+	}
+}
+
+// routeParams returns the ID of the requested clone, whether it has been passed as the database name,
+// and the startup parameters to send to the clone.
+func routeParams(params startupParams) (string, bool, startupParams, error) {
+	cloneID, options := extractCloneOption(params.get("options"))
+
+	if cloneID != "" {
+		if options == "" {
+			return cloneID, false, params.remove("options"), nil
+		}
+
+		return cloneID, false, params.set("options", options), nil
+	}
+
+	if database := params.get("database"); database != "" {
+		return database, true, params, nil
+	}
+
+	return "", false, nil, errors.New("no clone specified: use the clone ID as the database name or set options=clone=<id>")
+}
+
+func rejectConnection(conn net.Conn, code, message string) {
+	if _, err := conn.Write(encodeErrorResponse(code, message)); err != nil {
+		log.Dbg("failed to send error to Postgres client:", err)
+	}
+}
+
+// proxy passes messages between the client and the clone until either side closes the connection.
+// Messages from the clone are inspected until the session is established to learn its cancellation key.
+func (r *Router) proxy(client, backend net.Conn, clone *models.Clone) {
+	done := make(chan struct{}, 2)
+
+	go func() {
+		_, _ = io.Copy(backend, client)
+		done <- struct{}{}
+	}()
+
+	go func() {
+		key := r.relayStartup(client, backend, clone)
+		if key != nil {
+			defer r.forgetCancelKey(*key)
+		}
+
+		done <- struct{}{}
+	}()
+
+	<-done
+}
+
+// relayStartup forwards the authentication and startup phase, then the rest of the session.
+// It returns the cancellation key of the backend, if it has been received.
+func (r *Router) relayStartup(client, backend net.Conn, clone *models.Clone) *backendKey {
+	reader := bufio.NewReader(backend)
+
+	var key *backendKey
+
+	for {
+		messageType, message, err := readBackendMessage(reader)
+		if err != nil {
+			return key
+		}
+
+		if messageType == backendKeyDataType && len(message) >= 13 {
+			key = &backendKey{
+				processID: binary.BigEndian.Uint32(message[5:9]),
+				secretKey: binary.BigEndian.Uint32(message[9:13]),
+			}
+
+			r.rememberCancelKey(*key, r.address(clone))
+		}
+
+		if _, err := client.Write(message); err != nil {
+			return key
+		}
+
+		if messageType == readyForQueryType || messageType == errorResponseType {
+			break
+		}
+	}
+
+	_, _ = io.Copy(client, reader)
+
+	return key
+}
+
+// cancel forwards a cancel request to the clone that owns the backend.
+func (r *Router) cancel(payload []byte) {
+	if len(payload) < 8 {
+		return
+	}
+
+	key := backendKey{
+		processID: binary.BigEndian.Uint32(payload[:4]),
+		secretKey: binary.BigEndian.Uint32(payload[4:8]),
+	}
+
+	r.statsMu.Lock()
+	address, ok := r.cancelKeys[key]
+	r.statsMu.Unlock()
+
+	if !ok {
+		log.Dbg("Postgres router received cancel request for unknown backend")
+		return
+	}
+
+	backend, err := net.DialTimeout("tcp", address, dialTimeout)
+	if err != nil {
+		log.Err("Postgres router failed to forward cancel request:", err)
+		return
+	}
+
+	defer func() { _ = backend.Close() }()
+
+	request := binary.BigEndian.AppendUint32(nil, uint32(startupHeaderBytes+len(payload)))
+	request = binary.BigEndian.AppendUint32(request, cancelRequestCode)
+
+	if _, err := backend.Write(append(request, payload...)); err != nil {
+		log.Err("Postgres router failed to forward cancel request:", err)
+	}
+}
+
+func (r *Router) rememberCancelKey(key backendKey, address string) {
+	r.statsMu.Lock()
+	r.cancelKeys[key] = address
+	r.statsMu.Unlock()
+}
+
+func (r *Router) forgetCancelKey(key backendKey) {
+	r.statsMu.Lock()
+	delete(r.cancelKeys, key)
+	r.statsMu.Unlock()
+}
+
+func (r *Router) connected(cloneID string) {
+	r.statsMu.Lock()
+
+	stats, ok := r.stats[cloneID]
+	if !ok {
+		stats = &cloneStats{}
+		r.stats[cloneID] = stats
+	}
+
+	stats.active++
+	stats.total++
+	stats.lastConnectedAt = time.Now()
+
+	r.statsMu.Unlock()
+}
+
+func (r *Router) disconnected(cloneID string) {
+	r.statsMu.Lock()
+
+	if stats, ok := r.stats[cloneID]; ok {
+		stats.active--
+	}
+
+	r.statsMu.Unlock()
+
+	if err := r.clones.UpdateCloneActivity(cloneID); err != nil {
+		log.Dbg(fmt.Sprintf("failed to update activity of clone %s: %v", cloneID, err))
+	}
+}
+
+// Connections reports connections routed to each clone. Statistics of removed clones are dropped.
+func (r *Router) Connections() []models.CloneConnections {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+
+	connections := make([]models.CloneConnections, 0, len(r.stats))
+
+	for cloneID, stats := range r.stats {
+		if stats.active == 0 && !r.clones.HasClone(cloneID) {
+			delete(r.stats, cloneID)
+			continue
+		}
+
+		connections = append(connections, models.CloneConnections{
+			CloneID:         cloneID,
+			Active:          stats.active,
+			Total:           stats.total,
+			LastConnectedAt: models.NewLocalTime(stats.lastConnectedAt),
+		})
+	}
+
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].CloneID < connections[j].CloneID
+	})
+
+	return connections
+}
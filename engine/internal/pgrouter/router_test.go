@@ -0,0 +1,337 @@
+package pgrouter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+type mockClones struct {
+	mu       sync.Mutex
+	clones   map[string]*models.Clone
+	activity map[string]int
+}
+
+func (m *mockClones) ConnectClone(_ context.Context, cloneID string) (*models.Clone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clone, ok := m.clones[cloneID]
+	if !ok {
+		return nil, models.New(models.ErrCodeNotFound, "clone not found")
+	}
+
+	return clone, nil
+}
+
+func (m *mockClones) UpdateCloneActivity(cloneID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.activity[cloneID]++
+
+	return nil
+}
+
+func (m *mockClones) HasClone(cloneID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.clones[cloneID]
+
+	return ok
+}
+
+// fakeBackend imitates Postgres: it completes the startup with a cancellation key and echoes the rest.
+type fakeBackend struct {
+	listener net.Listener
+	startups chan startupParams
+	cancels  chan []byte
+}
+
+func newFakeBackend(t *testing.T) *fakeBackend {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	backend := &fakeBackend{
+		listener: listener,
+		startups: make(chan startupParams, 1),
+		cancels:  make(chan []byte, 1),
+	}
+
+	go backend.serve()
+
+	return backend
+}
+
+func (b *fakeBackend) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go b.handle(conn)
+	}
+}
+
+func (b *fakeBackend) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	code, payload, err := readStartupMessage(conn)
+	if err != nil {
+		return
+	}
+
+	if code == cancelRequestCode {
+		b.cancels <- payload
+		return
+	}
+
+	params, err := parseStartupParams(payload)
+	if err != nil {
+		return
+	}
+
+	b.startups <- params
+
+	var messages []byte
+
+	messages = append(messages, 'R', 0, 0, 0, 8, 0, 0, 0, 0)
+	messages = append(messages, backendKeyDataType, 0, 0, 0, 12, 0, 0, 0, 1, 0, 0, 0, 2)
+	messages = append(messages, readyForQueryType, 0, 0, 0, 5, 'I')
+
+	if _, err := conn.Write(messages); err != nil {
+		return
+	}
+
+	_, _ = io.Copy(conn, conn)
+}
+
+func startRouter(t *testing.T, clones Clones, backend *fakeBackend) *Router {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	router := New(Config{Enabled: true, Host: "127.0.0.1", Port: uint(port)}, clones)
+	router.address = func(*models.Clone) string { return backend.listener.Addr().String() }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	require.NoError(t, router.Run(ctx))
+
+	return router
+}
+
+func dialRouter(t *testing.T, router *Router) net.Conn {
+	t.Helper()
+
+	conn, err := net.DialTimeout("tcp", router.cfg.listenAddress(), time.Second)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	return conn
+}
+
+func specialRequest(code uint32, payload ...byte) []byte {
+	request := binary.BigEndian.AppendUint32(nil, uint32(startupHeaderBytes+len(payload)))
+	request = binary.BigEndian.AppendUint32(request, code)
+
+	return append(request, payload...)
+}
+
+func TestRouter(t *testing.T) {
+	clones := &mockClones{
+		clones:   map[string]*models.Clone{"clone1": {ID: "clone1", DB: models.Database{DBName: "test"}}},
+		activity: make(map[string]int),
+	}
+	backend := newFakeBackend(t)
+	router := startRouter(t, clones, backend)
+
+	conn := dialRouter(t, router)
+	reader := bufio.NewReader(conn)
+
+	// SSL is not configured, so the client is asked to continue without encryption.
+	_, err := conn.Write(specialRequest(sslRequestCode))
+	require.NoError(t, err)
+
+	response, err := reader.ReadByte()
+	require.NoError(t, err)
+	assert.Equal(t, byte(encryptionRejected), response)
+
+	_, err = conn.Write(encodeStartupMessage(startupParams{{name: "user", value: "john"}, {name: "database", value: "clone1"}}))
+	require.NoError(t, err)
+
+	select {
+	case params := <-backend.startups:
+		assert.Equal(t, "john", params.get("user"))
+		assert.Equal(t, "test", params.get("database"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("startup message has not been routed to the clone")
+	}
+
+	for _, expected := range []byte{'R', backendKeyDataType, readyForQueryType} {
+		messageType, _, err := readBackendMessage(reader)
+		require.NoError(t, err)
+		assert.Equal(t, expected, messageType)
+	}
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(reader, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(reply))
+
+	connections := router.Connections()
+	require.Len(t, connections, 1)
+	assert.Equal(t, "clone1", connections[0].CloneID)
+	assert.Equal(t, 1, connections[0].Active)
+	assert.Equal(t, uint64(1), connections[0].Total)
+
+	// A cancel request is forwarded to the clone that owns the backend.
+	cancelConn := dialRouter(t, router)
+	_, err = cancelConn.Write(specialRequest(cancelRequestCode, 0, 0, 0, 1, 0, 0, 0, 2))
+	require.NoError(t, err)
+
+	select {
+	case payload := <-backend.cancels:
+		assert.Equal(t, []byte{0, 0, 0, 1, 0, 0, 0, 2}, payload)
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancel request has not been forwarded to the clone")
+	}
+
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool {
+		connections := router.Connections()
+		return len(connections) == 1 && connections[0].Active == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	clones.mu.Lock()
+	assert.Equal(t, 1, clones.activity["clone1"])
+	clones.mu.Unlock()
+}
+
+func TestRouterUnknownClone(t *testing.T) {
+	clones := &mockClones{clones: map[string]*models.Clone{}, activity: make(map[string]int)}
+	router := startRouter(t, clones, newFakeBackend(t))
+
+	conn := dialRouter(t, router)
+
+	_, err := conn.Write(encodeStartupMessage(startupParams{{name: "user", value: "john"}, {name: "options", value: "-c clone=missing"}}))
+	require.NoError(t, err)
+
+	messageType, message, err := readBackendMessage(bufio.NewReader(conn))
+	require.NoError(t, err)
+	assert.Equal(t, byte(errorResponseType), messageType)
+	assert.Contains(t, string(message), codeUnknownClone)
+	assert.Contains(t, string(message), `clone "missing"`)
+
+	assert.Empty(t, router.Connections())
+}
+
+func TestRouterReload(t *testing.T) {
+	clones := &mockClones{clones: map[string]*models.Clone{}, activity: make(map[string]int)}
+	router := startRouter(t, clones, newFakeBackend(t))
+
+	address := router.cfg.listenAddress()
+
+	require.Error(t, router.Reload(Config{Enabled: true, TLS: TLSConfig{CertFile: "cert.pem"}}))
+	assert.Equal(t, address, router.cfg.listenAddress(), "invalid configuration must not stop the router")
+
+	require.NoError(t, router.Reload(Config{Enabled: false, Port: router.cfg.Port}))
+
+	_, err := net.DialTimeout("tcp", address, time.Second)
+	assert.Error(t, err)
+}
+
+func TestRouteParams(t *testing.T) {
+	testCases := []struct {
+		name       string
+		params     startupParams
+		cloneID    string
+		byDatabase bool
+		options    string
+	}{
+		{
+			name:       "database name",
+			params:     startupParams{{name: "database", value: "clone1"}},
+			cloneID:    "clone1",
+			byDatabase: true,
+		},
+		{
+			name:    "clone option",
+			params:  startupParams{{name: "database", value: "app"}, {name: "options", value: "clone=clone1"}},
+			cloneID: "clone1",
+		},
+		{
+			name:    "clone setting among other options",
+			params:  startupParams{{name: "options", value: "-c statement_timeout=1s -c clone=clone1 --search_path=a\\ b"}},
+			cloneID: "clone1",
+			options: "-c statement_timeout=1s --search_path=a\\ b",
+		},
+		{
+			name:    "long clone option",
+			params:  startupParams{{name: "options", value: "--clone=clone1"}},
+			cloneID: "clone1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cloneID, byDatabase, params, err := routeParams(tc.params)
+			require.NoError(t, err)
+			assert.Equal(t, tc.cloneID, cloneID)
+			assert.Equal(t, tc.byDatabase, byDatabase)
+			assert.Equal(t, tc.options, params.get("options"))
+		})
+	}
+
+	_, _, _, err := routeParams(startupParams{{name: "user", value: "john"}})
+	assert.Error(t, err)
+}
+
+func TestStartupMessage(t *testing.T) {
+	params := startupParams{{name: "user", value: "john"}, {name: "database", value: "test"}}
+
+	code, payload, err := readStartupMessage(bytes.NewReader(encodeStartupMessage(params)))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(protocolVersion3), code)
+
+	parsed, err := parseStartupParams(payload)
+	require.NoError(t, err)
+	assert.Equal(t, params, parsed)
+
+	_, _, err = readStartupMessage(bytes.NewReader(specialRequest(protocolVersion3, make([]byte, maxStartupLength)...)))
+	assert.ErrorIs(t, err, errStartupTooLong)
+}
+
+func TestListenAddress(t *testing.T) {
+	assert.Equal(t, ":5432", Config{}.listenAddress())
+	assert.Equal(t, "127.0.0.1:6432", Config{Host: "127.0.0.1", Port: 6432}.listenAddress())
+}
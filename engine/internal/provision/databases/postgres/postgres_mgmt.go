@@ -5,6 +5,7 @@
 package postgres
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
 
@@ -144,6 +145,26 @@ func CreateUser(c *resources.AppConfig, user resources.EphemeralUser) error {
 	return nil
 }
 
+// RunSQL runs an SQL script as the DBLab superuser in the given database of the clone.
+func RunSQL(c *resources.AppConfig, dbName, script string) error {
+	db, err := sql.Open("postgres", getPgConnStr(c.Host, dbName, c.DB.Username, c.Port))
+	if err != nil {
+		return fmt.Errorf("cannot connect to database: %w", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Err("cannot close database connection")
+		}
+	}()
+
+	if _, err := db.Exec(script); err != nil {
+		return fmt.Errorf("failed to execute SQL: %w", err)
+	}
+
+	return nil
+}
+
 func superuserQuery(username, password string, exists bool) string {
 	if exists {
 		return fmt.Sprintf(`alter role %s with password %s login superuser;`,
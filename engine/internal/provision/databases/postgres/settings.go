@@ -0,0 +1,289 @@
+/*
+2026 © Postgres.ai
+*/
+
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+// Postgres parameter types and contexts, as reported by pg_settings.
+const (
+	varTypeBool    = "bool"
+	varTypeInteger = "integer"
+	varTypeReal    = "real"
+	varTypeEnum    = "enum"
+	varTypeString  = "string"
+
+	contextInternal   = "internal"
+	contextPostmaster = "postmaster"
+)
+
+const settingsQuery = `select name, vartype, context, coalesce(unit, ''), coalesce(min_val, ''), coalesce(max_val, ''), enumvals
+from pg_catalog.pg_settings`
+
+// SettingsListQuery lists pg_settings metadata in the unaligned psql output, one parameter per line.
+const SettingsListQuery = `select name, vartype, context, coalesce(unit, ''), coalesce(min_val, ''), coalesce(max_val, ''),
+coalesce(array_to_string(enumvals, ','), '') from pg_catalog.pg_settings`
+
+// settingsListFields defines the number of fields in a line of the SettingsListQuery output.
+const settingsListFields = 7
+
+// Setting describes a Postgres parameter using pg_settings metadata.
+type Setting struct {
+	Name     string
+	VarType  string
+	Context  string
+	Unit     string
+	MinVal   string
+	MaxVal   string
+	EnumVals []string
+}
+
+// RequiresRestart checks if a change of the parameter takes effect only after a server restart.
+func (s Setting) RequiresRestart() bool {
+	return s.Context == contextPostmaster
+}
+
+var (
+	varTypes = map[string]bool{varTypeBool: true, varTypeInteger: true, varTypeReal: true, varTypeEnum: true, varTypeString: true}
+
+	boolValues = map[string]struct{}{
+		"on": {}, "off": {}, "true": {}, "false": {}, "yes": {}, "no": {}, "1": {}, "0": {},
+	}
+
+	memoryUnits = map[string]float64{"B": 1, "kB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30, "TB": 1 << 40}
+	timeUnits   = map[string]float64{"us": 1, "ms": 1e3, "s": 1e6, "min": 60e6, "h": 3600e6, "d": 86400e6}
+
+	numericValue = regexp.MustCompile(`^\s*([-+]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE][-+]?[0-9]+)?)\s*([a-zA-Z]*)\s*$`)
+	unitPrefix   = regexp.MustCompile(`^([0-9]*)([a-zA-Z]+)$`)
+)
+
+// Validate checks that the value is acceptable for the parameter.
+func (s Setting) Validate(value string) error {
+	if s.Context == contextInternal {
+		return fmt.Errorf("parameter %q cannot be changed", s.Name)
+	}
+
+	switch s.VarType {
+	case varTypeBool:
+		if _, ok := boolValues[strings.ToLower(strings.TrimSpace(value))]; !ok {
+			return fmt.Errorf("parameter %q requires a Boolean value", s.Name)
+		}
+
+	case varTypeInteger, varTypeReal:
+		number, err := s.parseNumber(value)
+		if err != nil {
+			return err
+		}
+
+		return s.checkRange(number)
+
+	case varTypeEnum:
+		for _, enumValue := range s.EnumVals {
+			if strings.EqualFold(enumValue, strings.TrimSpace(value)) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("invalid value for parameter %q: %q; available values: %s",
+			s.Name, value, strings.Join(s.EnumVals, ", "))
+	}
+
+	return nil
+}
+
+// parseNumber converts the value to the base unit of the parameter.
+func (s Setting) parseNumber(value string) (float64, error) {
+	matches := numericValue.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, fmt.Errorf("invalid value for parameter %q: %q", s.Name, value)
+	}
+
+	number, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for parameter %q: %q", s.Name, value)
+	}
+
+	valueUnit := matches[2]
+
+	if valueUnit == "" {
+		if s.VarType == varTypeInteger && number != math.Trunc(number) {
+			return 0, fmt.Errorf("parameter %q requires an integer value", s.Name)
+		}
+
+		return number, nil
+	}
+
+	baseFactor, units, ok := parseBaseUnit(s.Unit)
+	if !ok {
+		return 0, fmt.Errorf("invalid value for parameter %q: %q; the parameter does not accept units", s.Name, value)
+	}
+
+	factor, ok := units[valueUnit]
+	if !ok {
+		return 0, fmt.Errorf("invalid unit %q for parameter %q", valueUnit, s.Name)
+	}
+
+	return number * factor / baseFactor, nil
+}
+
+func (s Setting) checkRange(number float64) error {
+	if minVal, err := strconv.ParseFloat(s.MinVal, 64); err == nil && number < minVal {
+		return fmt.Errorf("value for parameter %q is out of range: must be at least %s%s", s.Name, s.MinVal, s.Unit)
+	}
+
+	if maxVal, err := strconv.ParseFloat(s.MaxVal, 64); err == nil && number > maxVal {
+		return fmt.Errorf("value for parameter %q is out of range: must be at most %s%s", s.Name, s.MaxVal, s.Unit)
+	}
+
+	return nil
+}
+
+// parseBaseUnit parses the unit of a parameter, such as "8kB" or "ms", and returns its size in the smallest unit
+// of the same kind along with the units accepted in values.
+func parseBaseUnit(unit string) (float64, map[string]float64, bool) {
+	matches := unitPrefix.FindStringSubmatch(unit)
+	if matches == nil {
+		return 0, nil, false
+	}
+
+	multiplier := 1.0
+
+	if matches[1] != "" {
+		parsed, err := strconv.ParseFloat(matches[1], 64)
+		if err != nil {
+			return 0, nil, false
+		}
+
+		multiplier = parsed
+	}
+
+	for _, units := range []map[string]float64{memoryUnits, timeUnits} {
+		if factor, ok := units[matches[2]]; ok {
+			return multiplier * factor, units, true
+		}
+	}
+
+	return 0, nil, false
+}
+
+// LoadSettings reads metadata of all parameters of a running Postgres instance.
+func LoadSettings(c *resources.AppConfig) (map[string]Setting, error) {
+	db, err := sql.Open("postgres", getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port))
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to database: %w", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Err("cannot close database connection")
+		}
+	}()
+
+	rows, err := db.Query(settingsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pg_settings: %w", err)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	settings := make(map[string]Setting)
+
+	for rows.Next() {
+		var s Setting
+
+		if err := rows.Scan(&s.Name, &s.VarType, &s.Context, &s.Unit, &s.MinVal, &s.MaxVal, pq.Array(&s.EnumVals)); err != nil {
+			return nil, fmt.Errorf("failed to scan pg_settings: %w", err)
+		}
+
+		settings[s.Name] = s
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pg_settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// ParseSettings parses the output of SettingsListQuery produced by psql in the unaligned mode.
+// Lines that do not describe a parameter, such as notices, are skipped.
+func ParseSettings(output string) (map[string]Setting, error) {
+	settings := make(map[string]Setting)
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimRight(line, "\r"), "|")
+		if len(fields) != settingsListFields || !varTypes[fields[1]] {
+			continue
+		}
+
+		s := Setting{
+			Name:    fields[0],
+			VarType: fields[1],
+			Context: fields[2],
+			Unit:    fields[3],
+			MinVal:  fields[4],
+			MaxVal:  fields[5],
+		}
+
+		if fields[6] != "" {
+			s.EnumVals = strings.Split(fields[6], ",")
+		}
+
+		settings[s.Name] = s
+	}
+
+	if len(settings) == 0 {
+		return nil, fmt.Errorf("no parameters found in pg_settings output")
+	}
+
+	return settings, nil
+}
+
+// ConfigFileErrors returns errors Postgres would report when reading its configuration files.
+func ConfigFileErrors(c *resources.AppConfig) ([]string, error) {
+	const query = `select coalesce(name, sourcefile) || ': ' || error
+from pg_catalog.pg_file_settings where error is not null`
+
+	return runSQLSelectQuery(query, getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port))
+}
+
+// ReloadConfig makes Postgres reread its configuration files.
+func ReloadConfig(c *resources.AppConfig) error {
+	if _, err := runSimpleSQL("select pg_reload_conf()", getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port)); err != nil {
+		return fmt.Errorf("failed to reload configuration: %w", err)
+	}
+
+	return nil
+}
+
+// WaitReady waits until Postgres accepts connections.
+func WaitReady(c *resources.AppConfig, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		_, err := runSimpleSQL("select 1", getPgConnStr(c.Host, c.DB.DBName, c.DB.Username, c.Port))
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("postgres is not ready after %s: %w", timeout, err)
+		}
+
+		time.Sleep(checkPostgresStatusPeriod * time.Millisecond)
+	}
+}
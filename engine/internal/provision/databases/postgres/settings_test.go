@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingValidate(t *testing.T) {
+	sharedBuffers := Setting{Name: "shared_buffers", VarType: "integer", Context: "postmaster", Unit: "8kB", MinVal: "16", MaxVal: "1073741823"}
+	statementTimeout := Setting{Name: "statement_timeout", VarType: "integer", Context: "user", Unit: "ms", MinVal: "0", MaxVal: "2147483647"}
+	costDelay := Setting{Name: "vacuum_cost_delay", VarType: "real", Context: "user", Unit: "ms", MinVal: "0", MaxVal: "100"}
+	jit := Setting{Name: "jit", VarType: "bool", Context: "user"}
+	logLevel := Setting{Name: "log_min_messages", VarType: "enum", Context: "superuser", EnumVals: []string{"debug1", "info", "warning", "error"}}
+	appName := Setting{Name: "application_name", VarType: "string", Context: "user"}
+	blockSize := Setting{Name: "block_size", VarType: "integer", Context: "internal", MinVal: "8192", MaxVal: "8192"}
+
+	tests := []struct {
+		setting Setting
+		value   string
+		valid   bool
+	}{
+		{setting: sharedBuffers, value: "1GB", valid: true},
+		{setting: sharedBuffers, value: "16384", valid: true},
+		{setting: sharedBuffers, value: "64kB", valid: false},
+		{setting: sharedBuffers, value: "1GiB", valid: false},
+		{setting: sharedBuffers, value: "1.5", valid: false},
+		{setting: statementTimeout, value: "30s", valid: true},
+		{setting: statementTimeout, value: "1 min", valid: true},
+		{setting: statementTimeout, value: "-1", valid: false},
+		{setting: statementTimeout, value: "1MB", valid: false},
+		{setting: costDelay, value: "2.5", valid: true},
+		{setting: costDelay, value: "1s", valid: false},
+		{setting: jit, value: "Off", valid: true},
+		{setting: jit, value: "maybe", valid: false},
+		{setting: logLevel, value: "WARNING", valid: true},
+		{setting: logLevel, value: "notice", valid: false},
+		{setting: appName, value: "anything goes", valid: true},
+		{setting: blockSize, value: "8192", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.setting.Name+"="+tt.value, func(t *testing.T) {
+			err := tt.setting.Validate(tt.value)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestSettingRequiresRestart(t *testing.T) {
+	assert.True(t, Setting{Context: "postmaster"}.RequiresRestart())
+	assert.False(t, Setting{Context: "sighup"}.RequiresRestart())
+	assert.False(t, Setting{}.RequiresRestart())
+}
+
+func TestParseSettings(t *testing.T) {
+	output := "WARNING:  could not flush dirty data\n" +
+		"shared_buffers|integer|postmaster|8kB|16|1073741823|\n" +
+		"log_min_messages|enum|superuser||||debug1,info,warning\r\n" +
+		"application_name|string|user||||\n"
+
+	settings, err := ParseSettings(output)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]Setting{
+		"shared_buffers": {Name: "shared_buffers", VarType: "integer", Context: "postmaster", Unit: "8kB", MinVal: "16", MaxVal: "1073741823"},
+		"log_min_messages": {
+			Name: "log_min_messages", VarType: "enum", Context: "superuser", EnumVals: []string{"debug1", "info", "warning"},
+		},
+		"application_name": {Name: "application_name", VarType: "string", Context: "user"},
+	}, settings)
+
+	_, err = ParseSettings("psql: error: connection failed\n")
+	assert.Error(t, err)
+}
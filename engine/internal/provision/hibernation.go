@@ -0,0 +1,50 @@
+/*
+2026 © Postgres.ai
+*/
+
+package provision
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// HibernateSession stops the container of a clone. The clone dataset, the container and the port are kept.
+func (p *Provisioner) HibernateSession(ctx context.Context, clone *models.Clone) error {
+	stopTimeout := int(restartTimeout.Seconds())
+
+	if err := p.dockerClient.ContainerStop(ctx, clone.ID,
+		container.StopOptions{Signal: restartSignal, Timeout: &stopTimeout}); err != nil {
+		return fmt.Errorf("failed to stop clone container: %w", err)
+	}
+
+	return nil
+}
+
+// WakeSession starts the container of a hibernated clone and waits until Postgres accepts connections.
+func (p *Provisioner) WakeSession(ctx context.Context, session *resources.Session, clone *models.Clone) error {
+	appConfig, err := p.sessionAppConfig(session, clone)
+	if err != nil {
+		return err
+	}
+
+	if err := p.StartCloneContainer(ctx, clone.ID); err != nil {
+		return fmt.Errorf("failed to start clone container: %w", err)
+	}
+
+	return postgres.WaitReady(appConfig, restartTimeout)
+}
+
+// ReservePort marks the port as busy, so it is not allocated to another clone.
+func (p *Provisioner) ReservePort(port uint) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.setPortStatus(port, true)
+}
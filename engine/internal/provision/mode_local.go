@@ -23,7 +23,9 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/operations"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres/pgconfig"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/docker"
@@ -33,6 +35,7 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/fs"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/tracing"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/util/branching"
@@ -55,12 +58,13 @@ type PortPool struct {
 
 // Config defines configuration for provisioning.
 type Config struct {
-	PortPool             PortPool          `yaml:"portPool"`
-	DockerImage          string            `yaml:"dockerImage"`
-	UseSudo              bool              `yaml:"useSudo"`
-	KeepUserPasswords    bool              `yaml:"keepUserPasswords"`
-	ContainerConfig      map[string]string `yaml:"containerConfig"`
-	CloneAccessAddresses string            `yaml:"cloneAccessAddresses"`
+	PortPool             PortPool                 `yaml:"portPool"`
+	DockerImage          string                   `yaml:"dockerImage"`
+	UseSudo              bool                     `yaml:"useSudo"`
+	KeepUserPasswords    bool                     `yaml:"keepUserPasswords"`
+	ContainerConfig      map[string]string        `yaml:"containerConfig"`
+	CloneAccessAddresses string                   `yaml:"cloneAccessAddresses"`
+	Templates            map[string]CloneTemplate `yaml:"templates"`
 }
 
 // Provisioner describes a struct for ports and clones management.
@@ -78,6 +82,7 @@ type Provisioner struct {
 	networkID      string
 	instanceID     string
 	gateway        string
+	settingsMu     sync.Mutex
 }
 
 // New creates a new Provisioner instance.
@@ -125,7 +130,7 @@ func isValidConfigModeLocal(config Config) error {
 		return errors.New(`"portPool" must include at least one port`)
 	}
 
-	return nil
+	return isValidTemplates(config.Templates)
 }
 
 // Init inits provision.
@@ -151,9 +156,12 @@ func (p *Provisioner) ContainerOptions() models.ContainerOptions {
 	}
 }
 
-// StartSession starts a new session.
-func (p *Provisioner) StartSession(clone *models.Clone, user resources.EphemeralUser,
-	extraConfig map[string]string) (*resources.Session, error) {
+// StartSession starts a new session. Settings of the clone template, if any, are applied to the container.
+func (p *Provisioner) StartSession(ctx context.Context, clone *models.Clone, user resources.EphemeralUser,
+	extraConfig map[string]string) (_ *resources.Session, err error) {
+	ctx, span := tracing.Start(ctx, "provision.StartSession", attribute.String("clone.id", clone.ID))
+	defer func() { tracing.End(span, err) }()
+
 	snapshot, err := p.getSnapshot(clone.Snapshot.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get snapshots")
@@ -171,7 +179,7 @@ func (p *Provisioner) StartSession(clone *models.Clone, user resources.Ephemeral
 		return nil, fmt.Errorf("cannot work with pool %s: %w", snapshot.Pool, err)
 	}
 
-	log.Dbg(fmt.Sprintf(`Starting session for port: %d.`, port))
+	log.DbgCtx(ctx, fmt.Sprintf(`Starting session for port: %d.`, port))
 
 	defer func() {
 		if err != nil {
@@ -183,23 +191,37 @@ func (p *Provisioner) StartSession(clone *models.Clone, user resources.Ephemeral
 		}
 	}()
 
-	if err = fsm.CreateClone(clone.Branch, name, snapshot.ID, clone.Revision); err != nil {
+	op := operations.FromContext(ctx)
+	op.SetProgress("creating clone dataset")
+
+	if err = tracing.Step(ctx, "fs.CreateClone", func(context.Context) error {
+		return fsm.CreateClone(clone.Branch, name, snapshot.ID, clone.Revision)
+	}); err != nil {
 		return nil, errors.Wrap(err, "failed to create clone")
 	}
 
+	tmpl, ok := p.Template(clone.Template)
+	if clone.Template != "" && !ok {
+		return nil, fmt.Errorf("clone template %q not found", clone.Template)
+	}
+
 	appConfig := p.getAppConfig(fsm.Pool(), clone.Branch, name, clone.Revision, port)
-	appConfig.SetExtraConf(extraConfig)
+	p.applyTemplate(appConfig, tmpl, extraConfig)
 
 	if err := fs.CleanupLogsDir(appConfig.DataDir()); err != nil {
 		log.Warn("Failed to clean up logs directory:", err.Error())
 	}
 
-	if err = postgres.Start(p.runner, appConfig); err != nil {
+	op.SetProgress("starting Postgres")
+
+	if err = p.startPostgres(ctx, appConfig); err != nil {
 		return nil, errors.Wrap(err, "failed to start a container")
 	}
 
-	if err = p.prepareDB(appConfig, user); err != nil {
-		return nil, errors.Wrap(err, "failed to prepare a database")
+	op.SetProgress("preparing database")
+
+	if err = p.prepareSession(ctx, appConfig, user, tmpl.InitSQL); err != nil {
+		return nil, err
 	}
 
 	atomic.AddUint32(&p.sessionCounter, 1)
@@ -238,7 +260,11 @@ func (p *Provisioner) StopSession(session *resources.Session, clone *models.Clon
 }
 
 // ResetSession resets an existing session.
-func (p *Provisioner) ResetSession(session *resources.Session, clone *models.Clone, snapshotID string) (*models.Snapshot, error) {
+func (p *Provisioner) ResetSession(ctx context.Context, session *resources.Session, clone *models.Clone,
+	snapshotID string) (_ *models.Snapshot, err error) {
+	ctx, span := tracing.Start(ctx, "provision.ResetSession", attribute.String("clone.id", clone.ID))
+	defer func() { tracing.End(span, err) }()
+
 	fsm, err := p.pm.GetFSManager(session.Pool)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to find filesystem manager of this session")
@@ -251,7 +277,7 @@ func (p *Provisioner) ResetSession(session *resources.Session, clone *models.Clo
 		return nil, errors.Wrap(err, "failed to get snapshots")
 	}
 
-	log.Dbg("Snapshot ID to reset session: ", snapshot.ID)
+	log.DbgCtx(ctx, "Snapshot ID to reset session: ", snapshot.ID)
 
 	newFSManager := fsm
 
@@ -271,31 +297,48 @@ func (p *Provisioner) ResetSession(session *resources.Session, clone *models.Clo
 		}
 	}()
 
-	if err = postgres.Stop(p.runner, fsm.Pool(), name, clone.DB.Port); err != nil {
+	op := operations.FromContext(ctx)
+	op.SetProgress("stopping Postgres")
+
+	if err = tracing.Step(ctx, "postgres.Stop", func(ctx context.Context) error {
+		return postgres.Stop(runners.WithContext(ctx, p.runner), fsm.Pool(), name, clone.DB.Port)
+	}); err != nil {
 		return nil, errors.Wrap(err, "failed to stop container")
 	}
 
-	if err = fsm.DestroyClone(clone.Branch, name, clone.Revision); err != nil {
+	op.SetProgress("recreating clone dataset")
+
+	if err = tracing.Step(ctx, "fs.DestroyClone", func(context.Context) error {
+		return fsm.DestroyClone(clone.Branch, name, clone.Revision)
+	}); err != nil {
 		return nil, errors.Wrap(err, "failed to destroy clone")
 	}
 
-	if err = newFSManager.CreateClone(clone.Branch, name, snapshot.ID, clone.Revision); err != nil {
+	if err = tracing.Step(ctx, "fs.CreateClone", func(context.Context) error {
+		return newFSManager.CreateClone(clone.Branch, name, snapshot.ID, clone.Revision)
+	}); err != nil {
 		return nil, errors.Wrap(err, "failed to create clone")
 	}
 
+	tmpl := p.cloneTemplate(clone)
+
 	appConfig := p.getAppConfig(newFSManager.Pool(), clone.Branch, name, clone.Revision, session.Port)
-	appConfig.SetExtraConf(session.ExtraConfig)
+	p.applyTemplate(appConfig, tmpl, clone.PostgresConfig)
 
 	if err := fs.CleanupLogsDir(appConfig.DataDir()); err != nil {
 		log.Warn("Failed to clean up logs directory:", err.Error())
 	}
 
-	if err = postgres.Start(p.runner, appConfig); err != nil {
+	op.SetProgress("starting Postgres")
+
+	if err = p.startPostgres(ctx, appConfig); err != nil {
 		return nil, errors.Wrap(err, "failed to start container")
 	}
 
-	if err = p.prepareDB(appConfig, session.EphemeralUser); err != nil {
-		return nil, errors.Wrap(err, "failed to prepare database")
+	op.SetProgress("preparing database")
+
+	if err = p.prepareSession(ctx, appConfig, session.EphemeralUser, tmpl.InitSQL); err != nil {
+		return nil, err
 	}
 
 	snapshotModel := &models.Snapshot{
@@ -442,6 +485,28 @@ func buildPoolEntry(fsm pool.FSManager) (models.PoolEntry, error) {
 }
 
 // Other methods.
+
+// startPostgres starts the container of a clone, tracing the Docker commands as steps of the operation.
+func (p *Provisioner) startPostgres(ctx context.Context, appConfig *resources.AppConfig) error {
+	return tracing.Step(ctx, "postgres.Start", func(ctx context.Context) error {
+		return postgres.Start(runners.WithContext(ctx, p.runner), appConfig)
+	})
+}
+
+// prepareSession prepares the database of a started clone for the user and runs the init SQL of its template.
+func (p *Provisioner) prepareSession(ctx context.Context, appConfig *resources.AppConfig, user resources.EphemeralUser,
+	initSQL []string) error {
+	if err := tracing.Step(ctx, "provision.prepareDB", func(context.Context) error {
+		return p.prepareDB(appConfig, user)
+	}); err != nil {
+		return errors.Wrap(err, "failed to prepare a database")
+	}
+
+	return tracing.Step(ctx, "provision.runInitSQL", func(context.Context) error {
+		return runInitSQL(appConfig, user, initSQL)
+	})
+}
+
 func (p *Provisioner) revertSession(fsm pool.FSManager, branch, name, port string, revision int) {
 	log.Dbg(`Reverting start of session...`)
 
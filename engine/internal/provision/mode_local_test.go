@@ -103,6 +103,10 @@ func (m mockFSManager) CleanupSnapshots(_ int, _ models.RetrievalMode) ([]string
 	return nil, nil
 }
 
+func (m mockFSManager) ApplyRetentionPolicy(_ thinclones.RetentionPolicy, _ models.RetrievalMode, _ bool) ([]string, error) {
+	return nil, nil
+}
+
 func (m mockFSManager) SnapshotList() []resources.Snapshot {
 	return nil
 }
@@ -212,6 +216,10 @@ func (m mockFSManager) SetMessage(_, _ string) error {
 	return nil
 }
 
+func (m mockFSManager) SetWALPosition(_, _ string) error {
+	return nil
+}
+
 func (m mockFSManager) SetMountpoint(_, _ string) error {
 	return nil
 }
@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"syscall"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/btrfs"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/lvm"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/zfs"
 )
@@ -19,6 +20,7 @@ import (
 var fsTypeToString = map[string]string{
 	"ef53":     ext4,
 	"2fc12fc1": zfs.PoolMode,
+	"9123683e": btrfs.PoolMode,
 }
 
 func (pm *Manager) getFSInfo(path string) (string, error) {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/btrfs"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/zfs"
 )
 
@@ -19,6 +20,7 @@ func TestDetectFSType(t *testing.T) {
 	}{
 		{name: "ext4 filesystem", fsType: 0xef53, expected: ext4},
 		{name: "zfs filesystem", fsType: 0x2fc12fc1, expected: zfs.PoolMode},
+		{name: "btrfs filesystem", fsType: 0x9123683e, expected: btrfs.PoolMode},
 		{name: "unknown filesystem returns empty string", fsType: 0x1234, expected: ""},
 		{name: "zero value returns empty string", fsType: 0, expected: ""},
 	}
@@ -14,6 +14,7 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/runners"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/btrfs"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/lvm"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/zfs"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
@@ -49,6 +50,7 @@ type Snapshotter interface {
 	CreateSnapshot(poolSuffix, dataStateAt string) (snapshotName string, err error)
 	DestroySnapshot(snapshotName string, options thinclones.DestroyOptions) (err error)
 	CleanupSnapshots(retentionLimit int, mode models.RetrievalMode) ([]string, error)
+	ApplyRetentionPolicy(policy thinclones.RetentionPolicy, mode models.RetrievalMode, dryRun bool) ([]string, error)
 	SnapshotList() []resources.Snapshot
 	RefreshSnapshotList()
 }
@@ -78,6 +80,7 @@ type Branching interface {
 	SetRoot(branch, snapshotName string) error
 	SetDSA(dsa, snapshotName string) error
 	SetMessage(message, snapshotName string) error
+	SetWALPosition(lsn, snapshotName string) error
 	Reset(snapshotID string, options thinclones.ResetOptions) error
 	HasDependentEntity(snapshotName string) ([]string, error)
 	KeepRelation(snapshotName string) error
@@ -102,10 +105,7 @@ type ManagerConfig struct {
 
 // NewManager defines constructor for thin-clone managers.
 func NewManager(runner runners.Runner, config ManagerConfig) (FSManager, error) {
-	var (
-		manager FSManager
-		err     error
-	)
+	var manager FSManager
 
 	switch config.Pool.Mode {
 	case zfs.PoolMode:
@@ -117,10 +117,23 @@ func NewManager(runner runners.Runner, config ManagerConfig) (FSManager, error)
 		manager = zfs.NewFSManager(runner, zfsConfig)
 
 	case lvm.PoolMode:
-		if manager, err = lvm.NewFSManager(runner, config.Pool); err != nil {
+		lvmConfig, err := buildLVMConfig(config)
+		if err != nil {
+			return nil, err
+		}
+
+		if manager, err = lvm.NewFSManager(runner, lvmConfig); err != nil {
 			return nil, errors.Wrap(err, "failed to initialize LVM thin-clone manager")
 		}
 
+	case btrfs.PoolMode:
+		btrfsConfig, err := buildBtrfsConfig(config)
+		if err != nil {
+			return nil, err
+		}
+
+		manager = btrfs.NewFSManager(runner, btrfsConfig)
+
 	default:
 		return nil, fmt.Errorf(`unsupported thin-clone manager specified: "%s"`, config.Pool.Mode)
 	}
@@ -144,7 +157,22 @@ func BuildFromExistingManager(fsm FSManager, config ManagerConfig) (FSManager, e
 		fsm = manager
 
 	case *lvm.LVManager:
-		manager.UpdateConfig(config.Pool)
+		lvmConfig, err := buildLVMConfig(config)
+		if err != nil {
+			return nil, err
+		}
+
+		manager.UpdateConfig(lvmConfig)
+
+		fsm = manager
+
+	case *btrfs.Manager:
+		btrfsConfig, err := buildBtrfsConfig(config)
+		if err != nil {
+			return nil, err
+		}
+
+		manager.UpdateConfig(btrfsConfig)
 
 		fsm = manager
 
@@ -167,3 +195,29 @@ func buildZFSConfig(config ManagerConfig) (zfs.Config, error) {
 		OSUsername:        osUser.Username,
 	}, nil
 }
+
+func buildBtrfsConfig(config ManagerConfig) (btrfs.Config, error) {
+	osUser, err := user.Current()
+	if err != nil {
+		return btrfs.Config{}, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	return btrfs.Config{
+		Pool:              config.Pool,
+		PreSnapshotSuffix: config.PreSnapshotSuffix,
+		OSUsername:        osUser.Username,
+	}, nil
+}
+
+func buildLVMConfig(config ManagerConfig) (lvm.Config, error) {
+	osUser, err := user.Current()
+	if err != nil {
+		return lvm.Config{}, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	return lvm.Config{
+		Pool:              config.Pool,
+		PreSnapshotSuffix: config.PreSnapshotSuffix,
+		OSUsername:        osUser.Username,
+	}, nil
+}
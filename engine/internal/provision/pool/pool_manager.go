@@ -17,6 +17,7 @@ import (
 
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/runners"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/btrfs"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/lvm"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/zfs"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/dbmarker"
@@ -287,7 +288,7 @@ func (pm *Manager) examineEntries(entries []os.DirEntry) (map[string]FSManager,
 			continue
 		}
 
-		if fsType != zfs.PoolMode && fsType != lvm.PoolMode {
+		if fsType != zfs.PoolMode && fsType != lvm.PoolMode && fsType != btrfs.PoolMode {
 			log.Msg("Unsupported filesystem: ", fsType, entry.Name())
 			continue
 		}
@@ -316,7 +317,7 @@ func (pm *Manager) examineEntries(entries []os.DirEntry) (map[string]FSManager,
 			log.Msg(pool.DSA.String())
 		}
 
-		// A custom pool name is not available for LVM.
+		// A custom pool name is not available for LVM and Btrfs.
 		if fsType == zfs.PoolMode {
 			if len(poolMappings) == 0 {
 				poolMappings, err = zfs.PoolMappings(pm.runner, pm.cfg.MountDir, pm.cfg.PreSnapshotSuffix)
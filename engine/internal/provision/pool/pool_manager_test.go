@@ -43,6 +43,9 @@ func (m *mockFSManager) DestroySnapshot(_ string, _ thinclones.DestroyOptions) e
 func (m *mockFSManager) CleanupSnapshots(_ int, _ models.RetrievalMode) ([]string, error) {
 	return nil, nil
 }
+func (m *mockFSManager) ApplyRetentionPolicy(_ thinclones.RetentionPolicy, _ models.RetrievalMode, _ bool) ([]string, error) {
+	return nil, nil
+}
 func (m *mockFSManager) SnapshotList() []resources.Snapshot       { return nil }
 func (m *mockFSManager) RefreshSnapshotList()                     {}
 func (m *mockFSManager) Pool() *resources.Pool                    { return m.pool }
@@ -72,6 +75,7 @@ func (m *mockFSManager) DeleteRootProp(_, _ string) error                { retur
 func (m *mockFSManager) SetRoot(_, _ string) error                       { return nil }
 func (m *mockFSManager) SetDSA(_, _ string) error                        { return nil }
 func (m *mockFSManager) SetMessage(_, _ string) error                    { return nil }
+func (m *mockFSManager) SetWALPosition(_, _ string) error                { return nil }
 func (m *mockFSManager) Reset(_ string, _ thinclones.ResetOptions) error { return nil }
 func (m *mockFSManager) HasDependentEntity(_ string) ([]string, error)   { return nil, nil }
 func (m *mockFSManager) KeepRelation(_ string) error                     { return nil }
@@ -0,0 +1,148 @@
+/*
+2026 © Postgres.ai
+*/
+
+package provision
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/docker/docker/api/types/container"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/docker"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/cont"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+const (
+	// referenceSettingsFile keeps pg_settings metadata of the clone image in the meta directory.
+	referenceSettingsFile = "pg_settings.json"
+
+	// settingsContainerPrefix defines the name of the container reading pg_settings metadata of the clone image.
+	settingsContainerPrefix = "dblab_settings_"
+
+	// settingsContainerLifetime bounds the life of the container if the engine fails to remove it.
+	settingsContainerLifetime = "600"
+
+	// settingsScript initializes a temporary cluster, starts it without TCP connections and lists pg_settings.
+	settingsScript = `export PATH=/usr/lib/postgresql/${PG_MAJOR}/bin:$PATH
+initdb -D /tmp/dblab_settings -U postgres > /dev/null 2>&1 &&
+pg_ctl -D /tmp/dblab_settings -o "-c listen_addresses= -k /tmp" -w start > /dev/null 2>&1 &&
+psql -h /tmp -U postgres -d postgres -XAtc "` + postgres.SettingsListQuery + `"`
+)
+
+// referenceSettings describes pg_settings metadata stored for the clone image.
+type referenceSettings struct {
+	Image    string                      `json:"image"`
+	Settings map[string]postgres.Setting `json:"settings"`
+}
+
+// ReferenceSettings returns pg_settings metadata of the Postgres image clones are created from, so user-defined
+// parameters can be validated without a running clone. The metadata is read once per image in a short-lived
+// container and kept in the meta directory.
+func (p *Provisioner) ReferenceSettings(ctx context.Context) (map[string]postgres.Setting, error) {
+	p.settingsMu.Lock()
+	defer p.settingsMu.Unlock()
+
+	image := p.config.DockerImage
+
+	filePath, err := util.GetMetaPath(referenceSettingsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get path of Postgres settings metadata: %w", err)
+	}
+
+	stored, err := loadReferenceSettings(filePath)
+	if err != nil {
+		log.Warn("Failed to load stored Postgres settings metadata:", err)
+	}
+
+	if stored != nil && stored.Image == image {
+		return stored.Settings, nil
+	}
+
+	settings, err := p.readImageSettings(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Postgres settings metadata of image %s: %w", image, err)
+	}
+
+	if err := saveReferenceSettings(filePath, &referenceSettings{Image: image, Settings: settings}); err != nil {
+		log.Warn("Failed to store Postgres settings metadata:", err)
+	}
+
+	return settings, nil
+}
+
+// readImageSettings lists pg_settings of a temporary cluster created in a container from the image.
+func (p *Provisioner) readImageSettings(ctx context.Context, image string) (map[string]postgres.Setting, error) {
+	if err := docker.PrepareImage(ctx, p.dockerClient, image); err != nil {
+		return nil, fmt.Errorf("failed to prepare image: %w", err)
+	}
+
+	containerName := settingsContainerPrefix + p.instanceID
+
+	containerID, err := tools.CreateContainerIfMissing(ctx, p.dockerClient, containerName,
+		&container.Config{
+			Image:      image,
+			Entrypoint: []string{"sleep", settingsContainerLifetime},
+			Labels: map[string]string{
+				cont.DBLabControlLabel:    cont.DBLabSettingsLabel,
+				cont.DBLabInstanceIDLabel: p.instanceID,
+			},
+		},
+		&container.HostConfig{NetworkMode: "none"},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create container: %w", err)
+	}
+
+	defer tools.RemoveContainer(ctx, p.dockerClient, containerID, cont.StopPhysicalTimeout)
+
+	if err := p.dockerClient.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to start container: %w", err)
+	}
+
+	output, err := tools.ExecCommandWithOutput(ctx, p.dockerClient, containerID, container.ExecOptions{
+		User: "postgres",
+		Cmd:  []string{"sh", "-c", settingsScript},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pg_settings: %w", err)
+	}
+
+	return postgres.ParseSettings(output)
+}
+
+func loadReferenceSettings(filePath string) (*referenceSettings, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	stored := &referenceSettings{}
+
+	if err := json.Unmarshal(data, stored); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+func saveReferenceSettings(filePath string, stored *referenceSettings) error {
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, data, 0600)
+}
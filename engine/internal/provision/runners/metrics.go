@@ -0,0 +1,81 @@
+/*
+2026 © Postgres.ai
+*/
+
+package runners
+
+import (
+	"path"
+	"strings"
+)
+
+// lvmCommands lists LVM tools used to manage thin clones.
+var lvmCommands = map[string]struct{}{
+	"lvcreate": {}, "lvremove": {}, "lvs": {}, "lvchange": {}, "lvrename": {}, "lvextend": {}, "vgs": {},
+}
+
+// fsCommand returns the label of a filesystem command for latency metrics: the tool with its subcommand
+// for ZFS and Btrfs, e.g. "zfs snapshot", and the tool alone for LVM. Other commands are not observed.
+func fsCommand(command string) (string, bool) {
+	args := commandArgs(command)
+	if len(args) == 0 {
+		return "", false
+	}
+
+	tool := path.Base(args[0])
+
+	switch tool {
+	case "zfs", "zpool", "btrfs":
+		return withSubcommand(tool, args[1:]), true
+	}
+
+	if _, ok := lvmCommands[tool]; ok {
+		return tool, true
+	}
+
+	return "", false
+}
+
+// commandName returns the name of a command for trace spans: the label of filesystem commands,
+// the tool with its subcommand for Docker, e.g. "docker exec", and the tool alone otherwise.
+// Arguments are left out, since they may contain credentials.
+func commandName(command string) string {
+	if label, ok := fsCommand(command); ok {
+		return label
+	}
+
+	args := commandArgs(command)
+	if len(args) == 0 {
+		return "command"
+	}
+
+	tool := path.Base(args[0])
+
+	if tool == "docker" {
+		return withSubcommand(tool, args[1:])
+	}
+
+	return tool
+}
+
+// commandArgs splits the command into arguments, skipping sudo and its options.
+func commandArgs(command string) []string {
+	args := strings.Fields(command)
+
+	for len(args) > 0 && (args[0] == sudoCmd || strings.HasPrefix(args[0], "-")) {
+		args = args[1:]
+	}
+
+	return args
+}
+
+// withSubcommand appends the first argument that is not an option to the tool name.
+func withSubcommand(tool string, args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return tool + " " + arg
+		}
+	}
+
+	return tool
+}
@@ -0,0 +1,45 @@
+package runners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSCommand(t *testing.T) {
+	testCases := []struct {
+		command string
+		label   string
+		ok      bool
+	}{
+		{command: "zfs snapshot -r dblab_pool@snapshot_1", label: "zfs snapshot", ok: true},
+		{command: "sudo --non-interactive zfs list -H -o name", label: "zfs list", ok: true},
+		{command: "/usr/sbin/zpool get -H size dblab_pool", label: "zpool get", ok: true},
+		{command: "btrfs subvolume snapshot -r /pool/main /pool/snap", label: "btrfs subvolume", ok: true},
+		{command: "lvcreate --snapshot --name clone_1 vg/lv", label: "lvcreate", ok: true},
+		{command: "docker ps -a", ok: false},
+		{command: "sudo", ok: false},
+	}
+
+	for _, tc := range testCases {
+		label, ok := fsCommand(tc.command)
+		assert.Equal(t, tc.ok, ok, tc.command)
+		assert.Equal(t, tc.label, label, tc.command)
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	testCases := []struct {
+		command string
+		name    string
+	}{
+		{command: "zfs clone -o mountpoint=/clones/c1 dblab_pool@snapshot_1 dblab_pool/c1", name: "zfs clone"},
+		{command: "docker exec --user postgres dblab_clone_1 psql -c 'select 1'", name: "docker exec"},
+		{command: "sudo --non-interactive rm -rf /var/lib/dblab/sockets/.*6000", name: "rm"},
+		{command: "   ", name: "command"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.name, commandName(tc.command), tc.command)
+	}
+}
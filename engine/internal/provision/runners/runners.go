@@ -7,13 +7,19 @@ package runners
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"runtime"
 	"strings"
 	"syscall"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/opmetrics"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/tracing"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 
 	"github.com/pkg/errors"
@@ -35,6 +41,32 @@ type Runner interface {
 	Run(string, ...bool) (string, error)
 }
 
+// ContextRunner runs commands as steps of the operation carried by a context, so that they are traced as its part.
+type ContextRunner interface {
+	RunContext(context.Context, string, ...bool) (string, error)
+}
+
+// WithContext binds the runner to the operation carried by the context.
+// Runners that are not context-aware are returned as is.
+func WithContext(ctx context.Context, r Runner) Runner {
+	if contextRunner, ok := r.(ContextRunner); ok {
+		return &boundRunner{ctx: ctx, runner: contextRunner}
+	}
+
+	return r
+}
+
+// boundRunner runs commands with the context it is bound to.
+type boundRunner struct {
+	ctx    context.Context
+	runner ContextRunner
+}
+
+// Run executes command.
+func (r *boundRunner) Run(command string, options ...bool) (string, error) {
+	return r.runner.RunContext(r.ctx, command, options...)
+}
+
 // RunnerError represents a runner error.
 type RunnerError struct {
 	Msg        string
@@ -93,6 +125,11 @@ func NewLocalRunner(useSudo bool) *LocalRunner {
 
 // Run executes command.
 func (r *LocalRunner) Run(command string, options ...bool) (string, error) {
+	return r.RunContext(context.Background(), command, options...)
+}
+
+// RunContext executes command as a step of the operation carried by the context.
+func (r *LocalRunner) RunContext(ctx context.Context, command string, options ...bool) (_ string, err error) {
 	command = strings.Trim(command, " \n")
 	if len(command) == 0 {
 		return "", errors.New("empty command")
@@ -103,9 +140,12 @@ func (r *LocalRunner) Run(command string, options ...bool) (string, error) {
 	logCommand := Hidden
 	if logsEnabled {
 		logCommand = command
-		log.Dbg(fmt.Sprintf(`Run(Local): "%s"`, logCommand))
+		log.DbgCtx(ctx, fmt.Sprintf(`Run(Local): "%s"`, logCommand))
 	}
 
+	_, span := tracing.Start(ctx, "exec "+commandName(command), attribute.String("command", logCommand))
+	defer func() { tracing.End(span, err) }()
+
 	if runtime.GOOS == "windows" {
 		return "", errors.New("Windows is not supported")
 	}
@@ -133,7 +173,13 @@ func (r *LocalRunner) Run(command string, options ...bool) (string, error) {
 	// psql with the file option returns error response to stderr with
 	// success exit code. In that case err will be nil, but we need
 	// to treat the case as error and read proper output.
-	err := cmd.Run()
+	startedAt := time.Now()
+	err = cmd.Run()
+
+	if fsCommandLabel, ok := fsCommand(command); ok {
+		opmetrics.ObserveCommand(fsCommandLabel, time.Since(startedAt), err)
+	}
+
 	psqlErr := strings.Contains(command, "psql") && len(stderr.String()) > 0
 
 	// TODO(anatoly): remove hotfix.
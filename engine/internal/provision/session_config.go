@@ -0,0 +1,89 @@
+/*
+2026 © Postgres.ai
+*/
+
+package provision
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres/pgconfig"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	// restartSignal makes Postgres perform a fast shutdown when the clone container is restarted.
+	restartSignal = "SIGINT"
+
+	// restartTimeout bounds waiting for Postgres to accept connections after a restart.
+	restartTimeout = 5 * time.Minute
+)
+
+// SessionSettings returns metadata of the Postgres parameters of a running clone.
+func (p *Provisioner) SessionSettings(session *resources.Session, clone *models.Clone) (map[string]postgres.Setting, error) {
+	appConfig, err := p.sessionAppConfig(session, clone)
+	if err != nil {
+		return nil, err
+	}
+
+	return postgres.LoadSettings(appConfig)
+}
+
+// UpdateSessionConfig replaces the user-defined Postgres parameters of a running clone and applies them
+// with a configuration reload or, if restart is set, with a restart of the clone container.
+func (p *Provisioner) UpdateSessionConfig(ctx context.Context, session *resources.Session, clone *models.Clone,
+	extraConfig map[string]string, restart bool) error {
+	appConfig, err := p.sessionAppConfig(session, clone)
+	if err != nil {
+		return err
+	}
+
+	p.applyTemplate(appConfig, p.cloneTemplate(clone), extraConfig)
+
+	configManager, err := pgconfig.NewCorrector(appConfig.DataDir())
+	if err != nil {
+		return fmt.Errorf("failed to create a config manager: %w", err)
+	}
+
+	if err := configManager.ApplyUserConfig(appConfig.ExtraConf()); err != nil {
+		return fmt.Errorf("cannot apply user configs: %w", err)
+	}
+
+	if restart {
+		stopTimeout := int(restartTimeout.Seconds())
+
+		if err := p.dockerClient.ContainerRestart(ctx, clone.ID,
+			container.StopOptions{Signal: restartSignal, Timeout: &stopTimeout}); err != nil {
+			return fmt.Errorf("failed to restart clone container: %w", err)
+		}
+
+		return postgres.WaitReady(appConfig, restartTimeout)
+	}
+
+	fileErrors, err := postgres.ConfigFileErrors(appConfig)
+	if err != nil {
+		return err
+	}
+
+	if len(fileErrors) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(fileErrors, "; "))
+	}
+
+	return postgres.ReloadConfig(appConfig)
+}
+
+func (p *Provisioner) sessionAppConfig(session *resources.Session, clone *models.Clone) (*resources.AppConfig, error) {
+	fsm, err := p.pm.GetFSManager(session.Pool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find filesystem manager of this session: %w", err)
+	}
+
+	return p.getAppConfig(fsm.Pool(), clone.Branch, clone.ID, clone.Revision, session.Port), nil
+}
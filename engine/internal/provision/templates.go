@@ -0,0 +1,135 @@
+/*
+2026 © Postgres.ai
+*/
+
+package provision
+
+import (
+	"fmt"
+	"strings"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// CloneTemplate defines a named, admin-defined preset for clones.
+type CloneTemplate struct {
+	// PostgresParameters are applied to the clone; parameters passed with the clone request take precedence.
+	PostgresParameters map[string]string `yaml:"postgresParameters"`
+
+	// ContainerConfig contains Docker options (e.g. resource limits like "cpus" or "memory")
+	// merged over the global "provision.containerConfig".
+	ContainerConfig map[string]string `yaml:"containerConfig"`
+
+	// Protected enables protection for clones created from the template.
+	Protected bool `yaml:"protected"`
+
+	// MaxIdleMinutes overrides "cloning.maxIdleMinutes" for clones created from the template.
+	MaxIdleMinutes uint `yaml:"maxIdleMinutes"`
+
+	// InitSQL lists SQL scripts run as a superuser in the clone database after the clone is started or reset.
+	InitSQL []string `yaml:"initSQL"`
+
+	// AllowedBranches restricts the branches the template can be used on. Empty means any branch.
+	AllowedBranches []string `yaml:"allowedBranches"`
+}
+
+// AllowsBranch checks if the template can be used to create a clone on the branch.
+func (t CloneTemplate) AllowsBranch(branch string) bool {
+	if len(t.AllowedBranches) == 0 {
+		return true
+	}
+
+	for _, allowed := range t.AllowedBranches {
+		if allowed == branch {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isValidTemplates(templates map[string]CloneTemplate) error {
+	for name, tmpl := range templates {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf(`"templates" must not contain templates with empty names`)
+		}
+
+		for _, script := range tmpl.InitSQL {
+			if strings.TrimSpace(script) == "" {
+				return fmt.Errorf(`template %q: "initSQL" must not contain empty scripts`, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Template returns the clone template with the given name.
+func (p *Provisioner) Template(name string) (CloneTemplate, bool) {
+	tmpl, ok := p.config.Templates[name]
+
+	return tmpl, ok
+}
+
+// cloneTemplate returns the template the clone was created from, or an empty template.
+func (p *Provisioner) cloneTemplate(clone *models.Clone) CloneTemplate {
+	if clone.Template == "" {
+		return CloneTemplate{}
+	}
+
+	tmpl, ok := p.Template(clone.Template)
+	if !ok {
+		log.Warn(fmt.Sprintf("Clone template %q of clone %s is no longer configured; starting the clone without it",
+			clone.Template, clone.ID))
+	}
+
+	return tmpl
+}
+
+// applyTemplate sets the container options and Postgres parameters of the clone, taking the template into account.
+func (p *Provisioner) applyTemplate(appConfig *resources.AppConfig, tmpl CloneTemplate, extraConfig map[string]string) {
+	appConfig.ContainerConf = mergeSettings(p.config.ContainerConfig, tmpl.ContainerConfig)
+	appConfig.SetExtraConf(mergeSettings(tmpl.PostgresParameters, extraConfig))
+}
+
+// runInitSQL runs the init scripts of the template in the database available to the clone user.
+func runInitSQL(appConfig *resources.AppConfig, user resources.EphemeralUser, scripts []string) error {
+	if len(scripts) == 0 {
+		return nil
+	}
+
+	dbName := appConfig.DB.DBName
+	if user.AvailableDB != "" {
+		dbName = user.AvailableDB
+	}
+
+	for i, script := range scripts {
+		if err := postgres.RunSQL(appConfig, dbName, script); err != nil {
+			return fmt.Errorf("failed to run init SQL script #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// mergeSettings returns a copy of base with overrides applied on top.
+func mergeSettings(base, overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return base
+	}
+
+	merged := make(map[string]string, len(base)+len(overrides))
+
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range overrides {
+		merged[key] = value
+	}
+
+	return merged
+}
@@ -0,0 +1,53 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+)
+
+func TestCloneTemplateAllowsBranch(t *testing.T) {
+	assert.True(t, CloneTemplate{}.AllowsBranch("dev"))
+
+	tmpl := CloneTemplate{AllowedBranches: []string{"main", "staging"}}
+	assert.True(t, tmpl.AllowsBranch("staging"))
+	assert.False(t, tmpl.AllowsBranch("dev"))
+}
+
+func TestApplyTemplate(t *testing.T) {
+	p := &Provisioner{config: &Config{
+		ContainerConfig: map[string]string{"shm-size": "1g", "memory": "2g"},
+	}}
+
+	tmpl := CloneTemplate{
+		PostgresParameters: map[string]string{"work_mem": "256MB", "statement_timeout": "0"},
+		ContainerConfig:    map[string]string{"memory": "8g", "cpus": "4"},
+	}
+
+	appConfig := &resources.AppConfig{}
+	p.applyTemplate(appConfig, tmpl, map[string]string{"statement_timeout": "1s"})
+
+	assert.Equal(t, map[string]string{"shm-size": "1g", "memory": "8g", "cpus": "4"}, appConfig.ContainerConf)
+	assert.Equal(t, map[string]string{"work_mem": "256MB", "statement_timeout": "1s"}, appConfig.ExtraConf())
+	assert.Equal(t, "2g", p.config.ContainerConfig["memory"], "the global container config must not be modified")
+
+	p.applyTemplate(appConfig, CloneTemplate{}, nil)
+	assert.Equal(t, p.config.ContainerConfig, appConfig.ContainerConf)
+	assert.Nil(t, appConfig.ExtraConf())
+}
+
+func TestTemplatesValidation(t *testing.T) {
+	cfg := Config{PortPool: PortPool{From: 6000, To: 6099}}
+
+	cfg.Templates = map[string]CloneTemplate{"analytics": {InitSQL: []string{"select 1"}}}
+	require.NoError(t, IsValidConfig(cfg))
+
+	cfg.Templates = map[string]CloneTemplate{" ": {}}
+	assert.Error(t, IsValidConfig(cfg))
+
+	cfg.Templates = map[string]CloneTemplate{"analytics": {InitSQL: []string{""}}}
+	assert.Error(t, IsValidConfig(cfg))
+}
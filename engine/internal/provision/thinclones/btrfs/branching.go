@@ -0,0 +1,207 @@
+/*
+2026 © Postgres.ai
+*/
+
+package btrfs
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/catalog"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+// CreateBranch clones data as a new branch.
+func (m *Manager) CreateBranch(branchName, snapshotID string) error {
+	snapshotPath, err := m.snapshotPath(snapshotID)
+	if err != nil {
+		return err
+	}
+
+	branchPath, err := m.datasetPath(branchName)
+	if err != nil {
+		return err
+	}
+
+	if out, err := m.runner.Run(snapshotCommand(snapshotPath, branchPath, false)); err != nil {
+		return fmt.Errorf("btrfs clone error: %w. Out: %v", err, out)
+	}
+
+	return m.store.Update(func(md *metadata) error {
+		md.RegisterParents(m.Pool().Name, branchName, newGroupEntry)
+
+		md.Datasets[branchName] = newEntry(snapshotID, true)
+
+		return nil
+	})
+}
+
+// Snapshot takes a read-only snapshot of the current data state.
+func (m *Manager) Snapshot(snapshotName string) error {
+	dataset := catalog.SnapshotDataset(snapshotName)
+
+	datasetPath, err := m.datasetPath(dataset)
+	if err != nil {
+		return err
+	}
+
+	snapshotPath, err := m.snapshotPath(snapshotName)
+	if err != nil {
+		return err
+	}
+
+	if out, err := m.runner.Run(snapshotCommand(datasetPath, snapshotPath, true), true); err != nil {
+		return fmt.Errorf("btrfs snapshot error: %w. Out: %v", err, out)
+	}
+
+	return m.store.Update(func(md *metadata) error {
+		md.Snapshots[snapshotName] = newEntry("", true)
+
+		return nil
+	})
+}
+
+// Move sends the incremental snapshot diff and receives it into the target dataset.
+func (m *Manager) Move(baseSnap, currentSnap, target string) error {
+	basePath, err := m.snapshotPath(baseSnap)
+	if err != nil {
+		return err
+	}
+
+	currentPath, err := m.snapshotPath(currentSnap)
+	if err != nil {
+		return err
+	}
+
+	_, name, _ := strings.Cut(currentSnap, "@")
+	targetSnapshot := target + "@" + name
+
+	targetPath, err := m.snapshotPath(targetSnapshot)
+	if err != nil {
+		return err
+	}
+
+	// "btrfs receive" names the new subvolume after the source one, which matches the target snapshot path.
+	cmd := fmt.Sprintf("mkdir -p '%s' && btrfs send -p '%s' '%s' | btrfs receive '%s'",
+		path.Dir(targetPath), basePath, currentPath, path.Dir(targetPath))
+
+	if out, err := m.runner.Run(cmd); err != nil {
+		return fmt.Errorf("btrfs moving snapshot error: %w. Out: %v", err, out)
+	}
+
+	return m.store.Update(func(md *metadata) error {
+		snapshot := newEntry("", true)
+		snapshot.Properties = make(map[string]string)
+
+		if source, ok := md.Snapshots[currentSnap]; ok {
+			for key, value := range source.Properties {
+				snapshot.Properties[key] = value
+			}
+		}
+
+		md.Snapshots[targetSnapshot] = snapshot
+
+		return nil
+	})
+}
+
+// Rename renames a dataset together with its nested datasets and snapshots.
+func (m *Manager) Rename(oldName, newName string) error {
+	oldPath, err := m.datasetPath(oldName)
+	if err != nil {
+		return err
+	}
+
+	newPath, err := m.datasetPath(newName)
+	if err != nil {
+		return err
+	}
+
+	return m.store.Update(func(md *metadata) error {
+		if out, err := m.runner.Run(moveCommand(oldPath, newPath)); err != nil {
+			return fmt.Errorf("btrfs renaming error: %w. Out: %v", err, out)
+		}
+
+		renames := make(map[string]string)
+
+		for _, name := range md.Subtree(oldName) {
+			newDataset := newName + strings.TrimPrefix(name, oldName)
+			md.Datasets[newDataset] = md.Datasets[name]
+			delete(md.Datasets, name)
+
+			for _, snapshotID := range md.DatasetSnapshots(name) {
+				newSnapshotID := newDataset + strings.TrimPrefix(snapshotID, name)
+
+				oldSnapshotPath, err := m.snapshotPath(snapshotID)
+				if err != nil {
+					return err
+				}
+
+				newSnapshotPath, err := m.snapshotPath(newSnapshotID)
+				if err != nil {
+					return err
+				}
+
+				if out, err := m.runner.Run(moveCommand(oldSnapshotPath, newSnapshotPath)); err != nil {
+					return fmt.Errorf("btrfs renaming error: %w. Out: %v", err, out)
+				}
+
+				md.Snapshots[newSnapshotID] = md.Snapshots[snapshotID]
+				delete(md.Snapshots, snapshotID)
+				renames[snapshotID] = newSnapshotID
+			}
+		}
+
+		md.RegisterParents(m.Pool().Name, newName, newGroupEntry)
+		md.ReplaceReferences(renames)
+
+		return nil
+	})
+}
+
+// SetMountpoint is a no-op in Btrfs mode: the mount point of a subvolume is derived from its dataset name.
+func (m *Manager) SetMountpoint(_, _ string) error {
+	log.Msg("SetMountpoint is not supported for Btrfs. Skip the operation")
+
+	return nil
+}
+
+// Reset rollbacks the dataset to the snapshot by replacing its subvolume with a writable snapshot.
+func (m *Manager) Reset(snapshotID string, _ thinclones.ResetOptions) error {
+	dataset := catalog.SnapshotDataset(snapshotID)
+
+	datasetPath, err := m.datasetPath(dataset)
+	if err != nil {
+		return err
+	}
+
+	snapshotPath, err := m.snapshotPath(snapshotID)
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+
+	if err := m.store.View(func(md *metadata) error {
+		_, exists = md.Snapshots[snapshotID]
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if !exists {
+		return errors.New("failed to rollback a snapshot: snapshot not found")
+	}
+
+	cmd := deleteSubvolumeCommand(datasetPath) + " && " + snapshotCommand(snapshotPath, datasetPath, false)
+
+	if out, err := m.runner.Run(cmd, true); err != nil {
+		return fmt.Errorf("failed to rollback a snapshot: %w. Out: %v", err, out)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+/*
+2026 © Postgres.ai
+*/
+
+package btrfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRename(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+	require.NoError(t, m.CreateBranch("dblab_pool/branch/dev", snapshotID))
+	require.NoError(t, m.Snapshot("dblab_pool/branch/dev@20260102000000"))
+	require.NoError(t, m.CreateClone("dev", "clone1", "dblab_pool/branch/dev@20260102000000", 0))
+
+	require.NoError(t, m.Rename("dblab_pool/branch/dev", "dblab_pool/branch/feature"))
+
+	require.NoError(t, m.store.View(func(md *metadata) error {
+		assert.Contains(t, md.Snapshots, "dblab_pool/branch/feature@20260102000000")
+		assert.NotContains(t, md.Snapshots, "dblab_pool/branch/dev@20260102000000")
+		assert.Equal(t, "dblab_pool/branch/feature@20260102000000", md.Datasets["dblab_pool/branch/feature/clone1/r0"].Origin)
+
+		return nil
+	}))
+}
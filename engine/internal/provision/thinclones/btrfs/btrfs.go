@@ -0,0 +1,429 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package btrfs provides an interface to work with Btrfs subvolumes and snapshots.
+package btrfs
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/runners"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/catalog"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util/branching"
+)
+
+const (
+	// PoolMode defines the btrfs filesystem name.
+	PoolMode = "btrfs"
+
+	// metaDir keeps the metadata file and read-only snapshots inside the pool.
+	metaDir      = ".dblab"
+	snapshotsDir = "snapshots"
+
+	// snapshotPrefix separates snapshot subvolumes from nested dataset directories in the snapshot tree.
+	snapshotPrefix = "@"
+)
+
+// Manager describes a filesystem manager for Btrfs.
+type Manager struct {
+	*catalog.Base[*entry]
+	runner runners.Runner
+	store  *metadataStore
+}
+
+// Config defines configuration for Btrfs filesystem manager.
+type Config = catalog.Config
+
+// NewFSManager creates a new Manager instance for Btrfs.
+func NewFSManager(runner runners.Runner, config Config) *Manager {
+	m := &Manager{runner: runner}
+
+	// The metadata file follows the pool when the configuration is updated.
+	m.store = newMetadataStore(func() string {
+		return path.Join(poolRoot(m.Pool()), metaDir)
+	})
+	m.Base = catalog.NewBase[*entry](runner, config, m.store, m)
+
+	return m
+}
+
+func poolRoot(pool *resources.Pool) string {
+	return path.Join(pool.MountDir, pool.PoolDirName)
+}
+
+// relativeDataset returns the dataset path relative to the pool root.
+func (m *Manager) relativeDataset(dataset string) (string, error) {
+	poolName := m.Config().Pool.Name
+
+	if dataset == poolName {
+		return "", nil
+	}
+
+	rel, found := strings.CutPrefix(dataset, poolName+"/")
+	if !found {
+		return "", fmt.Errorf("dataset %q does not belong to pool %q", dataset, poolName)
+	}
+
+	return rel, nil
+}
+
+// datasetPath returns the filesystem path of a dataset.
+func (m *Manager) datasetPath(dataset string) (string, error) {
+	rel, err := m.relativeDataset(dataset)
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(poolRoot(m.Config().Pool), rel), nil
+}
+
+// snapshotPath returns the filesystem path of a read-only snapshot subvolume.
+func (m *Manager) snapshotPath(snapshotID string) (string, error) {
+	dataset, name, found := strings.Cut(snapshotID, "@")
+	if !found || name == "" {
+		return "", fmt.Errorf("invalid snapshot name %q", snapshotID)
+	}
+
+	rel, err := m.relativeDataset(dataset)
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(poolRoot(m.Config().Pool), metaDir, snapshotsDir, rel, snapshotPrefix+name), nil
+}
+
+// CreateClone creates a new Btrfs clone as a writable snapshot.
+func (m *Manager) CreateClone(branchName, cloneName, snapshotID string, revision int) error {
+	cloneMountName := m.Config().Pool.CloneName(branchName, cloneName, revision)
+
+	log.Dbg(cloneMountName)
+
+	if err := m.store.View(func(md *metadata) error {
+		if _, ok := md.Snapshots[snapshotID]; !ok {
+			return fmt.Errorf("snapshot %q not found", snapshotID)
+		}
+
+		if _, ok := md.Datasets[cloneMountName]; ok && revision == branching.DefaultRevision {
+			return fmt.Errorf("clone %q is already exists; skipping", cloneName)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	snapshotPath, err := m.snapshotPath(snapshotID)
+	if err != nil {
+		return err
+	}
+
+	cloneMountLocation := m.Config().Pool.CloneLocation(branchName, cloneName, revision)
+	cloneDataDir := m.Config().Pool.ClonePath(branchName, cloneName, revision)
+
+	cmd := snapshotCommand(snapshotPath, cloneMountLocation, false) + " && " +
+		catalog.ChownCloneCommand(cloneDataDir, cloneMountLocation, m.Config().OSUsername)
+
+	log.Dbg(cmd)
+
+	out, err := m.runner.Run(cmd)
+	if err != nil {
+		return fmt.Errorf("btrfs clone error: %w. Out: %v", err, out)
+	}
+
+	return m.store.Update(func(md *metadata) error {
+		md.RegisterParents(m.Pool().Name, cloneMountName, newGroupEntry)
+
+		md.Datasets[cloneMountName] = newEntry(snapshotID, true)
+
+		return nil
+	})
+}
+
+// CreateDataset creates a new dataset.
+func (m *Manager) CreateDataset(datasetName string) error {
+	var exists bool
+
+	if err := m.store.View(func(md *metadata) error {
+		_, exists = md.Datasets[datasetName]
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	datasetPath, err := m.datasetPath(datasetName)
+	if err != nil {
+		return err
+	}
+
+	if out, err := m.runner.Run(createSubvolumeCommand(datasetPath)); err != nil {
+		log.Dbg(out)
+		return fmt.Errorf("failed to create dataset: %w", err)
+	}
+
+	return m.store.Update(func(md *metadata) error {
+		md.RegisterParents(m.Pool().Name, datasetName, newGroupEntry)
+
+		md.Datasets[datasetName] = newEntry("", true)
+
+		return nil
+	})
+}
+
+// Destroy removes datasets and snapshots together with their dependents, the way "zfs destroy -R" does.
+func (m *Manager) Destroy(datasets, snapshots []string) error {
+	var destroyErr error
+
+	if err := m.store.Update(func(md *metadata) error {
+		dsSet, snSet := md.Dependents(datasets, snapshots)
+
+		type removal struct {
+			name      string
+			path      string
+			subvolume bool
+			snapshot  bool
+		}
+
+		removals := make([]removal, 0, len(dsSet)+len(snSet))
+
+		for name := range snSet {
+			snapshotPath, err := m.snapshotPath(name)
+			if err != nil {
+				return err
+			}
+
+			removals = append(removals, removal{name: name, path: snapshotPath, subvolume: true, snapshot: true})
+		}
+
+		for name := range dsSet {
+			datasetPath, err := m.datasetPath(name)
+			if err != nil {
+				return err
+			}
+
+			removals = append(removals, removal{name: name, path: datasetPath, subvolume: md.Datasets[name].Subvolume})
+		}
+
+		// Nested subvolumes must be removed before their parents.
+		sort.Slice(removals, func(i, j int) bool {
+			return strings.Count(removals[i].path, "/") > strings.Count(removals[j].path, "/")
+		})
+
+		for _, r := range removals {
+			cmd := removeDirCommand(r.path)
+
+			if r.subvolume {
+				cmd = deleteSubvolumeCommand(r.path)
+			}
+
+			if out, err := m.runner.Run(cmd); err != nil {
+				// The subvolumes removed so far are gone, so their entries are still dropped from the metadata.
+				destroyErr = fmt.Errorf("failed to destroy %s: %w. Out: %v", r.path, err, out)
+				break
+			}
+
+			if r.snapshot {
+				delete(md.Snapshots, r.name)
+			} else {
+				delete(md.Datasets, r.name)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return destroyErr
+}
+
+// GetBatchSessionState returns session states for multiple clones using a single qgroup query.
+func (m *Manager) GetBatchSessionState(requests []resources.SessionStateRequest) (map[string]resources.SessionState, error) {
+	subvolumes, err := m.listSubvolumes()
+	if err != nil {
+		log.Dbg("failed to get subvolume usage, Btrfs quotas may be disabled:", err)
+	}
+
+	sessionStates := make(map[string]resources.SessionState, len(requests))
+
+	if err := m.store.View(func(md *metadata) error {
+		for _, req := range requests {
+			cloneDataset := branching.CloneDataset(m.Config().Pool.Name, req.Branch, req.CloneID)
+
+			if _, ok := md.Datasets[cloneDataset]; !ok {
+				continue
+			}
+
+			rel, err := m.relativeDataset(cloneDataset)
+			if err != nil {
+				return err
+			}
+
+			state := resources.SessionState{}
+
+			for _, sv := range subvolumes {
+				if sv.Path != rel && !strings.HasPrefix(sv.Path, rel+"/") {
+					continue
+				}
+
+				state.CloneDiffSize += sv.Exclusive
+				state.LogicalReferenced = max(state.LogicalReferenced, sv.Referenced)
+			}
+
+			sessionStates[req.CloneID] = state
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	return sessionStates, nil
+}
+
+// GetFilesystemState returns a disk state.
+func (m *Manager) GetFilesystemState() (models.FileSystem, error) {
+	out, err := m.runner.Run(usageCommand(poolRoot(m.Config().Pool)), false)
+	if err != nil {
+		return models.FileSystem{}, fmt.Errorf("failed to get filesystem usage: %w", err)
+	}
+
+	usage, err := parseUsage(out)
+	if err != nil {
+		return models.FileSystem{}, err
+	}
+
+	fileSystem := models.FileSystem{
+		Mode:          PoolMode,
+		Size:          usage.Size,
+		Free:          usage.Free,
+		Used:          usage.Used,
+		DataSize:      usage.Used,
+		CompressRatio: 1,
+	}
+
+	subvolumes, err := m.listSubvolumes()
+	if err != nil {
+		log.Dbg("failed to get subvolume usage, Btrfs quotas may be disabled:", err)
+
+		return fileSystem, nil
+	}
+
+	snapshotsRoot := path.Join(metaDir, snapshotsDir) + "/"
+	branchRoot := branching.BranchDir + "/"
+
+	for _, sv := range subvolumes {
+		switch {
+		case sv.Path == "":
+			fileSystem.DataSize = sv.Referenced
+		case strings.HasPrefix(sv.Path, snapshotsRoot):
+			fileSystem.UsedBySnapshots += sv.Exclusive
+		case strings.HasPrefix(sv.Path, branchRoot):
+			fileSystem.UsedByClones += sv.Exclusive
+		}
+	}
+
+	return fileSystem, nil
+}
+
+// listSubvolumes returns subvolumes of the pool with paths relative to the pool root and their qgroup usage.
+func (m *Manager) listSubvolumes() ([]subvolume, error) {
+	root := poolRoot(m.Config().Pool)
+
+	rootIDOut, err := m.runner.Run(rootIDCommand(root), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get root subvolume ID: %w", err)
+	}
+
+	rootID, err := strconv.ParseUint(strings.TrimSpace(rootIDOut), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse root subvolume ID: %w", err)
+	}
+
+	listOut, err := m.runner.Run(listSubvolumesCommand(root), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subvolumes: %w", err)
+	}
+
+	paths, err := parseSubvolumeList(listOut)
+	if err != nil {
+		return nil, err
+	}
+
+	qgroupOut, err := m.runner.Run(qgroupCommand(root), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to show qgroups: %w", err)
+	}
+
+	usage := parseQgroups(qgroupOut)
+
+	rootPrefix := ""
+	if rootID != topLevelSubvolumeID {
+		rootPrefix = paths[rootID] + "/"
+	}
+
+	subvolumes := make([]subvolume, 0, len(paths)+1)
+
+	if rootUsage, ok := usage[rootID]; ok {
+		subvolumes = append(subvolumes, subvolume{ID: rootID, Referenced: rootUsage.Referenced, Exclusive: rootUsage.Exclusive})
+	}
+
+	for id, subvolumePath := range paths {
+		rel, found := strings.CutPrefix(subvolumePath, rootPrefix)
+		if !found || id == rootID {
+			continue
+		}
+
+		sv := usage[id]
+		sv.ID = id
+		sv.Path = rel
+
+		subvolumes = append(subvolumes, sv)
+	}
+
+	return subvolumes, nil
+}
+
+// ListSnapshots returns snapshots available for cloning along with their subvolume usage, the newest first.
+func (m *Manager) ListSnapshots() ([]resources.Snapshot, error) {
+	subvolumes, err := m.listSubvolumes()
+	if err != nil {
+		log.Dbg("failed to get subvolume usage, Btrfs quotas may be disabled:", err)
+	}
+
+	usage := make(map[string]subvolume, len(subvolumes))
+	for _, sv := range subvolumes {
+		usage[sv.Path] = sv
+	}
+
+	snapshots := []resources.Snapshot{}
+
+	if err := m.store.View(func(md *metadata) error {
+		for _, snapshot := range m.ClonableSnapshots(md) {
+			if snapshotPath, err := m.snapshotPath(snapshot.ID); err == nil {
+				sv := usage[strings.TrimPrefix(snapshotPath, poolRoot(m.Config().Pool)+"/")]
+				snapshot.Used = sv.Exclusive
+				snapshot.LogicalReferenced = sv.Referenced
+			}
+
+			snapshots = append(snapshots, snapshot)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,285 @@
+/*
+2026 © Postgres.ai
+*/
+
+package btrfs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+type runnerMock struct {
+	cmds    []string
+	outputs map[string]string
+	failing []string
+}
+
+func (r *runnerMock) Run(cmd string, _ ...bool) (string, error) {
+	r.cmds = append(r.cmds, cmd)
+
+	for _, part := range r.failing {
+		if strings.Contains(cmd, part) {
+			return "", errors.New("command failed")
+		}
+	}
+
+	for prefix, out := range r.outputs {
+		if strings.HasPrefix(cmd, prefix) {
+			return out, nil
+		}
+	}
+
+	if strings.HasPrefix(cmd, "btrfs qgroup") || strings.HasPrefix(cmd, "btrfs inspect-internal") {
+		return "", errors.New("quotas not enabled")
+	}
+
+	return "", nil
+}
+
+func (r *runnerMock) commands(prefix string) []string {
+	found := []string{}
+
+	for _, cmd := range r.cmds {
+		if strings.Contains(cmd, prefix) {
+			found = append(found, cmd)
+		}
+	}
+
+	return found
+}
+
+func newTestManager(t *testing.T) (*Manager, *runnerMock) {
+	t.Helper()
+
+	runner := &runnerMock{outputs: make(map[string]string)}
+	pool := &resources.Pool{
+		Name:        "dblab_pool",
+		Mode:        PoolMode,
+		PoolDirName: "dblab_pool",
+		MountDir:    t.TempDir(),
+		DataSubDir:  "data",
+	}
+
+	return NewFSManager(runner, Config{Pool: pool, PreSnapshotSuffix: "_pre", OSUsername: "postgres"}), runner
+}
+
+func TestParseUsage(t *testing.T) {
+	out := `Overall:
+    Device size:                 10737418240
+    Device allocated:             1103101952
+    Device unallocated:           9634316288
+    Used:                          393216000
+    Free (estimated):             9911173120      (min: 5094014976)
+    Free (statfs, df):            9910124544
+    Data ratio:                         1.00
+`
+
+	usage, err := parseUsage(out)
+	require.NoError(t, err)
+	assert.Equal(t, fsUsage{Size: 10737418240, Used: 393216000, Free: 9911173120}, usage)
+
+	_, err = parseUsage("ERROR: not a btrfs filesystem")
+	require.Error(t, err)
+}
+
+func TestParseSubvolumeList(t *testing.T) {
+	out := `ID 256 gen 12 top level 5 path dblab_pool
+ID 257 gen 14 top level 256 path dblab_pool/.dblab/snapshots/@snapshot_20260101000000
+ID 258 gen 15 top level 256 path dblab_pool/branch/main/clone 1/r0
+`
+
+	subvolumes, err := parseSubvolumeList(out)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint64]string{
+		256: "dblab_pool",
+		257: "dblab_pool/.dblab/snapshots/@snapshot_20260101000000",
+		258: "dblab_pool/branch/main/clone 1/r0",
+	}, subvolumes)
+}
+
+func TestParseQgroups(t *testing.T) {
+	out := `qgroupid         rfer         excl
+--------         ----         ----
+0/5             16384        16384
+0/257       104857600      1048576
+1/100           16384        16384
+`
+
+	usage := parseQgroups(out)
+	assert.Len(t, usage, 2)
+	assert.Equal(t, subvolume{ID: 257, Referenced: 104857600, Exclusive: 1048576}, usage[257])
+}
+
+func TestPaths(t *testing.T) {
+	m, _ := newTestManager(t)
+	root := poolRoot(m.Pool())
+
+	datasetPath, err := m.datasetPath("dblab_pool/branch/main/clone1/r0")
+	require.NoError(t, err)
+	assert.Equal(t, root+"/branch/main/clone1/r0", datasetPath)
+
+	snapshotPath, err := m.snapshotPath("dblab_pool@snapshot_20260101000000")
+	require.NoError(t, err)
+	assert.Equal(t, root+"/.dblab/snapshots/@snapshot_20260101000000", snapshotPath)
+
+	snapshotPath, err = m.snapshotPath("dblab_pool/branch/dev@20260101000000")
+	require.NoError(t, err)
+	assert.Equal(t, root+"/.dblab/snapshots/branch/dev/@20260101000000", snapshotPath)
+
+	_, err = m.datasetPath("another_pool/branch/main")
+	require.Error(t, err)
+
+	_, err = m.snapshotPath("dblab_pool")
+	require.Error(t, err)
+}
+
+func TestCloneLifecycle(t *testing.T) {
+	m, runner := newTestManager(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+	assert.Equal(t, "dblab_pool@snapshot_20260101000000", snapshotID)
+	require.Len(t, runner.commands("btrfs subvolume snapshot -r"), 1)
+
+	_, err = m.CreateSnapshot("", "20260101000000")
+	var existsErr *thinclones.SnapshotExistsError
+	require.ErrorAs(t, err, &existsErr)
+
+	snapshots := m.SnapshotList()
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "main", snapshots[0].Branch)
+	assert.Equal(t, "2026-01-01 00:00:00 +0000 UTC", snapshots[0].DataStateAt.String())
+
+	require.NoError(t, m.CreateClone("main", "clone1", snapshotID, 0))
+	require.Error(t, m.CreateClone("main", "clone1", snapshotID, 0))
+	require.Error(t, m.CreateClone("main", "clone2", "dblab_pool@missing", 0))
+
+	clones, err := m.ListClonesNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"clone1"}, clones)
+
+	assert.Equal(t, []string{"-", snapshotID}, m.GetDatasetOrigins("dblab_pool/branch/main/clone1"))
+
+	dependent, err := m.HasDependentEntity(snapshotID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dblab_pool/branch/main/clone1/r0"}, dependent)
+
+	require.Error(t, m.DestroySnapshot(snapshotID, thinclones.DestroyOptions{}))
+
+	require.NoError(t, m.DestroyClone("main", "clone1", 0))
+
+	clones, err = m.ListClonesNames()
+	require.NoError(t, err)
+	assert.Empty(t, clones)
+
+	deleteCmds := runner.commands("btrfs subvolume delete")
+	require.Len(t, deleteCmds, 1)
+	assert.Contains(t, deleteCmds[0], "/branch/main/clone1/r0")
+
+	require.NoError(t, m.DestroySnapshot(snapshotID, thinclones.DestroyOptions{}))
+	assert.Empty(t, m.SnapshotList())
+}
+
+func TestDestroyDatasetWithDependents(t *testing.T) {
+	m, runner := newTestManager(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+
+	require.NoError(t, m.CreateBranch("dblab_pool/branch/dev", snapshotID))
+	require.NoError(t, m.Snapshot("dblab_pool/branch/dev@20260102000000"))
+	require.NoError(t, m.CreateClone("dev", "clone1", "dblab_pool/branch/dev@20260102000000", 0))
+
+	require.NoError(t, m.DestroyBranchDataset("dblab_pool/branch/dev"))
+
+	require.NoError(t, m.store.View(func(md *metadata) error {
+		assert.Empty(t, md.Subtree("dblab_pool/branch/dev"))
+		assert.Contains(t, md.Snapshots, snapshotID)
+		assert.NotContains(t, md.Snapshots, "dblab_pool/branch/dev@20260102000000")
+
+		return nil
+	}))
+
+	deleteCmds := runner.commands("btrfs subvolume delete")
+	require.Len(t, deleteCmds, 3)
+	// Nested subvolumes go before their parents.
+	assert.Contains(t, deleteCmds[1], "/branch/dev/clone1/r0")
+	assert.True(t, strings.HasSuffix(deleteCmds[2], "/branch/dev'"))
+}
+
+func TestDestroyPartialFailure(t *testing.T) {
+	m, runner := newTestManager(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+
+	require.NoError(t, m.CreateBranch("dblab_pool/branch/dev", snapshotID))
+	require.NoError(t, m.Snapshot("dblab_pool/branch/dev@20260102000000"))
+	require.NoError(t, m.CreateClone("dev", "clone1", "dblab_pool/branch/dev@20260102000000", 0))
+
+	runner.failing = []string{"/branch/dev'"}
+
+	require.Error(t, m.DestroyBranchDataset("dblab_pool/branch/dev"))
+
+	require.NoError(t, m.store.View(func(md *metadata) error {
+		assert.Contains(t, md.Datasets, "dblab_pool/branch/dev")
+		assert.NotContains(t, md.Datasets, "dblab_pool/branch/dev/clone1/r0")
+		assert.NotContains(t, md.Snapshots, "dblab_pool/branch/dev@20260102000000")
+		assert.Contains(t, md.Snapshots, snapshotID)
+
+		return nil
+	}))
+
+	runner.failing = nil
+
+	require.NoError(t, m.DestroyBranchDataset("dblab_pool/branch/dev"))
+
+	require.NoError(t, m.store.View(func(md *metadata) error {
+		assert.Empty(t, md.Subtree("dblab_pool/branch/dev"))
+
+		return nil
+	}))
+}
+
+func TestGetFilesystemState(t *testing.T) {
+	m, runner := newTestManager(t)
+
+	runner.outputs["btrfs filesystem usage"] = `Overall:
+    Device size:                 1000
+    Used:                         400
+    Free (estimated):             600      (min: 300)
+`
+	runner.outputs["btrfs inspect-internal rootid"] = "5\n"
+	runner.outputs["btrfs subvolume list"] = `ID 257 gen 14 top level 5 path .dblab/snapshots/@snapshot_1
+ID 258 gen 15 top level 5 path branch/main/clone1/r0
+ID 259 gen 16 top level 5 path branch/main/clone2/r0
+`
+	runner.outputs["btrfs qgroup show"] = `qgroupid rfer excl
+0/5      300  100
+0/257    290  20
+0/258    295  30
+0/259    300  40
+`
+
+	fs, err := m.GetFilesystemState()
+	require.NoError(t, err)
+	assert.Equal(t, models.FileSystem{
+		Mode:            PoolMode,
+		Size:            1000,
+		Free:            600,
+		Used:            400,
+		UsedBySnapshots: 20,
+		UsedByClones:    70,
+		DataSize:        300,
+		CompressRatio:   1,
+	}, fs)
+}
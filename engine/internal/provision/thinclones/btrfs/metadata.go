@@ -0,0 +1,140 @@
+/*
+2026 © Postgres.ai
+*/
+
+package btrfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/catalog"
+)
+
+const (
+	metadataFileName = "metadata.json"
+	metadataFileMode = 0600
+	metadataDirMode  = 0700
+)
+
+// entry describes a dataset or a read-only snapshot.
+// A dataset is either a writable subvolume or a plain directory that groups other datasets.
+type entry struct {
+	catalog.Entry
+	Subvolume bool `json:"subvolume,omitempty"`
+}
+
+// metadata is the content of the sidecar file that replaces ZFS user properties.
+type metadata = catalog.Catalog[*entry]
+
+func newEntry(origin string, subvolume bool) *entry {
+	return &entry{Entry: catalog.Entry{Origin: origin, CreatedAt: time.Now()}, Subvolume: subvolume}
+}
+
+// newGroupEntry creates a plain directory that groups other datasets.
+func newGroupEntry() *entry {
+	return newEntry("", false)
+}
+
+// metadataStore keeps dataset and snapshot metadata in a JSON file inside the pool.
+type metadataStore struct {
+	mu  sync.Mutex
+	dir func() string
+}
+
+func newMetadataStore(dir func() string) *metadataStore {
+	return &metadataStore{dir: dir}
+}
+
+func (s *metadataStore) filePath() string {
+	return path.Join(s.dir(), metadataFileName)
+}
+
+// View provides read-only access to the current metadata.
+func (s *metadataStore) View(fn func(md *metadata) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	md, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	return fn(md)
+}
+
+// Update loads the metadata, applies changes and atomically writes the result back.
+func (s *metadataStore) Update(fn func(md *metadata) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	md, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(md); err != nil {
+		return err
+	}
+
+	return s.save(md)
+}
+
+func (s *metadataStore) load() (*metadata, error) {
+	filePath := s.filePath()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return catalog.New[*entry](), nil
+		}
+
+		return nil, fmt.Errorf("failed to read metadata file: %w", err)
+	}
+
+	md := catalog.New[*entry]()
+
+	if err := json.Unmarshal(data, md); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata file %s: %w", filePath, err)
+	}
+
+	if md.Datasets == nil {
+		md.Datasets = make(map[string]*entry)
+	}
+
+	if md.Snapshots == nil {
+		md.Snapshots = make(map[string]*entry)
+	}
+
+	return md, nil
+}
+
+func (s *metadataStore) save(md *metadata) error {
+	data, err := json.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	filePath := s.filePath()
+
+	if err := os.MkdirAll(path.Dir(filePath), metadataDirMode); err != nil {
+		return fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+
+	tmpFile := filePath + ".tmp"
+
+	if err := os.WriteFile(tmpFile, data, metadataFileMode); err != nil {
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile, filePath); err != nil {
+		return fmt.Errorf("failed to replace metadata file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+/*
+2026 © Postgres.ai
+*/
+
+package btrfs
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// topLevelSubvolumeID defines the ID of the top-level subvolume of a Btrfs filesystem.
+	topLevelSubvolumeID = 5
+
+	subvolumeListFields = 9
+)
+
+var qgroupLineRegexp = regexp.MustCompile(`^0/(\d+)\s+(\d+)\s+(\d+)`)
+
+// subvolume describes a Btrfs subvolume and its qgroup usage.
+type subvolume struct {
+	ID         uint64
+	Path       string
+	Referenced uint64
+	Exclusive  uint64
+}
+
+// fsUsage describes the overall usage of a Btrfs filesystem.
+type fsUsage struct {
+	Size uint64
+	Used uint64
+	Free uint64
+}
+
+func createSubvolumeCommand(subvolumePath string) string {
+	return fmt.Sprintf("mkdir -p '%s' && btrfs subvolume create '%s'", path.Dir(subvolumePath), subvolumePath)
+}
+
+func snapshotCommand(source, target string, readOnly bool) string {
+	flags := ""
+
+	if readOnly {
+		flags = "-r "
+	}
+
+	return fmt.Sprintf("mkdir -p '%s' && btrfs subvolume snapshot %s'%s' '%s'", path.Dir(target), flags, source, target)
+}
+
+func deleteSubvolumeCommand(subvolumePath string) string {
+	return fmt.Sprintf("btrfs subvolume delete '%s'", subvolumePath)
+}
+
+func removeDirCommand(dirPath string) string {
+	return fmt.Sprintf("rm -rf '%s'", dirPath)
+}
+
+func moveCommand(source, target string) string {
+	return fmt.Sprintf("mkdir -p '%s' && mv '%s' '%s'", path.Dir(target), source, target)
+}
+
+func usageCommand(rootPath string) string {
+	return fmt.Sprintf("btrfs filesystem usage -b '%s'", rootPath)
+}
+
+func rootIDCommand(rootPath string) string {
+	return fmt.Sprintf("btrfs inspect-internal rootid '%s'", rootPath)
+}
+
+func listSubvolumesCommand(rootPath string) string {
+	return fmt.Sprintf("btrfs subvolume list '%s'", rootPath)
+}
+
+func qgroupCommand(rootPath string) string {
+	return fmt.Sprintf("btrfs qgroup show --raw '%s'", rootPath)
+}
+
+// parseUsage parses the output of "btrfs filesystem usage -b".
+func parseUsage(out string) (fsUsage, error) {
+	usage := fsUsage{}
+	found := 0
+
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var target *uint64
+
+		switch key {
+		case "Device size":
+			target = &usage.Size
+		case "Used":
+			target = &usage.Used
+		case "Free (estimated)":
+			target = &usage.Free
+		default:
+			continue
+		}
+
+		number, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return fsUsage{}, fmt.Errorf("failed to parse %q value: %w", key, err)
+		}
+
+		*target = number
+		found++
+	}
+
+	const expectedValues = 3
+
+	if found != expectedValues {
+		return fsUsage{}, fmt.Errorf("unexpected output of filesystem usage: %q", out)
+	}
+
+	return usage, nil
+}
+
+// parseSubvolumeList parses the output of "btrfs subvolume list" into a map of subvolume IDs to paths.
+func parseSubvolumeList(out string) (map[uint64]string, error) {
+	subvolumes := make(map[uint64]string)
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		// ID 257 gen 12 top level 5 path dblab_pool/branch/main
+		fields := strings.Fields(line)
+		if len(fields) < subvolumeListFields || fields[0] != "ID" || fields[7] != "path" {
+			continue
+		}
+
+		id, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse subvolume ID %q: %w", fields[1], err)
+		}
+
+		subvolumes[id] = strings.Join(fields[8:], " ")
+	}
+
+	return subvolumes, nil
+}
+
+// parseQgroups parses the output of "btrfs qgroup show --raw" into a map of subvolume IDs to usage.
+func parseQgroups(out string) map[uint64]subvolume {
+	usage := make(map[uint64]subvolume)
+
+	for _, line := range strings.Split(out, "\n") {
+		matches := qgroupLineRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if len(matches) == 0 {
+			continue
+		}
+
+		id, errID := strconv.ParseUint(matches[1], 10, 64)
+		referenced, errRef := strconv.ParseUint(matches[2], 10, 64)
+		exclusive, errExcl := strconv.ParseUint(matches[3], 10, 64)
+
+		if errID != nil || errRef != nil || errExcl != nil {
+			continue
+		}
+
+		usage[id] = subvolume{ID: id, Referenced: referenced, Exclusive: exclusive}
+	}
+
+	return usage
+}
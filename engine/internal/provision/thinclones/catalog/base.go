@@ -0,0 +1,465 @@
+/*
+2026 © Postgres.ai
+*/
+
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/runners"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util/branching"
+)
+
+const (
+	dataStateAtLabel    = "dblab:datastateat"
+	isRoughStateAtLabel = "dblab:isroughdsa"
+
+	// Clone must have 3 segments: branch, name, revision.
+	numCloneSegments = 3
+)
+
+// Config defines configuration of a thin-clone manager.
+type Config struct {
+	Pool              *resources.Pool
+	PreSnapshotSuffix string
+	OSUsername        string
+}
+
+// Filesystem runs the filesystem-specific commands for datasets and snapshots of a catalog.
+type Filesystem interface {
+	// Snapshot takes a read-only snapshot of a dataset and registers it in the catalog.
+	Snapshot(snapshotName string) error
+
+	// CreateDataset creates a dataset for nested datasets.
+	CreateDataset(datasetName string) error
+
+	// Destroy removes datasets and snapshots together with their dependents, the way "zfs destroy -R" does.
+	Destroy(datasets, snapshots []string) error
+
+	// ListSnapshots returns snapshots available for cloning along with their space usage, the newest first.
+	ListSnapshots() ([]resources.Snapshot, error)
+
+	// GetBatchSessionState returns session states of clones.
+	GetBatchSessionState(requests []resources.SessionStateRequest) (map[string]resources.SessionState, error)
+}
+
+// Base implements the filesystem-independent part of a thin-clone manager on top of a catalog.
+type Base[N Node] struct {
+	runner    runners.Runner
+	config    Config
+	store     Store[N]
+	fs        Filesystem
+	mu        *sync.Mutex
+	snapshots []resources.Snapshot
+}
+
+// NewBase creates a new Base.
+func NewBase[N Node](runner runners.Runner, config Config, store Store[N], fs Filesystem) *Base[N] {
+	return &Base[N]{
+		runner:    runner,
+		config:    config,
+		store:     store,
+		fs:        fs,
+		mu:        &sync.Mutex{},
+		snapshots: make([]resources.Snapshot, 0),
+	}
+}
+
+// Pool gets a storage pool.
+func (b *Base[N]) Pool() *resources.Pool {
+	return b.config.Pool
+}
+
+// Config returns the manager's configuration.
+func (b *Base[N]) Config() Config {
+	return b.config
+}
+
+// UpdateConfig updates the manager's configuration.
+func (b *Base[N]) UpdateConfig(cfg Config) {
+	b.config = cfg
+}
+
+// EnsureDataOwnership makes dataDir and its contents owned by the engine OS user.
+func (b *Base[N]) EnsureDataOwnership(dataDir string) error {
+	cmd := fmt.Sprintf("if ! %s; then chown -R '%s' '%s'; fi",
+		ownedByUserCondition(dataDir, b.config.OSUsername), b.config.OSUsername, dataDir)
+
+	log.Dbg(cmd)
+
+	out, err := b.runner.Run(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to ensure data ownership: %w. Out: %v", err, out)
+	}
+
+	return nil
+}
+
+// ownedByUserCondition returns a shell test that succeeds when path is owned by osUsername.
+func ownedByUserCondition(path, osUsername string) string {
+	return fmt.Sprintf("[ \"$(stat -c '%%u' '%s' 2>/dev/null)\" = \"$(id -u '%s' 2>/dev/null || echo nouid)\" ]",
+		path, osUsername)
+}
+
+// ChownCloneCommand builds the ownership step for a freshly created clone. A thin clone keeps file ownership
+// of its origin, so the recursive walk is skipped when the data directory already belongs to the engine OS user.
+func ChownCloneCommand(dataDir, mountLocation, osUsername string) string {
+	return fmt.Sprintf("if %s; then chown '%s' '%s'; else chown -R '%s' '%s'; fi",
+		ownedByUserCondition(dataDir, osUsername), osUsername, mountLocation, osUsername, mountLocation)
+}
+
+// DestroyClone destroys a clone.
+func (b *Base[N]) DestroyClone(branchName, cloneName string, revision int) error {
+	cloneMountName := b.config.Pool.CloneName(branchName, cloneName, revision)
+	cloneDataset := b.config.Pool.CloneDataset(branchName, cloneName)
+
+	log.Dbg(cloneMountName)
+
+	var (
+		exists       bool
+		hasSnapshots bool
+		revisions    int
+	)
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		_, exists = c.Datasets[cloneMountName]
+		hasSnapshots = len(c.DatasetSnapshots(cloneMountName)) > 0
+		revisions = len(c.Subtree(cloneDataset))
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to read catalog: %w", err)
+	}
+
+	if !exists {
+		log.Msg(fmt.Sprintf("clone %q is not exists; skipping", cloneMountName))
+		return nil
+	}
+
+	if hasSnapshots {
+		log.Msg(fmt.Sprintf("clone %q has dependent snapshot; skipping", cloneMountName))
+		return nil
+	}
+
+	target := cloneMountName
+
+	if revisions <= branching.MinDatasetNumber {
+		// There are no other revisions, so we can destroy the entire clone dataset.
+		target = cloneDataset
+	}
+
+	if err := b.DestroyDataset(target); err != nil {
+		if strings.Contains(cloneName, "clone_pre") {
+			return fmt.Errorf("failed to destroy clone: %w", err)
+		}
+
+		log.Dbg(err)
+	}
+
+	return nil
+}
+
+// ListClonesNames returns a list of clone names.
+func (b *Base[N]) ListClonesNames() ([]string, error) {
+	cloneNames := []string{}
+	branchPrefix := b.config.Pool.Name + "/" + branching.BranchDir + "/"
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		for name := range c.Datasets {
+			bc, found := strings.CutPrefix(name, branchPrefix)
+			if !found {
+				continue
+			}
+
+			segments := strings.Split(bc, "/")
+
+			if len(segments) != numCloneSegments {
+				// It's a branch dataset, not a clone. Skip it.
+				continue
+			}
+
+			cloneName := segments[1]
+
+			if cloneName != "" && !strings.Contains(name, "_pre") {
+				cloneNames = append(cloneNames, cloneName)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list clones: %w", err)
+	}
+
+	sort.Strings(cloneNames)
+
+	return util.Unique(cloneNames), nil
+}
+
+// CreateSnapshot creates a new snapshot.
+func (b *Base[N]) CreateSnapshot(poolSuffix, dataStateAt string) (string, error) {
+	poolName := b.config.Pool.Name
+
+	if poolSuffix != "" {
+		poolName = util.GetPoolName(b.config.Pool.Name, poolSuffix)
+	}
+
+	originalDSA := dataStateAt
+
+	if dataStateAt == "" {
+		dataStateAt = time.Now().Format(util.DataStateAtFormat)
+	}
+
+	snapshotName := getSnapshotName(poolName, dataStateAt)
+
+	var exists bool
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		_, exists = c.Snapshots[snapshotName]
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("failed to get a snapshot list: %w", err)
+	}
+
+	if exists {
+		return "", thinclones.NewSnapshotExistsError(snapshotName)
+	}
+
+	if err := b.fs.Snapshot(snapshotName); err != nil {
+		return "", fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	trimmedDSA := strings.TrimSuffix(dataStateAt, b.config.PreSnapshotSuffix)
+
+	if err := b.store.Update(func(c *Catalog[N]) error {
+		if err := c.SetProperty(snapshotName, dataStateAtLabel, trimmedDSA); err != nil {
+			return err
+		}
+
+		if originalDSA == "" {
+			return c.SetProperty(snapshotName, isRoughStateAtLabel, "1")
+		}
+
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("failed to set the dataStateAt option for snapshot: %w", err)
+	}
+
+	dataStateTime, err := util.ParseCustomTime(trimmedDSA)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse dataStateAt: %w", err)
+	}
+
+	branch := branching.ParseBranchNameFromSnapshot(snapshotName, poolName)
+	if branch == "" {
+		branch = branching.DefaultBranch
+	}
+
+	newSnapshot := resources.Snapshot{
+		ID:          snapshotName,
+		CreatedAt:   time.Now(),
+		DataStateAt: dataStateTime,
+		Pool:        b.config.Pool.Name,
+		Branch:      branch,
+	}
+
+	if !strings.HasSuffix(snapshotName, b.config.PreSnapshotSuffix) {
+		b.addSnapshotToList(newSnapshot)
+
+		log.Dbg("New snapshot:", newSnapshot)
+
+		b.RefreshSnapshotList()
+	}
+
+	return snapshotName, nil
+}
+
+// getSnapshotName builds a snapshot name.
+func getSnapshotName(pool, dataStateAt string) string {
+	return fmt.Sprintf("%s@snapshot_%s", pool, dataStateAt)
+}
+
+// DestroySnapshot destroys the snapshot.
+func (b *Base[N]) DestroySnapshot(snapshotName string, opts thinclones.DestroyOptions) error {
+	rel, err := b.detectBranching(snapshotName)
+	if err != nil {
+		return fmt.Errorf("failed to inspect snapshot properties: %w", err)
+	}
+
+	var clones []string
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		clones = c.Clones(snapshotName)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to read catalog: %w", err)
+	}
+
+	if len(clones) > 0 && !opts.Force {
+		return fmt.Errorf("snapshot %s has dependent clones: %s", snapshotName, strings.Join(clones, branchSep))
+	}
+
+	if err := b.fs.Destroy(nil, []string{snapshotName}); err != nil {
+		return err
+	}
+
+	if rel != nil {
+		if err := b.moveBranchPointer(rel, snapshotName); err != nil {
+			return err
+		}
+	}
+
+	b.removeSnapshotFromList(snapshotName)
+	thinclones.DeleteSnapshotMetadata(snapshotName)
+
+	return nil
+}
+
+// DestroyDataset destroys dataset with all dependent objects.
+func (b *Base[N]) DestroyDataset(dataset string) error {
+	return b.fs.Destroy([]string{dataset}, nil)
+}
+
+// DestroyBranchDataset recursively destroys a branch dataset and everything nested under it.
+// Callers must run it under the clone-deletion lock because nothing prevents removing a live clone.
+func (b *Base[N]) DestroyBranchDataset(branchDataset string) error {
+	return b.DestroyDataset(branchDataset)
+}
+
+type snapshotRelation struct {
+	parent string
+	branch string
+}
+
+func (b *Base[N]) detectBranching(snapshotName string) (*snapshotRelation, error) {
+	var parent, branch string
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		var err error
+
+		if parent, err = c.Property(snapshotName, parentProp); err != nil {
+			return err
+		}
+
+		branch, err = c.Property(snapshotName, branchProp)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	if parent == "" || branch == "" {
+		return nil, nil
+	}
+
+	return &snapshotRelation{parent: parent, branch: branch}, nil
+}
+
+func (b *Base[N]) moveBranchPointer(rel *snapshotRelation, snapshotName string) error {
+	if rel == nil {
+		return nil
+	}
+
+	if err := b.DeleteChildProp(snapshotName, rel.parent); err != nil {
+		return fmt.Errorf("failed to delete a child property from snapshot %s: %w", rel.parent, err)
+	}
+
+	parentProperties, err := b.GetSnapshotProperties(rel.parent)
+	if err != nil {
+		return fmt.Errorf("failed to get parent snapshot properties: %w", err)
+	}
+
+	if parentProperties.Root == rel.branch {
+		if err := b.DeleteRootProp(rel.branch, rel.parent); err != nil {
+			return fmt.Errorf("failed to delete root property: %w", err)
+		}
+	} else {
+		if err := b.AddBranchProp(rel.branch, rel.parent); err != nil {
+			return fmt.Errorf("failed to set branch property to snapshot %s: %w", rel.parent, err)
+		}
+	}
+
+	return nil
+}
+
+// GetSessionState returns a state of a session.
+func (b *Base[N]) GetSessionState(branch, name string) (*resources.SessionState, error) {
+	states, err := b.fs.GetBatchSessionState([]resources.SessionStateRequest{{CloneID: name, Branch: branch}})
+	if err != nil {
+		return nil, err
+	}
+
+	state, ok := states[name]
+	if !ok {
+		return nil, errors.New("cannot get session state: clone dataset does not exist")
+	}
+
+	return &state, nil
+}
+
+// SnapshotList returns a list of snapshots.
+func (b *Base[N]) SnapshotList() []resources.Snapshot {
+	b.mu.Lock()
+	snapshots := b.snapshots
+	b.mu.Unlock()
+
+	return snapshots
+}
+
+// RefreshSnapshotList updates the list of snapshots.
+func (b *Base[N]) RefreshSnapshotList() {
+	snapshots, err := b.fs.ListSnapshots()
+	if err != nil {
+		log.Err("failed to refresh snapshot list: ", err)
+		return
+	}
+
+	b.mu.Lock()
+	b.snapshots = snapshots
+	b.mu.Unlock()
+}
+
+func (b *Base[N]) addSnapshotToList(snapshot resources.Snapshot) {
+	b.mu.Lock()
+	b.snapshots = append([]resources.Snapshot{snapshot}, b.snapshots...)
+	b.mu.Unlock()
+}
+
+func (b *Base[N]) removeSnapshotFromList(snapshotName string) {
+	b.mu.Lock()
+
+	for i, snapshot := range b.snapshots {
+		if snapshot.ID == snapshotName {
+			b.snapshots = append((b.snapshots)[:i], (b.snapshots)[i+1:]...)
+
+			break
+		}
+	}
+
+	b.mu.Unlock()
+}
+
+// ClonableSnapshots returns snapshots of the catalog available for cloning, the newest first.
+// Pre-snapshots are not allowed to be used for cloning.
+func (b *Base[N]) ClonableSnapshots(c *Catalog[N]) []resources.Snapshot {
+	snapshots := []resources.Snapshot{}
+
+	for _, snapshot := range c.SortedSnapshots(b.config.Pool.Name) {
+		if strings.HasSuffix(snapshot.ID, b.config.PreSnapshotSuffix) {
+			continue
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots
+}
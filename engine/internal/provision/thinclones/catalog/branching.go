@@ -0,0 +1,676 @@
+/*
+2026 © Postgres.ai
+*/
+
+package catalog
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util/branching"
+)
+
+const (
+	branchProp        = "dle:branch"
+	parentProp        = "dle:parent"
+	childProp         = "dle:child"
+	rootProp          = "dle:root"
+	messageProp       = "dle:message"
+	protectedTillProp = "dle:protected_till"
+	deleteAtProp      = "dle:delete_at"
+	walPositionProp   = "dle:wal_position"
+	branchSep         = ","
+	empty             = "-"
+)
+
+// InitBranching inits data branching.
+func (b *Base[N]) InitBranching() error {
+	snapshots := b.SnapshotList()
+
+	numberSnapshots := len(snapshots)
+
+	if numberSnapshots == 0 {
+		log.Dbg("no snapshots to init data branching")
+		return nil
+	}
+
+	latest := snapshots[0]
+
+	latestBranchProperty, err := b.getProperty(branchProp, latest.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot property: %w", err)
+	}
+
+	if latestBranchProperty != "" {
+		log.Dbg("data branching is already initialized")
+
+		return nil
+	}
+
+	if err := b.AddBranchProp(branching.DefaultBranch, latest.ID); err != nil {
+		return fmt.Errorf("failed to add branch property: %w", err)
+	}
+
+	leader := latest
+
+	for i := 1; i < numberSnapshots; i++ {
+		follower := snapshots[i]
+
+		if err := b.SetRelation(follower.ID, leader.ID); err != nil {
+			return fmt.Errorf("failed to set snapshot relations: %w", err)
+		}
+
+		brProperty, err := b.getProperty(branchProp, follower.ID)
+		if err != nil {
+			return fmt.Errorf("failed to read branch property: %w", err)
+		}
+
+		if brProperty == branching.DefaultBranch {
+			if err := b.DeleteBranchProp(branching.DefaultBranch, follower.ID); err != nil {
+				return fmt.Errorf("failed to delete default branch property: %w", err)
+			}
+
+			break
+		}
+
+		leader = follower
+	}
+
+	brName := b.Pool().BranchName(b.Pool().Name, branching.DefaultBranch)
+
+	if err := b.fs.CreateDataset(brName); err != nil {
+		return fmt.Errorf("failed to init main branch dataset: %w", err)
+	}
+
+	b.RefreshSnapshotList()
+
+	log.Msg("data branching has been successfully initialized")
+
+	return nil
+}
+
+// VerifyBranchMetadata verifies data branching metadata and rebuilds parent/child links from branch tags.
+func (b *Base[N]) VerifyBranchMetadata() error {
+	snapshots := b.SnapshotList()
+
+	numberSnapshots := len(snapshots)
+
+	if numberSnapshots == 0 {
+		log.Dbg("no snapshots to verify data branching")
+		return nil
+	}
+
+	return b.store.Update(func(c *Catalog[N]) error {
+		branchHeads := make(map[string]string)
+		branchRoots := make(map[string]string)
+		parents := make(map[string]string, numberSnapshots)
+		children := make(map[string][]string, numberSnapshots)
+
+		// Iterate oldest → newest to compute the chain in memory.
+		for i := numberSnapshots; i > 0; i-- {
+			sn := snapshots[i-1]
+
+			for _, br := range splitBranches(sn.Branch) {
+				head, ok := branchHeads[br]
+				if !ok {
+					branchHeads[br] = sn.ID
+					branchRoots[br] = sn.ID
+
+					continue
+				}
+
+				parents[sn.ID] = head
+				children[head] = appendUnique(children[head], sn.ID)
+				branchHeads[br] = sn.ID
+			}
+		}
+
+		// Restore cross-branch parent/child links using dle:root properties.
+		for forkSnap, entry := range c.Snapshots {
+			for _, br := range unwindField(entry.entry().Properties[rootProp]) {
+				oldest, ok := branchRoots[br]
+				if !ok || parents[oldest] != "" {
+					continue
+				}
+
+				parents[oldest] = forkSnap
+				children[forkSnap] = appendUnique(children[forkSnap], oldest)
+			}
+		}
+
+		for _, sn := range snapshots {
+			if _, ok := c.Snapshots[sn.ID]; !ok {
+				continue
+			}
+
+			if err := c.SetProperty(sn.ID, parentProp, parents[sn.ID]); err != nil {
+				return err
+			}
+
+			if err := c.SetProperty(sn.ID, childProp, strings.Join(children[sn.ID], branchSep)); err != nil {
+				return err
+			}
+
+			// Keep branch tags on head snapshots only.
+			branches := []string{}
+
+			for br, head := range branchHeads {
+				if head == sn.ID {
+					branches = append(branches, br)
+				}
+			}
+
+			sort.Strings(branches)
+
+			if err := c.SetProperty(sn.ID, branchProp, strings.Join(branches, branchSep)); err != nil {
+				return err
+			}
+		}
+
+		log.Msg("data branching has been verified")
+
+		return nil
+	})
+}
+
+func appendUnique(slice []string, val string) []string {
+	for _, s := range slice {
+		if s == val {
+			return slice
+		}
+	}
+
+	return append(slice, val)
+}
+
+// splitBranches parses a comma-separated branch property value into individual branch names.
+// Snapshots with no branch tag default to the main branch, consistent with InitBranching.
+func splitBranches(branch string) []string {
+	branches := unwindField(branch)
+
+	if len(branches) == 0 {
+		return []string{branching.DefaultBranch}
+	}
+
+	return branches
+}
+
+// ListBranches lists data pool branches.
+func (b *Base[N]) ListBranches() (map[string]string, error) {
+	return b.listBranches()
+}
+
+// ListAllBranches lists all branches of the pool if it matches the pool filter.
+func (b *Base[N]) ListAllBranches(poolList []string) ([]models.BranchEntity, error) {
+	if len(poolList) > 0 && !containsString(poolList, b.config.Pool.Name) {
+		return []models.BranchEntity{}, nil
+	}
+
+	branches := make([]models.BranchEntity, 0)
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		for _, snapshot := range c.SortedSnapshots(b.config.Pool.Name) {
+			for _, branchName := range unwindField(c.Snapshots[snapshot.ID].entry().Properties[branchProp]) {
+				branches = append(branches, models.BranchEntity{
+					Name:       branchName,
+					Dataset:    branching.ParseBaseDatasetFromSnapshot(snapshot.ID),
+					SnapshotID: snapshot.ID,
+				})
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	return branches, nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *Base[N]) listBranches() (map[string]string, error) {
+	branches := make(map[string]string)
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		for name, entry := range c.Snapshots {
+			for _, branchName := range unwindField(entry.entry().Properties[branchProp]) {
+				branches[branchName] = name
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	return branches, nil
+}
+
+// GetRepo provides repository details about snapshots and branches of the pool.
+func (b *Base[N]) GetRepo() (*models.Repo, error) {
+	return b.getRepo()
+}
+
+// GetAllRepo provides all repository details about snapshots and branches.
+// A catalog-based manager only knows its own pool, so the result is the same as GetRepo.
+func (b *Base[N]) GetAllRepo() (*models.Repo, error) {
+	return b.getRepo()
+}
+
+func (b *Base[N]) getRepo() (*models.Repo, error) {
+	repo := models.NewRepo()
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		for name, entry := range c.Snapshots {
+			props := entry.entry().Properties
+
+			protected, protectedTill, err := models.ParseProtectedTill(props[protectedTillProp])
+			if err != nil {
+				log.Warn(err)
+			}
+
+			deleteAt, err := models.ParseDeleteAt(props[deleteAtProp])
+			if err != nil {
+				log.Warn(err)
+			}
+
+			snDetail := models.SnapshotDetails{
+				ID:            name,
+				Parent:        props[parentProp],
+				Child:         unwindField(props[childProp]),
+				Branch:        unwindField(props[branchProp]),
+				Root:          unwindField(props[rootProp]),
+				DataStateAt:   props[dataStateAtLabel],
+				Message:       decodeCommitMessage(props[messageProp]),
+				Dataset:       SnapshotDataset(name),
+				Clones:        c.Clones(name),
+				Protected:     protected,
+				ProtectedTill: protectedTill,
+				DeleteAt:      deleteAt,
+			}
+
+			if len(snDetail.Clones) == 0 {
+				snDetail.Clones = nil
+			}
+
+			repo.Snapshots[name] = snDetail
+
+			for _, br := range snDetail.Branch {
+				repo.Branches[br] = name
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	return repo, nil
+}
+
+func decodeCommitMessage(field string) string {
+	if field == "" || field == empty {
+		return ""
+	}
+
+	decodedString, err := base64.StdEncoding.DecodeString(field)
+	if err != nil {
+		log.Dbg(fmt.Sprintf("Unable to decode commit message: %#v\n", field))
+		return field
+	}
+
+	return string(decodedString)
+}
+
+func unwindField(field string) []string {
+	items := make([]string, 0)
+
+	for _, item := range strings.Split(field, branchSep) {
+		item = strings.TrimSpace(item)
+
+		if item != "" && item != empty {
+			items = append(items, item)
+		}
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	return items
+}
+
+// GetSnapshotProperties get custom snapshot properties.
+func (b *Base[N]) GetSnapshotProperties(snapshotName string) (thinclones.SnapshotProperties, error) {
+	properties := thinclones.SnapshotProperties{}
+
+	err := b.store.View(func(c *Catalog[N]) error {
+		entry, ok := c.Snapshots[snapshotName]
+		if !ok {
+			return fmt.Errorf("%w: snapshot %s", ErrEntryNotFound, snapshotName)
+		}
+
+		props := entry.entry().Properties
+
+		properties = thinclones.SnapshotProperties{
+			Name:          snapshotName,
+			Parent:        props[parentProp],
+			Child:         props[childProp],
+			Branch:        props[branchProp],
+			Root:          props[rootProp],
+			DataStateAt:   props[dataStateAtLabel],
+			Message:       decodeCommitMessage(props[messageProp]),
+			Clones:        strings.Join(c.Clones(snapshotName), branchSep),
+			ProtectedTill: props[protectedTillProp],
+			DeleteAt:      props[deleteAtProp],
+			WALPosition:   props[walPositionProp],
+		}
+
+		return nil
+	})
+
+	return properties, err
+}
+
+// AddBranchProp adds branch to snapshot property.
+func (b *Base[N]) AddBranchProp(branch, snapshotName string) error {
+	return b.addToSet(branchProp, snapshotName, branch)
+}
+
+// DeleteBranchProp deletes branch from snapshot property.
+func (b *Base[N]) DeleteBranchProp(branch, snapshotName string) error {
+	return b.deleteFromSet(branchProp, branch, snapshotName)
+}
+
+// SetRelation sets up relation between two snapshots.
+func (b *Base[N]) SetRelation(parent, snapshotName string) error {
+	if err := b.setParent(parent, snapshotName); err != nil {
+		return err
+	}
+
+	return b.addChild(parent, snapshotName)
+}
+
+// DeleteChildProp deletes child from snapshot property.
+func (b *Base[N]) DeleteChildProp(childSnapshot, snapshotName string) error {
+	return b.deleteFromSet(childProp, childSnapshot, snapshotName)
+}
+
+// DeleteRootProp deletes root from snapshot property.
+func (b *Base[N]) DeleteRootProp(branch, snapshotName string) error {
+	return b.deleteFromSet(rootProp, branch, snapshotName)
+}
+
+func (b *Base[N]) setParent(parent, snapshotName string) error {
+	return b.setProperty(parentProp, parent, snapshotName)
+}
+
+func (b *Base[N]) addChild(parent, snapshotName string) error {
+	return b.addToSet(childProp, parent, snapshotName)
+}
+
+// SetRoot marks snapshot as a root of branch.
+func (b *Base[N]) SetRoot(branch, snapshotName string) error {
+	return b.addToSet(rootProp, snapshotName, branch)
+}
+
+// SetDSA sets value of DataStateAt to snapshot.
+func (b *Base[N]) SetDSA(dsa, snapshotName string) error {
+	return b.setProperty(dataStateAtLabel, dsa, snapshotName)
+}
+
+// SetMessage uses the given message as the commit message.
+func (b *Base[N]) SetMessage(message, snapshotName string) error {
+	encodedMessage := base64.StdEncoding.EncodeToString([]byte(message))
+	return b.setProperty(messageProp, encodedMessage, snapshotName)
+}
+
+// SetWALPosition records the WAL location the data of the snapshot has been replayed to.
+func (b *Base[N]) SetWALPosition(lsn, snapshotName string) error {
+	return b.setProperty(walPositionProp, lsn, snapshotName)
+}
+
+// HasDependentEntity returns datasets cloned from the snapshot and warns about dependent branches and snapshots.
+func (b *Base[N]) HasDependentEntity(snapshotName string) ([]string, error) {
+	var (
+		root, child string
+		clones      []string
+	)
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		var err error
+
+		if root, err = c.Property(snapshotName, rootProp); err != nil {
+			return fmt.Errorf("failed to check root property: %w", err)
+		}
+
+		if child, err = c.Property(snapshotName, childProp); err != nil {
+			return fmt.Errorf("failed to check snapshot child property: %w", err)
+		}
+
+		clones = c.Clones(snapshotName)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if root != "" {
+		log.Warn(fmt.Errorf("snapshot has dependent branches: %s", root))
+	}
+
+	if child != "" {
+		log.Warn(fmt.Sprintf("snapshot %s has dependent snapshots: %s", snapshotName, child))
+	}
+
+	return clones, nil
+}
+
+// KeepRelation keeps relation between adjacent snapshots.
+func (b *Base[N]) KeepRelation(snapshotName string) error {
+	child, err := b.getProperty(childProp, snapshotName)
+	if err != nil {
+		return fmt.Errorf("failed to check snapshot child property: %w", err)
+	}
+
+	parent, err := b.getProperty(parentProp, snapshotName)
+	if err != nil {
+		return fmt.Errorf("failed to check snapshot parent property: %w", err)
+	}
+
+	if parent != "" {
+		if err := b.DeleteChildProp(snapshotName, parent); err != nil {
+			return fmt.Errorf("failed to delete child: %w", err)
+		}
+
+		if child != "" {
+			if err := b.addChild(parent, child); err != nil {
+				return fmt.Errorf("failed to add child: %w", err)
+			}
+		}
+	}
+
+	if child != "" {
+		if err := b.setParent(parent, child); err != nil {
+			return fmt.Errorf("failed to set parent: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetDatasetOrigins returns origins of the dataset and all nested datasets, "-" for datasets without an origin.
+func (b *Base[N]) GetDatasetOrigins(cloneDataset string) []string {
+	origins := []string{}
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		for _, name := range c.Subtree(cloneDataset) {
+			origin := c.Datasets[name].entry().Origin
+			if origin == "" {
+				origin = empty
+			}
+
+			origins = append(origins, origin)
+		}
+
+		return nil
+	}); err != nil {
+		log.Warn(fmt.Sprintf("failed to check clone dataset %s: %v", cloneDataset, err))
+		return nil
+	}
+
+	return origins
+}
+
+// GetActiveDatasets returns snapshots of the pool whose names contain the dataset.
+func (b *Base[N]) GetActiveDatasets(cloneDataset string) ([]string, error) {
+	datasetRegistry := []string{}
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		for name := range c.Snapshots {
+			if strings.Contains(name, cloneDataset) {
+				datasetRegistry = append(datasetRegistry, name)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(datasetRegistry)
+
+	return datasetRegistry, nil
+}
+
+func (b *Base[N]) addToSet(property, snapshot, value string) error {
+	return b.store.Update(func(c *Catalog[N]) error {
+		original, err := c.Property(snapshot, property)
+		if err != nil {
+			return err
+		}
+
+		return c.SetProperty(snapshot, property, strings.Join(appendUnique(unwindField(original), value), branchSep))
+	})
+}
+
+// deleteFromSet deletes specific value from snapshot property.
+func (b *Base[N]) deleteFromSet(prop, value, snapshotName string) error {
+	return b.store.Update(func(c *Catalog[N]) error {
+		propertyValue, err := c.Property(snapshotName, prop)
+		if err != nil {
+			return err
+		}
+
+		resultList := make([]string, 0)
+
+		for _, item := range unwindField(propertyValue) {
+			if item != value {
+				resultList = append(resultList, item)
+			}
+		}
+
+		return c.SetProperty(snapshotName, prop, strings.Join(resultList, branchSep))
+	})
+}
+
+func (b *Base[N]) getProperty(property, target string) (string, error) {
+	var value string
+
+	err := b.store.View(func(c *Catalog[N]) error {
+		var err error
+
+		value, err = c.Property(target, property)
+
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("error when trying to get property: %w", err)
+	}
+
+	return value, nil
+}
+
+func (b *Base[N]) setProperty(property, value, target string) error {
+	if err := b.store.Update(func(c *Catalog[N]) error {
+		return c.SetProperty(target, property, value)
+	}); err != nil {
+		return fmt.Errorf("error when trying to set property: %w", err)
+	}
+
+	return nil
+}
+
+// SetProtectedTill sets the protection-expiry timestamp on a snapshot or branch dataset.
+// An empty value clears the property.
+func (b *Base[N]) SetProtectedTill(value, target string) error {
+	return b.setProperty(protectedTillProp, value, target)
+}
+
+// SetDeleteAt sets the scheduled-deletion timestamp on a snapshot or branch dataset.
+// An empty value clears the property.
+func (b *Base[N]) SetDeleteAt(value, target string) error {
+	return b.setProperty(deleteAtProp, value, target)
+}
+
+// GetProtection returns the protection properties of a snapshot or branch dataset.
+// Properties are never inherited in a catalog, so only values set on the target itself are reported.
+func (b *Base[N]) GetProtection(target string) (thinclones.ProtectionProperties, error) {
+	props := thinclones.ProtectionProperties{}
+
+	err := b.store.View(func(c *Catalog[N]) error {
+		values, err := c.Properties(target)
+		if err != nil {
+			return err
+		}
+
+		props.ProtectedTill = values[protectedTillProp]
+		props.DeleteAt = values[deleteAtProp]
+
+		return nil
+	})
+	if err != nil {
+		return thinclones.ProtectionProperties{}, fmt.Errorf("failed to get protection properties: %w", err)
+	}
+
+	return props, nil
+}
+
+// ListProtection returns the protection properties of every snapshot in the pool that has any set.
+func (b *Base[N]) ListProtection() (map[string]thinclones.ProtectionProperties, error) {
+	result := make(map[string]thinclones.ProtectionProperties)
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		for name, entry := range c.Snapshots {
+			props := thinclones.ProtectionProperties{
+				ProtectedTill: entry.entry().Properties[protectedTillProp],
+				DeleteAt:      entry.entry().Properties[deleteAtProp],
+			}
+
+			if props.ProtectedTill != "" || props.DeleteAt != "" {
+				result[name] = props
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list protection properties: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,300 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package catalog provides the filesystem-independent part of thin-clone managers for filesystems without ZFS user properties.
+// Such managers keep the ZFS-like dataset and snapshot hierarchy along with user properties in a catalog
+// and run only the filesystem-specific commands themselves.
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util/branching"
+)
+
+// ErrEntryNotFound means that the requested dataset or snapshot is not registered in the catalog.
+var ErrEntryNotFound = errors.New("entry not found")
+
+// Entry describes a dataset or a snapshot. Filesystem-specific entries embed it.
+type Entry struct {
+	// Origin is the name of the snapshot a dataset has been cloned from.
+	Origin     string            `json:"origin,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+func (e *Entry) entry() *Entry {
+	return e
+}
+
+// Node is a filesystem-specific entry that embeds Entry.
+type Node interface {
+	entry() *Entry
+}
+
+// Catalog reflects the ZFS dataset and snapshot hierarchy of a pool.
+type Catalog[N Node] struct {
+	Datasets  map[string]N `json:"datasets"`
+	Snapshots map[string]N `json:"snapshots"`
+}
+
+// New creates an empty catalog.
+func New[N Node]() *Catalog[N] {
+	return &Catalog[N]{
+		Datasets:  make(map[string]N),
+		Snapshots: make(map[string]N),
+	}
+}
+
+// Store provides serialized access to the catalog of a pool.
+type Store[N Node] interface {
+	// View provides read-only access to the current catalog.
+	View(fn func(c *Catalog[N]) error) error
+
+	// Update loads the catalog, applies changes and persists the result.
+	Update(fn func(c *Catalog[N]) error) error
+}
+
+// RegisterParents registers intermediate datasets that group other datasets, the same way "zfs create -p" does.
+func (c *Catalog[N]) RegisterParents(poolName, dataset string, newNode func() N) {
+	for parent := path.Dir(dataset); parent != poolName && parent != "." && parent != "/"; parent = path.Dir(parent) {
+		if _, ok := c.Datasets[parent]; ok {
+			continue
+		}
+
+		c.Datasets[parent] = newNode()
+	}
+}
+
+// Properties returns the property map of a snapshot or a dataset.
+func (c *Catalog[N]) Properties(target string) (map[string]string, error) {
+	node, ok := c.Snapshots[target]
+	if !ok {
+		node, ok = c.Datasets[target]
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, target)
+	}
+
+	e := node.entry()
+
+	if e.Properties == nil {
+		e.Properties = make(map[string]string)
+	}
+
+	return e.Properties, nil
+}
+
+// Property returns a property value or an empty string if it is not set.
+func (c *Catalog[N]) Property(target, property string) (string, error) {
+	props, err := c.Properties(target)
+	if err != nil {
+		return "", err
+	}
+
+	return props[property], nil
+}
+
+// SetProperty sets a property value. An empty value removes the property.
+func (c *Catalog[N]) SetProperty(target, property, value string) error {
+	props, err := c.Properties(target)
+	if err != nil {
+		return err
+	}
+
+	if value == "" || value == empty {
+		delete(props, property)
+		return nil
+	}
+
+	props[property] = value
+
+	return nil
+}
+
+// Subtree returns the dataset itself and all datasets nested under it.
+func (c *Catalog[N]) Subtree(dataset string) []string {
+	prefix := dataset + "/"
+	names := []string{}
+
+	for name := range c.Datasets {
+		if name == dataset || strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// DatasetSnapshots returns snapshots taken from the dataset.
+func (c *Catalog[N]) DatasetSnapshots(dataset string) []string {
+	names := []string{}
+
+	for name := range c.Snapshots {
+		if SnapshotDataset(name) == dataset {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// origin returns the snapshot the dataset has been cloned from, or an empty string.
+func (c *Catalog[N]) origin(dataset string) string {
+	if ds, ok := c.Datasets[dataset]; ok {
+		return ds.entry().Origin
+	}
+
+	return ""
+}
+
+// Clones returns datasets created from the snapshot.
+func (c *Catalog[N]) Clones(snapshotID string) []string {
+	names := []string{}
+
+	for name, ds := range c.Datasets {
+		if ds.entry().Origin == snapshotID {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Dependents collects everything "zfs destroy -R" would remove together with the given datasets and snapshots:
+// nested datasets, their snapshots, and datasets cloned from any of those snapshots.
+func (c *Catalog[N]) Dependents(datasets, snapshots []string) (map[string]struct{}, map[string]struct{}) {
+	dsSet := make(map[string]struct{})
+	snSet := make(map[string]struct{})
+
+	queueDS := append([]string{}, datasets...)
+	queueSN := append([]string{}, snapshots...)
+
+	for len(queueDS) > 0 || len(queueSN) > 0 {
+		if len(queueDS) > 0 {
+			ds := queueDS[0]
+			queueDS = queueDS[1:]
+
+			for _, name := range c.Subtree(ds) {
+				if _, ok := dsSet[name]; ok {
+					continue
+				}
+
+				dsSet[name] = struct{}{}
+				queueSN = append(queueSN, c.DatasetSnapshots(name)...)
+			}
+
+			continue
+		}
+
+		sn := queueSN[0]
+		queueSN = queueSN[1:]
+
+		if _, ok := snSet[sn]; ok {
+			continue
+		}
+
+		snSet[sn] = struct{}{}
+		queueDS = append(queueDS, c.Clones(sn)...)
+	}
+
+	return dsSet, snSet
+}
+
+// ReplaceReferences updates origins and relation properties that point to renamed snapshots.
+func (c *Catalog[N]) ReplaceReferences(renames map[string]string) {
+	if len(renames) == 0 {
+		return
+	}
+
+	for _, ds := range c.Datasets {
+		if newName, ok := renames[ds.entry().Origin]; ok {
+			ds.entry().Origin = newName
+		}
+	}
+
+	for _, sn := range c.Snapshots {
+		props := sn.entry().Properties
+
+		for _, property := range []string{parentProp, childProp} {
+			items := unwindField(props[property])
+
+			for i, item := range items {
+				if newName, ok := renames[item]; ok {
+					items[i] = newName
+				}
+			}
+
+			if len(items) > 0 {
+				props[property] = strings.Join(items, branchSep)
+			}
+		}
+	}
+}
+
+// SortedSnapshots returns snapshots of the pool ordered by dataStateAt and creation time, the newest first.
+func (c *Catalog[N]) SortedSnapshots(poolName string) []resources.Snapshot {
+	snapshots := make([]resources.Snapshot, 0, len(c.Snapshots))
+
+	for name, node := range c.Snapshots {
+		e := node.entry()
+		branch := e.Properties[branchProp]
+
+		if branch == "" {
+			if parsedBranch := branching.ParseBranchNameFromSnapshot(name, poolName); parsedBranch != "" {
+				branch = parsedBranch
+			} else {
+				branch = branching.DefaultBranch
+			}
+		}
+
+		snapshot := resources.Snapshot{
+			ID:        name,
+			CreatedAt: e.CreatedAt,
+			Pool:      poolName,
+			Branch:    branch,
+			Message:   e.Properties[messageProp],
+		}
+
+		if dsa, err := util.ParseCustomTime(e.Properties[dataStateAtLabel]); err == nil {
+			snapshot.DataStateAt = dsa
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if !snapshots[i].DataStateAt.Equal(snapshots[j].DataStateAt) {
+			return snapshots[i].DataStateAt.After(snapshots[j].DataStateAt)
+		}
+
+		if !snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+		}
+
+		return snapshots[i].ID > snapshots[j].ID
+	})
+
+	return snapshots
+}
+
+// SnapshotDataset returns the dataset part of the snapshot ID.
+func SnapshotDataset(snapshotID string) string {
+	dataset, _, _ := strings.Cut(snapshotID, "@")
+	return dataset
+}
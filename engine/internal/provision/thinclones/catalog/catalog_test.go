@@ -0,0 +1,396 @@
+package catalog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+type testNode struct {
+	Entry
+}
+
+// memStore keeps the catalog in memory.
+type memStore struct {
+	mu      sync.Mutex
+	catalog *Catalog[*testNode]
+}
+
+func (s *memStore) View(fn func(c *Catalog[*testNode]) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fn(s.catalog)
+}
+
+func (s *memStore) Update(fn func(c *Catalog[*testNode]) error) error {
+	return s.View(fn)
+}
+
+// memFS emulates filesystem commands by registering entries in the catalog.
+type memFS struct {
+	base      *Base[*testNode]
+	store     *memStore
+	destroyed []string
+}
+
+func newNode(origin string) *testNode {
+	return &testNode{Entry: Entry{Origin: origin, CreatedAt: time.Now()}}
+}
+
+func (f *memFS) Snapshot(snapshotName string) error {
+	return f.store.Update(func(c *Catalog[*testNode]) error {
+		c.Snapshots[snapshotName] = newNode("")
+		return nil
+	})
+}
+
+func (f *memFS) CreateDataset(datasetName string) error {
+	return f.clone(datasetName, "")
+}
+
+func (f *memFS) clone(datasetName, origin string) error {
+	return f.store.Update(func(c *Catalog[*testNode]) error {
+		c.RegisterParents(f.base.Pool().Name, datasetName, func() *testNode { return newNode("") })
+		c.Datasets[datasetName] = newNode(origin)
+
+		return nil
+	})
+}
+
+func (f *memFS) Destroy(datasets, snapshots []string) error {
+	return f.store.Update(func(c *Catalog[*testNode]) error {
+		dsSet, snSet := c.Dependents(datasets, snapshots)
+
+		for name := range snSet {
+			delete(c.Snapshots, name)
+			f.destroyed = append(f.destroyed, name)
+		}
+
+		for name := range dsSet {
+			delete(c.Datasets, name)
+			f.destroyed = append(f.destroyed, name)
+		}
+
+		return nil
+	})
+}
+
+func (f *memFS) ListSnapshots() ([]resources.Snapshot, error) {
+	var snapshots []resources.Snapshot
+
+	err := f.store.View(func(c *Catalog[*testNode]) error {
+		snapshots = f.base.ClonableSnapshots(c)
+		return nil
+	})
+
+	return snapshots, err
+}
+
+func (f *memFS) GetBatchSessionState(requests []resources.SessionStateRequest) (map[string]resources.SessionState, error) {
+	states := make(map[string]resources.SessionState, len(requests))
+
+	for _, req := range requests {
+		states[req.CloneID] = resources.SessionState{}
+	}
+
+	return states, nil
+}
+
+type runnerMock struct{}
+
+func (runnerMock) Run(string, ...bool) (string, error) {
+	return "", nil
+}
+
+func newTestBase(t *testing.T) (*Base[*testNode], *memFS) {
+	t.Helper()
+
+	store := &memStore{catalog: New[*testNode]()}
+	fs := &memFS{store: store}
+	pool := &resources.Pool{Name: "dblab_pool", Mode: "test", PoolDirName: "dblab_pool", MountDir: t.TempDir(), DataSubDir: "data"}
+
+	fs.base = NewBase[*testNode](runnerMock{}, Config{Pool: pool, PreSnapshotSuffix: "_pre", OSUsername: "postgres"}, store, fs)
+
+	return fs.base, fs
+}
+
+func TestCatalogDependents(t *testing.T) {
+	c := New[*testNode]()
+	c.RegisterParents("dblab_pool", "dblab_pool/branch/dev/clone1/r0", func() *testNode { return newNode("") })
+	c.Datasets["dblab_pool/branch/dev"] = newNode("dblab_pool@snapshot_1")
+	c.Datasets["dblab_pool/branch/dev/clone1/r0"] = newNode("dblab_pool/branch/dev@2")
+	c.Snapshots["dblab_pool@snapshot_1"] = newNode("")
+	c.Snapshots["dblab_pool/branch/dev@2"] = newNode("")
+
+	assert.Equal(t, []string{"dblab_pool/branch", "dblab_pool/branch/dev", "dblab_pool/branch/dev/clone1", "dblab_pool/branch/dev/clone1/r0"},
+		c.Subtree("dblab_pool/branch"))
+
+	datasets, snapshots := c.Dependents(nil, []string{"dblab_pool@snapshot_1"})
+	assert.Len(t, datasets, 3)
+	assert.Contains(t, datasets, "dblab_pool/branch/dev/clone1/r0")
+	assert.Equal(t, map[string]struct{}{"dblab_pool@snapshot_1": {}, "dblab_pool/branch/dev@2": {}}, snapshots)
+
+	c.ReplaceReferences(map[string]string{"dblab_pool/branch/dev@2": "dblab_pool/branch/feature@2"})
+	assert.Equal(t, "dblab_pool/branch/feature@2", c.Datasets["dblab_pool/branch/dev/clone1/r0"].Origin)
+
+	require.NoError(t, c.SetProperty("dblab_pool@snapshot_1", branchProp, "main"))
+	require.NoError(t, c.SetProperty("dblab_pool@snapshot_1", branchProp, empty))
+	assert.Empty(t, c.Snapshots["dblab_pool@snapshot_1"].Properties)
+
+	_, err := c.Property("dblab_pool@missing", branchProp)
+	require.ErrorIs(t, err, ErrEntryNotFound)
+}
+
+func TestBranchMetadata(t *testing.T) {
+	m, fs := newTestBase(t)
+
+	mainSnapshot, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+	require.NoError(t, m.InitBranching())
+
+	branches, err := m.ListBranches()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"main": mainSnapshot}, branches)
+
+	const devSnapshot = "dblab_pool/branch/dev@20260102000000"
+
+	require.NoError(t, fs.clone("dblab_pool/branch/dev", mainSnapshot))
+	require.NoError(t, fs.Snapshot(devSnapshot))
+	require.NoError(t, m.AddBranchProp("dev", devSnapshot))
+	require.NoError(t, m.SetRoot("dev", mainSnapshot))
+	require.NoError(t, m.SetRelation(mainSnapshot, devSnapshot))
+	require.NoError(t, m.SetDSA("20260102000000", devSnapshot))
+	require.NoError(t, m.SetMessage("add users table", devSnapshot))
+
+	props, err := m.GetSnapshotProperties(devSnapshot)
+	require.NoError(t, err)
+	assert.Equal(t, thinclones.SnapshotProperties{
+		Name:        devSnapshot,
+		Parent:      mainSnapshot,
+		Branch:      "dev",
+		DataStateAt: "20260102000000",
+		Message:     "add users table",
+	}, props)
+
+	repo, err := m.GetRepo()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"main": mainSnapshot, "dev": devSnapshot}, repo.Branches)
+	assert.Equal(t, []string{devSnapshot}, repo.Snapshots[mainSnapshot].Child)
+	assert.Equal(t, []string{"dev"}, repo.Snapshots[mainSnapshot].Root)
+	assert.Equal(t, []string{"dblab_pool/branch/dev"}, repo.Snapshots[mainSnapshot].Clones)
+	assert.Equal(t, "dblab_pool/branch/dev", repo.Snapshots[devSnapshot].Dataset)
+
+	all, err := m.ListAllBranches([]string{"another_pool"})
+	require.NoError(t, err)
+	assert.Empty(t, all)
+
+	all, err = m.ListAllBranches(nil)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "dev", all[0].Name)
+	assert.Equal(t, "dblab_pool", all[0].Dataset)
+
+	require.NoError(t, m.DeleteBranchProp("dev", devSnapshot))
+	require.NoError(t, m.DeleteRootProp("dev", mainSnapshot))
+
+	branches, err = m.ListBranches()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"main": mainSnapshot}, branches)
+}
+
+func TestVerifyBranchMetadata(t *testing.T) {
+	m, _ := newTestBase(t)
+
+	for _, dsa := range []string{"20260101000000", "20260102000000", "20260103000000"} {
+		_, err := m.CreateSnapshot("", dsa)
+		require.NoError(t, err)
+	}
+
+	// A stale tag on an older snapshot must move to the branch head.
+	require.NoError(t, m.AddBranchProp("main", "dblab_pool@snapshot_20260101000000"))
+	require.NoError(t, m.AddBranchProp("main", "dblab_pool@snapshot_20260103000000"))
+	m.RefreshSnapshotList()
+
+	require.NoError(t, m.VerifyBranchMetadata())
+
+	repo, err := m.GetRepo()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"main": "dblab_pool@snapshot_20260103000000"}, repo.Branches)
+	assert.Equal(t, "dblab_pool@snapshot_20260102000000", repo.Snapshots["dblab_pool@snapshot_20260103000000"].Parent)
+	assert.Equal(t, []string{"dblab_pool@snapshot_20260102000000"}, repo.Snapshots["dblab_pool@snapshot_20260101000000"].Child)
+}
+
+func TestProtection(t *testing.T) {
+	m, fs := newTestBase(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+	require.NoError(t, fs.CreateDataset("dblab_pool/branch/dev"))
+
+	require.NoError(t, m.SetProtectedTill("2026-02-01T00:00:00Z", snapshotID))
+	require.NoError(t, m.SetDeleteAt("2026-03-01T00:00:00Z", "dblab_pool/branch/dev"))
+
+	props, err := m.GetProtection(snapshotID)
+	require.NoError(t, err)
+	assert.Equal(t, thinclones.ProtectionProperties{ProtectedTill: "2026-02-01T00:00:00Z"}, props)
+
+	props, err = m.GetProtection("dblab_pool/branch/dev")
+	require.NoError(t, err)
+	assert.Equal(t, thinclones.ProtectionProperties{DeleteAt: "2026-03-01T00:00:00Z"}, props)
+
+	list, err := m.ListProtection()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]thinclones.ProtectionProperties{
+		snapshotID: {ProtectedTill: "2026-02-01T00:00:00Z"},
+	}, list)
+
+	require.NoError(t, m.SetProtectedTill("", snapshotID))
+
+	list, err = m.ListProtection()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	_, err = m.GetProtection("dblab_pool/branch/missing")
+	require.Error(t, err)
+}
+
+func TestKeepRelation(t *testing.T) {
+	m, _ := newTestBase(t)
+
+	for _, dsa := range []string{"20260101000000", "20260102000000", "20260103000000"} {
+		_, err := m.CreateSnapshot("", dsa)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, m.InitBranching())
+	require.NoError(t, m.KeepRelation("dblab_pool@snapshot_20260102000000"))
+
+	props, err := m.GetSnapshotProperties("dblab_pool@snapshot_20260103000000")
+	require.NoError(t, err)
+	assert.Equal(t, "dblab_pool@snapshot_20260101000000", props.Parent)
+
+	props, err = m.GetSnapshotProperties("dblab_pool@snapshot_20260101000000")
+	require.NoError(t, err)
+	assert.Equal(t, "dblab_pool@snapshot_20260103000000", props.Child)
+}
+
+func TestCloneBookkeeping(t *testing.T) {
+	m, fs := newTestBase(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+
+	_, err = m.CreateSnapshot("", "20260101000000")
+	var existsErr *thinclones.SnapshotExistsError
+	require.ErrorAs(t, err, &existsErr)
+
+	snapshots := m.SnapshotList()
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "main", snapshots[0].Branch)
+
+	require.NoError(t, fs.clone("dblab_pool/branch/main/clone1/r0", snapshotID))
+
+	clones, err := m.ListClonesNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"clone1"}, clones)
+
+	assert.Equal(t, []string{"-", snapshotID}, m.GetDatasetOrigins("dblab_pool/branch/main/clone1"))
+
+	dependent, err := m.HasDependentEntity(snapshotID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dblab_pool/branch/main/clone1/r0"}, dependent)
+
+	_, err = m.GetSessionState("main", "clone1")
+	require.NoError(t, err)
+
+	require.Error(t, m.DestroySnapshot(snapshotID, thinclones.DestroyOptions{}))
+	require.NoError(t, m.DestroyClone("main", "clone1", 0))
+
+	clones, err = m.ListClonesNames()
+	require.NoError(t, err)
+	assert.Empty(t, clones)
+
+	require.NoError(t, m.DestroySnapshot(snapshotID, thinclones.DestroyOptions{}))
+	assert.Empty(t, m.SnapshotList())
+}
+
+func TestCleanupSnapshots(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	m, _ := newTestBase(t)
+
+	metadata, err := thinclones.DefaultMetadataStore()
+	require.NoError(t, err)
+
+	for _, dsa := range []string{"20260101000000", "20260102000000", "20260103000000", "20260104000000"} {
+		_, err := m.CreateSnapshot("", dsa)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, m.InitBranching())
+	require.NoError(t, m.SetProtectedTill("2999-01-01T00:00:00Z", "dblab_pool@snapshot_20260101000000"))
+
+	for _, snapshotID := range []string{"dblab_pool@snapshot_20260102000000", "dblab_pool@snapshot_20260104000000"} {
+		require.NoError(t, metadata.Save(thinclones.MigrationMetadata, snapshotID, []byte("select 1")))
+	}
+
+	destroyed, err := m.CleanupSnapshots(1, models.Logical)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dblab_pool@snapshot_20260102000000", "dblab_pool@snapshot_20260103000000"}, destroyed)
+
+	migration, err := metadata.Load(thinclones.MigrationMetadata, "dblab_pool@snapshot_20260102000000")
+	require.NoError(t, err)
+	assert.Nil(t, migration)
+
+	migration, err = metadata.Load(thinclones.MigrationMetadata, "dblab_pool@snapshot_20260104000000")
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", string(migration))
+
+	snapshots := m.SnapshotList()
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "dblab_pool@snapshot_20260104000000", snapshots[0].ID)
+	assert.Equal(t, "dblab_pool@snapshot_20260101000000", snapshots[1].ID)
+}
+
+func TestApplyRetentionPolicy(t *testing.T) {
+	m, fs := newTestBase(t)
+
+	for _, dsa := range []string{"20260101000000", "20260102000000", "20260103000000", "20260103120000"} {
+		_, err := m.CreateSnapshot("", dsa)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, m.InitBranching())
+	require.NoError(t, m.SetProtectedTill("2999-01-01T00:00:00Z", "dblab_pool@snapshot_20260101000000"))
+
+	policy := thinclones.RetentionPolicy{Daily: 2}
+
+	candidates, err := m.ApplyRetentionPolicy(policy, models.Logical, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dblab_pool@snapshot_20260103000000"}, candidates)
+	assert.Empty(t, fs.destroyed, "dry run must not destroy snapshots")
+
+	destroyed, err := m.ApplyRetentionPolicy(policy, models.Logical, false)
+	require.NoError(t, err)
+	assert.Equal(t, candidates, destroyed)
+
+	snapshots := m.SnapshotList()
+	require.Len(t, snapshots, 3)
+	assert.Equal(t, "dblab_pool@snapshot_20260103120000", snapshots[0].ID)
+}
+
+func TestChownCloneCommand(t *testing.T) {
+	cmd := ChownCloneCommand("/clone/data", "/clone", "postgres")
+
+	assert.True(t, strings.HasPrefix(cmd, "if [ \"$(stat -c '%u' '/clone/data'"))
+	assert.Contains(t, cmd, "then chown 'postgres' '/clone'; else chown -R 'postgres' '/clone'; fi")
+}
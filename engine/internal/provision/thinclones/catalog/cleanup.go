@@ -0,0 +1,268 @@
+/*
+2026 © Postgres.ai
+*/
+
+package catalog
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util/branching"
+)
+
+// CleanupSnapshots destroys old snapshots considering retention limit and related clones.
+func (b *Base[N]) CleanupSnapshots(retentionLimit int, mode models.RetrievalMode) ([]string, error) {
+	return b.cleanupSnapshots(false, func(c *Catalog[N], busy []string) []string {
+		return b.cleanupCandidates(c, retentionLimit, mode, busy)
+	})
+}
+
+// ApplyRetentionPolicy destroys snapshots the retention policy does not keep, considering related clones.
+// In dry-run mode, it only returns the snapshots that would be destroyed.
+func (b *Base[N]) ApplyRetentionPolicy(policy thinclones.RetentionPolicy, mode models.RetrievalMode, dryRun bool) ([]string, error) {
+	return b.cleanupSnapshots(dryRun, func(c *Catalog[N], busy []string) []string {
+		return policy.Select(b.oldestSnapshots(c), mode, busy)
+	})
+}
+
+func (b *Base[N]) cleanupSnapshots(dryRun bool, selectCandidates func(c *Catalog[N], busy []string) []string) ([]string, error) {
+	branchHeads, err := b.getBranchHeadSnapshots()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine protected snapshots: %w", err)
+	}
+
+	protectedSnapshots, err := b.getProtectedSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		busy := b.getBusySnapshotList(c)
+		busy = append(busy, branchHeads...)
+		busy = append(busy, withOrigins(c, protectedSnapshots)...)
+
+		candidates = selectCandidates(c, busy)
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	if dryRun {
+		return candidates, nil
+	}
+
+	destroyed := make([]string, 0, len(candidates))
+
+	defer func() {
+		thinclones.DeleteSnapshotMetadata(destroyed...)
+	}()
+
+	for _, snapshotID := range candidates {
+		if err := b.fs.Destroy(nil, []string{snapshotID}); err != nil {
+			return nil, fmt.Errorf("failed to clean up snapshots: %w", err)
+		}
+
+		destroyed = append(destroyed, snapshotID)
+	}
+
+	if err := b.cleanupEmptyDatasets(); err != nil {
+		return nil, fmt.Errorf("failed to clean up empty datasets: %w", err)
+	}
+
+	b.RefreshSnapshotList()
+
+	firstSnapshotID := ""
+
+	b.mu.Lock()
+	if l := len(b.snapshots); l > 0 {
+		firstSnapshotID = b.snapshots[l-1].ID
+	}
+	b.mu.Unlock()
+
+	b.reviewParentProperty(firstSnapshotID)
+
+	return destroyed, nil
+}
+
+// cleanupCandidates selects snapshots to destroy: all but the newest retentionLimit ones, excluding busy snapshots.
+func (b *Base[N]) cleanupCandidates(c *Catalog[N], retentionLimit int, mode models.RetrievalMode, busy []string) []string {
+	entries := thinclones.RetentionEntries(b.oldestSnapshots(c), mode)
+
+	if retentionLimit >= len(entries) {
+		return nil
+	}
+
+	expired := make([]string, 0, len(entries)-retentionLimit)
+
+	for _, entry := range entries[:len(entries)-retentionLimit] {
+		expired = append(expired, entry.ID)
+	}
+
+	return thinclones.ExcludeSnapshots(expired, busy)
+}
+
+// oldestSnapshots returns snapshots of the pool, oldest first, matching "zfs list -s dblab:datastateat -s creation".
+func (b *Base[N]) oldestSnapshots(c *Catalog[N]) []resources.Snapshot {
+	snapshots := c.SortedSnapshots(b.config.Pool.Name)
+	slices.Reverse(snapshots)
+
+	return snapshots
+}
+
+// withOrigins returns the snapshots along with the origins of their datasets,
+// so that protecting a promoted snapshot also keeps the snapshot it was cloned from.
+func withOrigins[N Node](c *Catalog[N], snapshots []string) []string {
+	result := make([]string, 0, 2*len(snapshots))
+
+	for _, snapshotID := range snapshots {
+		result = append(result, snapshotID)
+
+		if origin := c.origin(SnapshotDataset(snapshotID)); origin != "" {
+			result = append(result, origin)
+		}
+	}
+
+	return result
+}
+
+func (b *Base[N]) reviewParentProperty(snapshotID string) {
+	if snapshotID == "" {
+		return
+	}
+
+	if err := b.store.Update(func(c *Catalog[N]) error {
+		parent, err := c.Property(snapshotID, parentProp)
+		if err != nil || parent == "" {
+			return err
+		}
+
+		if _, ok := c.Snapshots[parent]; !ok {
+			// Parent snapshot not found, clean up the property.
+			return c.SetProperty(snapshotID, parentProp, "")
+		}
+
+		return nil
+	}); err != nil {
+		log.Err("failed to review parent property:", err)
+	}
+}
+
+// cleanupEmptyDatasets removes clone datasets that have neither an origin nor nested datasets.
+func (b *Base[N]) cleanupEmptyDatasets() error {
+	var datasetsToRemove []string
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		datasetsToRemove = b.getEmptyDatasets(c)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, dataset := range datasetsToRemove {
+		log.Dbg("Remove empty dataset: ", dataset)
+
+		if err := b.DestroyDataset(dataset); err != nil {
+			return fmt.Errorf("failed to destroy dataset %s: %w", dataset, err)
+		}
+	}
+
+	return nil
+}
+
+func (b *Base[N]) getEmptyDatasets(c *Catalog[N]) []string {
+	datasetsToRemove := []string{}
+	branchPrefix := b.config.Pool.Name + "/" + branching.BranchDir + "/"
+
+	for name, ds := range c.Datasets {
+		// Only process clones: <pool_name>/branch/<branch_name>/<clone_name>[/r<number>].
+		bc, found := strings.CutPrefix(name, branchPrefix)
+		if !found || !strings.Contains(bc, "/") || ds.entry().Origin != "" {
+			continue
+		}
+
+		if len(c.Subtree(name)) == 1 {
+			datasetsToRemove = append(datasetsToRemove, name)
+		}
+	}
+
+	// Sort by depth (the deepest first) to avoid conflicts.
+	sort.Slice(datasetsToRemove, func(i, j int) bool {
+		return strings.Count(datasetsToRemove[i], "/") > strings.Count(datasetsToRemove[j], "/")
+	})
+
+	return datasetsToRemove
+}
+
+// getBusySnapshotList returns snapshots used by system "clone_pre" datasets that user datasets depend on.
+func (b *Base[N]) getBusySnapshotList(c *Catalog[N]) []string {
+	systemDatasetPrefix := fmt.Sprintf("%s/%s/%s/clone_pre_", b.config.Pool.Name, branching.BranchDir, branching.DefaultBranch)
+	busySnapshots := []string{}
+
+	for name, ds := range c.Datasets {
+		origin := ds.entry().Origin
+
+		if origin == "" || strings.HasPrefix(name, systemDatasetPrefix) || !strings.HasPrefix(origin, systemDatasetPrefix) {
+			continue
+		}
+
+		if systemOrigin := c.origin(SnapshotDataset(origin)); systemOrigin != "" {
+			busySnapshots = append(busySnapshots, systemOrigin)
+		}
+	}
+
+	return busySnapshots
+}
+
+// getBranchHeadSnapshots returns branch head snapshots and the origins of their datasets.
+func (b *Base[N]) getBranchHeadSnapshots() ([]string, error) {
+	branches, err := b.listBranches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches for cleanup protection: %w", err)
+	}
+
+	protected := make([]string, 0, len(branches))
+
+	if err := b.store.View(func(c *Catalog[N]) error {
+		for _, snapshotID := range branches {
+			protected = append(protected, snapshotID)
+
+			if origin := c.origin(SnapshotDataset(snapshotID)); origin != "" {
+				protected = append(protected, origin)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return protected, nil
+}
+
+// getProtectedSnapshots returns names of snapshots with a currently-active dle:protected_till.
+func (b *Base[N]) getProtectedSnapshots() ([]string, error) {
+	protection, err := b.ListProtection()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protected snapshots: %w", err)
+	}
+
+	protected := make([]string, 0, len(protection))
+
+	for name, props := range protection {
+		if models.ProtectedTillActive(props.ProtectedTill) {
+			protected = append(protected, name)
+		}
+	}
+
+	return protected, nil
+}
@@ -0,0 +1,227 @@
+/*
+2026 © Postgres.ai
+*/
+
+package lvm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/catalog"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+// CreateBranch clones data as a new branch.
+func (m *LVManager) CreateBranch(branchName, snapshotID string) error {
+	branchPath, err := m.datasetPath(branchName)
+	if err != nil {
+		return err
+	}
+
+	return m.store.update(func(md *metadata) error {
+		snapshot, ok := md.Snapshots[snapshotID]
+		if !ok {
+			return fmt.Errorf("%w: snapshot %s", catalog.ErrEntryNotFound, snapshotID)
+		}
+
+		branch, err := m.createVolume(md, branchName, snapshot.LVName, snapshotID, nil)
+		if err != nil {
+			return err
+		}
+
+		if out, err := m.runner.Run(mountCommand(m.volumeGroup, branch.LVName, branchPath), true); err != nil {
+			return fmt.Errorf("failed to mount branch volume: %w. Out: %v", err, out)
+		}
+
+		return nil
+	})
+}
+
+// Snapshot takes a read-only thin snapshot of the current data state.
+func (m *LVManager) Snapshot(snapshotName string) error {
+	return m.store.update(func(md *metadata) error {
+		source, err := m.datasetVolume(md, catalog.SnapshotDataset(snapshotName))
+		if err != nil {
+			return err
+		}
+
+		_, err = m.createVolume(md, snapshotName, source, "", nil)
+
+		return err
+	})
+}
+
+// Move registers the current snapshot in the target dataset.
+// Thin snapshots share data blocks, so a new snapshot of the current one replaces sending the incremental diff.
+func (m *LVManager) Move(_, currentSnap, target string) error {
+	_, name, _ := strings.Cut(currentSnap, "@")
+	targetSnapshot := target + "@" + name
+
+	return m.store.update(func(md *metadata) error {
+		current, ok := md.Snapshots[currentSnap]
+		if !ok {
+			return fmt.Errorf("%w: snapshot %s", catalog.ErrEntryNotFound, currentSnap)
+		}
+
+		props := make(map[string]string, len(current.Properties))
+
+		for key, value := range current.Properties {
+			props[key] = value
+		}
+
+		if _, err := m.createVolume(md, targetSnapshot, current.LVName, "", props); err != nil {
+			return fmt.Errorf("lvm moving snapshot error: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Rename renames a dataset together with its nested datasets and snapshots, remounting volumes at the new location.
+func (m *LVManager) Rename(oldName, newName string) error {
+	oldPath, err := m.datasetPath(oldName)
+	if err != nil {
+		return err
+	}
+
+	newPath, err := m.datasetPath(newName)
+	if err != nil {
+		return err
+	}
+
+	return m.store.update(func(md *metadata) error {
+		datasets := md.Subtree(oldName)
+
+		// Nested mounts must be unmounted before their parents.
+		for i := len(datasets) - 1; i >= 0; i-- {
+			if md.Datasets[datasets[i]].LVName == "" {
+				continue
+			}
+
+			datasetPath, err := m.datasetPath(datasets[i])
+			if err != nil {
+				return err
+			}
+
+			if out, err := m.runner.Run(unmountCommand(datasetPath), true); err != nil {
+				return fmt.Errorf("lvm renaming error: %w. Out: %v", err, out)
+			}
+		}
+
+		if out, err := m.runner.Run(moveDirCommand(oldPath, newPath), true); err != nil {
+			return fmt.Errorf("lvm renaming error: %w. Out: %v", err, out)
+		}
+
+		renames := make(map[string]string)
+
+		for _, name := range datasets {
+			newDataset := newName + strings.TrimPrefix(name, oldName)
+
+			if err := m.renameVolume(md.Datasets[name], newDataset); err != nil {
+				return err
+			}
+
+			md.Datasets[newDataset] = md.Datasets[name]
+			delete(md.Datasets, name)
+
+			for _, snapshotID := range md.DatasetSnapshots(name) {
+				newSnapshotID := newDataset + strings.TrimPrefix(snapshotID, name)
+
+				if err := m.renameVolume(md.Snapshots[snapshotID], newSnapshotID); err != nil {
+					return err
+				}
+
+				md.Snapshots[newSnapshotID] = md.Snapshots[snapshotID]
+				delete(md.Snapshots, snapshotID)
+				renames[snapshotID] = newSnapshotID
+			}
+		}
+
+		md.RegisterParents(m.Pool().Name, newName, newGroupVolume)
+		md.ReplaceReferences(renames)
+
+		for _, name := range md.Subtree(newName) {
+			ds := md.Datasets[name]
+			if ds.LVName == "" {
+				continue
+			}
+
+			datasetPath, err := m.datasetPath(name)
+			if err != nil {
+				return err
+			}
+
+			if out, err := m.runner.Run(mountCommand(m.volumeGroup, ds.LVName, datasetPath), true); err != nil {
+				return fmt.Errorf("lvm renaming error: %w. Out: %v", err, out)
+			}
+		}
+
+		return nil
+	})
+}
+
+// renameVolume renames the logical volume after the new dataset or snapshot name.
+// The name tag is updated when the metadata is saved.
+func (m *LVManager) renameVolume(v *volume, newName string) error {
+	if v.LVName == "" {
+		return nil
+	}
+
+	newVolume := volumeName(m.logicalVolume, m.Config().Pool.Name, newName)
+
+	if out, err := m.runner.Run(renameVolumeCommand(m.volumeGroup, v.LVName, newVolume), true); err != nil {
+		return fmt.Errorf("lvm renaming error: %w. Out: %v", err, out)
+	}
+
+	v.LVName = newVolume
+
+	return nil
+}
+
+// SetMountpoint is a no-op in LVM mode: the mount point of a volume is derived from its dataset name.
+func (m *LVManager) SetMountpoint(_, _ string) error {
+	log.Msg("SetMountpoint is not supported for LVM. Skip the operation")
+
+	return nil
+}
+
+// Reset rollbacks the dataset to the snapshot by replacing its volume with a writable snapshot.
+func (m *LVManager) Reset(snapshotID string, _ thinclones.ResetOptions) error {
+	dataset := catalog.SnapshotDataset(snapshotID)
+
+	if dataset == m.Config().Pool.Name {
+		return errors.New("failed to rollback a snapshot: the pool volume cannot be replaced")
+	}
+
+	datasetPath, err := m.datasetPath(dataset)
+	if err != nil {
+		return err
+	}
+
+	return m.store.update(func(md *metadata) error {
+		snapshot, ok := md.Snapshots[snapshotID]
+		if !ok {
+			return errors.New("failed to rollback a snapshot: snapshot not found")
+		}
+
+		ds, ok := md.Datasets[dataset]
+		if !ok || ds.LVName == "" {
+			return errors.New("failed to rollback a snapshot: dataset volume not found")
+		}
+
+		cmd := unmountCommand(datasetPath) + " && " + removeVolumeCommand(m.volumeGroup, ds.LVName) + " && " +
+			thinSnapshotCommand(m.volumeGroup, snapshot.LVName, ds.LVName, false, encodeTags(ds.persisted)) + " && " +
+			mountCommand(m.volumeGroup, ds.LVName, datasetPath)
+
+		if out, err := m.runner.Run(cmd, true); err != nil {
+			return fmt.Errorf("failed to rollback a snapshot: %w. Out: %v", err, out)
+		}
+
+		ds.LVOrigin = snapshot.LVName
+
+		return nil
+	})
+}
@@ -0,0 +1,90 @@
+/*
+2026 © Postgres.ai
+*/
+
+package lvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+)
+
+func TestProtectionTags(t *testing.T) {
+	m, runner := newTestManager(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+	require.NoError(t, m.CreateBranch("dblab_vg-pg_lv/branch/dev", snapshotID))
+
+	require.NoError(t, m.SetDeleteAt("2026-03-01T00:00:00Z", "dblab_vg-pg_lv/branch/dev"))
+
+	changeCmds := runner.commands("lvchange")
+	require.NotEmpty(t, changeCmds)
+	assert.Contains(t, changeCmds[len(changeCmds)-1], "--addtag "+encodeTag("dle:delete_at", "2026-03-01T00:00:00Z"))
+
+	props, err := m.GetProtection("dblab_vg-pg_lv/branch/dev")
+	require.NoError(t, err)
+	assert.Equal(t, thinclones.ProtectionProperties{DeleteAt: "2026-03-01T00:00:00Z"}, props)
+
+	// Datasets that only group other datasets have no volume to keep tags.
+	require.Error(t, m.SetDeleteAt("2026-03-01T00:00:00Z", "dblab_vg-pg_lv/branch"))
+}
+
+func TestRename(t *testing.T) {
+	m, runner := newTestManager(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+	require.NoError(t, m.CreateBranch("dblab_vg-pg_lv/branch/dev", snapshotID))
+	require.NoError(t, m.Snapshot("dblab_vg-pg_lv/branch/dev@20260102000000"))
+	require.NoError(t, m.SetRelation(snapshotID, "dblab_vg-pg_lv/branch/dev@20260102000000"))
+	require.NoError(t, m.CreateClone("dev", "clone1", "dblab_vg-pg_lv/branch/dev@20260102000000", 0))
+
+	require.NoError(t, m.Rename("dblab_vg-pg_lv/branch/dev", "dblab_vg-pg_lv/branch/feature"))
+
+	require.NoError(t, m.store.view(func(md *metadata) error {
+		const renamedSnapshot = "dblab_vg-pg_lv/branch/feature@20260102000000"
+
+		assert.Contains(t, md.Snapshots, renamedSnapshot)
+		assert.NotContains(t, md.Snapshots, "dblab_vg-pg_lv/branch/dev@20260102000000")
+		assert.Equal(t, renamedSnapshot, md.Datasets["dblab_vg-pg_lv/branch/feature/clone1/r0"].Origin)
+		assert.Equal(t, renamedSnapshot, md.Snapshots[snapshotID].Properties["dle:child"])
+
+		for name, ds := range md.Datasets {
+			if ds.LVName != "" {
+				assert.Equal(t, volumeName(testPoolVolume, "dblab_vg-pg_lv", name), ds.LVName)
+			}
+		}
+
+		return nil
+	}))
+
+	assert.Len(t, runner.commands("lvrename"), 3)
+	assert.Len(t, runner.commands("mount /dev/"), 4)
+}
+
+func TestReset(t *testing.T) {
+	m, runner := newTestManager(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+	require.NoError(t, m.CreateBranch("dblab_vg-pg_lv/branch/dev", snapshotID))
+	require.NoError(t, m.SetDeleteAt("2026-03-01T00:00:00Z", "dblab_vg-pg_lv/branch/dev"))
+	require.NoError(t, m.Snapshot("dblab_vg-pg_lv/branch/dev@20260102000000"))
+
+	require.NoError(t, m.Reset("dblab_vg-pg_lv/branch/dev@20260102000000", thinclones.ResetOptions{}))
+
+	props, err := m.GetProtection("dblab_vg-pg_lv/branch/dev")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03-01T00:00:00Z", props.DeleteAt)
+
+	lvName := volumeName(testPoolVolume, "dblab_vg-pg_lv", "dblab_vg-pg_lv/branch/dev")
+	assert.Equal(t, volumeName(testPoolVolume, "dblab_vg-pg_lv", "dblab_vg-pg_lv/branch/dev@20260102000000"),
+		runner.volumes[lvName].Origin)
+
+	require.Error(t, m.Reset(snapshotID, thinclones.ResetOptions{}))
+}
@@ -8,19 +8,24 @@ package lvm
 import (
 	"encoding/json"
 	"fmt"
+	"path"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
-
-	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/runners"
-	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 )
 
 const (
-	sizePortion = 10
-
 	// PoolMode defines the lvm filesystem name.
 	PoolMode = "lvm"
+
+	// lvTimeLayout defines the layout of the "lv_time" field in "lvs" command response.
+	lvTimeLayout = "2006-01-02 15:04:05 -0700"
+
+	lvsFields = "lv_name,vg_name,lv_attr,lv_size,pool_lv,origin,data_percent,lv_tags,lv_time"
+
+	percent = 100
 )
 
 // LvsOutput defines "lvs" command response.
@@ -41,69 +46,121 @@ type ListEntry struct {
 	Size        string `json:"lv_size"`
 	Pool        string `json:"pool_lv"`
 	Origin      string `json:"origin"`
-	DataPercent string `json:"data_percent"` // TODO(anatoly): Float64.
+	DataPercent string `json:"data_percent"`
+	Tags        string `json:"lv_tags"`
+	Time        string `json:"lv_time"`
 }
 
-// CreateVolume creates LVM volume.
-func CreateVolume(r runners.Runner, vg, lv, name, mountDir string) error {
-	fullName := getFullName(vg, name)
+// SizeBytes returns the virtual size of the volume in bytes.
+func (e ListEntry) SizeBytes() uint64 {
+	size, err := strconv.ParseUint(strings.TrimSuffix(e.Size, "B"), 10, 64)
+	if err != nil {
+		return 0
+	}
 
-	volumeCreateCmd := "lvcreate --snapshot " +
-		"--extents " + strconv.Itoa(sizePortion) + "%FREE " +
-		"--name " + name + " " + getFullName(vg, lv)
+	return size
+}
 
-	_, err := r.Run(volumeCreateCmd, true)
+// MappedBytes returns the amount of data mapped by a thin volume or used in a thin pool.
+// The value is empty for inactive volumes, so zero is returned for them.
+func (e ListEntry) MappedBytes() uint64 {
+	dataPercent, err := strconv.ParseFloat(e.DataPercent, 64)
 	if err != nil {
-		return errors.Wrap(err, "failed to create a volume")
+		return 0
 	}
 
-	fullMountDir := getFullMountDir(mountDir, name)
-	mountCmd := "mkdir -p " + fullMountDir + " && " +
-		"mount /dev/" + fullName + " " + fullMountDir
+	return uint64(float64(e.SizeBytes()) * dataPercent / percent)
+}
 
-	_, err = r.Run(mountCmd, true)
+// CreatedAt returns the creation time of the volume.
+func (e ListEntry) CreatedAt() time.Time {
+	createdAt, err := time.Parse(lvTimeLayout, e.Time)
 	if err != nil {
-		return errors.Wrap(err, "failed to mount a volume")
+		return time.Time{}
 	}
 
-	return nil
+	return createdAt
 }
 
-// RemoveVolume removes LVM volume.
-func RemoveVolume(r runners.Runner, vg, _, name, mountDir string) error {
-	fullName := getFullName(vg, name)
+// TagList returns the list of volume tags.
+func (e ListEntry) TagList() []string {
+	if e.Tags == "" {
+		return nil
+	}
 
-	unmountCmd := "umount " + getFullMountDir(mountDir, name)
+	return strings.Split(e.Tags, ",")
+}
 
-	_, err := r.Run(unmountCmd, true)
-	if err != nil {
-		// Can be already unmounted.
-		log.Err(errors.Wrap(err, "failed to unmount volume"))
+func listVolumesCommand(vg string) string {
+	return fmt.Sprintf("lvs --reportformat json --units b --nosuffix --options %s %s", lvsFields, vg)
+}
+
+// thinSnapshotCommand builds a command creating a thin snapshot that is activated right away.
+func thinSnapshotCommand(vg, source, name string, readOnly bool, tags []string) string {
+	permission := "rw"
+
+	if readOnly {
+		permission = "r"
 	}
 
-	volumeRemoveCmd := fmt.Sprintf("lvremove --yes %s", fullName)
+	var sb strings.Builder
 
-	out, err := r.Run(volumeRemoveCmd, true)
-	if err != nil {
-		return errors.Wrap(err, "failed to remove volume")
+	fmt.Fprintf(&sb, "lvcreate --snapshot --setactivationskip n --permission %s --name %s", permission, name)
+
+	for _, tag := range tags {
+		sb.WriteString(" --addtag " + tag)
 	}
 
-	log.Dbg(out)
+	sb.WriteString(" " + getFullName(vg, source))
 
-	return nil
+	return sb.String()
 }
 
-// ListVolumes lists LVM volumes.
-func ListVolumes(r runners.Runner, lv string) ([]ListEntry, error) {
-	listVolumesCmd := `lvs --reportformat json --units b --yes ` +
-		`--select origin="` + lv + `"`
+func removeVolumeCommand(vg, name string) string {
+	return fmt.Sprintf("lvremove --yes %s", getFullName(vg, name))
+}
 
-	out, err := r.Run(listVolumesCmd, false)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to list volumes")
+func renameVolumeCommand(vg, oldName, newName string) string {
+	return fmt.Sprintf("lvrename %s %s %s", vg, oldName, newName)
+}
+
+// changeTagsCommand builds a command that removes and adds tags of a volume in one call.
+func changeTagsCommand(vg, name string, deleteTags, addTags []string) string {
+	var sb strings.Builder
+
+	sb.WriteString("lvchange")
+
+	for _, tag := range deleteTags {
+		sb.WriteString(" --deltag " + tag)
+	}
+
+	for _, tag := range addTags {
+		sb.WriteString(" --addtag " + tag)
 	}
 
-	return parseLVMOutput(out)
+	sb.WriteString(" " + getFullName(vg, name))
+
+	return sb.String()
+}
+
+func mountCommand(vg, name, mountDir string) string {
+	return fmt.Sprintf("mkdir -p '%s' && mount /dev/%s '%s'", mountDir, getFullName(vg, name), mountDir)
+}
+
+func unmountCommand(mountDir string) string {
+	return fmt.Sprintf("if mountpoint -q '%s'; then umount '%s'; fi", mountDir, mountDir)
+}
+
+func removeDirCommand(dirPath string) string {
+	return fmt.Sprintf("rm -rf '%s'", dirPath)
+}
+
+func createDirCommand(dirPath string) string {
+	return fmt.Sprintf("mkdir -p '%s'", dirPath)
+}
+
+func moveDirCommand(source, target string) string {
+	return fmt.Sprintf("mkdir -p '%s' && mv '%s' '%s'", path.Dir(target), source, target)
 }
 
 func parseLVMOutput(out string) ([]ListEntry, error) {
@@ -122,7 +179,3 @@ func parseLVMOutput(out string) ([]ListEntry, error) {
 func getFullName(vg, name string) string {
 	return fmt.Sprintf("%s/%s", vg, name)
 }
-
-func getFullMountDir(mountDir, name string) string {
-	return fmt.Sprintf("%s/%s", mountDir, name)
-}
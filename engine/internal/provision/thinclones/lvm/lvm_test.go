@@ -5,6 +5,7 @@
 package lvm
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -54,3 +55,48 @@ func TestParseLVMOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestListEntrySizes(t *testing.T) {
+	entry := ListEntry{Size: "1073741824", DataPercent: "12.50", Time: "2026-01-02 03:04:05 +0000"}
+
+	assert.Equal(t, uint64(1073741824), entry.SizeBytes())
+	assert.Equal(t, uint64(134217728), entry.MappedBytes())
+	assert.Equal(t, "2026-01-02 03:04:05 +0000 UTC", entry.CreatedAt().String())
+
+	inactive := ListEntry{Size: "1073741824"}
+	assert.Equal(t, uint64(0), inactive.MappedBytes())
+}
+
+func TestTags(t *testing.T) {
+	tag := encodeTag("dle:message", "add users table, 50% done")
+
+	key, value, ok := decodeTag(tag)
+	require.True(t, ok)
+	assert.Equal(t, "dle:message", key)
+	assert.Equal(t, "add users table, 50% done", value)
+	assert.NotContains(t, tag, ",")
+
+	_, _, ok = decodeTag("unrelated")
+	assert.False(t, ok)
+
+	deleteTags, addTags := tagsDiff(
+		map[string]string{"dle:branch": "main", "dle:child": "a"},
+		map[string]string{"dle:branch": "dev", "dle:child": "a", "dle:root": "main"},
+	)
+	assert.Equal(t, []string{encodeTag("dle:branch", "main")}, deleteTags)
+	assert.Equal(t, []string{encodeTag("dle:branch", "dev"), encodeTag("dle:root", "main")}, addTags)
+}
+
+func TestVolumeName(t *testing.T) {
+	name := volumeName("pg_lv", "vg-pg_lv", "vg-pg_lv/branch/main/clone1/r0")
+	assert.Regexp(t, `^pg_lv\.branch\.main\.clone1\.r0_[0-9a-f]{8}$`, name)
+
+	snapshotName := volumeName("pg_lv", "vg-pg_lv", "vg-pg_lv@snapshot_20260101000000")
+	assert.Regexp(t, `^pg_lv\._snapshot_20260101000000_[0-9a-f]{8}$`, snapshotName)
+
+	// Names that only differ in unsupported characters must not collide.
+	assert.NotEqual(t, volumeName("pg_lv", "vg-pg_lv", "vg-pg_lv/branch/a b"), volumeName("pg_lv", "vg-pg_lv", "vg-pg_lv/branch/a:b"))
+
+	long := volumeName("pg_lv", "vg-pg_lv", "vg-pg_lv/branch/"+strings.Repeat("x", 200))
+	assert.LessOrEqual(t, len(long), 127)
+}
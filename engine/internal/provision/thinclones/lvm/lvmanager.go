@@ -5,16 +5,19 @@
 package lvm
 
 import (
+	"fmt"
+	"path"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/pkg/errors"
 
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/runners"
-	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/catalog"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util/branching"
 )
 
 const (
@@ -22,67 +25,36 @@ const (
 )
 
 // LVManager describes an LVM2 filesystem manager.
+// Datasets and snapshots are thin volumes of the pool volume, their hierarchy and metadata are kept in LV tags.
 type LVManager struct {
+	*catalog.Base[*volume]
 	runner        runners.Runner
-	pool          *resources.Pool
 	volumeGroup   string
 	logicalVolume string
+	store         *tagStore
 }
 
+// Config defines configuration for LVM filesystem manager.
+type Config = catalog.Config
+
 // NewFSManager creates a new Manager instance for LVM.
-func NewFSManager(runner runners.Runner, pool *resources.Pool) (*LVManager, error) {
-	m := LVManager{
-		runner: runner,
-		pool:   pool,
-	}
+func NewFSManager(runner runners.Runner, config Config) (*LVManager, error) {
+	m := &LVManager{runner: runner}
 
-	if err := m.parsePool(); err != nil {
+	if err := m.parsePool(config.Pool.Name); err != nil {
 		return nil, err
 	}
 
-	return &m, nil
-}
-
-// Pool gets a storage pool.
-func (m *LVManager) Pool() *resources.Pool {
-	return m.pool
-}
-
-// UpdateConfig updates the manager's pool.
-func (m *LVManager) UpdateConfig(pool *resources.Pool) {
-	m.pool = pool
-}
-
-// CreateClone creates a new volume.
-func (m *LVManager) CreateClone(branch, name, _ string, _ int) error {
-	return CreateVolume(m.runner, m.volumeGroup, m.logicalVolume, name, m.pool.ClonesDir(branch))
-}
-
-// DestroyClone destroys volumes.
-func (m *LVManager) DestroyClone(branch, name string, _ int) error {
-	return RemoveVolume(m.runner, m.volumeGroup, m.logicalVolume, name, m.pool.ClonesDir(branch))
-}
-
-// ListClonesNames returns a list of clone names.
-func (m *LVManager) ListClonesNames() ([]string, error) {
-	volumes, err := ListVolumes(m.runner, m.volumeGroup)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to list LVM volumes")
-	}
-
-	volumesNames := make([]string, 0, len(volumes))
-
-	for _, volume := range volumes {
-		volumesNames = append(volumesNames, volume.Name)
-	}
+	m.store = &tagStore{runner: runner, volumeGroup: m.volumeGroup, poolName: config.Pool.Name}
+	m.Base = catalog.NewBase[*volume](runner, config, m.store, m)
 
-	return volumesNames, nil
+	return m, nil
 }
 
-func (m *LVManager) parsePool() error {
-	parts := strings.SplitN(m.pool.Name, "-", poolPartsLen)
+func (m *LVManager) parsePool(poolName string) error {
+	parts := strings.SplitN(poolName, "-", poolPartsLen)
 	if len(parts) < poolPartsLen {
-		return errors.Errorf("failed to extract volume group and logical volume from %q", m.pool.Name)
+		return errors.Errorf("failed to extract volume group and logical volume from %q", poolName)
 	}
 
 	m.volumeGroup = parts[0]
@@ -91,287 +63,271 @@ func (m *LVManager) parsePool() error {
 	return nil
 }
 
-// EnsureDataOwnership is a no-op in LVM mode: volume ownership is managed at the volume level.
-func (m *LVManager) EnsureDataOwnership(_ string) error {
-	return nil
+func poolRoot(pool *resources.Pool) string {
+	return path.Join(pool.MountDir, pool.PoolDirName)
 }
 
-// CreateSnapshot is not supported in LVM mode.
-func (m *LVManager) CreateSnapshot(_, _ string) (string, error) {
-	log.Msg("Creating a snapshot is not supported in LVM mode. Skip the operation.")
-
-	return "", nil
-}
-
-// DestroySnapshot is not supported in LVM mode.
-func (m *LVManager) DestroySnapshot(_ string, _ thinclones.DestroyOptions) error {
-	log.Msg("Destroying a snapshot is not supported in LVM mode. Skip the operation.")
-
-	return nil
-}
-
-// CleanupSnapshots is not supported in LVM mode.
-func (m *LVManager) CleanupSnapshots(_ int, _ models.RetrievalMode) ([]string, error) {
-	log.Msg("Cleanup snapshots is not supported in LVM mode. Skip the operation.")
-
-	return nil, nil
-}
+// datasetPath returns the mount point of a dataset.
+func (m *LVManager) datasetPath(dataset string) (string, error) {
+	poolName := m.Config().Pool.Name
 
-// SnapshotList is not implemented.
-func (m *LVManager) SnapshotList() []resources.Snapshot {
-	// TODO(anatoly): Not supported in LVM mode warning.
-	return []resources.Snapshot{
-		{
-			ID:          "TechnicalSnapshot",
-			CreatedAt:   time.Now(),
-			DataStateAt: time.Now(),
-			Pool:        m.pool.Name,
-		},
+	if dataset == poolName {
+		return poolRoot(m.Config().Pool), nil
 	}
-}
-
-// RefreshSnapshotList is not supported in LVM mode.
-func (m *LVManager) RefreshSnapshotList() {
-	log.Msg("RefreshSnapshotList is not supported for LVM. Skip the operation")
-}
-
-// GetSessionState is not implemented.
-func (m *LVManager) GetSessionState(_, _ string) (*resources.SessionState, error) {
-	// TODO(anatoly): Implement.
-	return &resources.SessionState{}, nil
-}
-
-// GetBatchSessionState is not implemented.
-func (m *LVManager) GetBatchSessionState(_ []resources.SessionStateRequest) (map[string]resources.SessionState, error) {
-	return make(map[string]resources.SessionState), nil
-}
-
-// GetFilesystemState is not implemented.
-func (m *LVManager) GetFilesystemState() (models.FileSystem, error) {
-	// TODO(anatoly): Implement.
-	return models.FileSystem{Mode: PoolMode}, nil
-}
-
-// InitBranching inits data branching.
-func (m *LVManager) InitBranching() error {
-	log.Msg("InitBranching is not supported for LVM. Skip the operation")
-
-	return nil
-}
 
-// VerifyBranchMetadata checks snapshot metadata.
-func (m *LVManager) VerifyBranchMetadata() error {
-	log.Msg("VerifyBranchMetadata is not supported for LVM. Skip the operation")
+	rel, found := strings.CutPrefix(dataset, poolName+"/")
+	if !found {
+		return "", errors.Errorf("dataset %q does not belong to pool %q", dataset, poolName)
+	}
 
-	return nil
+	return path.Join(poolRoot(m.Config().Pool), rel), nil
 }
 
-// CreateDataset creates a new dataset.
-func (m *LVManager) CreateDataset(_ string) error {
-	log.Msg("CreateDataset is not supported for LVM. Skip the operation")
+// datasetVolume returns the logical volume keeping the dataset data.
+func (m *LVManager) datasetVolume(md *metadata, dataset string) (string, error) {
+	if dataset == m.Config().Pool.Name {
+		return m.logicalVolume, nil
+	}
 
-	return nil
-}
+	ds, ok := md.Datasets[dataset]
+	if !ok {
+		return "", fmt.Errorf("%w: dataset %s", catalog.ErrEntryNotFound, dataset)
+	}
 
-// CreateBranch clones data as a new branch.
-func (m *LVManager) CreateBranch(_, _ string) error {
-	log.Msg("CreateBranch is not supported for LVM. Skip the operation")
+	if ds.LVName == "" {
+		return "", errors.Errorf("dataset %s has no logical volume", dataset)
+	}
 
-	return nil
+	return ds.LVName, nil
 }
 
-// DestroyDataset destroys dataset.
-func (m *LVManager) DestroyDataset(_ string) error {
-	log.Msg("DestroyDataset is not supported for LVM; skipping operation")
+// createVolume creates a thin snapshot of the source volume and registers it as a dataset or a snapshot.
+func (m *LVManager) createVolume(md *metadata, name, source, origin string, props map[string]string) (*volume, error) {
+	isSnapshot := strings.Contains(name, "@")
+	lvName := volumeName(m.logicalVolume, m.Config().Pool.Name, name)
 
-	return nil
-}
+	v := newVolume(name, lvName, source, origin, props)
 
-// DestroyBranchDataset destroys a branch dataset.
-func (m *LVManager) DestroyBranchDataset(_ string) error {
-	log.Msg("destroyBranchDataset is not supported for LVM. Skip the operation")
+	cmd := thinSnapshotCommand(m.volumeGroup, source, lvName, isSnapshot, encodeTags(v.persisted))
 
-	return nil
-}
-
-// Snapshot takes a snapshot of the current data state.
-func (m *LVManager) Snapshot(_ string) error {
-	log.Msg("Snapshot is not supported for LVM. Skip the operation")
-
-	return nil
-}
-
-// Reset rollbacks data to ZFS snapshot.
-func (m *LVManager) Reset(_ string, _ thinclones.ResetOptions) error {
-	log.Msg("Reset is not supported for LVM. Skip the operation")
+	if out, err := m.runner.Run(cmd, true); err != nil {
+		return nil, errors.Wrapf(err, "failed to create volume %s. Out: %v", name, out)
+	}
 
-	return nil
-}
+	if isSnapshot {
+		md.Snapshots[name] = v
+		return v, nil
+	}
 
-// ListBranches lists data pool branches.
-func (m *LVManager) ListBranches() (map[string]string, error) {
-	log.Msg("ListBranches is not supported for LVM. Skip the operation")
+	md.Datasets[name] = v
+	md.RegisterParents(m.Pool().Name, name, newGroupVolume)
 
-	return nil, nil
+	return v, nil
 }
 
-// ListAllBranches lists all branches.
-func (m *LVManager) ListAllBranches(_ []string) ([]models.BranchEntity, error) {
-	log.Msg("ListAllBranches is not supported for LVM. Skip the operation")
+// CreateClone creates a new clone as a writable thin snapshot and mounts it.
+func (m *LVManager) CreateClone(branchName, cloneName, snapshotID string, revision int) error {
+	cloneMountName := m.Config().Pool.CloneName(branchName, cloneName, revision)
 
-	return nil, nil
-}
+	log.Dbg(cloneMountName)
 
-// GetSnapshotProperties get custom snapshot properties.
-func (m *LVManager) GetSnapshotProperties(_ string) (thinclones.SnapshotProperties, error) {
-	log.Msg("GetSnapshotProperties is not supported for LVM. Skip the operation")
+	return m.store.update(func(md *metadata) error {
+		snapshot, ok := md.Snapshots[snapshotID]
+		if !ok {
+			return errors.Errorf("snapshot %q not found", snapshotID)
+		}
 
-	return thinclones.SnapshotProperties{}, nil
-}
+		if _, ok := md.Datasets[cloneMountName]; ok && revision == branching.DefaultRevision {
+			return errors.Errorf("clone %q is already exists; skipping", cloneName)
+		}
 
-// SetProtectedTill sets the protection-expiry timestamp.
-func (m *LVManager) SetProtectedTill(_, _ string) error {
-	log.Msg("setProtectedTill is not supported for LVM. Skip the operation")
+		clone, err := m.createVolume(md, cloneMountName, snapshot.LVName, snapshotID, nil)
+		if err != nil {
+			return err
+		}
 
-	return nil
-}
+		cloneMountLocation := m.Config().Pool.CloneLocation(branchName, cloneName, revision)
+		cloneDataDir := m.Config().Pool.ClonePath(branchName, cloneName, revision)
 
-// SetDeleteAt sets the scheduled-deletion timestamp.
-func (m *LVManager) SetDeleteAt(_, _ string) error {
-	log.Msg("setDeleteAt is not supported for LVM. Skip the operation")
+		cmd := mountCommand(m.volumeGroup, clone.LVName, cloneMountLocation) + " && " +
+			catalog.ChownCloneCommand(cloneDataDir, cloneMountLocation, m.Config().OSUsername)
 
-	return nil
-}
+		log.Dbg(cmd)
 
-// GetProtection returns protection properties.
-func (m *LVManager) GetProtection(_ string) (thinclones.ProtectionProperties, error) {
-	log.Msg("getProtection is not supported for LVM. Skip the operation")
+		if out, err := m.runner.Run(cmd, true); err != nil {
+			return errors.Wrapf(err, "failed to mount clone. Out: %v", out)
+		}
 
-	return thinclones.ProtectionProperties{}, nil
+		return nil
+	})
 }
 
-// ListProtection returns protection properties for all snapshots.
-func (m *LVManager) ListProtection() (map[string]thinclones.ProtectionProperties, error) {
-	log.Msg("listProtection is not supported for LVM. Skip the operation")
-
-	return nil, nil
-}
+// CreateDataset creates a directory for datasets grouping other datasets.
+// Such datasets keep no data, so no volume is created for them.
+func (m *LVManager) CreateDataset(datasetName string) error {
+	datasetPath, err := m.datasetPath(datasetName)
+	if err != nil {
+		return err
+	}
 
-// AddBranchProp adds branch to snapshot property.
-func (m *LVManager) AddBranchProp(_, _ string) error {
-	log.Msg("AddBranchProp is not supported for LVM. Skip the operation")
+	if out, err := m.runner.Run(createDirCommand(datasetPath)); err != nil {
+		log.Dbg(out)
+		return errors.Wrap(err, "failed to create dataset")
+	}
 
 	return nil
 }
 
-// DeleteBranchProp deletes branch from snapshot property.
-func (m *LVManager) DeleteBranchProp(_, _ string) error {
-	log.Msg("DeleteBranchProp is not supported for LVM. Skip the operation")
+// Destroy removes datasets and snapshots together with their dependents, the way "zfs destroy -R" does.
+func (m *LVManager) Destroy(datasets, snapshots []string) error {
+	for _, dataset := range datasets {
+		if dataset == m.Config().Pool.Name {
+			return errors.Errorf("cannot destroy the pool volume %s", dataset)
+		}
+	}
 
-	return nil
-}
+	return m.store.update(func(md *metadata) error {
+		dsSet, snSet := md.Dependents(datasets, snapshots)
 
-// DeleteChildProp deletes child from snapshot property.
-func (m *LVManager) DeleteChildProp(_, _ string) error {
-	log.Msg("DeleteChildProp is not supported for LVM. Skip the operation")
+		datasetNames := make([]string, 0, len(dsSet))
+		for name := range dsSet {
+			datasetNames = append(datasetNames, name)
+		}
 
-	return nil
-}
+		// Nested mounts must be removed before their parents.
+		sort.Slice(datasetNames, func(i, j int) bool {
+			return strings.Count(datasetNames[i], "/") > strings.Count(datasetNames[j], "/")
+		})
 
-// DeleteRootProp deletes root from snapshot property.
-func (m *LVManager) DeleteRootProp(_, _ string) error {
-	log.Msg("DeleteRootProp is not supported for LVM. Skip the operation")
+		for _, name := range datasetNames {
+			datasetPath, err := m.datasetPath(name)
+			if err != nil {
+				return err
+			}
 
-	return nil
-}
+			cmd := removeDirCommand(datasetPath)
 
-// SetRelation sets relation between snapshots.
-func (m *LVManager) SetRelation(_, _ string) error {
-	log.Msg("SetRelation is not supported for LVM. Skip the operation")
+			if lvName := md.Datasets[name].LVName; lvName != "" {
+				cmd = unmountCommand(datasetPath) + " && " + removeVolumeCommand(m.volumeGroup, lvName) + " && " + cmd
+			}
 
-	return nil
-}
+			if out, err := m.runner.Run(cmd, true); err != nil {
+				return errors.Wrapf(err, "failed to destroy %s. Out: %v", name, out)
+			}
 
-// SetRoot marks snapshot as a root of branch.
-func (m *LVManager) SetRoot(_, _ string) error {
-	log.Msg("SetRoot is not supported for LVM. Skip the operation")
+			delete(md.Datasets, name)
+		}
 
-	return nil
-}
+		snapshotNames := make([]string, 0, len(snSet))
+		for name := range snSet {
+			snapshotNames = append(snapshotNames, name)
+		}
 
-// GetRepo provides data repository details.
-func (m *LVManager) GetRepo() (*models.Repo, error) {
-	log.Msg("GetRepo is not supported for LVM. Skip the operation")
+		sort.Strings(snapshotNames)
 
-	return nil, nil
-}
+		for _, name := range snapshotNames {
+			if out, err := m.runner.Run(removeVolumeCommand(m.volumeGroup, md.Snapshots[name].LVName), true); err != nil {
+				return errors.Wrapf(err, "failed to destroy %s. Out: %v", name, out)
+			}
 
-// GetAllRepo provides data repository details.
-func (m *LVManager) GetAllRepo() (*models.Repo, error) {
-	log.Msg("GetAllRepo is not supported for LVM. Skip the operation")
+			delete(md.Snapshots, name)
+		}
 
-	return nil, nil
+		return nil
+	})
 }
 
-// SetDSA sets value of DataStateAt to snapshot.
-func (m *LVManager) SetDSA(_, _ string) error {
-	log.Msg("SetDSA is not supported for LVM. Skip the operation")
+// GetBatchSessionState returns session states for multiple clones using a single "lvs" call.
+func (m *LVManager) GetBatchSessionState(requests []resources.SessionStateRequest) (map[string]resources.SessionState, error) {
+	sessionStates := make(map[string]resources.SessionState, len(requests))
 
-	return nil
-}
+	if err := m.store.view(func(md *metadata) error {
+		for _, req := range requests {
+			cloneDataset := branching.CloneDataset(m.Config().Pool.Name, req.Branch, req.CloneID)
 
-// SetMessage sets commit message to snapshot.
-func (m *LVManager) SetMessage(_, _ string) error {
-	log.Msg("SetMessage is not supported for LVM. Skip the operation")
+			if _, ok := md.Datasets[cloneDataset]; !ok {
+				continue
+			}
 
-	return nil
-}
+			state := resources.SessionState{}
 
-// SetMountpoint sets clone mount point.
-func (m *LVManager) SetMountpoint(_, _ string) error {
-	log.Msg("SetMountpoint is not supported for LVM. Skip the operation")
+			for _, name := range md.Subtree(cloneDataset) {
+				ds := md.Datasets[name]
 
-	return nil
-}
+				if ds.LVName == "" {
+					continue
+				}
 
-// Rename renames clone.
-func (m *LVManager) Rename(_, _ string) error {
-	log.Msg("Rename is not supported for LVM. Skip the operation")
+				state.CloneDiffSize += md.diffSize(ds)
+				state.LogicalReferenced = max(state.LogicalReferenced, ds.Mapped)
+			}
 
-	return nil
-}
+			sessionStates[req.CloneID] = state
+		}
 
-// Move moves snapshot diff.
-func (m *LVManager) Move(_, _, _ string) error {
-	log.Msg("Move is not supported for LVM. Skip the operation")
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to read volume tags")
+	}
 
-	return nil
+	return sessionStates, nil
 }
 
-// HasDependentEntity checks if snapshot has dependent entities.
-func (m *LVManager) HasDependentEntity(_ string) ([]string, error) {
-	log.Msg("HasDependentEntity is not supported for LVM. Skip the operation")
+// GetFilesystemState returns a disk state of the thin pool.
+func (m *LVManager) GetFilesystemState() (models.FileSystem, error) {
+	fileSystem := models.FileSystem{Mode: PoolMode, CompressRatio: 1}
+
+	if err := m.store.view(func(md *metadata) error {
+		root, ok := md.volumes[m.logicalVolume]
+		if !ok {
+			return errors.Errorf("logical volume %s not found", getFullName(m.volumeGroup, m.logicalVolume))
+		}
+
+		thinPool, ok := md.volumes[root.Pool]
+		if root.Pool == "" || !ok {
+			return errors.Errorf("logical volume %s is not a thin volume", getFullName(m.volumeGroup, m.logicalVolume))
+		}
+
+		fileSystem.Size = thinPool.SizeBytes()
+		fileSystem.Used = thinPool.MappedBytes()
+		fileSystem.Free = fileSystem.Size - min(fileSystem.Used, fileSystem.Size)
+		fileSystem.DataSize = root.MappedBytes()
+
+		for _, sn := range md.Snapshots {
+			fileSystem.UsedBySnapshots += md.diffSize(sn)
+		}
+
+		for _, ds := range md.Datasets {
+			if ds.LVName != "" {
+				fileSystem.UsedByClones += md.diffSize(ds)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return models.FileSystem{}, errors.Wrap(err, "failed to get filesystem state")
+	}
 
-	return nil, nil
+	return fileSystem, nil
 }
 
-// KeepRelation keeps relation between adjacent snapshots.
-func (m *LVManager) KeepRelation(_ string) error {
-	log.Msg("KeepRelation is not supported for LVM. Skip the operation")
+// ListSnapshots returns snapshots available for cloning along with their space usage, the newest first.
+func (m *LVManager) ListSnapshots() ([]resources.Snapshot, error) {
+	snapshots := []resources.Snapshot{}
 
-	return nil
-}
-
-// GetDatasetOrigins provides a list of dataset origins.
-func (m *LVManager) GetDatasetOrigins(_ string) []string {
-	log.Msg("GetDatasetOrigins is not supported for LVM. Skip the operation")
+	if err := m.store.view(func(md *metadata) error {
+		for _, snapshot := range m.ClonableSnapshots(md.Catalog) {
+			sn := md.Snapshots[snapshot.ID]
+			snapshot.Used = md.diffSize(sn)
+			snapshot.LogicalReferenced = sn.Mapped
 
-	return nil
-}
+			snapshots = append(snapshots, snapshot)
+		}
 
-// GetActiveDatasets provides a list of active datasets.
-func (m *LVManager) GetActiveDatasets(_ string) ([]string, error) {
-	log.Msg("GetDatasetOrigins is not supported for LVM. Skip the operation")
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to list snapshots")
+	}
 
-	return nil, nil
+	return snapshots, nil
 }
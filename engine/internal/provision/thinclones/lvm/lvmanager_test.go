@@ -0,0 +1,371 @@
+/*
+2026 © Postgres.ai
+*/
+
+package lvm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	testVolumeGroup = "dblab_vg"
+	testPoolVolume  = "pg_lv"
+	testThinPool    = "thinpool"
+)
+
+// fakeLVM emulates LVM commands that the manager runs and keeps volumes in memory.
+type fakeLVM struct {
+	cmds    []string
+	volumes map[string]*ListEntry
+	clock   time.Time
+}
+
+func newFakeLVM() *fakeLVM {
+	return &fakeLVM{
+		volumes: map[string]*ListEntry{
+			testThinPool:   {Name: testThinPool, GroupName: testVolumeGroup, Attr: "twi-aotz--", Size: "10000", DataPercent: "10.00"},
+			testPoolVolume: {Name: testPoolVolume, GroupName: testVolumeGroup, Attr: "Vwi-aotz--", Size: "1000", Pool: testThinPool, DataPercent: "40.00"},
+		},
+		clock: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (f *fakeLVM) Run(cmd string, _ ...bool) (string, error) {
+	f.cmds = append(f.cmds, cmd)
+
+	for _, part := range strings.Split(cmd, " && ") {
+		fields := strings.Fields(part)
+
+		var err error
+
+		switch fields[0] {
+		case "lvs":
+			return f.report()
+		case "lvcreate":
+			err = f.create(fields[1:])
+		case "lvremove":
+			err = f.remove(fields[len(fields)-1])
+		case "lvchange":
+			err = f.change(fields[1:])
+		case "lvrename":
+			err = f.rename(fields[2], fields[3])
+		}
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "", nil
+}
+
+func (f *fakeLVM) report() (string, error) {
+	names := make([]string, 0, len(f.volumes))
+	for name := range f.volumes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	entries := make([]ListEntry, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, *f.volumes[name])
+	}
+
+	out, err := json.Marshal(LvsOutput{Reports: []ReportEntry{{Volumes: entries}}})
+
+	return string(out), err
+}
+
+func (f *fakeLVM) create(args []string) error {
+	entry := &ListEntry{GroupName: testVolumeGroup, Attr: "Vwi-a-tz--"}
+	tags := []string{}
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--name":
+			i++
+			entry.Name = args[i]
+		case "--addtag":
+			i++
+			tags = append(tags, args[i])
+		case "--setactivationskip", "--permission":
+			i++
+		case "--snapshot":
+		default:
+			_, source, _ := strings.Cut(args[i], "/")
+
+			origin, ok := f.volumes[source]
+			if !ok {
+				return fmt.Errorf("volume %s not found", source)
+			}
+
+			entry.Origin = source
+			entry.Pool = origin.Pool
+			entry.Size = origin.Size
+			entry.DataPercent = origin.DataPercent
+		}
+	}
+
+	if _, ok := f.volumes[entry.Name]; ok {
+		return fmt.Errorf("volume %s already exists", entry.Name)
+	}
+
+	f.clock = f.clock.Add(time.Second)
+	entry.Time = f.clock.Format(lvTimeLayout)
+	entry.Tags = strings.Join(tags, ",")
+	f.volumes[entry.Name] = entry
+
+	return nil
+}
+
+func (f *fakeLVM) remove(fullName string) error {
+	_, name, _ := strings.Cut(fullName, "/")
+
+	if _, ok := f.volumes[name]; !ok {
+		return fmt.Errorf("volume %s not found", name)
+	}
+
+	delete(f.volumes, name)
+
+	return nil
+}
+
+func (f *fakeLVM) change(args []string) error {
+	_, name, _ := strings.Cut(args[len(args)-1], "/")
+
+	entry, ok := f.volumes[name]
+	if !ok {
+		return fmt.Errorf("volume %s not found", name)
+	}
+
+	tags := entry.TagList()
+
+	for i := 0; i+1 < len(args); i += 2 {
+		switch args[i] {
+		case "--deltag":
+			for j, tag := range tags {
+				if tag == args[i+1] {
+					tags = append(tags[:j], tags[j+1:]...)
+					break
+				}
+			}
+		case "--addtag":
+			tags = append(tags, args[i+1])
+		}
+	}
+
+	entry.Tags = strings.Join(tags, ",")
+
+	return nil
+}
+
+func (f *fakeLVM) rename(oldName, newName string) error {
+	entry, ok := f.volumes[oldName]
+	if !ok {
+		return fmt.Errorf("volume %s not found", oldName)
+	}
+
+	if _, ok := f.volumes[newName]; ok {
+		return fmt.Errorf("volume %s already exists", newName)
+	}
+
+	entry.Name = newName
+	f.volumes[newName] = entry
+	delete(f.volumes, oldName)
+
+	for _, v := range f.volumes {
+		if v.Origin == oldName {
+			v.Origin = newName
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeLVM) commands(prefix string) []string {
+	found := []string{}
+
+	for _, cmd := range f.cmds {
+		if strings.Contains(cmd, prefix) {
+			found = append(found, cmd)
+		}
+	}
+
+	return found
+}
+
+func newTestManager(t *testing.T) (*LVManager, *fakeLVM) {
+	t.Helper()
+
+	runner := newFakeLVM()
+	pool := &resources.Pool{
+		Name:        testVolumeGroup + "-" + testPoolVolume,
+		Mode:        PoolMode,
+		PoolDirName: testVolumeGroup + "-" + testPoolVolume,
+		MountDir:    "/var/lib/dblab",
+		DataSubDir:  "data",
+	}
+
+	m, err := NewFSManager(runner, Config{Pool: pool, PreSnapshotSuffix: "_pre", OSUsername: "postgres"})
+	require.NoError(t, err)
+
+	return m, runner
+}
+
+func TestNewFSManagerInvalidPool(t *testing.T) {
+	_, err := NewFSManager(newFakeLVM(), Config{Pool: &resources.Pool{Name: "pool"}})
+	require.Error(t, err)
+}
+
+func TestCloneLifecycle(t *testing.T) {
+	m, runner := newTestManager(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+	assert.Equal(t, "dblab_vg-pg_lv@snapshot_20260101000000", snapshotID)
+
+	createCmds := runner.commands("lvcreate")
+	require.Len(t, createCmds, 1)
+	assert.Contains(t, createCmds[0], "--permission r ")
+	assert.True(t, strings.HasSuffix(createCmds[0], " dblab_vg/pg_lv"))
+
+	_, err = m.CreateSnapshot("", "20260101000000")
+	var existsErr *thinclones.SnapshotExistsError
+	require.ErrorAs(t, err, &existsErr)
+
+	snapshots := m.SnapshotList()
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "main", snapshots[0].Branch)
+	assert.Equal(t, "2026-01-01 00:00:00 +0000 UTC", snapshots[0].DataStateAt.String())
+
+	require.NoError(t, m.CreateClone("main", "clone1", snapshotID, 0))
+	require.Error(t, m.CreateClone("main", "clone1", snapshotID, 0))
+	require.Error(t, m.CreateClone("main", "clone2", "dblab_vg-pg_lv@missing", 0))
+
+	mountCmds := runner.commands("mount /dev/")
+	require.Len(t, mountCmds, 1)
+	assert.Contains(t, mountCmds[0], "'/var/lib/dblab/dblab_vg-pg_lv/branch/main/clone1/r0'")
+
+	clones, err := m.ListClonesNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"clone1"}, clones)
+
+	assert.Equal(t, []string{"-", snapshotID}, m.GetDatasetOrigins("dblab_vg-pg_lv/branch/main/clone1"))
+
+	dependent, err := m.HasDependentEntity(snapshotID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dblab_vg-pg_lv/branch/main/clone1/r0"}, dependent)
+
+	require.Error(t, m.DestroySnapshot(snapshotID, thinclones.DestroyOptions{}))
+
+	require.NoError(t, m.DestroyClone("main", "clone1", 0))
+
+	clones, err = m.ListClonesNames()
+	require.NoError(t, err)
+	assert.Empty(t, clones)
+
+	require.NoError(t, m.DestroySnapshot(snapshotID, thinclones.DestroyOptions{}))
+	assert.Empty(t, m.SnapshotList())
+	assert.Len(t, runner.volumes, 2)
+}
+
+func TestDestroyDatasetWithDependents(t *testing.T) {
+	m, runner := newTestManager(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+
+	require.NoError(t, m.CreateBranch("dblab_vg-pg_lv/branch/dev", snapshotID))
+	require.NoError(t, m.Snapshot("dblab_vg-pg_lv/branch/dev@20260102000000"))
+	require.NoError(t, m.CreateClone("dev", "clone1", "dblab_vg-pg_lv/branch/dev@20260102000000", 0))
+
+	require.NoError(t, m.DestroyBranchDataset("dblab_vg-pg_lv/branch/dev"))
+
+	require.NoError(t, m.store.view(func(md *metadata) error {
+		assert.Empty(t, md.Subtree("dblab_vg-pg_lv/branch/dev"))
+		assert.Contains(t, md.Snapshots, snapshotID)
+		assert.NotContains(t, md.Snapshots, "dblab_vg-pg_lv/branch/dev@20260102000000")
+
+		return nil
+	}))
+
+	removeCmds := runner.commands("lvremove")
+	require.Len(t, removeCmds, 3)
+	// Nested mounts go before their parents.
+	assert.Contains(t, removeCmds[0], "umount '/var/lib/dblab/dblab_vg-pg_lv/branch/dev/clone1/r0'")
+	assert.Contains(t, removeCmds[1], "umount '/var/lib/dblab/dblab_vg-pg_lv/branch/dev'")
+	assert.Contains(t, removeCmds[2], volumeName(testPoolVolume, "dblab_vg-pg_lv", "dblab_vg-pg_lv/branch/dev@20260102000000"))
+	assert.Equal(t, []string{"rm -rf '/var/lib/dblab/dblab_vg-pg_lv/branch/dev/clone1'"}, runner.commands("rm -rf '/var/lib/dblab/dblab_vg-pg_lv/branch/dev/clone1'"))
+	assert.Len(t, runner.volumes, 3)
+
+	require.Error(t, m.DestroyDataset("dblab_vg-pg_lv"))
+}
+
+func TestGetFilesystemState(t *testing.T) {
+	m, runner := newTestManager(t)
+
+	snapshotID, err := m.CreateSnapshot("", "20260101000000")
+	require.NoError(t, err)
+	require.NoError(t, m.CreateClone("main", "clone1", snapshotID, 0))
+
+	for _, v := range runner.volumes {
+		if strings.Contains(v.Name, "clone1") {
+			v.DataPercent = "45.00"
+		}
+	}
+
+	fs, err := m.GetFilesystemState()
+	require.NoError(t, err)
+	assert.Equal(t, models.FileSystem{
+		Mode:          PoolMode,
+		Size:          10000,
+		Free:          9000,
+		Used:          1000,
+		UsedByClones:  50,
+		DataSize:      400,
+		CompressRatio: 1,
+	}, fs)
+
+	state, err := m.GetSessionState("main", "clone1")
+	require.NoError(t, err)
+	assert.Equal(t, &resources.SessionState{CloneDiffSize: 50, LogicalReferenced: 450}, state)
+
+	_, err = m.GetSessionState("main", "missing")
+	require.Error(t, err)
+
+	delete(runner.volumes, testThinPool)
+	runner.volumes[testPoolVolume].Pool = ""
+
+	_, err = m.GetFilesystemState()
+	require.Error(t, err)
+}
+
+func TestListVolumesError(t *testing.T) {
+	m, _ := newTestManager(t)
+	m.runner = failingRunner{}
+	m.store.runner = failingRunner{}
+
+	_, err := m.ListClonesNames()
+	require.Error(t, err)
+}
+
+type failingRunner struct{}
+
+func (failingRunner) Run(string, ...bool) (string, error) {
+	return "", errors.New("lvs failed")
+}
@@ -0,0 +1,360 @@
+/*
+2026 © Postgres.ai
+*/
+
+package lvm
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/runners"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones/catalog"
+)
+
+const (
+	// nameTag keeps the dataset or snapshot name of a volume, "pool/branch/main/clone/r0" or "pool@snapshot_X".
+	nameTag = "dle:name"
+
+	// originTag keeps the name of the snapshot a dataset volume has been created from.
+	originTag = "dle:origin"
+
+	tagSep = "="
+
+	// Leave room for the hash suffix: LVM limits volume names to 127 characters.
+	maxVolumeNameBase = 100
+	volumeHashLen     = 8
+)
+
+// volume describes a dataset or a snapshot kept in a thin logical volume.
+// Datasets that only group other datasets have no volume and an empty LVName.
+type volume struct {
+	catalog.Entry
+	LVName   string
+	LVOrigin string
+	Size     uint64
+	Mapped   uint64
+
+	// persisted keeps the tags stored in LVM to find out what has to be changed.
+	persisted map[string]string
+}
+
+// metadata reflects the ZFS dataset and snapshot hierarchy rebuilt from the tags of logical volumes.
+type metadata struct {
+	*catalog.Catalog[*volume]
+
+	// volumes contains all volumes of the group, including untagged ones, by their names.
+	volumes map[string]ListEntry
+}
+
+func newMetadata() *metadata {
+	return &metadata{
+		Catalog: catalog.New[*volume](),
+		volumes: make(map[string]ListEntry),
+	}
+}
+
+// newGroupVolume creates an entry of a dataset that only groups other datasets and has no volume.
+func newGroupVolume() *volume {
+	return &volume{Entry: catalog.Entry{Properties: make(map[string]string)}}
+}
+
+// newVolume creates an entry of a volume that is about to be created with the tags from the persisted field.
+func newVolume(name, lvName, lvOrigin, origin string, props map[string]string) *volume {
+	if props == nil {
+		props = make(map[string]string)
+	}
+
+	v := &volume{
+		Entry:    catalog.Entry{Origin: origin, CreatedAt: time.Now(), Properties: props},
+		LVName:   lvName,
+		LVOrigin: lvOrigin,
+	}
+	v.persisted = v.tagValues(name)
+
+	return v
+}
+
+// tagValues returns the tag values the volume must have in LVM.
+func (v *volume) tagValues(name string) map[string]string {
+	values := make(map[string]string, len(v.Properties)+2)
+
+	for key, value := range v.Properties {
+		values[key] = value
+	}
+
+	if name != "" {
+		values[nameTag] = name
+	}
+
+	if v.Origin != "" {
+		values[originTag] = v.Origin
+	}
+
+	return values
+}
+
+// encodeTag builds an LVM tag. Values are encoded because LVM allows only a limited set of characters in tags.
+func encodeTag(key, value string) string {
+	return key + tagSep + base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+// decodeTag parses an LVM tag built by encodeTag.
+func decodeTag(tag string) (string, string, bool) {
+	key, encoded, found := strings.Cut(tag, tagSep)
+	if !found {
+		return "", "", false
+	}
+
+	value, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", false
+	}
+
+	return key, string(value), true
+}
+
+// encodeTags builds a sorted list of tags from the tag values.
+func encodeTags(values map[string]string) []string {
+	tags := make([]string, 0, len(values))
+
+	for key, value := range values {
+		tags = append(tags, encodeTag(key, value))
+	}
+
+	sort.Strings(tags)
+
+	return tags
+}
+
+// volumeName builds a valid logical volume name for a dataset or a snapshot.
+func volumeName(baseVolume, poolName, name string) string {
+	rel := strings.TrimPrefix(strings.TrimPrefix(name, poolName), "/")
+
+	readable := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '+', r == '.':
+			return r
+		case r == '/':
+			return '.'
+		default:
+			return '_'
+		}
+	}, baseVolume+"."+rel)
+
+	if len(readable) > maxVolumeNameBase {
+		readable = readable[:maxVolumeNameBase]
+	}
+
+	hash := sha256.Sum256([]byte(name))
+
+	return readable + "_" + hex.EncodeToString(hash[:])[:volumeHashLen]
+}
+
+// tagStore keeps dataset and snapshot metadata in tags of logical volumes.
+type tagStore struct {
+	mu          sync.Mutex
+	runner      runners.Runner
+	volumeGroup string
+	poolName    string
+}
+
+// View provides read-only access to the current catalog.
+func (s *tagStore) View(fn func(c *catalog.Catalog[*volume]) error) error {
+	return s.view(func(md *metadata) error {
+		return fn(md.Catalog)
+	})
+}
+
+// Update loads the catalog, applies changes and writes changed tags back.
+func (s *tagStore) Update(fn func(c *catalog.Catalog[*volume]) error) error {
+	return s.update(func(md *metadata) error {
+		return fn(md.Catalog)
+	})
+}
+
+// view provides read-only access to the current metadata.
+func (s *tagStore) view(fn func(md *metadata) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	md, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	return fn(md)
+}
+
+// update loads the metadata, applies changes and writes changed tags back.
+// Volumes created or removed inside fn must be created with their tags or removed there as well.
+func (s *tagStore) update(fn func(md *metadata) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	md, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(md); err != nil {
+		return err
+	}
+
+	return s.save(md)
+}
+
+func (s *tagStore) load() (*metadata, error) {
+	out, err := s.runner.Run(listVolumesCommand(s.volumeGroup), false)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list volumes")
+	}
+
+	entries, err := parseLVMOutput(out)
+	if err != nil {
+		return nil, err
+	}
+
+	md := newMetadata()
+
+	for _, entry := range entries {
+		md.volumes[entry.Name] = entry
+
+		values := make(map[string]string)
+
+		for _, tag := range entry.TagList() {
+			if key, value, ok := decodeTag(tag); ok {
+				values[key] = value
+			}
+		}
+
+		name := values[nameTag]
+		if name == "" || (name != s.poolName && !strings.HasPrefix(name, s.poolName+"/") && !strings.HasPrefix(name, s.poolName+"@")) {
+			continue
+		}
+
+		v := &volume{
+			Entry: catalog.Entry{
+				Origin:     values[originTag],
+				CreatedAt:  entry.CreatedAt(),
+				Properties: make(map[string]string),
+			},
+			LVName:    entry.Name,
+			LVOrigin:  entry.Origin,
+			Size:      entry.SizeBytes(),
+			Mapped:    entry.MappedBytes(),
+			persisted: values,
+		}
+
+		for key, value := range values {
+			if key != nameTag && key != originTag {
+				v.Properties[key] = value
+			}
+		}
+
+		if strings.Contains(name, "@") {
+			md.Snapshots[name] = v
+			continue
+		}
+
+		md.Datasets[name] = v
+	}
+
+	for name := range md.Datasets {
+		md.RegisterParents(s.poolName, name, newGroupVolume)
+	}
+
+	return md, nil
+}
+
+// save writes tags of volumes that have changed.
+func (s *tagStore) save(md *metadata) error {
+	names := make([]string, 0, len(md.Datasets)+len(md.Snapshots))
+	entries := make(map[string]*volume, len(md.Datasets)+len(md.Snapshots))
+
+	for name, v := range md.Datasets {
+		names = append(names, name)
+		entries[name] = v
+	}
+
+	for name, v := range md.Snapshots {
+		names = append(names, name)
+		entries[name] = v
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		v := entries[name]
+
+		if v.LVName == "" {
+			if len(v.Properties) > 0 {
+				return errors.Errorf("dataset %s has no logical volume to keep properties", name)
+			}
+
+			continue
+		}
+
+		expected := v.tagValues(name)
+		deleteTags, addTags := tagsDiff(v.persisted, expected)
+
+		if len(deleteTags) == 0 && len(addTags) == 0 {
+			continue
+		}
+
+		if out, err := s.runner.Run(changeTagsCommand(s.volumeGroup, v.LVName, deleteTags, addTags), true); err != nil {
+			return errors.Wrapf(err, "failed to update tags of %s. Out: %v", name, out)
+		}
+
+		v.persisted = expected
+	}
+
+	return nil
+}
+
+// tagsDiff returns tags to delete and tags to add to turn the current tag values into the expected ones.
+func tagsDiff(current, expected map[string]string) ([]string, []string) {
+	deleteTags := []string{}
+	addTags := []string{}
+
+	for key, value := range current {
+		if expectedValue, ok := expected[key]; !ok || expectedValue != value {
+			deleteTags = append(deleteTags, encodeTag(key, value))
+		}
+	}
+
+	for key, value := range expected {
+		if currentValue, ok := current[key]; !ok || currentValue != value {
+			addTags = append(addTags, encodeTag(key, value))
+		}
+	}
+
+	sort.Strings(deleteTags)
+	sort.Strings(addTags)
+
+	return deleteTags, addTags
+}
+
+// diffSize estimates the amount of data a thin volume does not share with its origin.
+// LVM does not account exclusive blocks of thin volumes, so the difference in mapped data is used.
+func (md *metadata) diffSize(v *volume) uint64 {
+	origin, ok := md.volumes[v.LVOrigin]
+	if !ok {
+		return v.Mapped
+	}
+
+	originMapped := origin.MappedBytes()
+
+	if v.Mapped <= originMapped {
+		return 0
+	}
+
+	return v.Mapped - originMapped
+}
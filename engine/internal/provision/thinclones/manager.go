@@ -47,6 +47,7 @@ type SnapshotProperties struct {
 	Clones        string
 	ProtectedTill string
 	DeleteAt      string
+	WALPosition   string
 }
 
 // ProtectionProperties describes the protection-related properties of an entity.
@@ -0,0 +1,120 @@
+/*
+2026 © Postgres.ai
+*/
+
+package thinclones
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+// MetadataKind defines a kind of snapshot metadata: the directory keeping it and the extension of its files.
+type MetadataKind struct {
+	dir       string
+	extension string
+	title     string
+}
+
+var (
+	// MigrationMetadata keeps the DDL/SQL migrations recorded when committing clones.
+	MigrationMetadata = MetadataKind{dir: "migrations", extension: ".sql", title: "migration"}
+
+	// MaskingReportMetadata keeps the reports of the masking applied to the snapshot data.
+	MaskingReportMetadata = MetadataKind{dir: "masking", extension: ".json", title: "masking report"}
+)
+
+// metadataKinds lists the kinds removed along with the snapshot.
+var metadataKinds = []MetadataKind{MigrationMetadata, MaskingReportMetadata}
+
+// MetadataStore keeps the metadata of snapshots that is too large for snapshot properties,
+// one file per snapshot and kind, and looks it up by snapshot ID.
+type MetadataStore struct {
+	dir string
+}
+
+// NewMetadataStore creates a new MetadataStore keeping files in the given directory.
+func NewMetadataStore(dir string) *MetadataStore {
+	return &MetadataStore{dir: dir}
+}
+
+// DefaultMetadataStore returns the store located in the engine meta directory.
+func DefaultMetadataStore() (*MetadataStore, error) {
+	dir, err := util.GetMetaPath("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get path of snapshot metadata: %w", err)
+	}
+
+	return NewMetadataStore(dir), nil
+}
+
+func (s *MetadataStore) filename(kind MetadataKind, snapshotID string) string {
+	return path.Join(s.dir, kind.dir, url.PathEscape(snapshotID)+kind.extension)
+}
+
+// Save records the metadata of the snapshot.
+func (s *MetadataStore) Save(kind MetadataKind, snapshotID string, data []byte) error {
+	if err := os.MkdirAll(path.Join(s.dir, kind.dir), 0700); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", kind.title, err)
+	}
+
+	if err := os.WriteFile(s.filename(kind, snapshotID), data, 0600); err != nil {
+		return fmt.Errorf("failed to save %s of snapshot %s: %w", kind.title, snapshotID, err)
+	}
+
+	return nil
+}
+
+// Load returns the metadata of the snapshot or nil if there is none.
+func (s *MetadataStore) Load(kind MetadataKind, snapshotID string) ([]byte, error) {
+	data, err := os.ReadFile(s.filename(kind, snapshotID))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read %s of snapshot %s: %w", kind.title, snapshotID, err)
+	}
+
+	return data, nil
+}
+
+// Delete removes metadata of all kinds recorded for the snapshots.
+func (s *MetadataStore) Delete(snapshotIDs ...string) error {
+	var errs []error
+
+	for _, snapshotID := range snapshotIDs {
+		for _, kind := range metadataKinds {
+			if err := os.Remove(s.filename(kind, snapshotID)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("failed to remove %s of snapshot %s: %w", kind.title, snapshotID, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// DeleteSnapshotMetadata removes the metadata of destroyed snapshots from the engine meta directory.
+// The snapshots are already gone, so failures are only logged.
+func DeleteSnapshotMetadata(snapshotIDs ...string) {
+	if len(snapshotIDs) == 0 {
+		return
+	}
+
+	store, err := DefaultMetadataStore()
+	if err != nil {
+		log.Err(err)
+		return
+	}
+
+	if err := store.Delete(snapshotIDs...); err != nil {
+		log.Err(err)
+	}
+}
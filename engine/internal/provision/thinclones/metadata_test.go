@@ -0,0 +1,37 @@
+package thinclones
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataStore(t *testing.T) {
+	store := NewMetadataStore(t.TempDir())
+	snapshotID := "pool1/pg14/branch/dev/clone1/r0@20240912082141"
+
+	migration, err := store.Load(MigrationMetadata, snapshotID)
+	require.NoError(t, err)
+	assert.Nil(t, migration)
+
+	require.NoError(t, store.Save(MigrationMetadata, snapshotID, []byte("alter table t1 add column c1 int")))
+	require.NoError(t, store.Save(MaskingReportMetadata, snapshotID, []byte(`{"database":"test"}`)))
+
+	migration, err = store.Load(MigrationMetadata, snapshotID)
+	require.NoError(t, err)
+	assert.Equal(t, "alter table t1 add column c1 int", string(migration))
+
+	report, err := store.Load(MaskingReportMetadata, snapshotID)
+	require.NoError(t, err)
+	assert.Equal(t, `{"database":"test"}`, string(report))
+
+	require.NoError(t, store.Delete(snapshotID))
+	require.NoError(t, store.Delete(snapshotID))
+
+	for _, kind := range metadataKinds {
+		data, err := store.Load(kind, snapshotID)
+		require.NoError(t, err)
+		assert.Nil(t, data)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+2026 © Postgres.ai
+*/
+
+package thinclones
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// RetentionPolicy defines a grandfather-father-son snapshot retention policy.
+// Each field is the number of the most recent hourly, daily, weekly and monthly periods
+// for which the newest snapshot is kept. Periods are evaluated over dataStateAt in UTC.
+type RetentionPolicy struct {
+	Hourly  int `yaml:"hourly" json:"hourly"`
+	Daily   int `yaml:"daily" json:"daily"`
+	Weekly  int `yaml:"weekly" json:"weekly"`
+	Monthly int `yaml:"monthly" json:"monthly"`
+}
+
+// Enabled reports whether at least one retention tier is configured.
+func (p RetentionPolicy) Enabled() bool {
+	return p.Hourly > 0 || p.Daily > 0 || p.Weekly > 0 || p.Monthly > 0
+}
+
+// Validate checks the retention policy.
+func (p RetentionPolicy) Validate() error {
+	if p.Hourly < 0 || p.Daily < 0 || p.Weekly < 0 || p.Monthly < 0 {
+		return fmt.Errorf("retention policy values must not be negative: %s", p)
+	}
+
+	return nil
+}
+
+// String returns a short description of the policy.
+func (p RetentionPolicy) String() string {
+	return fmt.Sprintf("hourly=%d daily=%d weekly=%d monthly=%d", p.Hourly, p.Daily, p.Weekly, p.Monthly)
+}
+
+// Expired returns IDs of snapshots the policy does not retain, oldest first.
+// The newest snapshot and snapshots without dataStateAt are always retained.
+func (p RetentionPolicy) Expired(snapshots []resources.Snapshot) []string {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	sorted := make([]resources.Snapshot, len(snapshots))
+	copy(sorted, snapshots)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].DataStateAt.After(sorted[j].DataStateAt)
+	})
+
+	keep := map[string]struct{}{sorted[0].ID: {}}
+
+	tiers := []struct {
+		limit  int
+		period func(time.Time) string
+	}{
+		{p.Hourly, func(t time.Time) string { return t.Format("2006-01-02T15") }},
+		{p.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{p.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{p.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+
+	for _, tier := range tiers {
+		if tier.limit <= 0 {
+			continue
+		}
+
+		lastPeriod := ""
+		periods := 0
+
+		for _, snapshot := range sorted {
+			if snapshot.DataStateAt.IsZero() {
+				continue
+			}
+
+			period := tier.period(snapshot.DataStateAt.UTC())
+			if period == lastPeriod {
+				continue
+			}
+
+			lastPeriod = period
+			periods++
+
+			if periods > tier.limit {
+				break
+			}
+
+			keep[snapshot.ID] = struct{}{}
+		}
+	}
+
+	expired := make([]string, 0, len(sorted))
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		snapshot := sorted[i]
+
+		if _, ok := keep[snapshot.ID]; ok || snapshot.DataStateAt.IsZero() {
+			continue
+		}
+
+		expired = append(expired, snapshot.ID)
+	}
+
+	return expired
+}
+
+// Select returns IDs of snapshots the policy does not retain, oldest first.
+// Snapshots of clones, snapshots not subject to retention in the given mode and busy snapshots are never selected.
+func (p RetentionPolicy) Select(snapshots []resources.Snapshot, mode models.RetrievalMode, busy []string) []string {
+	return ExcludeSnapshots(p.Expired(RetentionEntries(snapshots, mode)), busy)
+}
+
+// RetentionEntries returns snapshots subject to retention, preserving the order.
+// Snapshots of clones are skipped; in physical mode, only pre-snapshots are subject to retention.
+func RetentionEntries(snapshots []resources.Snapshot, mode models.RetrievalMode) []resources.Snapshot {
+	selected := make([]resources.Snapshot, 0, len(snapshots))
+
+	for _, snapshot := range snapshots {
+		if strings.Contains(snapshot.ID, "clone") {
+			continue
+		}
+
+		if mode == models.Physical && !strings.HasSuffix(snapshot.ID, "_pre") {
+			continue
+		}
+
+		selected = append(selected, snapshot)
+	}
+
+	return selected
+}
+
+// ExcludeSnapshots returns names that are not in the busy list, preserving the order.
+func ExcludeSnapshots(names, busy []string) []string {
+	busySet := make(map[string]struct{}, len(busy))
+	for _, name := range busy {
+		busySet[name] = struct{}{}
+	}
+
+	candidates := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if _, ok := busySet[name]; ok {
+			continue
+		}
+
+		candidates = append(candidates, name)
+	}
+
+	return candidates
+}
@@ -0,0 +1,114 @@
+package thinclones
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func hourlySnapshots(from time.Time, count int) []resources.Snapshot {
+	snapshots := make([]resources.Snapshot, 0, count)
+
+	for i := 0; i < count; i++ {
+		dsa := from.Add(time.Duration(i) * time.Hour)
+		snapshots = append(snapshots, resources.Snapshot{ID: fmt.Sprintf("pool@snapshot_%s", dsa.Format("20060102150405")), DataStateAt: dsa})
+	}
+
+	return snapshots
+}
+
+func TestRetentionPolicyValidate(t *testing.T) {
+	require.NoError(t, RetentionPolicy{Hourly: 24, Daily: 7}.Validate())
+	require.NoError(t, RetentionPolicy{}.Validate())
+	require.Error(t, RetentionPolicy{Daily: -1}.Validate())
+
+	assert.True(t, RetentionPolicy{Monthly: 1}.Enabled())
+	assert.False(t, RetentionPolicy{}.Enabled())
+}
+
+func TestRetentionPolicyExpired(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("hourly tier keeps the newest snapshots", func(t *testing.T) {
+		snapshots := hourlySnapshots(start, 5)
+
+		expired := RetentionPolicy{Hourly: 3}.Expired(snapshots)
+
+		assert.Equal(t, []string{snapshots[0].ID, snapshots[1].ID}, expired)
+	})
+
+	t.Run("daily tier keeps the newest snapshot of each day", func(t *testing.T) {
+		snapshots := hourlySnapshots(start, 72)
+
+		expired := RetentionPolicy{Daily: 2}.Expired(snapshots)
+
+		assert.Len(t, expired, 70)
+		assert.NotContains(t, expired, snapshots[71].ID)
+		assert.NotContains(t, expired, snapshots[47].ID)
+		assert.Contains(t, expired, snapshots[23].ID)
+	})
+
+	t.Run("tiers are combined", func(t *testing.T) {
+		snapshots := hourlySnapshots(start, 24*60)
+
+		expired := RetentionPolicy{Hourly: 24, Daily: 7, Weekly: 4, Monthly: 6}.Expired(snapshots)
+
+		kept := make(map[string]struct{}, len(snapshots))
+		for _, snapshot := range snapshots {
+			kept[snapshot.ID] = struct{}{}
+		}
+
+		for _, id := range expired {
+			delete(kept, id)
+		}
+
+		// Tiers overlap: the newest snapshot of a day is also the newest one of its hour.
+		assert.Contains(t, kept, snapshots[len(snapshots)-1].ID)
+		assert.Contains(t, kept, snapshots[24*31-1].ID, "newest snapshot of January must be kept")
+		assert.NotContains(t, kept, snapshots[0].ID)
+		assert.Less(t, len(kept), 24+7+4+6)
+	})
+
+	t.Run("snapshots without dataStateAt are kept", func(t *testing.T) {
+		snapshots := append(hourlySnapshots(start, 3), resources.Snapshot{ID: "pool@unknown"})
+
+		expired := RetentionPolicy{Hourly: 1}.Expired(snapshots)
+
+		assert.Equal(t, []string{snapshots[0].ID, snapshots[1].ID}, expired)
+	})
+
+	t.Run("newest snapshot is always kept", func(t *testing.T) {
+		snapshots := hourlySnapshots(start, 2)
+
+		expired := RetentionPolicy{}.Expired(snapshots)
+
+		assert.Equal(t, []string{snapshots[0].ID}, expired)
+	})
+}
+
+func TestRetentionPolicySelect(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := []resources.Snapshot{
+		{ID: "dblab_pool@snapshot_20260101000000_pre", DataStateAt: start},
+		{ID: "dblab_pool/branch/main/clone_pre_20260101000000/r0@snapshot_20260101000000", DataStateAt: start},
+		{ID: "dblab_pool@snapshot_20260102000000_pre", DataStateAt: start.AddDate(0, 0, 1)},
+		{ID: "dblab_pool@snapshot_20260102000000", DataStateAt: start.AddDate(0, 0, 1)},
+		{ID: "dblab_pool@snapshot_20260103000000_pre", DataStateAt: start.AddDate(0, 0, 2)},
+	}
+
+	entries := RetentionEntries(snapshots, models.Physical)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "dblab_pool@snapshot_20260101000000_pre", entries[0].ID)
+	assert.Equal(t, "dblab_pool@snapshot_20260103000000_pre", entries[2].ID)
+
+	assert.Len(t, RetentionEntries(snapshots, models.Logical), 4)
+
+	selected := RetentionPolicy{Daily: 1}.Select(snapshots, models.Physical, []string{"dblab_pool@snapshot_20260101000000_pre"})
+	assert.Equal(t, []string{"dblab_pool@snapshot_20260102000000_pre"}, selected)
+}
@@ -25,6 +25,7 @@ const (
 	messageProp       = "dle:message"
 	protectedTillProp = "dle:protected_till"
 	deleteAtProp      = "dle:delete_at"
+	walPositionProp   = "dle:wal_position"
 	branchSep         = ","
 	empty             = "-"
 )
@@ -508,7 +509,7 @@ func (m *Manager) listBranches() (map[string]string, error) {
 
 var repoFields = []any{
 	"name", parentProp, childProp, branchProp, rootProp, dataStateAtLabel, messageProp, "clones",
-	protectedTillProp, deleteAtProp,
+	protectedTillProp, deleteAtProp, walPositionProp,
 }
 
 // GetRepo provides repository details about snapshots and branches filtered by data pool.
@@ -661,6 +662,7 @@ func (m *Manager) GetSnapshotProperties(snapshotName string) (thinclones.Snapsho
 		Clones:        strings.Trim(fields[7], empty),
 		ProtectedTill: strings.Trim(fields[8], empty),
 		DeleteAt:      strings.Trim(fields[9], empty),
+		WALPosition:   strings.Trim(fields[10], empty),
 	}
 
 	return properties, nil
@@ -719,6 +721,11 @@ func (m *Manager) SetMessage(message, snapshotName string) error {
 	return m.setProperty(messageProp, encodedMessage, snapshotName)
 }
 
+// SetWALPosition records the WAL location the data of the snapshot has been replayed to.
+func (m *Manager) SetWALPosition(lsn, snapshotName string) error {
+	return m.setProperty(walPositionProp, lsn, snapshotName)
+}
+
 // HasDependentEntity gets the root property of the snapshot.
 func (m *Manager) HasDependentEntity(snapshotName string) ([]string, error) {
 	root, err := m.getProperty(rootProp, snapshotName)
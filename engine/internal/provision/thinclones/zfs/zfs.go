@@ -542,6 +542,7 @@ func (m *Manager) DestroySnapshot(snapshotName string, opts thinclones.DestroyOp
 	}
 
 	m.removeSnapshotFromList(snapshotName)
+	thinclones.DeleteSnapshotMetadata(snapshotName)
 
 	return nil
 }
@@ -636,38 +637,26 @@ func (m *Manager) checkDependentClones(snapshotName string) (string, error) {
 
 // CleanupSnapshots destroys old snapshots considering retention limit and related clones.
 func (m *Manager) CleanupSnapshots(retentionLimit int, mode models.RetrievalMode) ([]string, error) {
-	clonesCmd := fmt.Sprintf("zfs list -S clones -o name,origin -H -r %s", m.config.Pool.Name)
-
-	clonesOutput, err := m.runner.Run(clonesCmd)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to list snapshots")
-	}
-
-	busySnapshots := m.getBusySnapshotList(clonesOutput)
-
-	branchHeads, err := m.getBranchHeadSnapshots()
+	clonesOutput, err := m.listClonesOrigins()
 	if err != nil {
-		return nil, fmt.Errorf("failed to determine protected snapshots: %w", err)
+		return nil, err
 	}
 
-	busySnapshots = append(busySnapshots, branchHeads...)
-
-	protectedSnapshots, err := m.getProtectedSnapshots()
+	busySnapshots, err := m.getCleanupBusySnapshots(clonesOutput)
 	if err != nil {
 		return nil, err
 	}
 
-	busySnapshots = append(busySnapshots, protectedSnapshots...)
-
 	modeFilter := ""
 
 	if mode == models.Physical {
 		modeFilter = "| grep _pre$"
 	}
 
+	// Each destroyed snapshot is printed, so its metadata can be removed as well.
 	cleanupCmd := fmt.Sprintf(
 		"zfs list -t snapshot -H -o name -s %s -s creation -r %s | grep -v clone %s | head -n -%d %s"+
-			"| xargs -n1 --no-run-if-empty zfs destroy -R ",
+			"| xargs -n1 --no-run-if-empty sh -c 'zfs destroy -R \"$0\" && echo \"$0\"'",
 		dataStateAtLabel, m.config.Pool.Name, modeFilter, retentionLimit, excludeBusySnapshots(busySnapshots))
 
 	out, err := m.runner.Run(cleanupCmd)
@@ -677,11 +666,121 @@ func (m *Manager) CleanupSnapshots(retentionLimit int, mode models.RetrievalMode
 		return nil, errors.Wrap(err, "failed to clean up snapshots")
 	}
 
-	if err := m.cleanupEmptyDatasets(clonesOutput); err != nil {
-		return nil, fmt.Errorf("failed to clean up empty datasets: %w", err)
+	destroyed := strings.Fields(out)
+	thinclones.DeleteSnapshotMetadata(destroyed...)
+
+	if err := m.finishCleanup(clonesOutput); err != nil {
+		return nil, err
 	}
 
-	lines := strings.Split(out, "\n")
+	return destroyed, nil
+}
+
+// ApplyRetentionPolicy destroys snapshots the retention policy does not keep, considering related clones.
+// In dry-run mode, it only returns the snapshots that would be destroyed.
+func (m *Manager) ApplyRetentionPolicy(policy thinclones.RetentionPolicy, mode models.RetrievalMode, dryRun bool) ([]string, error) {
+	clonesOutput, err := m.listClonesOrigins()
+	if err != nil {
+		return nil, err
+	}
+
+	busySnapshots, err := m.getCleanupBusySnapshots(clonesOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := m.listDetails(snapshotFilter{
+		fields:  defaultFields,
+		sorting: defaultSorting,
+		pool:    m.config.Pool.Name,
+		dsType:  snapshotType,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list snapshots")
+	}
+
+	snapshots := make([]resources.Snapshot, 0, len(entries))
+
+	for _, entry := range entries {
+		snapshots = append(snapshots, resources.Snapshot{ID: entry.Name, DataStateAt: entry.DataStateAt})
+	}
+
+	candidates := policy.Select(snapshots, mode, busySnapshots)
+
+	if dryRun {
+		return candidates, nil
+	}
+
+	destroyed, err := m.destroySnapshots(candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.finishCleanup(clonesOutput); err != nil {
+		return nil, err
+	}
+
+	return destroyed, nil
+}
+
+// destroySnapshots destroys the snapshots along with their dependents and removes their metadata.
+func (m *Manager) destroySnapshots(snapshots []string) ([]string, error) {
+	destroyed := make([]string, 0, len(snapshots))
+
+	defer func() {
+		thinclones.DeleteSnapshotMetadata(destroyed...)
+	}()
+
+	for _, snapshotID := range snapshots {
+		if out, err := m.runner.Run("zfs destroy -R " + snapshotID); err != nil {
+			log.Dbg(out)
+
+			return nil, errors.Wrap(err, "failed to clean up snapshots")
+		}
+
+		destroyed = append(destroyed, snapshotID)
+	}
+
+	return destroyed, nil
+}
+
+func (m *Manager) listClonesOrigins() (string, error) {
+	clonesCmd := fmt.Sprintf("zfs list -S clones -o name,origin -H -r %s", m.config.Pool.Name)
+
+	clonesOutput, err := m.runner.Run(clonesCmd)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list snapshots")
+	}
+
+	return clonesOutput, nil
+}
+
+// getCleanupBusySnapshots returns snapshots that must survive cleanup: the ones used by clones,
+// branch heads and protected snapshots along with the origins of their datasets.
+func (m *Manager) getCleanupBusySnapshots(clonesOutput string) ([]string, error) {
+	busySnapshots := m.getBusySnapshotList(clonesOutput)
+
+	branchHeads, err := m.getBranchHeadSnapshots()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine protected snapshots: %w", err)
+	}
+
+	busySnapshots = append(busySnapshots, branchHeads...)
+
+	protectedSnapshots, err := m.getProtectedSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	busySnapshots = append(busySnapshots, withOrigins(clonesOutput, protectedSnapshots)...)
+
+	return busySnapshots, nil
+}
+
+func (m *Manager) finishCleanup(clonesOutput string) error {
+	if err := m.cleanupEmptyDatasets(clonesOutput); err != nil {
+		return fmt.Errorf("failed to clean up empty datasets: %w", err)
+	}
 
 	m.RefreshSnapshotList()
 
@@ -695,7 +794,39 @@ func (m *Manager) CleanupSnapshots(retentionLimit int, mode models.RetrievalMode
 
 	m.reviewParentProperty(firstSnapshotID)
 
-	return lines, nil
+	return nil
+}
+
+// withOrigins returns the snapshots along with the origins of their datasets,
+// so that protecting a promoted snapshot also keeps the snapshot it was cloned from.
+func withOrigins(clonesOutput string, snapshots []string) []string {
+	origins := make(map[string]string)
+
+	for _, line := range strings.Split(clonesOutput, "\n") {
+		fields := strings.FieldsFunc(line, unicode.IsSpace)
+		if len(fields) != 2 || fields[1] == "-" {
+			continue
+		}
+
+		origins[fields[0]] = fields[1]
+	}
+
+	result := make([]string, 0, 2*len(snapshots))
+
+	for _, snapshotID := range snapshots {
+		result = append(result, snapshotID)
+
+		dataset, _, found := strings.Cut(snapshotID, "@")
+		if !found {
+			continue
+		}
+
+		if origin, ok := origins[dataset]; ok {
+			result = append(result, origin)
+		}
+	}
+
+	return result
 }
 
 func (m *Manager) reviewParentProperty(snapshotID string) {
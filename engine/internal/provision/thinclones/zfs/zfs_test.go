@@ -560,7 +560,7 @@ func TestGetRepo(t *testing.T) {
 		},
 		{
 			name:          "all fields populated",
-			output:        "pool@snap1\tparent1\tchild1\tmain\troot1\t20250101\t" + msg + "\t-\t-\t-",
+			output:        "pool@snap1\tparent1\tchild1\tmain\troot1\t20250101\t" + msg + "\t-\t-\t-\t-",
 			wantSnapshots: 1,
 			wantBranches:  map[string]string{"main": "pool@snap1"},
 		},
@@ -572,14 +572,14 @@ func TestGetRepo(t *testing.T) {
 		},
 		{
 			name:          "base64 commit message decodes correctly",
-			output:        "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t-\t-",
+			output:        "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t-\t-\t-",
 			wantSnapshots: 1,
 			wantBranches:  map[string]string{"main": "pool@snap1"},
 		},
 		{
 			name: "multiple snapshots with branches",
-			output: "pool@snap1\t-\tpool@snap2\tmain\troot1\t20250101\t" + msg + "\t-\t-\t-\n" +
-				"pool@snap2\tpool@snap1\t-\tfeature\troot1\t20250102\t" + msg + "\t-\t-\t-",
+			output: "pool@snap1\t-\tpool@snap2\tmain\troot1\t20250101\t" + msg + "\t-\t-\t-\t-\n" +
+				"pool@snap2\tpool@snap1\t-\tfeature\troot1\t20250102\t" + msg + "\t-\t-\t-\t-",
 			wantSnapshots: 2,
 			wantBranches:  map[string]string{"main": "pool@snap1", "feature": "pool@snap2"},
 		},
@@ -615,11 +615,11 @@ func TestGetRepoProtection(t *testing.T) {
 		wantProtectedTill bool
 		wantDeleteAt      bool
 	}{
-		{name: "no protection", output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t-\t-", wantProtected: false},
-		{name: "timed protection", output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t2026-06-17T14:30:00Z\t-", wantProtected: true, wantProtectedTill: true},
-		{name: "indefinite protection (forever)", output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\tforever\t-", wantProtected: true, wantProtectedTill: false},
-		{name: "scheduled deletion", output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t-\t2026-06-18T00:00:00Z", wantDeleteAt: true},
-		{name: "malformed protected_till is treated as unprotected", output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t2026-13-99\t-", wantProtected: false, wantProtectedTill: false},
+		{name: "no protection", output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t-\t-\t-", wantProtected: false},
+		{name: "timed protection", output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t2026-06-17T14:30:00Z\t-\t-", wantProtected: true, wantProtectedTill: true},
+		{name: "indefinite protection (forever)", output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\tforever\t-\t-", wantProtected: true, wantProtectedTill: false},
+		{name: "scheduled deletion", output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t-\t2026-06-18T00:00:00Z\t-", wantDeleteAt: true},
+		{name: "malformed protected_till is treated as unprotected", output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t2026-13-99\t-\t-", wantProtected: false, wantProtectedTill: false},
 	}
 
 	for _, tc := range testCases {
@@ -648,7 +648,7 @@ func TestGetSnapshotProperties(t *testing.T) {
 	}{
 		{
 			name:   "well-formed output",
-			output: "pool@snap1\tparent1\tchild1\tmain\troot1\t20250101\t" + msg + "\tclone1\t-\t-",
+			output: "pool@snap1\tparent1\tchild1\tmain\troot1\t20250101\t" + msg + "\tclone1\t-\t-\t-",
 			expected: thinclones.SnapshotProperties{
 				Name: "pool@snap1", Parent: "parent1", Child: "child1", Branch: "main",
 				Root: "root1", DataStateAt: "20250101", Message: "test message", Clones: "clone1",
@@ -656,7 +656,7 @@ func TestGetSnapshotProperties(t *testing.T) {
 		},
 		{
 			name:   "field containing spaces is preserved",
-			output: "pool@snap1\tparent with spaces\tchild1\tmain\troot1\t20250101\t" + msg + "\tclone1\t-\t-",
+			output: "pool@snap1\tparent with spaces\tchild1\tmain\troot1\t20250101\t" + msg + "\tclone1\t-\t-\t-",
 			expected: thinclones.SnapshotProperties{
 				Name: "pool@snap1", Parent: "parent with spaces", Child: "child1", Branch: "main",
 				Root: "root1", DataStateAt: "20250101", Message: "test message", Clones: "clone1",
@@ -664,12 +664,19 @@ func TestGetSnapshotProperties(t *testing.T) {
 		},
 		{
 			name:   "protection properties are parsed",
-			output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t2026-06-17T14:30:00Z\t2026-06-18T00:00:00Z",
+			output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t2026-06-17T14:30:00Z\t2026-06-18T00:00:00Z\t-",
 			expected: thinclones.SnapshotProperties{
 				Name: "pool@snap1", Branch: "main", DataStateAt: "20250101", Message: "test message",
 				ProtectedTill: "2026-06-17T14:30:00Z", DeleteAt: "2026-06-18T00:00:00Z",
 			},
 		},
+		{
+			name:   "WAL position is parsed",
+			output: "pool@snap1\t-\t-\tmain\t-\t20250101\t" + msg + "\t-\t-\t-\t16/B374D848",
+			expected: thinclones.SnapshotProperties{
+				Name: "pool@snap1", Branch: "main", DataStateAt: "20250101", Message: "test message", WALPosition: "16/B374D848",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -961,3 +968,20 @@ func countSetCmds(cmds []string) int {
 
 	return count
 }
+
+func TestWithOrigins(t *testing.T) {
+	clonesOutput := `dblab_pool	-
+dblab_pool/branch/main/clone_pre_20260101000000/r0	dblab_pool@snapshot_20260101000000_pre
+dblab_pool/branch/main/clone_pre_20260102000000/r0	dblab_pool@snapshot_20260102000000_pre`
+
+	protected := withOrigins(clonesOutput, []string{
+		"dblab_pool/branch/main/clone_pre_20260101000000/r0@snapshot_20260101000000",
+		"dblab_pool@snapshot_20260103000000",
+	})
+
+	assert.Equal(t, []string{
+		"dblab_pool/branch/main/clone_pre_20260101000000/r0@snapshot_20260101000000",
+		"dblab_pool@snapshot_20260101000000_pre",
+		"dblab_pool@snapshot_20260103000000",
+	}, protected)
+}
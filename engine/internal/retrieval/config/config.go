@@ -37,4 +37,7 @@ type JobConfig struct {
 	Docker *client.Client
 	Marker *dbmarker.Marker
 	FSPool *resources.Pool
+	// SnapshotReady is called by snapshot jobs when a new data state of the pool is available for cloning,
+	// including snapshots taken on schedule.
+	SnapshotReady func()
 }
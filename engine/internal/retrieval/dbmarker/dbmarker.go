@@ -121,6 +121,31 @@ func (m *Marker) SaveConfig(cfg *Config) error {
 	return os.WriteFile(m.buildFileName(configFilename), configData, 0600)
 }
 
+// SaveReport stores a report on the data preparation, such as masking, next to the DBMarker config,
+// so the report is kept in the snapshots of the data.
+func (m *Marker) SaveReport(name string, report interface{}) error {
+	if err := m.initDBLabDirectory(); err != nil {
+		return errors.Wrap(err, "failed to init DBMarker")
+	}
+
+	reportData, err := yaml.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(m.buildFileName(name), reportData, 0600)
+}
+
+// GetReport loads a report stored by SaveReport.
+func (m *Marker) GetReport(name string, report interface{}) error {
+	reportData, err := os.ReadFile(m.buildFileName(name))
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(reportData, report)
+}
+
 // buildFileName builds a DBMarker filename.
 func (m *Marker) buildFileName(filename string) string {
 	return path.Join(m.dataPath, configDir, filename)
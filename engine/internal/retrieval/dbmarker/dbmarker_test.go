@@ -57,6 +57,27 @@ func TestMarker_GetConfig_MissingFile(t *testing.T) {
 	assert.True(t, os.IsNotExist(err))
 }
 
+func TestMarker_SaveAndGetReport(t *testing.T) {
+	type report struct {
+		Tables []string `yaml:"tables"`
+	}
+
+	dataDir := t.TempDir()
+	m := NewMarker(dataDir)
+
+	require.NoError(t, m.SaveReport("masking", report{Tables: []string{"public.users"}}))
+	assert.FileExists(t, path.Join(dataDir, configDir, "masking"))
+
+	var loaded report
+
+	require.NoError(t, m.GetReport("masking", &loaded))
+	assert.Equal(t, []string{"public.users"}, loaded.Tables)
+
+	err := m.GetReport("missing", &loaded)
+	require.Error(t, err)
+	assert.True(t, os.IsNotExist(err))
+}
+
 func TestMarker_InitBranching(t *testing.T) {
 	tmpDir := t.TempDir()
 	m := NewMarker(tmpDir)
@@ -59,6 +59,9 @@ func (s *JobBuilder) BuildJob(jobCfg config.JobConfig) (components.JobRunner, er
 
 	case snapshot.PhysicalSnapshotType:
 		return snapshot.NewPhysicalInitialJob(jobCfg, s.globalCfg, s.engineProps, s.cloneManager, s.tm)
+
+	case snapshot.LogicalReplicationType:
+		return snapshot.NewLogicalReplicationJob(jobCfg, s.globalCfg, s.engineProps, s.cloneManager, s.tm)
 	}
 
 	return nil, errors.Errorf("unknown job type: %q", jobCfg.Spec.Name)
@@ -5,7 +5,12 @@
 package logical
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"strconv"
 )
 
 type compressionType string
@@ -14,8 +19,39 @@ const (
 	noCompression    compressionType = "no"
 	gzipCompression  compressionType = "gzip"
 	bzip2Compression compressionType = "bzip2"
+	zstdCompression  compressionType = "zstd"
+	lz4Compression   compressionType = "lz4"
 )
 
+// defaultGzipLevel is the default zlib compression level.
+const defaultGzipLevel = 6
+
+// compressionLevels defines the ranges of compression levels supported by pg_dump.
+var compressionLevels = map[compressionType][2]int{
+	gzipCompression: {1, 9},
+	zstdCompression: {1, 22},
+	lz4Compression:  {1, 12},
+}
+
+// compressionExtensions defines file extensions added to plain-text dumps compressed by pg_dump.
+var compressionExtensions = map[compressionType]string{
+	gzipCompression:  ".gz",
+	bzip2Compression: ".bz2",
+	zstdCompression:  ".zst",
+	lz4Compression:   ".lz4",
+}
+
+// compressionMagics defines the leading bytes of compressed files.
+var compressionMagics = []struct {
+	compression compressionType
+	magic       []byte
+}{
+	{compression: gzipCompression, magic: []byte{0x1f, 0x8b}},
+	{compression: bzip2Compression, magic: []byte("BZh")},
+	{compression: zstdCompression, magic: []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{compression: lz4Compression, magic: []byte{0x04, 0x22, 0x4d, 0x18}},
+}
+
 // getReadingArchiveCommand chooses command to read dump file.
 func getReadingArchiveCommand(compressionType compressionType) string {
 	switch compressionType {
@@ -25,6 +61,12 @@ func getReadingArchiveCommand(compressionType compressionType) string {
 	case bzip2Compression:
 		return "bunzip2 -c"
 
+	case zstdCompression:
+		return "zstd -dc"
+
+	case lz4Compression:
+		return "lz4 -dc"
+
 	default:
 		return "cat"
 	}
@@ -39,7 +81,91 @@ func getCompressionType(filename string) compressionType {
 	case ".bz2":
 		return bzip2Compression
 
+	case ".zst", ".zstd":
+		return zstdCompression
+
+	case ".lz4":
+		return lz4Compression
+
 	default:
 		return noCompression
 	}
 }
+
+// detectCompressionType identifies the archive type of the file by its magic bytes,
+// falling back to the filename extension if the file cannot be read.
+func detectCompressionType(filename string) compressionType {
+	f, err := os.Open(filename)
+	if err != nil {
+		return getCompressionType(filename)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	header := make([]byte, 4)
+
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return getCompressionType(filename)
+	}
+
+	for _, m := range compressionMagics {
+		if bytes.HasPrefix(header[:n], m.magic) {
+			return m.compression
+		}
+	}
+
+	return noCompression
+}
+
+// validateDumpCompression checks that pg_dump can write dumps with the compression method and level.
+func validateDumpCompression(compression compressionType, level int) error {
+	switch compression {
+	case "", noCompression:
+		if level != 0 {
+			return fmt.Errorf("compression level cannot be set without a compression method")
+		}
+
+		return nil
+
+	case bzip2Compression:
+		return fmt.Errorf("pg_dump does not support %s compression; use gzip, zstd or lz4", compression)
+	}
+
+	levels, ok := compressionLevels[compression]
+	if !ok {
+		return fmt.Errorf("unknown compression method %q; use gzip, zstd, lz4 or no", compression)
+	}
+
+	if level != 0 && (level < levels[0] || level > levels[1]) {
+		return fmt.Errorf("%s compression level must be between %d and %d", compression, levels[0], levels[1])
+	}
+
+	return nil
+}
+
+// getCompressOption builds the pg_dump option to compress the dump. An empty compression method keeps the pg_dump default.
+// Gzip is specified with a bare level to support pg_dump versions prior to 16; zstd and lz4 require pg_dump 16+.
+func getCompressOption(compression compressionType, level int) string {
+	switch compression {
+	case "":
+		return ""
+
+	case noCompression:
+		return "--compress=0"
+
+	case gzipCompression:
+		if level == 0 {
+			level = defaultGzipLevel
+		}
+
+		return "--compress=" + strconv.Itoa(level)
+
+	default:
+		if level == 0 {
+			return "--compress=" + string(compression)
+		}
+
+		return fmt.Sprintf("--compress=%s:%d", compression, level)
+	}
+}
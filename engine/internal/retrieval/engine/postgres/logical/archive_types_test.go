@@ -5,9 +5,12 @@
 package logical
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestReadingArchiveCommand(t *testing.T) {
@@ -23,6 +26,14 @@ func TestReadingArchiveCommand(t *testing.T) {
 			compressionType: bzip2Compression,
 			expectedCommand: "bunzip2 -c",
 		},
+		{
+			compressionType: zstdCompression,
+			expectedCommand: "zstd -dc",
+		},
+		{
+			compressionType: lz4Compression,
+			expectedCommand: "lz4 -dc",
+		},
 		{
 			compressionType: noCompression,
 			expectedCommand: "cat",
@@ -52,6 +63,14 @@ func TestArchiveType(t *testing.T) {
 			filename:                "dump.bz2",
 			expectedCompressionType: bzip2Compression,
 		},
+		{
+			filename:                "dump.sql.zst",
+			expectedCompressionType: zstdCompression,
+		},
+		{
+			filename:                "dump.lz4",
+			expectedCompressionType: lz4Compression,
+		},
 		{
 			filename:                "test.dmp",
 			expectedCompressionType: noCompression,
@@ -67,3 +86,102 @@ func TestArchiveType(t *testing.T) {
 		assert.Equal(t, tc.expectedCompressionType, compressionType)
 	}
 }
+
+func TestDetectCompressionType(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		filename                string
+		content                 []byte
+		expectedCompressionType compressionType
+	}{
+		{
+			filename:                "gzip.sql",
+			content:                 []byte{0x1f, 0x8b, 0x08, 0x00},
+			expectedCompressionType: gzipCompression,
+		},
+		{
+			filename:                "bzip2.sql",
+			content:                 []byte("BZh91AY"),
+			expectedCompressionType: bzip2Compression,
+		},
+		{
+			filename:                "zstd.sql",
+			content:                 []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00},
+			expectedCompressionType: zstdCompression,
+		},
+		{
+			filename:                "lz4.sql",
+			content:                 []byte{0x04, 0x22, 0x4d, 0x18, 0x64},
+			expectedCompressionType: lz4Compression,
+		},
+		{
+			filename:                "plain.sql.gz",
+			content:                 []byte("--\n-- PostgreSQL database dump\n"),
+			expectedCompressionType: noCompression,
+		},
+		{
+			filename:                "short.sql",
+			content:                 []byte{0x1f},
+			expectedCompressionType: noCompression,
+		},
+	}
+
+	for _, tc := range testCases {
+		filename := filepath.Join(dir, tc.filename)
+		require.NoError(t, os.WriteFile(filename, tc.content, 0600))
+
+		assert.Equal(t, tc.expectedCompressionType, detectCompressionType(filename), tc.filename)
+	}
+
+	assert.Equal(t, zstdCompression, detectCompressionType(filepath.Join(dir, "missing.sql.zst")))
+}
+
+func TestValidateDumpCompression(t *testing.T) {
+	testCases := []struct {
+		compression compressionType
+		level       int
+		wantErr     bool
+	}{
+		{compression: "", level: 0},
+		{compression: noCompression, level: 0},
+		{compression: noCompression, level: 3, wantErr: true},
+		{compression: gzipCompression, level: 9},
+		{compression: gzipCompression, level: 10, wantErr: true},
+		{compression: zstdCompression, level: 22},
+		{compression: zstdCompression, level: 23, wantErr: true},
+		{compression: lz4Compression, level: 0},
+		{compression: lz4Compression, level: -1, wantErr: true},
+		{compression: bzip2Compression, level: 0, wantErr: true},
+		{compression: "xz", level: 0, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		err := validateDumpCompression(tc.compression, tc.level)
+		if tc.wantErr {
+			assert.Error(t, err, "%s:%d", tc.compression, tc.level)
+		} else {
+			assert.NoError(t, err, "%s:%d", tc.compression, tc.level)
+		}
+	}
+}
+
+func TestCompressOption(t *testing.T) {
+	testCases := []struct {
+		compression    compressionType
+		level          int
+		expectedOption string
+	}{
+		{compression: "", expectedOption: ""},
+		{compression: noCompression, expectedOption: "--compress=0"},
+		{compression: gzipCompression, expectedOption: "--compress=6"},
+		{compression: gzipCompression, level: 2, expectedOption: "--compress=2"},
+		{compression: zstdCompression, expectedOption: "--compress=zstd"},
+		{compression: zstdCompression, level: 19, expectedOption: "--compress=zstd:19"},
+		{compression: lz4Compression, level: 4, expectedOption: "--compress=lz4:4"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedOption, getCompressOption(tc.compression, tc.level))
+	}
+}
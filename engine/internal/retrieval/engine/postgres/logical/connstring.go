@@ -125,3 +125,23 @@ func sourcePgxConfig(connStr string, conn Connection, dbName string) (*pgx.ConnC
 
 	return cfg, nil
 }
+
+// sourceDatabase returns the source database the replication slot is created in: the configured one or,
+// for sources defined only by a connection string, the database the string targets. As in libpq, a
+// connection string without a database targets the database named after the user.
+func sourceDatabase(connStr string, conn Connection) (string, error) {
+	if conn.DBName != "" || connStr == "" {
+		return conn.DBName, nil
+	}
+
+	cfg, err := pgx.ParseConfig(connStr)
+	if err != nil {
+		return "", fmt.Errorf("parse source connection string: %w", err)
+	}
+
+	if cfg.Database != "" {
+		return cfg.Database, nil
+	}
+
+	return cfg.User, nil
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -30,6 +31,8 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/cont"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/defaults"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/health"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/objstore"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/subset"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/options"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/probe"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
@@ -62,8 +65,15 @@ const (
 	customFormat    = "custom"
 	plainFormat     = "plain"
 	directoryFormat = "directory"
+
+	// File extensions of dumps in the custom and plain formats.
+	customDumpExtension = ".dump"
+	plainDumpExtension  = ".sql"
 )
 
+// replicationSlotRegexp matches names allowed for replication slots.
+var replicationSlotRegexp = regexp.MustCompile(`^[a-z0-9_]{1,63}$`)
+
 // DumpJob declares a job for logical dumping.
 type DumpJob struct {
 	name         string
@@ -73,6 +83,7 @@ type DumpJob struct {
 	engineProps  *global.EngineProps
 	config       dumpJobConfig
 	dumper       dumper
+	storage      objstore.Storage
 	dbMarker     *dbmarker.Marker
 	dbMark       *dbmarker.Config
 	DumpOptions
@@ -89,6 +100,19 @@ type DumpOptions struct {
 	ParallelJobs    int                       `yaml:"parallelJobs"`
 	Restore         ImmediateRestore          `yaml:"immediateRestore"`
 	CustomOptions   []string                  `yaml:"customOptions"`
+
+	// ReplicationSlot defines a logical replication slot created in the source database before the dump.
+	// The database is dumped from the snapshot exported by the slot, so a subscription using the slot
+	// continues exactly where the dump ends.
+	ReplicationSlot string `yaml:"replicationSlot"`
+
+	// Format, Compression and CompressionLevel apply to databases that do not define their own.
+	Format           string          `yaml:"format"`
+	Compression      compressionType `yaml:"compression"`
+	CompressionLevel int             `yaml:"compressionLevel"`
+
+	// ObjectStorage defines an S3-compatible object storage to upload dumps to.
+	ObjectStorage *objstore.Config `yaml:"objectStorage"`
 }
 
 // Source describes source of data to dump.
@@ -111,11 +135,15 @@ type Source struct {
 
 // DumpDefinition describes a database for dumping.
 type DumpDefinition struct {
-	Tables        []string        `yaml:"tables"`
-	ExcludeTables []string        `yaml:"excludeTables"`
-	Format        string          `yaml:"format"`
-	Compression   compressionType `yaml:"compression"`
-	dbName        string
+	Tables           []string        `yaml:"tables"`
+	ExcludeTables    []string        `yaml:"excludeTables"`
+	Format           string          `yaml:"format"`
+	Compression      compressionType `yaml:"compression"`
+	CompressionLevel int             `yaml:"compressionLevel"`
+	// Subset defines root tables to extract a referentially consistent part of the database instead of dumping it whole.
+	Subset   *subset.Config `yaml:"subset"`
+	dbName   string
+	snapshot string
 }
 
 type dumpJobConfig struct {
@@ -181,9 +209,115 @@ func (d *DumpJob) validate() error {
 Either set 'numberOfJobs' equals to 1 or disable the restore section`)
 	}
 
+	if d.Restore.Enabled && d.ObjectStorage.Enabled() {
+		return errors.New("dumps cannot be uploaded to the object storage when the immediate restore is enabled")
+	}
+
+	if err := validateDumpDefinition(d.withDumpDefaults(DumpDefinition{})); err != nil {
+		return err
+	}
+
+	for dbName, definition := range d.Databases {
+		if err := validateDumpDefinition(d.withDumpDefaults(definition)); err != nil {
+			return errors.Wrapf(err, "invalid dump definition of the database %s", dbName)
+		}
+
+		if definition.Subset != nil && d.DumpOptions.DumpLocation == "" {
+			return errors.Errorf("dumpLocation is required to extract a subset of the database %s", dbName)
+		}
+	}
+
+	return d.validateReplicationSlot()
+}
+
+func (d *DumpJob) validateReplicationSlot() error {
+	if d.DumpOptions.ReplicationSlot == "" {
+		return nil
+	}
+
+	if !replicationSlotRegexp.MatchString(d.DumpOptions.ReplicationSlot) {
+		return errors.Errorf("invalid replication slot name %q: it may contain only lower case letters, digits and underscores",
+			d.DumpOptions.ReplicationSlot)
+	}
+
+	dbName, err := sourceDatabase(d.DumpOptions.Source.ConnectionString, d.DumpOptions.Source.Connection)
+	if err != nil {
+		return err
+	}
+
+	if dbName == "" {
+		return errors.New("the source database is required to create the replication slot")
+	}
+
+	if len(d.Databases) == 0 {
+		return nil
+	}
+
+	definition, ok := d.Databases[dbName]
+	if !ok {
+		return errors.Errorf("the source database %s, where the replication slot is created, must be dumped", dbName)
+	}
+
+	if definition.Subset != nil {
+		return errors.Errorf("the source database %s cannot be dumped as a subset when the replication slot is used", dbName)
+	}
+
 	return nil
 }
 
+func validateDumpDefinition(definition DumpDefinition) error {
+	switch definition.Format {
+	case "", directoryFormat, customFormat, plainFormat:
+	default:
+		return errors.Errorf("unknown dump format %q; use directory, custom or plain", definition.Format)
+	}
+
+	if definition.Subset != nil {
+		if len(definition.Tables) > 0 || len(definition.ExcludeTables) > 0 {
+			return errors.New("subset cannot be combined with tables or excludeTables")
+		}
+
+		if err := definition.Subset.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return validateDumpCompression(definition.Compression, definition.CompressionLevel)
+}
+
+// withDumpDefaults fills the format and compression of the database definition from the job options.
+func (d *DumpJob) withDumpDefaults(definition DumpDefinition) DumpDefinition {
+	if definition.Format == "" {
+		definition.Format = d.DumpOptions.Format
+	}
+
+	if definition.Format == "" {
+		definition.Format = directoryFormat
+	}
+
+	if definition.Compression == "" {
+		definition.Compression = d.DumpOptions.Compression
+		definition.CompressionLevel = d.DumpOptions.CompressionLevel
+	}
+
+	return definition
+}
+
+// dumpOutputPath returns the path of the dump file or directory of the database.
+func (d *DumpJob) dumpOutputPath(dbName string, definition DumpDefinition) string {
+	outputPath := path.Join(d.DumpOptions.DumpLocation, dbName)
+
+	switch definition.Format {
+	case customFormat:
+		return outputPath + customDumpExtension
+
+	case plainFormat:
+		return outputPath + plainDumpExtension + compressionExtensions[definition.Compression]
+	}
+
+	return outputPath
+}
+
 func (d *DumpJob) setDefaults() {
 	// TODO: Default yaml values in tags.
 	if d.DumpOptions.Source.Connection.Port == 0 {
@@ -251,6 +385,24 @@ func (d *DumpJob) Reload(cfg map[string]interface{}) (err error) {
 
 	d.setDefaults()
 
+	return d.setupStorage()
+}
+
+// setupStorage creates a client of the object storage to upload dumps to, if it is configured.
+func (d *DumpJob) setupStorage() error {
+	d.storage = nil
+
+	if !d.DumpOptions.ObjectStorage.Enabled() {
+		return nil
+	}
+
+	storage, err := objstore.NewS3(*d.DumpOptions.ObjectStorage)
+	if err != nil {
+		return errors.Wrap(err, "failed to set up the object storage")
+	}
+
+	d.storage = storage
+
 	return nil
 }
 
@@ -414,12 +566,39 @@ func (d *DumpJob) Run(ctx context.Context) (err error) {
 		return err
 	}
 
+	if d.DumpOptions.ReplicationSlot != "" {
+		slot, slotErr := d.createReplicationSlot(ctx)
+		if slotErr != nil {
+			return errors.Wrap(slotErr, "failed to create the replication slot")
+		}
+
+		defer func() { slot.release(err) }()
+
+		if dbList, err = withSnapshot(dbList, slot.database, slot.snapshot); err != nil {
+			return err
+		}
+	}
+
 	for dbName, dbDetails := range dbList {
+		if dbDetails.Subset.Enabled() {
+			if err := d.dumpSubset(ctx, containerID, dbName, dbDetails); err != nil {
+				return errors.Wrapf(err, "failed to extract a subset of the database %s", dbName)
+			}
+
+			continue
+		}
+
 		if err := d.dumpDatabase(ctx, containerID, dbName, dbDetails); err != nil {
 			return errors.Wrapf(err, "failed to dump the database %s", dbName)
 		}
 	}
 
+	if d.storage != nil {
+		if err := d.uploadDumps(ctx, dbList); err != nil {
+			return errors.Wrap(err, "failed to upload dumps to the object storage")
+		}
+	}
+
 	if d.DumpOptions.Restore.Enabled {
 		if err := d.markDatabaseData(); err != nil {
 			return errors.Wrap(err, "failed to mark the created dump")
@@ -451,6 +630,25 @@ func (d *DumpJob) Run(ctx context.Context) (err error) {
 	return nil
 }
 
+// withSnapshot returns a copy of the database list where the database is dumped from the exported snapshot.
+func withSnapshot(dbList map[string]DumpDefinition, dbName, snapshot string) (map[string]DumpDefinition, error) {
+	definition, ok := dbList[dbName]
+	if !ok {
+		return nil, errors.Errorf("the source database %s, where the replication slot is created, is not in the list of dumped databases", dbName)
+	}
+
+	snapshotList := make(map[string]DumpDefinition, len(dbList))
+
+	for name, details := range dbList {
+		snapshotList[name] = details
+	}
+
+	definition.snapshot = snapshot
+	snapshotList[dbName] = definition
+
+	return snapshotList, nil
+}
+
 func collectDiagnostics(ctx context.Context, client *client.Client, postgresName, dataDir string) {
 	filterArgs := filters.NewArgs(
 		filters.KeyValuePair{Key: "label",
@@ -523,8 +721,9 @@ func (d *DumpJob) cleanupDumpLocation(ctx context.Context, dumpContID string, db
 
 	cleanupCmd := []string{"rm", "-rf"}
 
-	for dbName := range dbList {
-		cleanupCmd = append(cleanupCmd, path.Join(d.DumpOptions.DumpLocation, dbName))
+	for dbName, definition := range dbList {
+		outputPath := d.dumpOutputPath(dbName, d.withDumpDefaults(definition))
+		cleanupCmd = append(cleanupCmd, outputPath, outputPath+subset.ReportSuffix, d.subsetWorkDir(dbName))
 	}
 
 	log.Msg("Running cleanup command: ", cleanupCmd)
@@ -736,6 +935,8 @@ func (d *DumpJob) getExecEnvironmentVariables() []string {
 }
 
 func (d *DumpJob) buildLogicalDumpCommand(dbName string, dump DumpDefinition) ([]string, error) {
+	dump = d.withDumpDefaults(dump)
+
 	// don't use map here, it creates inconsistency in the order of arguments
 	dumpCmd := []string{"pg_dump", "--create"}
 
@@ -749,10 +950,15 @@ func (d *DumpJob) buildLogicalDumpCommand(dbName string, dump DumpDefinition) ([
 
 	dumpCmd = append(dumpCmd, connArgs...)
 
-	if d.DumpOptions.ParallelJobs > 0 {
+	// Only the directory format supports parallel dumps.
+	if d.DumpOptions.ParallelJobs > 0 && (dump.Format == directoryFormat || d.DumpOptions.Restore.Enabled) {
 		dumpCmd = append(dumpCmd, "--jobs", strconv.Itoa(d.DumpOptions.ParallelJobs))
 	}
 
+	if dump.snapshot != "" {
+		dumpCmd = append(dumpCmd, "--snapshot", dump.snapshot)
+	}
+
 	for _, table := range dump.Tables {
 		dumpCmd = append(dumpCmd, "--table", table)
 	}
@@ -774,7 +980,11 @@ func (d *DumpJob) buildLogicalDumpCommand(dbName string, dump DumpDefinition) ([
 		return []string{"sh", "-c", cmd}, nil
 	}
 
-	dumpCmd = append(dumpCmd, "--format", directoryFormat, "--file", path.Join(d.DumpOptions.DumpLocation, dbName))
+	if compressOption := getCompressOption(dump.Compression, dump.CompressionLevel); compressOption != "" {
+		dumpCmd = append(dumpCmd, compressOption)
+	}
+
+	dumpCmd = append(dumpCmd, "--format", dump.Format, "--file", d.dumpOutputPath(dbName, dump))
 
 	return dumpCmd, nil
 }
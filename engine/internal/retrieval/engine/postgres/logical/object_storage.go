@@ -0,0 +1,196 @@
+/*
+2026 © Postgres.ai
+*/
+
+package logical
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/objstore"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/subset"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+// storedDump describes a dump found in an object storage: a single file or a directory with a table of contents.
+type storedDump struct {
+	key          string
+	isDir        bool
+	objects      []objstore.Object
+	lastModified time.Time
+}
+
+// findLatestDump groups the objects into dumps and returns the most recently modified one.
+// Checksum and subset report sidecar objects are not considered dumps.
+func findLatestDump(objects []objstore.Object) (storedDump, bool) {
+	dirDumps := make(map[string]*storedDump)
+
+	for _, obj := range objects {
+		if dir := path.Dir(obj.Key); path.Base(obj.Key) == dumpMetafile && dir != "." {
+			dirDumps[dir] = &storedDump{key: dir, isDir: true}
+		}
+	}
+
+	var candidates []*storedDump
+
+	for _, dump := range dirDumps {
+		candidates = append(candidates, dump)
+	}
+
+	for _, obj := range objects {
+		if strings.HasSuffix(obj.Key, "/") || strings.HasSuffix(obj.Key, objstore.ChecksumSuffix) ||
+			strings.HasSuffix(obj.Key, subset.ReportSuffix) {
+			continue
+		}
+
+		dump := findDirDump(dirDumps, obj.Key)
+		if dump == nil {
+			dump = &storedDump{key: obj.Key}
+			candidates = append(candidates, dump)
+		}
+
+		dump.objects = append(dump.objects, obj)
+
+		if obj.LastModified.After(dump.lastModified) {
+			dump.lastModified = obj.LastModified
+		}
+	}
+
+	var latest *storedDump
+
+	for _, dump := range candidates {
+		if latest == nil || dump.lastModified.After(latest.lastModified) ||
+			dump.lastModified.Equal(latest.lastModified) && dump.key > latest.key {
+			latest = dump
+		}
+	}
+
+	if latest == nil {
+		return storedDump{}, false
+	}
+
+	return *latest, true
+}
+
+// findDirDump returns the directory dump containing the object key, if any.
+func findDirDump(dirDumps map[string]*storedDump, key string) *storedDump {
+	for dir := path.Dir(key); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if dump, ok := dirDumps[dir]; ok {
+			return dump
+		}
+	}
+
+	return nil
+}
+
+// fetchDump downloads the latest dump from the object storage to the dump location and returns its local name.
+func (r *RestoreJob) fetchDump(ctx context.Context) (string, error) {
+	storageCfg := r.RestoreOptions.ObjectStorage
+
+	objects, err := r.storage.List(ctx, storageCfg.Prefix)
+	if err != nil {
+		return "", err
+	}
+
+	dump, ok := findLatestDump(objects)
+	if !ok {
+		return "", errors.Errorf("no dumps found in bucket %q with prefix %q", storageCfg.Bucket, storageCfg.Prefix)
+	}
+
+	checksums := make(map[string]struct{})
+	hasSubsetReport := false
+
+	for _, obj := range objects {
+		if strings.HasSuffix(obj.Key, objstore.ChecksumSuffix) {
+			checksums[obj.Key] = struct{}{}
+		}
+
+		if obj.Key == dump.key+subset.ReportSuffix {
+			hasSubsetReport = true
+		}
+	}
+
+	dumpName := path.Base(dump.key)
+	localPath := filepath.Join(r.RestoreOptions.DumpLocation, dumpName)
+
+	if err := os.RemoveAll(localPath); err != nil {
+		return "", errors.Wrap(err, "failed to clean up the previously downloaded dump")
+	}
+
+	log.Msg(fmt.Sprintf("Downloading the dump %q (%d objects) to %s", dump.key, len(dump.objects), localPath))
+
+	for _, obj := range dump.objects {
+		dst := localPath
+
+		if dump.isDir {
+			relPath := strings.TrimPrefix(obj.Key, dump.key+"/")
+			if !filepath.IsLocal(relPath) {
+				return "", errors.Errorf("invalid object key %q", obj.Key)
+			}
+
+			dst = filepath.Join(localPath, relPath)
+		}
+
+		var checksum string
+
+		if _, ok := checksums[obj.Key+objstore.ChecksumSuffix]; ok {
+			if checksum, err = objstore.ReadChecksum(ctx, r.storage, obj.Key+objstore.ChecksumSuffix); err != nil {
+				return "", err
+			}
+		}
+
+		if err := objstore.Download(ctx, r.storage, obj.Key, dst, checksum); err != nil {
+			return "", err
+		}
+	}
+
+	if hasSubsetReport {
+		if err := objstore.Download(ctx, r.storage, dump.key+subset.ReportSuffix, localPath+subset.ReportSuffix, ""); err != nil {
+			return "", err
+		}
+	}
+
+	log.Msg("The dump has been downloaded and verified: ", dumpName)
+
+	return dumpName, nil
+}
+
+// uploadDumps copies the dumps of the databases to the object storage. Dumps of the same run share a timestamp
+// in their keys, so the latest dump of a database can be found by the "<prefix>/<database>/" prefix.
+func (d *DumpJob) uploadDumps(ctx context.Context, dbList map[string]DumpDefinition) error {
+	timestamp := time.Now().UTC().Format(tools.DataStateAtFormat)
+
+	for dbName, definition := range dbList {
+		definition = d.withDumpDefaults(definition)
+		outputPath := d.dumpOutputPath(dbName, definition)
+		key := d.DumpOptions.ObjectStorage.Key(dbName, timestamp, path.Base(outputPath))
+
+		log.Msg(fmt.Sprintf("Uploading the dump of the database %q to %q", dbName, key))
+
+		upload := objstore.Upload
+		if definition.Format == directoryFormat {
+			upload = objstore.UploadDir
+		}
+
+		if err := upload(ctx, d.storage, outputPath, key); err != nil {
+			return errors.Wrapf(err, "failed to upload the dump of the database %s", dbName)
+		}
+
+		if definition.Subset.Enabled() {
+			if err := objstore.Upload(ctx, d.storage, outputPath+subset.ReportSuffix, key+subset.ReportSuffix); err != nil {
+				return errors.Wrapf(err, "failed to upload the subset report of the database %s", dbName)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package logical
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/objstore"
+)
+
+type fakeStorage struct {
+	objects []objstore.Object
+	data    map[string][]byte
+}
+
+func (f *fakeStorage) List(_ context.Context, prefix string) ([]objstore.Object, error) {
+	var objects []objstore.Object
+
+	for _, obj := range f.objects {
+		if strings.HasPrefix(obj.Key, prefix) {
+			objects = append(objects, obj)
+		}
+	}
+
+	return objects, nil
+}
+
+func (f *fakeStorage) Get(_ context.Context, key string) (io.ReadCloser, objstore.Object, error) {
+	for _, obj := range f.objects {
+		if obj.Key == key {
+			return io.NopCloser(bytes.NewReader(f.data[key])), obj, nil
+		}
+	}
+
+	return nil, objstore.Object{}, objstore.ErrNotFound
+}
+
+func (f *fakeStorage) Put(_ context.Context, key string, body io.Reader, metadata map[string]string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	if f.data == nil {
+		f.data = make(map[string][]byte)
+	}
+
+	f.data[key] = data
+	f.objects = append(f.objects, objstore.Object{Key: key, Metadata: metadata, LastModified: time.Now()})
+
+	return nil
+}
+
+func (f *fakeStorage) add(key string, data []byte, modified time.Time) {
+	if f.data == nil {
+		f.data = make(map[string][]byte)
+	}
+
+	f.data[key] = data
+	f.objects = append(f.objects, objstore.Object{Key: key, LastModified: modified})
+}
+
+func TestFindLatestDump(t *testing.T) {
+	day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	t.Run("no dumps", func(t *testing.T) {
+		_, ok := findLatestDump([]objstore.Object{{Key: "dumps/shop.dump.sha256"}, {Key: "dumps/"}, {Key: "dumps/shop.dump.subset.yml"}})
+		assert.False(t, ok)
+	})
+
+	t.Run("latest file", func(t *testing.T) {
+		dump, ok := findLatestDump([]objstore.Object{
+			{Key: "dumps/shop-1.dump", LastModified: day},
+			{Key: "dumps/shop-2.dump", LastModified: day.Add(time.Hour)},
+			{Key: "dumps/shop-2.dump.sha256", LastModified: day.Add(2 * time.Hour)},
+		})
+		require.True(t, ok)
+		assert.Equal(t, "dumps/shop-2.dump", dump.key)
+		assert.False(t, dump.isDir)
+		assert.Len(t, dump.objects, 1)
+	})
+
+	t.Run("latest directory", func(t *testing.T) {
+		dump, ok := findLatestDump([]objstore.Object{
+			{Key: "dumps/shop/1/shop.dump", LastModified: day},
+			{Key: "dumps/shop/2/shop/toc.dat", LastModified: day.Add(time.Hour)},
+			{Key: "dumps/shop/2/shop/3001.dat.gz", LastModified: day.Add(2 * time.Hour)},
+			{Key: "dumps/shop/2/shop/blobs/blob_1.dat", LastModified: day.Add(time.Hour)},
+		})
+		require.True(t, ok)
+		assert.Equal(t, "dumps/shop/2/shop", dump.key)
+		assert.True(t, dump.isDir)
+		assert.Len(t, dump.objects, 3)
+		assert.Equal(t, day.Add(2*time.Hour), dump.lastModified)
+	})
+}
+
+func TestFetchDump(t *testing.T) {
+	toc, data := []byte("toc"), []byte("table data")
+	modified := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	storage := &fakeStorage{}
+	storage.add("dumps/shop/1/shop.dump", []byte("old"), modified)
+	storage.add("dumps/shop/2/shop/toc.dat", toc, modified.Add(time.Hour))
+	storage.add("dumps/shop/2/shop/3001.dat", data, modified.Add(time.Hour))
+
+	sum := sha256.Sum256(data)
+	storage.add("dumps/shop/2/shop/3001.dat.sha256", []byte(hex.EncodeToString(sum[:])+"  3001.dat\n"), modified)
+
+	dumpLocation := t.TempDir()
+	job := &RestoreJob{
+		storage: storage,
+		RestoreOptions: RestoreOptions{
+			DumpLocation:  dumpLocation,
+			ObjectStorage: &objstore.Config{Bucket: "backups", Prefix: "dumps/shop/"},
+		},
+	}
+
+	dumpName, err := job.fetchDump(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "shop", dumpName)
+	assert.True(t, job.storedDumpIsDir(dumpName))
+
+	downloaded, err := os.ReadFile(filepath.Join(dumpLocation, "shop", "3001.dat"))
+	require.NoError(t, err)
+	assert.Equal(t, data, downloaded)
+
+	storage.data["dumps/shop/2/shop/3001.dat"] = []byte("corrupted")
+
+	_, err = job.fetchDump(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+}
+
+func TestUploadDumps(t *testing.T) {
+	dumpLocation := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dumpLocation, "shop.dump"), []byte("PGDMP"), 0600))
+
+	storage := &fakeStorage{}
+	job := &DumpJob{
+		storage: storage,
+		DumpOptions: DumpOptions{
+			DumpLocation:  dumpLocation,
+			Format:        customFormat,
+			ObjectStorage: &objstore.Config{Bucket: "backups", Prefix: "dumps"},
+		},
+	}
+
+	require.NoError(t, job.uploadDumps(context.Background(), map[string]DumpDefinition{"shop": {}}))
+	require.Len(t, storage.objects, 1)
+
+	obj := storage.objects[0]
+	assert.Regexp(t, `^dumps/shop/\d{14}/shop\.dump$`, obj.Key)
+
+	sum := sha256.Sum256([]byte("PGDMP"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), obj.Metadata[objstore.ChecksumMetadataKey])
+}
+
+func TestDumpJobValidateObjectStorage(t *testing.T) {
+	job := DumpJob{DumpOptions: DumpOptions{
+		Restore:       ImmediateRestore{Enabled: true},
+		ObjectStorage: &objstore.Config{Bucket: "backups"},
+	}}
+
+	err := job.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "immediate restore")
+}
@@ -34,7 +34,9 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/cont"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/defaults"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/health"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/objstore"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/query"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/subset"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/options"
 
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
@@ -93,6 +95,7 @@ type RestoreJob struct {
 	dbMark            *dbmarker.Config
 	queryProcessor    *query.Processor
 	isDumpLocationDir bool
+	storage           objstore.Storage
 	RestoreOptions
 }
 
@@ -108,6 +111,7 @@ type RestoreOptions struct {
 	QueryPreprocessing query.PreprocessorCfg     `yaml:"queryPreprocessing"`
 	CustomOptions      []string                  `yaml:"customOptions"`
 	SkipPolicies       bool                      `yaml:"skipPolicies"`
+	ObjectStorage      *objstore.Config          `yaml:"objectStorage"`
 }
 
 // Partial defines tables and rules for a partial logical restore.
@@ -182,6 +186,32 @@ func (r *RestoreJob) Reload(cfg map[string]interface{}) (err error) {
 
 	r.isDumpLocationDir = stat.IsDir()
 
+	return r.setupStorage()
+}
+
+// setupStorage creates a client of the object storage to download dumps from, if it is configured.
+func (r *RestoreJob) setupStorage() error {
+	r.storage = nil
+
+	if !r.RestoreOptions.ObjectStorage.Enabled() {
+		return nil
+	}
+
+	if !r.isDumpLocationDir {
+		return errors.New("dumpLocation must be a directory to download dumps from the object storage")
+	}
+
+	if len(r.RestoreOptions.Databases) > 0 {
+		return errors.New("databases cannot be defined when restoring from the object storage")
+	}
+
+	storage, err := objstore.NewS3(*r.RestoreOptions.ObjectStorage)
+	if err != nil {
+		return errors.Wrap(err, "failed to set up the object storage")
+	}
+
+	r.storage = storage
+
 	return nil
 }
 
@@ -214,6 +244,14 @@ func (r *RestoreJob) Run(ctx context.Context) (err error) {
 		return errors.Wrap(err, "failed to scan image pulling response")
 	}
 
+	var fetchedDump string
+
+	if r.storage != nil {
+		if fetchedDump, err = r.fetchDump(ctx); err != nil {
+			return errors.Wrap(err, "failed to fetch the dump from the object storage")
+		}
+	}
+
 	hostConfig, err := r.buildHostConfig(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to build container host config")
@@ -272,7 +310,7 @@ func (r *RestoreJob) Run(ctx context.Context) (err error) {
 		return errors.Wrap(err, "failed to run preprocessing queries")
 	}
 
-	dbList, err := r.getDBList(ctx, containerID)
+	dbList, err := r.getDBList(ctx, containerID, fetchedDump)
 	if err != nil {
 		return err
 	}
@@ -307,6 +345,16 @@ func (r *RestoreJob) Run(ctx context.Context) (err error) {
 		return errors.Wrap(err, "failed to stop Postgres instance")
 	}
 
+	if fetchedDump != "" {
+		if err := os.RemoveAll(path.Join(r.RestoreOptions.DumpLocation, fetchedDump)); err != nil {
+			log.Warn("Failed to remove the downloaded dump: ", err)
+		}
+
+		if err := os.RemoveAll(path.Join(r.RestoreOptions.DumpLocation, fetchedDump) + subset.ReportSuffix); err != nil {
+			log.Warn("Failed to remove the downloaded subset report: ", err)
+		}
+	}
+
 	log.Msg("Restoring job has been finished")
 
 	return nil
@@ -336,11 +384,20 @@ func (r *RestoreJob) buildHostConfig(ctx context.Context) (*container.HostConfig
 	return hostConfig, nil
 }
 
-func (r *RestoreJob) getDBList(ctx context.Context, contID string) (map[string]DumpDefinition, error) {
+func (r *RestoreJob) getDBList(ctx context.Context, contID, fetchedDump string) (map[string]DumpDefinition, error) {
 	if len(r.Databases) > 0 {
 		return r.Databases, nil
 	}
 
+	if fetchedDump != "" {
+		dumpDefinition, ok := r.exploreDumpEntry(ctx, contID, fetchedDump, r.storedDumpIsDir(fetchedDump))
+		if !ok {
+			return nil, errors.Errorf("failed to find a database to restore in the downloaded dump %s", fetchedDump)
+		}
+
+		return map[string]DumpDefinition{fetchedDump: dumpDefinition}, nil
+	}
+
 	if !r.isDumpLocationDir {
 		dbDefinition, err := r.exploreDumpFile(ctx, contID, r.RestoreOptions.DumpLocation)
 		if err != nil {
@@ -381,15 +438,16 @@ func (r *RestoreJob) exploreDumpFile(ctx context.Context, contID, dumpPath strin
 	// Identify type of compression if plain-text dump is archived.
 	dbDefinition := &DumpDefinition{
 		Format:      plainFormat,
-		Compression: getCompressionType(dumpPath),
+		Compression: detectCompressionType(dumpPath),
 	}
 
+	// Extract database name from plain-text dump.
 	if dbDefinition.Compression != noCompression {
-		return dbDefinition, nil
+		dbName, err = r.parseCompressedPlainFile(ctx, contID, dumpPath, dbDefinition.Compression)
+	} else {
+		dbName, err = r.parsePlainFile(dumpPath)
 	}
 
-	// Extract database name from plain-text dump.
-	dbName, err = r.parsePlainFile(dumpPath)
 	if err != nil {
 		if errors.Is(err, errDBNameNotFound) {
 			return dbDefinition, nil
@@ -432,10 +490,35 @@ func (r *RestoreJob) parsePlainFile(dumpPath string) (string, error) {
 
 	defer func() { _ = f.Close() }()
 
+	return parsePlainDump(f, dumpPath)
+}
+
+// parseCompressedPlainFile decompresses the beginning of a compressed plain-text dump inside the container
+// to find the database name.
+func (r *RestoreJob) parseCompressedPlainFile(ctx context.Context, contID, dumpPath string,
+	compression compressionType) (string, error) {
+	parseCmd := fmt.Sprintf("%s %s | grep -m 1 -E '^(%s|%s)'",
+		getReadingArchiveCommand(compression), dumpPath, `\\connect `, prefixCreateTable)
+	log.Dbg("Parse compressed dump: ", parseCmd)
+
+	output, err := tools.ExecCommandWithOutput(ctx, r.dockerClient, contID, container.ExecOptions{
+		Cmd: []string{"bash", "-c", parseCmd},
+	})
+	if err != nil {
+		log.Dbg(fmt.Sprintf("Cannot find the database name in the compressed dump %s: %v", dumpPath, err))
+
+		return "", errDBNameNotFound
+	}
+
+	return parsePlainDump(strings.NewReader(output), dumpPath)
+}
+
+// parsePlainDump reads a plain-text dump until the database name is found.
+func parsePlainDump(reader io.Reader, dumpPath string) (string, error) {
 	connectPrefix := []byte(prefixConnectDB)
 	tablePrefix := []byte(prefixCreateTable)
 
-	sc := bufio.NewScanner(f)
+	sc := bufio.NewScanner(reader)
 
 	for sc.Scan() {
 		if bytes.HasPrefix(sc.Bytes(), connectPrefix) {
@@ -480,39 +563,51 @@ func (r *RestoreJob) discoverDumpLocation(ctx context.Context, contID string) (m
 	for _, info := range fileInfos {
 		log.Dbg("Explore: ", info.Name())
 
-		if info.IsDir() {
-			dumpDirectory := path.Join(r.RestoreOptions.DumpLocation, info.Name())
-
-			dumpDefinition, err := r.getDirectoryDumpDefinition(ctx, contID, dumpDirectory)
-			if err != nil {
-				log.Msg(fmt.Sprintf("Dump not found: %v. Skip directory: %s", err, info.Name()))
-				continue
-			}
-
+		if dumpDefinition, ok := r.exploreDumpEntry(ctx, contID, info.Name(), info.IsDir()); ok {
 			dbList[info.Name()] = dumpDefinition
+		}
+	}
 
-			log.Msg("Found the directory dump: ", info.Name())
+	return dbList, nil
+}
 
-			continue
-		}
+// exploreDumpEntry explores a file or a directory of the dump location to find a database to restore.
+func (r *RestoreJob) exploreDumpEntry(ctx context.Context, contID, name string, isDir bool) (DumpDefinition, bool) {
+	if isDir {
+		dumpDirectory := path.Join(r.RestoreOptions.DumpLocation, name)
 
-		dumpDefinition, err := r.exploreDumpFile(ctx, contID, path.Join(r.RestoreOptions.DumpLocation, info.Name()))
+		dumpDefinition, err := r.getDirectoryDumpDefinition(ctx, contID, dumpDirectory)
 		if err != nil {
-			log.Dbg(fmt.Sprintf("Skip file %q due to failure to find a database to restore: %v", info.Name(), err))
-			continue
+			log.Msg(fmt.Sprintf("Dump not found: %v. Skip directory: %s", err, name))
+			return DumpDefinition{}, false
 		}
 
-		if dumpDefinition == nil {
-			log.Dbg(fmt.Sprintf("Skip file %q because the database definition is empty", info.Name()))
-			continue
-		}
+		log.Msg("Found the directory dump: ", name)
 
-		dbList[info.Name()] = *dumpDefinition
+		return dumpDefinition, true
+	}
 
-		log.Msg(fmt.Sprintf("Found the %s dump file: %s", dumpDefinition.Format, info.Name()))
+	dumpDefinition, err := r.exploreDumpFile(ctx, contID, path.Join(r.RestoreOptions.DumpLocation, name))
+	if err != nil {
+		log.Dbg(fmt.Sprintf("Skip file %q due to failure to find a database to restore: %v", name, err))
+		return DumpDefinition{}, false
 	}
 
-	return dbList, nil
+	if dumpDefinition == nil {
+		log.Dbg(fmt.Sprintf("Skip file %q because the database definition is empty", name))
+		return DumpDefinition{}, false
+	}
+
+	log.Msg(fmt.Sprintf("Found the %s dump file: %s", dumpDefinition.Format, name))
+
+	return *dumpDefinition, true
+}
+
+// storedDumpIsDir checks if the dump downloaded to the dump location is a directory.
+func (r *RestoreJob) storedDumpIsDir(name string) bool {
+	stat, err := os.Stat(path.Join(r.RestoreOptions.DumpLocation, name))
+
+	return err == nil && stat.IsDir()
 }
 
 func (r *RestoreJob) getDirectoryDumpDefinition(ctx context.Context, contID, dumpDir string) (DumpDefinition, error) {
@@ -613,9 +708,38 @@ func (r *RestoreJob) restoreDB(ctx context.Context, contID, dbName string, dbDef
 		return errors.Wrap(err, "failed to mark the database")
 	}
 
+	if err := r.recordSubset(dbName); err != nil {
+		return errors.Wrap(err, "failed to record the subset report")
+	}
+
 	return nil
 }
 
+// recordSubset adds the report stored next to the dump of a database subset to the DBMarker metadata.
+func (r *RestoreJob) recordSubset(dumpName string) error {
+	report, err := subset.LoadFile(r.subsetReportPath(dumpName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	log.Msg(fmt.Sprintf("The dump %q contains a subset of the database %q", dumpName, report.Database))
+
+	return subset.Record(r.dbMarker, report)
+}
+
+// subsetReportPath returns the path of the subset report of the dump.
+func (r *RestoreJob) subsetReportPath(dumpName string) string {
+	if !r.isDumpLocationDir {
+		return r.RestoreOptions.DumpLocation + subset.ReportSuffix
+	}
+
+	return path.Join(r.RestoreOptions.DumpLocation, dumpName) + subset.ReportSuffix
+}
+
 // prepareDB creates a new database if it does not exist in the dump file.
 func (r *RestoreJob) prepareDB(ctx context.Context, contID, dbName string) error {
 	log.Dbg("The dump has a plain-text format with an empty database name. Creating a database for the dump:", dbName)
@@ -712,6 +836,10 @@ func createTarArchive(tempFile *os.File, dstPath string) (io.Reader, error) {
 
 // formatDBName extracts a database name from a file name and adjusts it.
 func formatDBName(fileName string) string {
+	if getCompressionType(fileName) != noCompression {
+		fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	}
+
 	return filenameFormatter.ReplaceAllString(strings.TrimSuffix(fileName, filepath.Ext(fileName)), "_")
 }
 
@@ -831,9 +959,16 @@ func (r *RestoreJob) buildPlainTextCommand(dumpName string, definition DumpDefin
 		log.Msg("Parallel restore is not available for plain-text dump. It is always single-threaded")
 	}
 
+	dumpLocation := r.getDumpLocation(definition.Format, dumpName)
+
+	compression := definition.Compression
+	if compression == "" {
+		compression = detectCompressionType(dumpLocation)
+	}
+
 	return []string{
-		"sh", "-c", fmt.Sprintf("%s %s | psql --username %s --dbname %s", getReadingArchiveCommand(definition.Compression),
-			r.getDumpLocation(definition.Format, dumpName), r.globalCfg.Database.User(), dbName),
+		"sh", "-c", fmt.Sprintf("%s %s | psql --username %s --dbname %s", getReadingArchiveCommand(compression),
+			dumpLocation, r.globalCfg.Database.User(), dbName),
 	}
 }
 
@@ -151,6 +151,88 @@ func TestRestoreJobSetDefaults(t *testing.T) {
 	})
 }
 
+func TestDumpJobValidateCompression(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    DumpOptions
+		wantErr string
+	}{
+		{
+			name: "defaults with zstd",
+			opts: DumpOptions{Format: plainFormat, Compression: zstdCompression, CompressionLevel: 10},
+		},
+		{
+			name: "database with lz4",
+			opts: DumpOptions{Databases: map[string]DumpDefinition{"shop": {Format: customFormat, Compression: lz4Compression}}},
+		},
+		{
+			name:    "unknown format",
+			opts:    DumpOptions{Format: "tar"},
+			wantErr: "unknown dump format",
+		},
+		{
+			name:    "bzip2 is not supported by pg_dump",
+			opts:    DumpOptions{Compression: bzip2Compression},
+			wantErr: "does not support bzip2",
+		},
+		{
+			name:    "database level out of range",
+			opts:    DumpOptions{Databases: map[string]DumpDefinition{"shop": {Compression: gzipCompression, CompressionLevel: 12}}},
+			wantErr: "invalid dump definition of the database shop",
+		},
+		{
+			name: "replication slot in the dumped source database",
+			opts: DumpOptions{
+				ReplicationSlot: "dblab_slot",
+				Source:          Source{Connection: Connection{DBName: "shop"}},
+				Databases:       map[string]DumpDefinition{"shop": {}},
+			},
+		},
+		{
+			name:    "invalid replication slot name",
+			opts:    DumpOptions{ReplicationSlot: "DBLab Slot", Source: Source{Connection: Connection{DBName: "shop"}}},
+			wantErr: "invalid replication slot name",
+		},
+		{
+			name: "replication slot in the database of the connection string",
+			opts: DumpOptions{
+				ReplicationSlot: "dblab_slot",
+				Source:          Source{ConnectionString: "postgresql://app@db.example.com/shop"},
+				Databases:       map[string]DumpDefinition{"shop": {}},
+			},
+		},
+		{
+			name:    "replication slot without the source database",
+			opts:    DumpOptions{ReplicationSlot: "dblab_slot"},
+			wantErr: "the source database is required",
+		},
+		{
+			name: "replication slot in a database which is not dumped",
+			opts: DumpOptions{
+				ReplicationSlot: "dblab_slot",
+				Source:          Source{Connection: Connection{DBName: "postgres"}},
+				Databases:       map[string]DumpDefinition{"shop": {}},
+			},
+			wantErr: "must be dumped",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := DumpJob{DumpOptions: tc.opts}
+
+			err := job.validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
 func TestRestoreJobContainerName(t *testing.T) {
 	job := &RestoreJob{engineProps: &global.EngineProps{InstanceID: "xyz789"}}
 	assert.Equal(t, "dblab_lr_xyz789", job.restoreContainerName())
@@ -463,6 +545,47 @@ func TestDumpCommandBuilding(t *testing.T) {
 			},
 			command: []string{"sh", "-c", `pg_dump --create -d 'host=localhost port=5432 dbname=postgres sslmode=require connect_timeout=5 dbname='\''shop'\''' --jobs 1 --format custom | pg_restore --username postgres --dbname postgres --create --no-owner`},
 		},
+		{
+			copyOptions: DumpOptions{
+				ParallelJobs: 2,
+				DumpLocation: "/tmp/db.dump",
+				Source:       Source{ConnectionString: "postgres://john@localhost:5432/postgres"},
+				Databases:    map[string]DumpDefinition{"shop": {Format: plainFormat}},
+				Compression:  zstdCompression,
+			},
+			command: []string{"pg_dump", "--create", "-d", "postgres://john@localhost:5432/shop", "--compress=zstd", "--format", "plain", "--file", "/tmp/db.dump/shop.sql.zst"},
+		},
+		{
+			copyOptions: DumpOptions{
+				ParallelJobs: 2,
+				DumpLocation: "/tmp/db.dump",
+				Source:       Source{ConnectionString: "postgres://john@localhost:5432/postgres"},
+				Databases:    map[string]DumpDefinition{"shop": {Compression: lz4Compression, CompressionLevel: 9}},
+				Format:       customFormat,
+				Compression:  gzipCompression,
+			},
+			command: []string{"pg_dump", "--create", "-d", "postgres://john@localhost:5432/shop", "--compress=lz4:9", "--format", "custom", "--file", "/tmp/db.dump/shop.dump"},
+		},
+		{
+			copyOptions: DumpOptions{
+				ParallelJobs: 2,
+				DumpLocation: "/tmp/db.dump",
+				Source:       Source{ConnectionString: "postgres://john@localhost:5432/postgres"},
+				Databases:    map[string]DumpDefinition{"shop": {}},
+				Compression:  gzipCompression,
+			},
+			command: []string{"pg_dump", "--create", "-d", "postgres://john@localhost:5432/shop", "--jobs", "2", "--compress=6", "--format", "directory", "--file", "/tmp/db.dump/shop"},
+		},
+		{
+			copyOptions: DumpOptions{
+				ParallelJobs: 2,
+				DumpLocation: "/tmp/db.dump",
+				Source:       Source{ConnectionString: "postgres://john@localhost:5432/postgres"},
+				Databases:    map[string]DumpDefinition{"shop": {snapshot: "00000003-00000002-1"}},
+			},
+			command: []string{"pg_dump", "--create", "-d", "postgres://john@localhost:5432/shop", "--jobs", "2", "--snapshot", "00000003-00000002-1",
+				"--format", "directory", "--file", "/tmp/db.dump/shop"},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -721,6 +844,14 @@ func TestDBNameFormatter(t *testing.T) {
 			filename: "test-dump-2021-07.dump",
 			dbname:   "test_dump_2021_07",
 		},
+		{
+			filename: "test.sql.zst",
+			dbname:   "test",
+		},
+		{
+			filename: "test.lz4",
+			dbname:   "test",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,164 @@
+/*
+2026 © Postgres.ai
+*/
+
+package logical
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+const (
+	// replicationSlotPlugin defines the output plugin of the slot, which is used by subscriptions.
+	replicationSlotPlugin = "pgoutput"
+
+	// snapshotNameField defines the position of snapshot_name in the CREATE_REPLICATION_SLOT result.
+	snapshotNameField = 2
+
+	// staleSlotDropAttempts defines how many times dropping the stale slot is tried while it is still in use.
+	staleSlotDropAttempts = 5
+
+	// staleSlotDropInterval defines the pause between attempts to drop the stale slot.
+	staleSlotDropInterval = time.Second
+
+	// objectInUseCode defines the SQLSTATE reported when dropping a slot that is still active.
+	objectInUseCode = "55006"
+)
+
+// exportedSlot holds the replication connection that created a logical slot. The exported snapshot stays valid
+// until the connection is closed or runs another command, so it must be kept open while the dump is running.
+type exportedSlot struct {
+	name     string
+	database string
+	snapshot string
+	conn     *pgconn.PgConn
+}
+
+// createReplicationSlot creates a logical replication slot in the source database and exports its snapshot,
+// so the dump contains exactly the data preceding the first change streamed from the slot.
+func (d *DumpJob) createReplicationSlot(ctx context.Context) (*exportedSlot, error) {
+	conn := d.config.db
+	conn.Password = d.getPassword()
+
+	database, err := sourceDatabase(d.DumpOptions.Source.ConnectionString, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	pgxCfg, err := sourcePgxConfig(d.DumpOptions.Source.ConnectionString, conn, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build source connection config: %w", err)
+	}
+
+	pgxCfg.RuntimeParams["replication"] = "database"
+	pgxCfg.RuntimeParams["application_name"] = dleRetrieval
+
+	replConn, err := pgconn.ConnectConfig(ctx, &pgxCfg.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a replication connection: %w", err)
+	}
+
+	slotName := d.DumpOptions.ReplicationSlot
+
+	// The slot left by the previous data refresh points to a position preceding the new dump.
+	if err := dropStaleSlot(ctx, replConn, slotName); err != nil {
+		_ = replConn.Close(context.Background())
+		return nil, fmt.Errorf("failed to drop the stale replication slot: %w", err)
+	}
+
+	results, err := replConn.Exec(ctx, buildCreateSlotCommand(slotName)).ReadAll()
+	if err != nil {
+		_ = replConn.Close(context.Background())
+		return nil, fmt.Errorf("failed to create replication slot %q: %w", slotName, err)
+	}
+
+	if len(results) == 0 || len(results[0].Rows) == 0 || len(results[0].Rows[0]) <= snapshotNameField {
+		_ = replConn.Close(context.Background())
+		return nil, fmt.Errorf("unexpected result of creating replication slot %q", slotName)
+	}
+
+	slot := &exportedSlot{
+		name:     slotName,
+		database: database,
+		snapshot: string(results[0].Rows[0][snapshotNameField]),
+		conn:     replConn,
+	}
+
+	log.Msg(fmt.Sprintf("Replication slot %q has been created, exported snapshot: %s", slot.name, slot.snapshot))
+
+	return slot, nil
+}
+
+// release closes the replication connection. If the dump has failed, the slot is dropped
+// to stop retaining WAL on the source.
+func (s *exportedSlot) release(dumpErr error) {
+	if dumpErr != nil {
+		if _, err := s.conn.Exec(context.Background(), "DROP_REPLICATION_SLOT "+pgx.Identifier{s.name}.Sanitize()).ReadAll(); err != nil {
+			log.Err(fmt.Sprintf("failed to drop replication slot %q: %v", s.name, err))
+		}
+	}
+
+	if err := s.conn.Close(context.Background()); err != nil {
+		log.Err("failed to close the replication connection: ", err)
+	}
+}
+
+// dropStaleSlot drops the slot left by the previous data refresh. The subscription of the previous refresh
+// may still stream from it, so its walsender is terminated first. The subscription reconnects only after
+// a retry interval, which leaves time to drop the released slot; otherwise dropping is tried again.
+func dropStaleSlot(ctx context.Context, conn *pgconn.PgConn, slotName string) error {
+	var err error
+
+	for attempt := 0; attempt < staleSlotDropAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(staleSlotDropInterval):
+			}
+		}
+
+		if _, err = conn.Exec(ctx, buildTerminateSlotQuery(slotName)).ReadAll(); err != nil {
+			return fmt.Errorf("failed to terminate the process using the slot: %w", err)
+		}
+
+		if _, err = conn.Exec(ctx, buildDropStaleSlotQuery(slotName)).ReadAll(); err == nil {
+			return nil
+		}
+
+		// The walsender has not exited yet, or the subscription has already reconnected.
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != objectInUseCode {
+			return err
+		}
+	}
+
+	return err
+}
+
+func buildTerminateSlotQuery(slotName string) string {
+	return fmt.Sprintf("select pg_terminate_backend(active_pid) from pg_replication_slots where slot_name = '%s' and active",
+		quoteSlotName(slotName))
+}
+
+func buildDropStaleSlotQuery(slotName string) string {
+	return fmt.Sprintf("select pg_drop_replication_slot(slot_name) from pg_replication_slots where slot_name = '%s'",
+		quoteSlotName(slotName))
+}
+
+func quoteSlotName(slotName string) string {
+	return strings.ReplaceAll(slotName, "'", "''")
+}
+
+func buildCreateSlotCommand(slotName string) string {
+	return fmt.Sprintf("CREATE_REPLICATION_SLOT %s LOGICAL %s EXPORT_SNAPSHOT", pgx.Identifier{slotName}.Sanitize(), replicationSlotPlugin)
+}
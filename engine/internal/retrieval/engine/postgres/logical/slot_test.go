@@ -0,0 +1,54 @@
+package logical
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicationSlotCommands(t *testing.T) {
+	assert.Equal(t, `CREATE_REPLICATION_SLOT "dblab_slot" LOGICAL pgoutput EXPORT_SNAPSHOT`, buildCreateSlotCommand("dblab_slot"))
+	assert.Equal(t,
+		"select pg_terminate_backend(active_pid) from pg_replication_slots where slot_name = 'dblab_slot' and active",
+		buildTerminateSlotQuery("dblab_slot"))
+	assert.Equal(t,
+		"select pg_drop_replication_slot(slot_name) from pg_replication_slots where slot_name = 'dblab_slot'",
+		buildDropStaleSlotQuery("dblab_slot"))
+}
+
+func TestSourceDatabase(t *testing.T) {
+	testCases := []struct {
+		name    string
+		connStr string
+		conn    Connection
+		want    string
+	}{
+		{name: "configured database", conn: Connection{DBName: "shop"}, want: "shop"},
+		{name: "database of the connection string", connStr: "postgresql://app@db.example.com:5432/shop?sslmode=require", want: "shop"},
+		{name: "keyword/value connection string", connStr: "host=db.example.com user=app dbname=billing", want: "billing"},
+		{name: "connection string without a database", connStr: "host=db.example.com user=app", want: "app"},
+		{name: "no source database", want: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dbName, err := sourceDatabase(tc.connStr, tc.conn)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, dbName)
+		})
+	}
+}
+
+func TestWithSnapshot(t *testing.T) {
+	dbList := map[string]DumpDefinition{"shop": {}, "billing": {Format: customFormat}}
+
+	snapshotList, err := withSnapshot(dbList, "shop", "00000003-00000002-1")
+	require.NoError(t, err)
+	assert.Equal(t, "00000003-00000002-1", snapshotList["shop"].snapshot)
+	assert.Equal(t, DumpDefinition{Format: customFormat}, snapshotList["billing"])
+	assert.Empty(t, dbList["shop"].snapshot, "the configured list must not be modified")
+
+	_, err = withSnapshot(dbList, "postgres", "00000003-00000002-1")
+	assert.ErrorContains(t, err, "is not in the list of dumped databases")
+}
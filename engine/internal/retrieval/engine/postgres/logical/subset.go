@@ -0,0 +1,185 @@
+/*
+2026 © Postgres.ai
+*/
+
+package logical
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/defaults"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/subset"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+// subsetWorkDirSuffix defines the suffix of the directory keeping the extracted rows of a database.
+const subsetWorkDirSuffix = ".subset"
+
+// subsetWorkDir returns the directory to keep the extracted rows of the database until they are loaded.
+// The directory is in the dump location because both the engine and the dump container have access to it.
+func (d *DumpJob) subsetWorkDir(dbName string) string {
+	return path.Join(d.DumpOptions.DumpLocation, dbName+subsetWorkDirSuffix)
+}
+
+// dumpSubset extracts the rows selected by the root tables together with all rows they reference,
+// loads them into the database of the dump container and, unless the immediate restore is enabled,
+// dumps this database to the dump location, so the normal logical restore can pick it up.
+//
+// The schema and the rows are read from the same snapshot of the source.
+func (d *DumpJob) dumpSubset(ctx context.Context, dumpContID, dbName string, definition DumpDefinition) error {
+	definition = d.withDumpDefaults(definition)
+	workDir := d.subsetWorkDir(dbName)
+
+	if err := os.RemoveAll(workDir); err != nil {
+		return errors.Wrap(err, "failed to clean up the subset directory")
+	}
+
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return errors.Wrap(err, "failed to create the subset directory")
+	}
+
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			log.Warn("Failed to remove the subset directory: ", err)
+		}
+	}()
+
+	conn := d.config.db
+	conn.Password = d.getPassword()
+
+	pgxCfg, err := sourcePgxConfig(d.DumpOptions.Source.ConnectionString, conn, dbName)
+	if err != nil {
+		return fmt.Errorf("failed to build source connection config: %w", err)
+	}
+
+	querier, err := pgx.ConnectConfig(ctx, pgxCfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to DB: %w", err)
+	}
+
+	defer func() { _ = querier.Close(context.Background()) }()
+
+	// The transaction keeps the exported snapshot alive until the schema is dumped.
+	tx, err := querier.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("failed to start a transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var snapshotID string
+
+	if err := tx.QueryRow(ctx, "select pg_export_snapshot()").Scan(&snapshotID); err != nil {
+		return fmt.Errorf("failed to export the snapshot: %w", err)
+	}
+
+	log.Msg(fmt.Sprintf("Extracting a subset of the database %q from %d root tables", dbName, len(definition.Subset.Roots)))
+
+	report, err := subset.NewExtractor(tx, definition.Subset, workDir).Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	report.Database = dbName
+
+	commands, err := d.buildSubsetCommands(dbName, definition, snapshotID)
+	if err != nil {
+		return fmt.Errorf("failed to build subset commands: %w", err)
+	}
+
+	for _, cmd := range commands {
+		log.Msg("Running subset command: ", cmd)
+
+		output, err := d.performDumpCommand(ctx, dumpContID, container.ExecOptions{
+			Tty: true,
+			Cmd: cmd,
+			Env: d.getExecEnvironmentVariables(),
+		})
+		if err != nil {
+			log.Err("subset command failed: ", output)
+
+			return fmt.Errorf("failed to load the subset: %w. Output: %s", err, output)
+		}
+
+		if output != "" {
+			log.Dbg("Output of the subset command: ", output)
+		}
+	}
+
+	if d.DumpOptions.Restore.Enabled {
+		if err := subset.Record(d.dbMarker, report); err != nil {
+			return fmt.Errorf("failed to record the subset report: %w", err)
+		}
+	} else if err := subset.SaveFile(d.dumpOutputPath(dbName, definition)+subset.ReportSuffix, report); err != nil {
+		return fmt.Errorf("failed to save the subset report: %w", err)
+	}
+
+	log.Msg(fmt.Sprintf("Subset of the database %q has been extracted: %d tables", dbName, len(report.Tables)))
+
+	return nil
+}
+
+// buildSubsetCommands builds the commands creating the schema of the database in the dump container,
+// loading the subset, adding indexes and constraints, and dumping the result if the immediate restore is disabled.
+func (d *DumpJob) buildSubsetCommands(dbName string, definition DumpDefinition, snapshotID string) ([][]string, error) {
+	connArgs, err := d.dumpConnectionArgs(dbName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	username := d.globalCfg.Database.User()
+
+	schemaDump := func(section string, create bool) string {
+		dumpCmd := []string{"pg_dump", "--section", section, "--snapshot", snapshotID}
+
+		if create {
+			dumpCmd = append(dumpCmd, "--create")
+		}
+
+		dumpCmd = append(dumpCmd, connArgs...)
+
+		return strings.Join(append(dumpCmd, d.DumpOptions.CustomOptions...), " ")
+	}
+
+	// To avoid recreating of the default database.
+	preDataDB := defaults.DBName
+	createDB := dbName != defaults.DBName
+
+	if !createDB {
+		preDataDB = dbName
+	}
+
+	commands := [][]string{
+		{"sh", "-c", fmt.Sprintf("%s | psql --username %s --dbname %s -X -q", schemaDump("pre-data", createDB), username, preDataDB)},
+		{"psql", "--username", username, "--dbname", dbName, "-X", "-q", "-v", "ON_ERROR_STOP=1", "--single-transaction",
+			"--file", path.Join(d.subsetWorkDir(dbName), subset.LoadScriptName)},
+		{"sh", "-c", fmt.Sprintf("%s | psql --username %s --dbname %s -X -q", schemaDump("post-data", false), username, dbName)},
+	}
+
+	if d.DumpOptions.Restore.Enabled {
+		return commands, nil
+	}
+
+	dumpCmd := []string{"pg_dump", "--create", "--username", username, "--dbname", dbName}
+
+	if d.DumpOptions.ParallelJobs > 0 && definition.Format == directoryFormat {
+		dumpCmd = append(dumpCmd, "--jobs", strconv.Itoa(d.DumpOptions.ParallelJobs))
+	}
+
+	if compressOption := getCompressOption(definition.Compression, definition.CompressionLevel); compressOption != "" {
+		dumpCmd = append(dumpCmd, compressOption)
+	}
+
+	dumpCmd = append(dumpCmd, "--format", definition.Format, "--file", d.dumpOutputPath(dbName, definition))
+
+	return append(commands, dumpCmd), nil
+}
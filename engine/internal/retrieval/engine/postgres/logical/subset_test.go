@@ -0,0 +1,129 @@
+package logical
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/dbmarker"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/subset"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
+)
+
+func TestDumpJobValidateSubset(t *testing.T) {
+	roots := &subset.Config{Roots: []subset.Root{{Table: "public.customers", Where: "country = 'NZ'"}}}
+
+	testCases := []struct {
+		name    string
+		options DumpOptions
+		wantErr string
+	}{
+		{
+			name: "valid",
+			options: DumpOptions{
+				DumpLocation: "/var/lib/dblab/dump",
+				Databases:    map[string]DumpDefinition{"shop": {Subset: roots}},
+			},
+		},
+		{
+			name:    "missing dump location",
+			options: DumpOptions{Databases: map[string]DumpDefinition{"shop": {Subset: roots}}},
+			wantErr: "dumpLocation is required",
+		},
+		{
+			name: "combined with tables",
+			options: DumpOptions{
+				DumpLocation: "/var/lib/dblab/dump",
+				Databases:    map[string]DumpDefinition{"shop": {Subset: roots, Tables: []string{"orders"}}},
+			},
+			wantErr: "cannot be combined",
+		},
+		{
+			name: "invalid percent",
+			options: DumpOptions{
+				DumpLocation: "/var/lib/dblab/dump",
+				Databases: map[string]DumpDefinition{"shop": {
+					Subset: &subset.Config{Roots: []subset.Root{{Table: "public.orders", Percent: 150}}},
+				}},
+			},
+			wantErr: "between 0 and 100",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := (&DumpJob{DumpOptions: tc.options}).validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestSubsetCommandBuilding(t *testing.T) {
+	job := &DumpJob{
+		globalCfg: &global.Config{Database: global.Database{Username: "john"}},
+		config:    dumpJobConfig{db: Connection{Host: "source.example.com", Port: 5432, Username: "reader"}},
+		DumpOptions: DumpOptions{
+			DumpLocation: "/tmp/dump",
+			ParallelJobs: 2,
+		},
+	}
+
+	commands, err := job.buildSubsetCommands("shop", job.withDumpDefaults(DumpDefinition{}), "00000003-0000001B-1")
+	require.NoError(t, err)
+	require.Len(t, commands, 4)
+
+	assert.Equal(t, []string{"sh", "-c",
+		"pg_dump --section pre-data --snapshot 00000003-0000001B-1 --create " +
+			"--host source.example.com --port 5432 --username reader --dbname shop | psql --username john --dbname postgres -X -q"}, commands[0])
+	assert.Equal(t, []string{"psql", "--username", "john", "--dbname", "shop", "-X", "-q", "-v", "ON_ERROR_STOP=1",
+		"--single-transaction", "--file", "/tmp/dump/shop.subset/load.sql"}, commands[1])
+	assert.Equal(t, []string{"sh", "-c",
+		"pg_dump --section post-data --snapshot 00000003-0000001B-1 " +
+			"--host source.example.com --port 5432 --username reader --dbname shop | psql --username john --dbname shop -X -q"}, commands[2])
+	assert.Equal(t, []string{"pg_dump", "--create", "--username", "john", "--dbname", "shop", "--jobs", "2",
+		"--format", "directory", "--file", "/tmp/dump/shop"}, commands[3])
+
+	t.Run("immediate restore of the default database", func(t *testing.T) {
+		job.DumpOptions.Restore.Enabled = true
+
+		commands, err := job.buildSubsetCommands("postgres", job.withDumpDefaults(DumpDefinition{}), "00000003-0000001B-1")
+		require.NoError(t, err)
+		require.Len(t, commands, 3)
+		assert.NotContains(t, commands[0][2], "--create")
+		assert.Contains(t, commands[0][2], "| psql --username john --dbname postgres")
+	})
+}
+
+func TestRecordSubset(t *testing.T) {
+	dumpLocation, dataDir := t.TempDir(), t.TempDir()
+
+	job := &RestoreJob{
+		dbMarker:          dbmarker.NewMarker(dataDir),
+		isDumpLocationDir: true,
+		RestoreOptions:    RestoreOptions{DumpLocation: dumpLocation},
+	}
+
+	require.NoError(t, job.recordSubset("shop.dump"))
+
+	report := &subset.Report{
+		Database: "shop",
+		Roots:    []subset.Root{{Table: "public.customers", Percent: 5}},
+		Tables:   []subset.TableReport{{Table: "public.customers", Rows: 50}, {Table: "public.countries", Rows: 12}},
+	}
+	require.NoError(t, subset.SaveFile(filepath.Join(dumpLocation, "shop.dump"+subset.ReportSuffix), report))
+
+	require.NoError(t, job.recordSubset("shop.dump"))
+
+	var recorded []subset.Report
+
+	require.NoError(t, dbmarker.NewMarker(dataDir).GetReport(subset.ReportName, &recorded))
+	assert.Equal(t, []subset.Report{*report}, recorded)
+}
@@ -0,0 +1,292 @@
+/*
+2026 © Postgres.ai
+*/
+
+package physical
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/jackc/pgx/v5"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/activity"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/defaults"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+const (
+	pgbasebackupTool = "pgbasebackup"
+
+	// defaultReplicationSlot defines the physical replication slot used for the backup and the sync instance.
+	defaultReplicationSlot = "dblab_slot"
+
+	// backupApplicationName marks the backup connection to find its progress on the source.
+	backupApplicationName = "dle_retrieval"
+
+	// syncApplicationName marks the streaming connection of the sync instance.
+	syncApplicationName = "dblab_sync"
+
+	// progressReportVersion defines the version that introduced pg_stat_progress_basebackup.
+	progressReportVersion = 130000
+
+	activityConnectTimeout = 10 * time.Second
+
+	backupProgressQuery = `select coalesce(a.usename, ''),
+  coalesce(extract(epoch from (clock_timestamp() - a.backend_start)), 0),
+  format('pg_basebackup: %s, %s of %s streamed', p.phase, pg_size_pretty(p.backup_streamed),
+    coalesce(pg_size_pretty(p.backup_total), 'unknown')),
+  coalesce(a.wait_event_type, ''), coalesce(a.wait_event, '')
+from pg_stat_progress_basebackup p join pg_stat_activity a using (pid)
+where a.application_name = $1`
+
+	backupActivityQuery = `select coalesce(usename, ''),
+  coalesce(extract(epoch from (clock_timestamp() - backend_start)), 0),
+  'pg_basebackup', coalesce(wait_event_type, ''), coalesce(wait_event, '')
+from pg_stat_activity
+where application_name = $1`
+)
+
+var (
+	replicationSlotRegexp = regexp.MustCompile(`^[a-z0-9_]{1,63}$`)
+	maxRateRegexp         = regexp.MustCompile(`^[0-9]+[kM]?$`)
+)
+
+// pgbasebackup defines pg_basebackup as a tool copying data directly from the source and streaming WAL
+// through a permanent physical replication slot.
+type pgbasebackup struct {
+	dockerClient *client.Client
+	pgDataDir    string
+	envs         map[string]string
+	options      pgbasebackupOptions
+}
+
+type pgbasebackupOptions struct {
+	Connection pgbasebackupConnection `yaml:"connection"`
+	SlotName   string                 `yaml:"slotName"`
+	Checkpoint string                 `yaml:"checkpoint"`
+	MaxRate    string                 `yaml:"maxRate"`
+}
+
+// pgbasebackupConnection describes the connection to the source. The password is taken from PGPASSWORD.
+// Empty fields are taken from the PG* environment variables.
+type pgbasebackupConnection struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	DBName   string `yaml:"dbname"`
+}
+
+func newPgBaseBackup(dockerClient *client.Client, pgDataDir string, envs map[string]string,
+	options pgbasebackupOptions) (*pgbasebackup, error) {
+	if options.SlotName == "" {
+		options.SlotName = defaultReplicationSlot
+	}
+
+	if !replicationSlotRegexp.MatchString(options.SlotName) {
+		return nil, fmt.Errorf("invalid replication slot name %q: use lowercase letters, numbers and underscores", options.SlotName)
+	}
+
+	switch options.Checkpoint {
+	case "", "fast", "spread":
+	default:
+		return nil, fmt.Errorf("unknown checkpoint mode %q: use fast or spread", options.Checkpoint)
+	}
+
+	if options.MaxRate != "" && !maxRateRegexp.MatchString(options.MaxRate) {
+		return nil, fmt.Errorf("invalid max rate %q: use kilobytes per second with an optional k or M suffix", options.MaxRate)
+	}
+
+	return &pgbasebackup{
+		dockerClient: dockerClient,
+		pgDataDir:    pgDataDir,
+		envs:         envs,
+		options:      options,
+	}, nil
+}
+
+// GetRestoreCommand returns a command to restore data.
+func (p *pgbasebackup) GetRestoreCommand() string {
+	restoreCmd := []string{"pg_basebackup", "--pgdata=" + p.pgDataDir, "--wal-method=stream",
+		"--slot=" + p.options.SlotName, "--progress", "--verbose"}
+
+	if p.options.Checkpoint != "" {
+		restoreCmd = append(restoreCmd, "--checkpoint="+p.options.Checkpoint)
+	}
+
+	if p.options.MaxRate != "" {
+		restoreCmd = append(restoreCmd, "--max-rate="+p.options.MaxRate)
+	}
+
+	restoreCmd = append(restoreCmd, "--dbname="+shellQuote(p.connInfo(backupApplicationName)))
+
+	return strings.Join(restoreCmd, " ")
+}
+
+// GetRecoveryConfig returns a recovery config to stream WAL from the source through the replication slot.
+func (p *pgbasebackup) GetRecoveryConfig(pgVersion float64) map[string]string {
+	recoveryCfg := map[string]string{
+		"primary_conninfo":  p.connInfo(syncApplicationName),
+		"primary_slot_name": p.options.SlotName,
+	}
+
+	if pgVersion < defaults.PGVersion12 {
+		recoveryCfg["standby_mode"] = "on"
+		recoveryCfg["recovery_target_timeline"] = "latest"
+	}
+
+	return recoveryCfg
+}
+
+// Init creates the replication slot on the source unless it exists.
+func (p *pgbasebackup) Init(ctx context.Context, containerID string) error {
+	createSlotCmd := []string{"pg_receivewal", "--create-slot", "--if-not-exists", "--slot=" + p.options.SlotName,
+		"--dbname=" + p.connInfo(backupApplicationName)}
+
+	log.Msg("Ensuring the replication slot exists: ", p.options.SlotName)
+
+	if output, err := tools.ExecCommandWithOutput(ctx, p.dockerClient, containerID, container.ExecOptions{
+		Cmd: createSlotCmd,
+	}); err != nil {
+		return fmt.Errorf("failed to create replication slot %q: %w. Output: %s", p.options.SlotName, err, output)
+	}
+
+	return nil
+}
+
+// ReportActivity reports the progress of the backup on the source.
+func (p *pgbasebackup) ReportActivity(ctx context.Context) (*activity.Activity, error) {
+	pgxCfg, err := pgx.ParseConfig(p.engineConnection().connInfo(""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build source connection config: %w", err)
+	}
+
+	pgxCfg.Password = p.password()
+	pgxCfg.ConnectTimeout = activityConnectTimeout
+
+	querier, err := pgx.ConnectConfig(ctx, pgxCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to DB: %w", err)
+	}
+
+	defer func() { _ = querier.Close(context.Background()) }()
+
+	query := backupActivityQuery
+	if serverVersionNum(ctx, querier) >= progressReportVersion {
+		query = backupProgressQuery
+	}
+
+	rows, err := querier.Query(ctx, query, backupApplicationName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup progress: %w", err)
+	}
+
+	defer rows.Close()
+
+	events := make([]activity.PGEvent, 0)
+
+	for rows.Next() {
+		var pge activity.PGEvent
+		if err := rows.Scan(&pge.User, &pge.Duration, &pge.Query, &pge.WaitEventType, &pge.WaitEvent); err != nil {
+			return nil, fmt.Errorf("failed to scan backup progress: %w", err)
+		}
+
+		events = append(events, pge)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &activity.Activity{Source: events}, nil
+}
+
+// connInfo builds the connection string of the source. The password is never included.
+func (p *pgbasebackup) connInfo(applicationName string) string {
+	return p.options.Connection.connInfo(applicationName)
+}
+
+// engineConnection returns the connection to the source with empty fields taken from the environment variables
+// of the job, which are passed to containers, but not to the engine.
+func (p *pgbasebackup) engineConnection() pgbasebackupConnection {
+	conn := p.options.Connection
+
+	if conn.Host == "" {
+		conn.Host = p.envs["PGHOST"]
+	}
+
+	if conn.Port == 0 {
+		conn.Port, _ = strconv.Atoi(p.envs["PGPORT"])
+	}
+
+	if conn.Username == "" {
+		conn.Username = p.envs["PGUSER"]
+	}
+
+	if conn.DBName == "" {
+		conn.DBName = p.envs["PGDATABASE"]
+	}
+
+	if conn.DBName == "" {
+		conn.DBName = defaults.DBName
+	}
+
+	return conn
+}
+
+func (c pgbasebackupConnection) connInfo(applicationName string) string {
+	var params []string
+
+	if c.Host != "" {
+		params = append(params, "host="+c.Host)
+	}
+
+	if c.Port > 0 {
+		params = append(params, "port="+strconv.Itoa(c.Port))
+	}
+
+	if c.Username != "" {
+		params = append(params, "user="+c.Username)
+	}
+
+	if c.DBName != "" {
+		params = append(params, "dbname="+c.DBName)
+	}
+
+	if applicationName != "" {
+		params = append(params, "application_name="+applicationName)
+	}
+
+	return strings.Join(params, " ")
+}
+
+func (p *pgbasebackup) password() string {
+	if password := p.envs["PGPASSWORD"]; password != "" {
+		return password
+	}
+
+	return os.Getenv("PGPASSWORD")
+}
+
+func serverVersionNum(ctx context.Context, querier *pgx.Conn) int {
+	var version int
+
+	if err := querier.QueryRow(ctx, "select current_setting('server_version_num')::int").Scan(&version); err != nil {
+		log.Dbg("Cannot detect the source version: ", err)
+	}
+
+	return version
+}
+
+// shellQuote quotes a value for a POSIX shell.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,89 @@
+package physical
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgBaseBackupRestoreCommand(t *testing.T) {
+	pgbasebackup, err := newPgBaseBackup(nil, "/var/lib/dblab/data", nil, pgbasebackupOptions{
+		Connection: pgbasebackupConnection{Host: "source.example.com", Port: 5432, Username: "replicator"},
+	})
+	require.NoError(t, err)
+
+	expectedResponse := "pg_basebackup --pgdata=/var/lib/dblab/data --wal-method=stream --slot=dblab_slot --progress --verbose " +
+		"--dbname='host=source.example.com port=5432 user=replicator application_name=dle_retrieval'"
+	assert.Equal(t, expectedResponse, pgbasebackup.GetRestoreCommand())
+
+	pgbasebackup.options.Checkpoint = "fast"
+	pgbasebackup.options.MaxRate = "100M"
+
+	expectedResponse = "pg_basebackup --pgdata=/var/lib/dblab/data --wal-method=stream --slot=dblab_slot --progress --verbose " +
+		"--checkpoint=fast --max-rate=100M " +
+		"--dbname='host=source.example.com port=5432 user=replicator application_name=dle_retrieval'"
+	assert.Equal(t, expectedResponse, pgbasebackup.GetRestoreCommand())
+}
+
+func TestPgBaseBackupRecoveryConfig(t *testing.T) {
+	pgbasebackup, err := newPgBaseBackup(nil, "/var/lib/dblab/data", nil, pgbasebackupOptions{
+		Connection: pgbasebackupConnection{Host: "source.example.com", Username: "replicator"},
+		SlotName:   "dle_sync",
+	})
+	require.NoError(t, err)
+
+	expectedResponse11 := map[string]string{
+		"primary_conninfo":         "host=source.example.com user=replicator application_name=dblab_sync",
+		"primary_slot_name":        "dle_sync",
+		"standby_mode":             "on",
+		"recovery_target_timeline": "latest",
+	}
+	assert.Equal(t, expectedResponse11, pgbasebackup.GetRecoveryConfig(11.7))
+
+	expectedResponse12 := map[string]string{
+		"primary_conninfo":  "host=source.example.com user=replicator application_name=dblab_sync",
+		"primary_slot_name": "dle_sync",
+	}
+	assert.Equal(t, expectedResponse12, pgbasebackup.GetRecoveryConfig(12.3))
+}
+
+func TestPgBaseBackupOptionsValidation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options pgbasebackupOptions
+		wantErr string
+	}{
+		{name: "defaults"},
+		{name: "all options", options: pgbasebackupOptions{SlotName: "dle_slot_1", Checkpoint: "spread", MaxRate: "32768k"}},
+		{name: "invalid slot", options: pgbasebackupOptions{SlotName: "DLE-slot"}, wantErr: "invalid replication slot name"},
+		{name: "unknown checkpoint", options: pgbasebackupOptions{Checkpoint: "immediate"}, wantErr: "unknown checkpoint mode"},
+		{name: "invalid max rate", options: pgbasebackupOptions{MaxRate: "10G"}, wantErr: "invalid max rate"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newPgBaseBackup(nil, "/var/lib/dblab/data", nil, tc.options)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestPgBaseBackupEngineConnection(t *testing.T) {
+	envs := map[string]string{"PGHOST": "env.example.com", "PGPORT": "6432", "PGUSER": "env_user", "PGPASSWORD": "secret"}
+
+	pgbasebackup, err := newPgBaseBackup(nil, "/var/lib/dblab/data", envs, pgbasebackupOptions{
+		Connection: pgbasebackupConnection{Username: "replicator"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "host=env.example.com port=6432 user=replicator dbname=postgres", pgbasebackup.engineConnection().connInfo(""))
+	assert.Equal(t, "secret", pgbasebackup.password())
+	assert.Equal(t, "user=replicator application_name=dblab_sync", pgbasebackup.connInfo(syncApplicationName))
+}
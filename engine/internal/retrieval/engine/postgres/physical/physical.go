@@ -76,6 +76,7 @@ type CopyOptions struct {
 	Envs            map[string]string      `yaml:"envs"`
 	WALG            walgOptions            `yaml:"walg"`
 	PgBackRest      pgbackrestOptions      `yaml:"pgbackrest"`
+	PgBaseBackup    pgbasebackupOptions    `yaml:"pgbasebackup"`
 	CustomTool      customOptions          `yaml:"customTool"`
 	Sync            Sync                   `yaml:"sync"`
 }
@@ -107,6 +108,11 @@ type restorer interface {
 	GetRecoveryConfig(version float64) map[string]string
 }
 
+// activityReporter describes restore tools able to report the progress of the restore.
+type activityReporter interface {
+	ReportActivity(ctx context.Context) (*activity.Activity, error)
+}
+
 // NewJob creates a new physical restore job.
 func NewJob(cfg config.JobConfig, global *global.Config, engineProps *global.EngineProps) (*RestoreJob, error) {
 	physicalJob := &RestoreJob{
@@ -141,6 +147,9 @@ func (r *RestoreJob) getRestorer(client *client.Client, tool string) (restorer,
 	case pgbackrestTool:
 		return newPgBackRest(r.PgBackRest), nil
 
+	case pgbasebackupTool:
+		return newPgBaseBackup(client, r.fsPool.DataDir(), r.Envs, r.PgBaseBackup)
+
 	case customTool:
 		return newCustomTool(r.CustomTool), nil
 	}
@@ -163,7 +172,11 @@ func (r *RestoreJob) Reload(cfg map[string]interface{}) (err error) {
 }
 
 // ReportActivity reports the current job activity.
-func (r *RestoreJob) ReportActivity(_ context.Context) (*activity.Activity, error) {
+func (r *RestoreJob) ReportActivity(ctx context.Context) (*activity.Activity, error) {
+	if reporter, ok := r.restorer.(activityReporter); ok {
+		return reporter.ReportActivity(ctx)
+	}
+
 	return &activity.Activity{}, nil
 }
 
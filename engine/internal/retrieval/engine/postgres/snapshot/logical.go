@@ -29,6 +29,7 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/activity"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/cont"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/health"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/masking"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/query"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/options"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/telemetry"
@@ -57,6 +58,7 @@ type LogicalInitial struct {
 	engineProps    *global.EngineProps
 	dbMarker       *dbmarker.Marker
 	queryProcessor *query.Processor
+	snapshotReady  func()
 }
 
 // LogicalOptions describes options for a logical initialization job.
@@ -66,6 +68,7 @@ type LogicalOptions struct {
 	DatabaseRename      map[string]string `yaml:"databaseRename"`
 	Configs             map[string]string `yaml:"configs"`
 	Schedule            Scheduler         `yaml:"schedule"`
+	Masking             *masking.Config   `yaml:"masking"`
 }
 
 // DataPatching allows executing queries to transform data before snapshot taking.
@@ -79,14 +82,15 @@ type DataPatching struct {
 func NewLogicalInitialJob(cfg config.JobConfig, global *global.Config, engineProps *global.EngineProps, cloneManager pool.FSManager,
 	tm *telemetry.Agent) (*LogicalInitial, error) {
 	li := &LogicalInitial{
-		name:         cfg.Spec.Name,
-		cloneManager: cloneManager,
-		fsPool:       cfg.FSPool,
-		dockerClient: cfg.Docker,
-		globalCfg:    global,
-		engineProps:  engineProps,
-		dbMarker:     cfg.Marker,
-		tm:           tm,
+		name:          cfg.Spec.Name,
+		cloneManager:  cloneManager,
+		fsPool:        cfg.FSPool,
+		dockerClient:  cfg.Docker,
+		globalCfg:     global,
+		engineProps:   engineProps,
+		dbMarker:      cfg.Marker,
+		tm:            tm,
+		snapshotReady: cfg.SnapshotReady,
 	}
 
 	if err := li.Reload(cfg.Spec.Options); err != nil {
@@ -112,7 +116,11 @@ func (s *LogicalInitial) patchContainerName() string {
 
 // Reload reloads job configuration.
 func (s *LogicalInitial) Reload(cfg map[string]interface{}) (err error) {
-	return options.Unmarshal(cfg, &s.options)
+	if err := options.Unmarshal(cfg, &s.options); err != nil {
+		return err
+	}
+
+	return s.options.Masking.Validate()
 }
 
 // ReportActivity reports the current job activity.
@@ -121,7 +129,9 @@ func (s *LogicalInitial) ReportActivity(_ context.Context) (*activity.Activity,
 }
 
 // Run starts the job.
-func (s *LogicalInitial) Run(ctx context.Context) error {
+func (s *LogicalInitial) Run(ctx context.Context) (err error) {
+	defer func(startedAt time.Time) { observeSnapshot(startedAt, err) }(time.Now())
+
 	if len(s.options.DatabaseRename) > 0 {
 		if err := validateDatabaseRenames(s.options.DatabaseRename, s.globalCfg.Database.Name()); err != nil {
 			return fmt.Errorf("invalid database rename configuration: %w", err)
@@ -151,7 +161,7 @@ func (s *LogicalInitial) Run(ctx context.Context) error {
 		return errors.Wrap(err, "failed to store PostgreSQL configs for the snapshot")
 	}
 
-	if s.queryProcessor != nil || len(s.options.DatabaseRename) > 0 {
+	if s.queryProcessor != nil || s.options.Masking.Enabled() || len(s.options.DatabaseRename) > 0 {
 		if err := s.runPreprocessingQueries(ctx, dataDir); err != nil {
 			return errors.Wrap(err, "failed to run preprocessing queries")
 		}
@@ -170,7 +180,8 @@ func (s *LogicalInitial) Run(ctx context.Context) error {
 		return errors.Wrap(err, "failed to ensure data ownership")
 	}
 
-	if _, err := s.cloneManager.CreateSnapshot("", dataStateAt); err != nil {
+	snapshotName, err := s.cloneManager.CreateSnapshot("", dataStateAt)
+	if err != nil {
 		var existsError *thinclones.SnapshotExistsError
 		if errors.As(err, &existsError) {
 			log.Msg("Skip snapshotting: ", existsError.Error())
@@ -180,10 +191,16 @@ func (s *LogicalInitial) Run(ctx context.Context) error {
 		return errors.Wrap(err, "failed to create a snapshot")
 	}
 
+	if s.options.Masking.Enabled() {
+		recordMaskingReport(dataDir, snapshotName)
+	}
+
 	if err := s.markDatabaseData(dataStateAt); err != nil {
 		return errors.Wrap(err, "failed to mark logical data")
 	}
 
+	notifySnapshotReady(s.snapshotReady)
+
 	s.tm.SendEvent(ctx, telemetry.SnapshotCreatedEvent, telemetry.SnapshotCreated{})
 
 	return nil
@@ -283,6 +300,12 @@ func (s *LogicalInitial) runPreprocessingQueries(ctx context.Context, dataDir st
 		}
 	}
 
+	if s.options.Masking.Enabled() {
+		if err := runMasking(ctx, s.dockerClient, containerID, s.options.Masking, s.globalCfg, dataDir); err != nil {
+			return err
+		}
+	}
+
 	if len(s.options.DatabaseRename) > 0 {
 		if err := executeDatabaseRenames(
 			ctx, s.dockerClient, containerID,
@@ -0,0 +1,64 @@
+/*
+2026 © Postgres.ai
+*/
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/docker/client"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/dbmarker"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/masking"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+// runMasking masks the data of the Postgres instance running in the container and stores the masking report
+// with the data, so it is kept in the snapshot.
+func runMasking(ctx context.Context, dockerClient *client.Client, containerID string, cfg *masking.Config,
+	globalCfg *global.Config, dataDir string) error {
+	masker := masking.NewMasker(dockerClient, cfg, globalCfg.Database.Name(), globalCfg.Database.User())
+
+	report, err := masker.Run(ctx, containerID)
+	if err != nil {
+		return fmt.Errorf("failed to mask data: %w", err)
+	}
+
+	if err := dbmarker.NewMarker(dataDir).SaveReport(masking.ReportName, report); err != nil {
+		return fmt.Errorf("failed to save masking report: %w", err)
+	}
+
+	return nil
+}
+
+// recordMaskingReport copies the masking report stored with the data to the engine meta directory,
+// so it can be served for the snapshot without cloning it.
+func recordMaskingReport(dataDir, snapshotID string) {
+	report := &masking.Report{}
+
+	if err := dbmarker.NewMarker(dataDir).GetReport(masking.ReportName, report); err != nil {
+		log.Warn("failed to read masking report:", err)
+		return
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Warn("failed to encode masking report:", err)
+		return
+	}
+
+	store, err := thinclones.DefaultMetadataStore()
+	if err != nil {
+		log.Warn(err)
+		return
+	}
+
+	if err := store.Save(thinclones.MaskingReportMetadata, snapshotID, data); err != nil {
+		log.Warn(err)
+	}
+}
@@ -40,6 +40,7 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/defaults"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/fs"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/health"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/masking"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/pgtool"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/query"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/options"
@@ -63,6 +64,7 @@ const (
 	checkpointTimestampLabel = "Time of latest checkpoint:"
 
 	restoreCommandOption = "restore_command"
+	primarySlotOption    = "primary_slot_name"
 	targetActionOption   = "recovery_target_action"
 	promoteTargetAction  = "promote"
 
@@ -107,6 +109,10 @@ type PhysicalInitial struct {
 	schedulerMutex sync.Mutex
 	queryProcessor *query.Processor
 	tm             *telemetry.Agent
+	snapshotReady  func()
+	// walPosition keeps the WAL location the promoted data has been replayed to, so point-in-time recovery
+	// to an LSN can start from the nearest snapshot.
+	walPosition string
 }
 
 // PhysicalOptions describes options for a physical initialization job.
@@ -119,6 +125,7 @@ type PhysicalOptions struct {
 	Sysctls             map[string]string `yaml:"sysctls"`
 	Envs                map[string]string `yaml:"envs"`
 	Scheduler           *Scheduler        `yaml:"scheduler"`
+	Masking             *masking.Config   `yaml:"masking"`
 }
 
 // Promotion describes promotion options.
@@ -144,10 +151,52 @@ type Scheduler struct {
 	Retention ScheduleSpec `yaml:"retention"`
 }
 
+// hasTimetables checks if any of the scheduler components is configured.
+func (s *Scheduler) hasTimetables() bool {
+	return s != nil && (s.Snapshot.Timetable != "" || s.Retention.Timetable != "")
+}
+
+// validate checks that the configured timetables are valid cron expressions.
+func (s *Scheduler) validate() error {
+	if !s.hasTimetables() {
+		return nil
+	}
+
+	specParser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+	if _, err := specParser.Parse(s.Snapshot.Timetable); s.Snapshot.Timetable != "" && err != nil {
+		return errors.Wrapf(err, "failed to parse schedule timetable %q", s.Snapshot.Timetable)
+	}
+
+	if _, err := specParser.Parse(s.Retention.Timetable); s.Retention.Timetable != "" && err != nil {
+		return errors.Wrapf(err, "failed to parse retention timetable %q", s.Retention.Timetable)
+	}
+
+	if s.Retention.Policy != nil {
+		if err := s.Retention.Policy.Validate(); err != nil {
+			return errors.Wrap(err, "invalid retention policy")
+		}
+	}
+
+	return nil
+}
+
 // ScheduleSpec defines options to set up scheduler components.
 type ScheduleSpec struct {
 	Timetable string `yaml:"timetable"`
 	Limit     int    `yaml:"limit"`
+
+	// Policy enables tiered retention; when set, it takes precedence over Limit.
+	Policy *thinclones.RetentionPolicy `yaml:"policy"`
+}
+
+// cleanupSnapshots destroys snapshots according to the retention policy if it is configured, otherwise keeps the newest Limit snapshots.
+func (s ScheduleSpec) cleanupSnapshots(cloneManager pool.FSManager, mode models.RetrievalMode) ([]string, error) {
+	if s.Policy != nil && s.Policy.Enabled() {
+		return cloneManager.ApplyRetentionPolicy(*s.Policy, mode, false)
+	}
+
+	return cloneManager.CleanupSnapshots(s.Limit, mode)
 }
 
 // QueryPreprocessing defines query preprocessing options.
@@ -168,15 +217,16 @@ func NewPhysicalInitialJob(
 	tm *telemetry.Agent,
 ) (*PhysicalInitial, error) {
 	p := &PhysicalInitial{
-		name:         cfg.Spec.Name,
-		cloneManager: cloneManager,
-		fsPool:       cfg.FSPool,
-		globalCfg:    global,
-		engineProps:  engineProps,
-		dbMarker:     cfg.Marker,
-		dbMark:       &dbmarker.Config{DataType: dbmarker.PhysicalDataType},
-		dockerClient: cfg.Docker,
-		tm:           tm,
+		name:          cfg.Spec.Name,
+		cloneManager:  cloneManager,
+		fsPool:        cfg.FSPool,
+		globalCfg:     global,
+		engineProps:   engineProps,
+		dbMarker:      cfg.Marker,
+		dbMark:        &dbmarker.Config{DataType: dbmarker.PhysicalDataType},
+		dockerClient:  cfg.Docker,
+		tm:            tm,
+		snapshotReady: cfg.SnapshotReady,
 	}
 
 	if err := p.loadConfig(cfg.Spec.Options); err != nil {
@@ -218,30 +268,23 @@ func (p *PhysicalInitial) validateConfig() error {
 			strings.Join(notSupportedSysctls, ", "))
 	}
 
+	if p.options.Masking.Enabled() && !p.options.Promotion.Enabled {
+		return errors.New("masking requires promotion to be enabled")
+	}
+
+	if err := p.options.Masking.Validate(); err != nil {
+		return err
+	}
+
 	return p.validateScheduler()
 }
 
 func (p *PhysicalInitial) hasSchedulingOptions() bool {
-	return p.options.Scheduler != nil &&
-		(p.options.Scheduler.Snapshot.Timetable != "" || p.options.Scheduler.Retention.Timetable != "")
+	return p.options.Scheduler.hasTimetables()
 }
 
 func (p *PhysicalInitial) validateScheduler() error {
-	if !p.hasSchedulingOptions() {
-		return nil
-	}
-
-	specParser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-
-	if _, err := specParser.Parse(p.options.Scheduler.Snapshot.Timetable); p.options.Scheduler.Snapshot.Timetable != "" && err != nil {
-		return errors.Wrapf(err, "failed to parse schedule timetable %q", p.options.Scheduler.Snapshot.Timetable)
-	}
-
-	if _, err := specParser.Parse(p.options.Scheduler.Retention.Timetable); p.options.Scheduler.Retention.Timetable != "" && err != nil {
-		return errors.Wrapf(err, "failed to parse retention timetable %q", p.options.Scheduler.Retention.Timetable)
-	}
-
-	return nil
+	return p.options.Scheduler.validate()
 }
 
 // Name returns a name of the job.
@@ -249,6 +292,11 @@ func (p *PhysicalInitial) Name() string {
 	return p.name
 }
 
+// Pool returns the pool the job takes snapshots of.
+func (p *PhysicalInitial) Pool() *resources.Pool {
+	return p.fsPool
+}
+
 // Reload reloads job configuration.
 func (p *PhysicalInitial) Reload(cfg map[string]interface{}) (err error) {
 	if err := p.loadConfig(cfg); err != nil {
@@ -326,9 +374,11 @@ func (p *PhysicalInitial) run(ctx context.Context) (err error) {
 	}
 
 	p.dbMark.DataStateAt = extractDataStateAt(p.dbMarker)
+	p.walPosition = ""
 
 	// Snapshot data.
-	preDataStateAt := time.Now().Format(tools.DataStateAtFormat)
+	startedAt := time.Now()
+	preDataStateAt := startedAt.Format(tools.DataStateAtFormat)
 	cloneName := fmt.Sprintf("clone%s_%s", pre, preDataStateAt)
 
 	defer func() {
@@ -338,6 +388,8 @@ func (p *PhysicalInitial) run(ctx context.Context) (err error) {
 		}
 	}()
 
+	defer func() { observeSnapshot(startedAt, err) }()
+
 	var syState syncState
 
 	if p.options.Promotion.Enabled {
@@ -419,11 +471,23 @@ func (p *PhysicalInitial) run(ctx context.Context) (err error) {
 
 	// Create a snapshot.
 	fullClonePath := path.Join(branching.BranchDir, branching.DefaultBranch, cloneName, branching.RevisionSegment(branching.DefaultRevision))
-	if _, err := p.cloneManager.CreateSnapshot(fullClonePath, p.dbMark.DataStateAt); err != nil {
+	snapshotID, err := p.cloneManager.CreateSnapshot(fullClonePath, p.dbMark.DataStateAt)
+	if err != nil {
 		return errors.Wrap(err, "failed to create snapshot")
 	}
 
+	if p.walPosition != "" {
+		if err := p.cloneManager.SetWALPosition(p.walPosition, snapshotID); err != nil {
+			log.Warn("failed to record the WAL position of the snapshot:", err)
+		}
+	}
+
+	if p.options.Masking.Enabled() {
+		recordMaskingReport(cloneDataDir, snapshotID)
+	}
+
 	p.updateDataStateAt()
+	notifySnapshotReady(p.snapshotReady)
 
 	p.tm.SendEvent(ctx, telemetry.SnapshotCreatedEvent, telemetry.SnapshotCreated{})
 
@@ -521,7 +585,7 @@ func (p *PhysicalInitial) startScheduler(ctx context.Context) {
 
 	if p.options.Scheduler.Retention.Timetable != "" {
 		if _, err := p.scheduler.AddFunc(p.options.Scheduler.Retention.Timetable,
-			p.runAutoCleanup(p.options.Scheduler.Retention.Limit)); err != nil {
+			p.runAutoCleanup(p.options.Scheduler.Retention)); err != nil {
 			log.Err(errors.Wrap(err, "failed to schedule a new cleanup job"))
 			return
 		}
@@ -565,7 +629,7 @@ func (p *PhysicalInitial) runAutoSnapshot(ctx context.Context) func() {
 	}
 }
 
-func (p *PhysicalInitial) runAutoCleanup(retentionLimit int) func() {
+func (p *PhysicalInitial) runAutoCleanup(retention ScheduleSpec) func() {
 	return func() {
 		if !p.schedulerMutex.TryLock() {
 			log.Msg("skipping scheduled cleanup: snapshot operation in progress")
@@ -573,7 +637,7 @@ func (p *PhysicalInitial) runAutoCleanup(retentionLimit int) func() {
 		}
 		defer p.schedulerMutex.Unlock()
 
-		if err := p.cleanupSnapshots(retentionLimit); err != nil {
+		if err := p.cleanupSnapshots(retention); err != nil {
 			log.Err(errors.Wrap(err, "failed to clean up snapshots automatically"))
 		}
 	}
@@ -709,6 +773,13 @@ func (p *PhysicalInitial) promoteInstance(ctx context.Context, clonePath string,
 
 	// Detect dataStateAt.
 	if shouldBePromoted == "t" {
+		walPosition, err := p.getLastReplayLSN(ctx, containerID)
+		if err != nil {
+			log.Warn("failed to get the WAL position of the promoted data:", err)
+		}
+
+		p.walPosition = walPosition
+
 		// Promote PGDATA.
 		if err := p.runPromoteCommand(ctx, containerID, clonePath); err != nil {
 			return errors.Wrapf(err, "failed to promote PGDATA: %s", clonePath)
@@ -728,12 +799,23 @@ func (p *PhysicalInitial) promoteInstance(ctx context.Context, clonePath string,
 		return errors.Wrap(err, "failed to mark dataStateAt")
 	}
 
+	return p.finalizeInstance(ctx, containerID, clonePath, cfgManager)
+}
+
+// finalizeInstance transforms the promoted data and prepares the configuration of the snapshot.
+func (p *PhysicalInitial) finalizeInstance(ctx context.Context, containerID, clonePath string, cfgManager *pgconfig.Manager) error {
 	if p.queryProcessor != nil {
 		if err := p.queryProcessor.ApplyPreprocessingQueries(ctx, containerID); err != nil {
 			return errors.Wrap(err, "failed to run preprocessing queries")
 		}
 	}
 
+	if p.options.Masking.Enabled() {
+		if err := runMasking(ctx, p.dockerClient, containerID, p.options.Masking, p.globalCfg, clonePath); err != nil {
+			return err
+		}
+	}
+
 	if len(p.options.DatabaseRename) > 0 {
 		if err := executeDatabaseRenames(
 			ctx, p.dockerClient, containerID,
@@ -880,6 +962,13 @@ func buildRecoveryConfig(fileConfig, userRecoveryConfig map[string]string) map[s
 
 	recoveryConf := fileConfig
 
+	// The promotion instance must not stream through the replication slot of the sync instance:
+	// its feedback would move the slot and the source could remove WAL the sync instance still needs.
+	// The recovery config is appended to the file, so the option is overridden rather than removed.
+	if _, ok := recoveryConf[primarySlotOption]; ok {
+		recoveryConf[primarySlotOption] = ""
+	}
+
 	if rc, ok := fileConfig[restoreCommandOption]; ok || rc != "" {
 		for k, v := range defaultRecoveryCfg {
 			recoveryConf[k] = v
@@ -1088,6 +1177,21 @@ func (p *PhysicalInitial) getLastXActReplayTimestamp(ctx context.Context, contai
 	return output, err
 }
 
+// getLastReplayLSN returns the WAL location the instance in recovery has replayed to.
+func (p *PhysicalInitial) getLastReplayLSN(ctx context.Context, containerID string) (string, error) {
+	extractionCommand := []string{"psql", "-U", p.globalCfg.Database.User(), "-d", p.globalCfg.Database.Name(), "-XAtc",
+		"select pg_last_wal_replay_lsn()"}
+
+	output, err := tools.ExecCommandWithOutput(ctx, p.dockerClient, containerID, container.ExecOptions{
+		Cmd:  extractionCommand,
+		User: defaults.Username,
+	})
+
+	log.Msg("Extracted last replay LSN: ", output)
+
+	return strings.TrimSpace(output), err
+}
+
 func getCheckPointTimestamp(ctx context.Context, r io.Reader) (string, error) {
 	scanner := bufio.NewScanner(r)
 	checkpointTitleBytes := []byte(checkpointTimestampLabel)
@@ -1152,7 +1256,7 @@ func (p *PhysicalInitial) updateDataStateAt() {
 	p.fsPool.SetDSA(dsaTime)
 }
 
-func (p *PhysicalInitial) cleanupSnapshots(retentionLimit int) error {
+func (p *PhysicalInitial) cleanupSnapshots(retention ScheduleSpec) error {
 	select {
 	case <-p.schedulerCtx.Done():
 		log.Msg("Stop automatic snapshot cleanup")
@@ -1160,7 +1264,7 @@ func (p *PhysicalInitial) cleanupSnapshots(retentionLimit int) error {
 	default:
 	}
 
-	_, err := p.cloneManager.CleanupSnapshots(retentionLimit, models.Physical)
+	_, err := retention.cleanupSnapshots(p.cloneManager, models.Physical)
 	if err != nil {
 		return errors.Wrap(err, "failed to clean up snapshots")
 	}
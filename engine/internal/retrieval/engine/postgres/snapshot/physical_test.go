@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/masking"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 )
 
@@ -180,6 +181,14 @@ func TestBuildRecoveryConfig_noRestoreCommand(t *testing.T) {
 	assert.Equal(t, map[string]string{"standby_mode": "true", "recovery_target_timeline": "latest"}, result)
 }
 
+func TestBuildRecoveryConfig_overridesReplicationSlot(t *testing.T) {
+	fileConfig := map[string]string{"primary_conninfo": "host=source user=replicator", "primary_slot_name": "dblab_slot"}
+
+	result := buildRecoveryConfig(fileConfig, nil)
+
+	assert.Equal(t, map[string]string{"primary_conninfo": "host=source user=replicator", "primary_slot_name": ""}, result)
+}
+
 func TestBuildRecoveryConfig_emptyFileConfig(t *testing.T) {
 	result := buildRecoveryConfig(map[string]string{}, nil)
 	assert.Equal(t, map[string]string{}, result)
@@ -299,3 +308,16 @@ func TestSkipSnapshotErr_emptyMessage(t *testing.T) {
 	err := newSkipSnapshotErr("")
 	assert.Equal(t, "", err.Error())
 }
+
+func TestPhysicalMaskingRequiresPromotion(t *testing.T) {
+	job := &PhysicalInitial{options: PhysicalOptions{Masking: &masking.Config{
+		Rules: []masking.Rule{{Table: "users", Columns: map[string]masking.Method{"email": masking.MethodFakeEmail}}},
+	}}}
+
+	err := job.validateConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires promotion")
+
+	job.options.Promotion.Enabled = true
+	assert.NoError(t, job.validateConfig())
+}
@@ -0,0 +1,439 @@
+/*
+2026 © Postgres.ai
+*/
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres/pgconfig"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/cont"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/fs"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util/branching"
+)
+
+const (
+	pitrContainerPrefix = "dblab_pitr_"
+
+	// pitrClonePrefix marks the clone as a system one, so it is not treated as a user clone.
+	pitrClonePrefix = "clone_pre_pitr_"
+
+	pitrBranchPrefix = "pitr_"
+
+	recoveryTargetTimeOption = "recovery_target_time"
+	recoveryTargetLSNOption  = "recovery_target_lsn"
+
+	recoveryTargetTimeLayout = "2006-01-02 15:04:05.999999"
+)
+
+var lsnRegexp = regexp.MustCompile(`^[0-9A-Fa-f]{1,8}/[0-9A-Fa-f]{1,8}$`)
+
+// RecoveryTarget describes the point to recover the data to.
+type RecoveryTarget struct {
+	// Time defines the time to stop the recovery at.
+	Time time.Time
+	// LSN defines the WAL location to stop the recovery at.
+	LSN string
+	// Branch defines the branch of the recovered snapshot. It is derived from the target if empty.
+	Branch string
+}
+
+// Validate checks that exactly one recovery target is defined.
+func (t RecoveryTarget) Validate() error {
+	if t.Time.IsZero() == (t.LSN == "") {
+		return errors.New("either recovery target time or LSN must be specified")
+	}
+
+	if t.LSN != "" && !lsnRegexp.MatchString(t.LSN) {
+		return errors.Errorf("invalid recovery target LSN %q", t.LSN)
+	}
+
+	return nil
+}
+
+// BranchName returns the branch of the recovered snapshot.
+func (t RecoveryTarget) BranchName() string {
+	if t.Branch != "" {
+		return t.Branch
+	}
+
+	if t.LSN != "" {
+		return pitrBranchPrefix + strings.ToUpper(strings.ReplaceAll(t.LSN, "/", "_"))
+	}
+
+	return pitrBranchPrefix + t.Time.UTC().Format(util.DataStateAtFormat)
+}
+
+// String describes the target for users.
+func (t RecoveryTarget) String() string {
+	if t.LSN != "" {
+		return "LSN " + t.LSN
+	}
+
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+// recoveryConfig returns recovery parameters stopping the replay at the target.
+func (t RecoveryTarget) recoveryConfig() map[string]string {
+	recoveryCfg := map[string]string{targetActionOption: promoteTargetAction}
+
+	if t.LSN != "" {
+		recoveryCfg[recoveryTargetLSNOption] = t.LSN
+	} else {
+		recoveryCfg[recoveryTargetTimeOption] = t.Time.UTC().Format(recoveryTargetTimeLayout) + "+00"
+	}
+
+	return recoveryCfg
+}
+
+// RecoverTo creates a snapshot of the data recovered to the target on a dedicated branch.
+// It waits for a scheduled snapshot in progress and holds off the next ones until the recovery completes.
+//
+// The data of the nearest snapshot taken before the target is cloned from its pre-snapshot, which has not been promoted yet,
+// so WAL can be replayed using restore_command of the restore tool. The promoted result goes through the same preprocessing
+// as scheduled snapshots, except for the preprocessing script, and is committed as the head of the branch.
+func (p *PhysicalInitial) RecoverTo(ctx context.Context, target RecoveryTarget) (_ string, err error) {
+	if err := target.Validate(); err != nil {
+		return "", err
+	}
+
+	p.schedulerMutex.Lock()
+	defer p.schedulerMutex.Unlock()
+
+	defer func(startedAt time.Time) { observeSnapshot(startedAt, err) }(time.Now())
+
+	branchName := target.BranchName()
+
+	branches, err := p.cloneManager.ListBranches()
+	if err != nil {
+		return "", fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	if _, ok := branches[branchName]; ok {
+		return "", fmt.Errorf("branch %q already exists", branchName)
+	}
+
+	p.cloneManager.RefreshSnapshotList()
+
+	baseSnapshot, preSnapshot, err := p.findRecoveryBase(target)
+	if err != nil {
+		return "", err
+	}
+
+	log.Msg(fmt.Sprintf("Recovering the data to %s from the snapshot %s", target, baseSnapshot.ID))
+
+	cloneName := pitrClonePrefix + time.Now().Format(tools.DataStateAtFormat)
+
+	if err := p.cloneManager.CreateClone(branchName, cloneName, preSnapshot, branching.DefaultRevision); err != nil {
+		return "", errors.Wrapf(err, "failed to create clone %s", cloneName)
+	}
+
+	defer func() {
+		if err != nil {
+			cloneDataset := branching.CloneDataset(p.fsPool.Name, branchName, cloneName)
+			if errDestroy := p.cloneManager.DestroyDataset(cloneDataset); errDestroy != nil {
+				log.Err(fmt.Sprintf("failed to destroy clone %q: %v", cloneName, errDestroy))
+			}
+		}
+	}()
+
+	cloneDataDir := path.Join(p.fsPool.CloneLocation(branchName, cloneName, branching.DefaultRevision), p.fsPool.DataSubDir)
+	if err := fs.CleanupLogsDir(cloneDataDir); err != nil {
+		log.Warn("Failed to clean up logs directory:", err.Error())
+	}
+
+	dataStateAt, err := p.recoverInstance(ctx, cloneDataDir, target)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to recover instance")
+	}
+
+	if err = p.cloneManager.EnsureDataOwnership(cloneDataDir); err != nil {
+		return "", errors.Wrap(err, "failed to ensure data ownership")
+	}
+
+	clonePath := path.Join(branching.BranchDir, branchName, cloneName, branching.RevisionSegment(branching.DefaultRevision))
+
+	snapshotName, err := p.cloneManager.CreateSnapshot(clonePath, dataStateAt)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create snapshot")
+	}
+
+	if err = p.commitRecoveredSnapshot(branchName, baseSnapshot.ID, snapshotName, target); err != nil {
+		return "", err
+	}
+
+	p.cloneManager.RefreshSnapshotList()
+
+	log.Msg(fmt.Sprintf("The data recovered to %s has been committed to the branch %q: %s", target, branchName, snapshotName))
+
+	return snapshotName, nil
+}
+
+// findRecoveryBase returns the latest snapshot of the main branch taken before the target and its pre-snapshot.
+// For LSN targets, the WAL position recorded when the snapshot was promoted is compared with the target,
+// and snapshots without a recorded position are skipped.
+func (p *PhysicalInitial) findRecoveryBase(target RecoveryTarget) (resources.Snapshot, string, error) {
+	candidates := make([]resources.Snapshot, 0)
+
+	for _, snapshot := range p.cloneManager.SnapshotList() {
+		if snapshot.Pool != p.fsPool.Name || snapshot.Branch != branching.DefaultBranch {
+			continue
+		}
+
+		if !target.Time.IsZero() && snapshot.DataStateAt.After(target.Time) {
+			continue
+		}
+
+		if target.LSN != "" && !p.replayedBefore(snapshot.ID, target.LSN) {
+			continue
+		}
+
+		candidates = append(candidates, snapshot)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].DataStateAt.After(candidates[j].DataStateAt)
+	})
+
+	for _, snapshot := range candidates {
+		if preSnapshot := p.preSnapshot(snapshot.ID); preSnapshot != "" {
+			return snapshot, preSnapshot, nil
+		}
+
+		log.Dbg("Skip the snapshot without a pre-snapshot: ", snapshot.ID)
+	}
+
+	return resources.Snapshot{}, "", errors.Errorf("no snapshots taken before %s to recover the data from", target)
+}
+
+// replayedBefore reports whether the data of the snapshot has been replayed to a WAL location not after the target LSN.
+func (p *PhysicalInitial) replayedBefore(snapshotID, targetLSN string) bool {
+	properties, err := p.cloneManager.GetSnapshotProperties(snapshotID)
+	if err != nil {
+		log.Dbg("Skip the snapshot with unknown properties: ", snapshotID, err)
+		return false
+	}
+
+	if properties.WALPosition == "" {
+		log.Dbg("Skip the snapshot without a recorded WAL position: ", snapshotID)
+		return false
+	}
+
+	position, err := parseLSN(properties.WALPosition)
+	if err != nil {
+		log.Dbg("Skip the snapshot with an invalid WAL position: ", snapshotID, err)
+		return false
+	}
+
+	target, err := parseLSN(targetLSN)
+	if err != nil {
+		return false
+	}
+
+	return position <= target
+}
+
+// parseLSN converts the textual WAL location, e.g. 16/B374D848, to a number that can be compared.
+func parseLSN(lsn string) (uint64, error) {
+	if !lsnRegexp.MatchString(lsn) {
+		return 0, errors.Errorf("invalid LSN %q", lsn)
+	}
+
+	high, low, _ := strings.Cut(lsn, "/")
+
+	highValue, err := strconv.ParseUint(high, 16, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	lowValue, err := strconv.ParseUint(low, 16, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	return highValue<<32 | lowValue, nil
+}
+
+// preSnapshot returns the pre-snapshot the snapshot dataset has been cloned from.
+func (p *PhysicalInitial) preSnapshot(snapshotID string) string {
+	dataset, _, found := strings.Cut(snapshotID, "@")
+	if !found {
+		return ""
+	}
+
+	origins := p.cloneManager.GetDatasetOrigins(dataset)
+	if len(origins) == 0 {
+		return ""
+	}
+
+	origin := strings.TrimSpace(origins[0])
+	if !strings.HasSuffix(origin, pre) {
+		return ""
+	}
+
+	return origin
+}
+
+func (p *PhysicalInitial) commitRecoveredSnapshot(branchName, baseSnapshotID, snapshotName string, target RecoveryTarget) error {
+	if err := p.cloneManager.AddBranchProp(branchName, snapshotName); err != nil {
+		return fmt.Errorf("failed to add branch property: %w", err)
+	}
+
+	if err := p.cloneManager.SetRoot(branchName, baseSnapshotID); err != nil {
+		return fmt.Errorf("failed to set branch root: %w", err)
+	}
+
+	if err := p.cloneManager.SetRelation(baseSnapshotID, snapshotName); err != nil {
+		return fmt.Errorf("failed to set snapshot relation: %w", err)
+	}
+
+	if err := p.cloneManager.SetMessage("Point-in-time recovery to "+target.String(), snapshotName); err != nil {
+		return fmt.Errorf("failed to set snapshot message: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PhysicalInitial) pitrContainerName() string {
+	return pitrContainerPrefix + p.engineProps.InstanceID
+}
+
+// recoverInstance replays WAL in the clone up to the target, promotes it and returns dataStateAt of the recovered data.
+func (p *PhysicalInitial) recoverInstance(ctx context.Context, clonePath string, target RecoveryTarget) (dsa string, err error) {
+	cfgManager, err := pgconfig.NewCorrector(clonePath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to init configs manager")
+	}
+
+	if err := cfgManager.AdjustRecoveryFiles(); err != nil {
+		return "", errors.Wrap(err, "failed to adjust recovery configuration")
+	}
+
+	recoveryFileConfig, err := cfgManager.ReadRecoveryConfig()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read recovery configuration file")
+	}
+
+	recoveryConfig, err := buildPITRConfig(recoveryFileConfig, p.options.Promotion.Recovery, target)
+	if err != nil {
+		return "", err
+	}
+
+	// Replace the recovery config of the sync instance, so the clone neither streams from the source nor stops too early.
+	if err := cfgManager.RemoveRecoveryConfig(); err != nil {
+		return "", errors.Wrap(err, "failed to remove recovery config file")
+	}
+
+	if err := cfgManager.ApplyRecovery(recoveryConfig); err != nil {
+		return "", errors.Wrap(err, "failed to apply recovery configuration")
+	}
+
+	if promotionConfig := p.options.Promotion.Configs; len(promotionConfig) > 0 {
+		if err := cfgManager.ApplyPromotion(promotionConfig); err != nil {
+			return "", errors.Wrap(err, "failed to store prepared configuration")
+		}
+	}
+
+	hostConfig, err := p.buildHostConfig(ctx, clonePath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build container host config")
+	}
+
+	promoteImage := p.options.Promotion.DockerImage
+	if promoteImage == "" {
+		promoteImage = fmt.Sprintf("postgresai/extended-postgres:%g", cfgManager.GetPgVersion())
+	}
+
+	if err := tools.PullImage(ctx, p.dockerClient, promoteImage); err != nil {
+		return "", errors.Wrap(err, "failed to scan image pulling response")
+	}
+
+	pwd, err := tools.GeneratePassword()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate PostgreSQL password")
+	}
+
+	containerID, err := tools.CreateContainerIfMissing(ctx, p.dockerClient, p.pitrContainerName(),
+		p.buildContainerConfig(clonePath, promoteImage, pwd, promoteTargetAction), hostConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container %w", err)
+	}
+
+	defer tools.RemoveContainer(ctx, p.dockerClient, containerID, cont.StopPhysicalTimeout)
+
+	defer func() {
+		if err != nil {
+			tools.PrintContainerLogs(ctx, p.dockerClient, p.pitrContainerName())
+			tools.PrintLastPostgresLogs(ctx, p.dockerClient, p.pitrContainerName(), clonePath)
+		}
+	}()
+
+	log.Msg(fmt.Sprintf("Running container: %s. ID: %v", p.pitrContainerName(), containerID))
+
+	if err := p.dockerClient.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
+		return "", errors.Wrap(err, "failed to start container")
+	}
+
+	log.Msg("Replaying WAL and waiting for promotion")
+	log.Msg(fmt.Sprintf("View logs using the command: %s %s", tools.ViewLogsCmd, p.pitrContainerName()))
+
+	if err := tools.CheckContainerReadiness(ctx, p.dockerClient, containerID); err != nil {
+		return "", errors.Wrap(err, "failed to readiness check")
+	}
+
+	isInRecovery, err := p.checkRecovery(ctx, containerID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to check recovery mode")
+	}
+
+	if isInRecovery != "f" {
+		return "", errors.Errorf("PostgreSQL is in recovery, the recovery target has not been reached: %s", clonePath)
+	}
+
+	dsa = target.Time.UTC().Format(util.DataStateAtFormat)
+
+	if target.Time.IsZero() {
+		if dsa, err = p.extractDataStateAt(ctx, containerID, clonePath, cfgManager.GetPgVersion(), ""); err != nil {
+			return "", errors.Wrap(err, "failed to extract dataStateAt")
+		}
+	}
+
+	if err := p.finalizeInstance(ctx, containerID, clonePath, cfgManager); err != nil {
+		return "", err
+	}
+
+	return dsa, nil
+}
+
+// buildPITRConfig builds a recovery config replaying WAL from the archive up to the target.
+func buildPITRConfig(fileConfig, userRecoveryConfig map[string]string, target RecoveryTarget) (map[string]string, error) {
+	restoreCommand := userRecoveryConfig[restoreCommandOption]
+	if restoreCommand == "" {
+		restoreCommand = fileConfig[restoreCommandOption]
+	}
+
+	if restoreCommand == "" {
+		return nil, errors.New("point-in-time recovery requires restore_command: " +
+			"the restore tool does not provide it, define it in the recovery options of the promotion")
+	}
+
+	recoveryConfig := target.recoveryConfig()
+	recoveryConfig[restoreCommandOption] = restoreCommand
+
+	return recoveryConfig, nil
+}
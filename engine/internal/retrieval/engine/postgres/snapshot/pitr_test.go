@@ -0,0 +1,139 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/pool"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+)
+
+func TestParseLSN(t *testing.T) {
+	lsn, err := parseLSN("16/B374D848")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0x16B374D848), lsn)
+
+	low, err := parseLSN("16/B374D84")
+	require.NoError(t, err)
+	assert.Less(t, low, lsn)
+
+	_, err = parseLSN("B374D848")
+	assert.Error(t, err)
+}
+
+func TestRecoveryTargetValidate(t *testing.T) {
+	targetTime := time.Date(2026, 10, 16, 14, 32, 10, 0, time.UTC)
+
+	assert.NoError(t, RecoveryTarget{Time: targetTime}.Validate())
+	assert.NoError(t, RecoveryTarget{LSN: "16/B374D848"}.Validate())
+	assert.Error(t, RecoveryTarget{}.Validate())
+	assert.Error(t, RecoveryTarget{Time: targetTime, LSN: "16/B374D848"}.Validate())
+	assert.Error(t, RecoveryTarget{LSN: "16B374D848"}.Validate())
+}
+
+func TestRecoveryTargetBranchName(t *testing.T) {
+	targetTime := time.Date(2026, 10, 16, 16, 32, 10, 0, time.FixedZone("CEST", 2*60*60))
+
+	assert.Equal(t, "pitr_20261016143210", RecoveryTarget{Time: targetTime}.BranchName())
+	assert.Equal(t, "pitr_16_B374D848", RecoveryTarget{LSN: "16/b374d848"}.BranchName())
+	assert.Equal(t, "incident", RecoveryTarget{LSN: "16/B374D848", Branch: "incident"}.BranchName())
+}
+
+func TestBuildPITRConfig(t *testing.T) {
+	fileConfig := map[string]string{
+		"restore_command":   "wal-g wal-fetch %f %p",
+		"primary_conninfo":  "host=source",
+		"primary_slot_name": "dblab_slot",
+		"standby_mode":      "on",
+	}
+
+	recoveryConfig, err := buildPITRConfig(fileConfig, nil,
+		RecoveryTarget{Time: time.Date(2026, 10, 16, 14, 32, 10, 500000000, time.UTC)})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"restore_command":        "wal-g wal-fetch %f %p",
+		"recovery_target_time":   "2026-10-16 14:32:10.5+00",
+		"recovery_target_action": "promote",
+	}, recoveryConfig)
+
+	recoveryConfig, err = buildPITRConfig(fileConfig, map[string]string{"restore_command": "cp /archive/%f %p"},
+		RecoveryTarget{LSN: "16/B374D848"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"restore_command":        "cp /archive/%f %p",
+		"recovery_target_lsn":    "16/B374D848",
+		"recovery_target_action": "promote",
+	}, recoveryConfig)
+
+	_, err = buildPITRConfig(map[string]string{"primary_conninfo": "host=source"}, nil, RecoveryTarget{LSN: "16/B374D848"})
+	assert.ErrorContains(t, err, "requires restore_command")
+}
+
+type recoveryBaseFSManager struct {
+	pool.FSManager
+	snapshots    []resources.Snapshot
+	origins      map[string]string
+	walPositions map[string]string
+}
+
+func (m *recoveryBaseFSManager) SnapshotList() []resources.Snapshot {
+	return m.snapshots
+}
+
+func (m *recoveryBaseFSManager) GetSnapshotProperties(snapshotName string) (thinclones.SnapshotProperties, error) {
+	return thinclones.SnapshotProperties{Name: snapshotName, WALPosition: m.walPositions[snapshotName]}, nil
+}
+
+func (m *recoveryBaseFSManager) GetDatasetOrigins(dataset string) []string {
+	return []string{m.origins[dataset]}
+}
+
+func TestFindRecoveryBase(t *testing.T) {
+	dsa := func(hour int) time.Time { return time.Date(2026, 10, 16, hour, 0, 0, 0, time.UTC) }
+
+	fsm := &recoveryBaseFSManager{
+		snapshots: []resources.Snapshot{
+			{ID: "dblab/branch/main/clone_pre_20261016060000/r0@snapshot_20261016060000", DataStateAt: dsa(6), Pool: "dblab", Branch: "main"},
+			{ID: "dblab/branch/main/clone_pre_20261016120000/r0@snapshot_20261016120000", DataStateAt: dsa(12), Pool: "dblab", Branch: "main"},
+			{ID: "dblab/branch/main/clone_pre_20261016180000/r0@snapshot_20261016180000", DataStateAt: dsa(18), Pool: "dblab", Branch: "main"},
+			{ID: "dblab/branch/dev/clone1/r0@20261016130000", DataStateAt: dsa(13), Pool: "dblab", Branch: "dev"},
+			{ID: "dblab/branch/main/clone2/r0@20261016140000", DataStateAt: dsa(14), Pool: "dblab", Branch: "main"},
+		},
+		origins: map[string]string{
+			"dblab/branch/main/clone_pre_20261016060000/r0": "dblab@snapshot_20261016060000_pre",
+			"dblab/branch/main/clone_pre_20261016120000/r0": "dblab@snapshot_20261016120000_pre",
+			"dblab/branch/main/clone_pre_20261016180000/r0": "dblab@snapshot_20261016180000_pre",
+			"dblab/branch/main/clone2/r0":                   "dblab/branch/main/clone_pre_20261016120000/r0@snapshot_20261016120000",
+		},
+		walPositions: map[string]string{
+			"dblab/branch/main/clone_pre_20261016060000/r0@snapshot_20261016060000": "16/A0000000",
+			"dblab/branch/main/clone_pre_20261016120000/r0@snapshot_20261016120000": "16/B0000028",
+			"dblab/branch/main/clone_pre_20261016180000/r0@snapshot_20261016180000": "17/10000000",
+		},
+	}
+
+	p := &PhysicalInitial{cloneManager: fsm, fsPool: &resources.Pool{Name: "dblab"}}
+
+	base, preSnapshot, err := p.findRecoveryBase(RecoveryTarget{Time: dsa(15)})
+	require.NoError(t, err)
+	assert.Equal(t, "dblab/branch/main/clone_pre_20261016120000/r0@snapshot_20261016120000", base.ID)
+	assert.Equal(t, "dblab@snapshot_20261016120000_pre", preSnapshot)
+
+	base, _, err = p.findRecoveryBase(RecoveryTarget{LSN: "16/B374D848"})
+	require.NoError(t, err)
+	assert.Equal(t, "dblab/branch/main/clone_pre_20261016120000/r0@snapshot_20261016120000", base.ID)
+
+	base, _, err = p.findRecoveryBase(RecoveryTarget{LSN: "16/A0000000"})
+	require.NoError(t, err)
+	assert.Equal(t, "dblab/branch/main/clone_pre_20261016060000/r0@snapshot_20261016060000", base.ID)
+
+	_, _, err = p.findRecoveryBase(RecoveryTarget{LSN: "15/FFFFFFFF"})
+	assert.ErrorContains(t, err, "no snapshots taken before")
+
+	_, _, err = p.findRecoveryBase(RecoveryTarget{Time: dsa(5)})
+	assert.ErrorContains(t, err, "no snapshots taken before")
+}
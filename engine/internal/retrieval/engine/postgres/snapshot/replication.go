@@ -0,0 +1,901 @@
+/*
+2026 © Postgres.ai
+*/
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/diagnostic"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/databases/postgres/pgconfig"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/pool"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/config"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/dbmarker"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/activity"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/cont"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/fs"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/health"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/masking"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/options"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/probe"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/telemetry"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util/branching"
+)
+
+const (
+	// LogicalReplicationType declares a job type for keeping logical data up to date using logical replication.
+	LogicalReplicationType = "logicalReplication"
+
+	// DefaultSubscriptionName defines the name of the subscription created if no name is configured.
+	DefaultSubscriptionName = "dblab_subscription"
+
+	// minLogicalReplicationVersion defines the first Postgres version supporting logical replication.
+	minLogicalReplicationVersion = 10
+
+	// disabledReplicationWorkers prevents a snapshot instance from starting the apply worker
+	// and consuming changes from the replication slot of the sync instance.
+	disabledReplicationWorkers = "max_logical_replication_workers"
+)
+
+// subscriptionActivityQuery reports the apply workers of the subscription. The duration is the time
+// since the last message received from the publisher.
+const subscriptionActivityQuery = `select coalesce(a.usename, ''),
+  coalesce(extract(epoch from (clock_timestamp() - s.last_msg_receipt_time)), 0),
+  format('subscription %%s: received %%s, reported %%s', s.subname,
+    coalesce(s.received_lsn::text, '-'), coalesce(s.latest_end_lsn::text, '-')),
+  coalesce(a.wait_event_type, ''), coalesce(a.wait_event, '')
+from pg_stat_subscription s join pg_stat_activity a on a.pid = s.pid
+where s.subname = %s`
+
+// subscriptionActivityFields defines the number of fields in the output of subscriptionActivityQuery.
+const subscriptionActivityFields = 5
+
+// replicationNameRegexp matches names allowed for subscriptions and replication slots.
+var replicationNameRegexp = regexp.MustCompile(`^[a-z0-9_]{1,63}$`)
+
+// LogicalReplication describes a job keeping the data of a logical pool up to date via a subscription to the source.
+type LogicalReplication struct {
+	name           string
+	cloneManager   pool.FSManager
+	fsPool         *resources.Pool
+	dockerClient   *client.Client
+	options        LogicalReplicationOptions
+	globalCfg      *global.Config
+	engineProps    *global.EngineProps
+	dbMarker       *dbmarker.Marker
+	dbMark         *dbmarker.Config
+	scheduler      *cron.Cron
+	schedulerCtx   context.Context
+	schedulerMutex sync.Mutex
+	tm             *telemetry.Agent
+	snapshotReady  func()
+}
+
+// LogicalReplicationOptions describes options for a logical replication job.
+type LogicalReplicationOptions struct {
+	DockerImage     string                 `yaml:"dockerImage"`
+	ContainerConfig map[string]interface{} `yaml:"containerConfig"`
+	HealthCheck     HealthCheck            `yaml:"healthCheck"`
+	Source          ReplicationSource      `yaml:"source"`
+	Subscription    Subscription           `yaml:"subscription"`
+	Configs         map[string]string      `yaml:"configs"`
+	Scheduler       *Scheduler             `yaml:"scheduler"`
+	Masking         *masking.Config        `yaml:"masking"`
+}
+
+// ReplicationSource describes the publisher database.
+type ReplicationSource struct {
+	// ConnectionString is an optional libpq connection string of the publisher. When set it wins over
+	// the Connection fields. The password must not be embedded in it, use Connection.Password or PGPASSWORD.
+	ConnectionString string           `yaml:"connectionString"`
+	Connection       SourceConnection `yaml:"connection"`
+}
+
+// SourceConnection provides connection options of the publisher.
+type SourceConnection struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	DBName   string `yaml:"dbname"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password" json:"-"`
+}
+
+// Subscription describes the subscription created in the pool data.
+type Subscription struct {
+	Name        string `yaml:"name"`
+	Publication string `yaml:"publication"`
+	Database    string `yaml:"database"`
+	SlotName    string `yaml:"slotName"`
+}
+
+// SetDefaults fills the subscription and slot names and the subscribed database if they are not configured.
+func (s *Subscription) SetDefaults(dbName string) {
+	if s.Name == "" {
+		s.Name = DefaultSubscriptionName
+	}
+
+	if s.SlotName == "" {
+		s.SlotName = s.Name
+	}
+
+	if s.Database == "" {
+		s.Database = dbName
+	}
+}
+
+// NewLogicalReplicationJob creates a new logical replication job.
+func NewLogicalReplicationJob(cfg config.JobConfig, global *global.Config, engineProps *global.EngineProps,
+	cloneManager pool.FSManager, tm *telemetry.Agent) (*LogicalReplication, error) {
+	lr := &LogicalReplication{
+		name:          cfg.Spec.Name,
+		cloneManager:  cloneManager,
+		fsPool:        cfg.FSPool,
+		dockerClient:  cfg.Docker,
+		globalCfg:     global,
+		engineProps:   engineProps,
+		dbMarker:      cfg.Marker,
+		dbMark:        &dbmarker.Config{DataType: dbmarker.LogicalDataType},
+		tm:            tm,
+		snapshotReady: cfg.SnapshotReady,
+	}
+
+	if err := lr.loadConfig(cfg.Spec.Options); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal configuration options")
+	}
+
+	if err := lr.validateConfig(); err != nil {
+		return nil, errors.Wrap(err, "invalid logicalReplication configuration")
+	}
+
+	if lr.options.Scheduler.hasTimetables() {
+		lr.scheduler = cron.New()
+	}
+
+	return lr, nil
+}
+
+// Name returns a name of the job.
+func (r *LogicalReplication) Name() string {
+	return r.name
+}
+
+// Reload reloads job configuration.
+func (r *LogicalReplication) Reload(cfg map[string]interface{}) error {
+	if err := r.loadConfig(cfg); err != nil {
+		return errors.Wrap(err, "failed to load job config")
+	}
+
+	if err := r.validateConfig(); err != nil {
+		return errors.Wrap(err, "invalid logicalReplication configuration")
+	}
+
+	r.reloadScheduler()
+
+	return nil
+}
+
+// ReportActivity reports the current job activity.
+func (r *LogicalReplication) ReportActivity(ctx context.Context) (*activity.Activity, error) {
+	syncContainer, err := r.dockerClient.ContainerInspect(ctx, r.syncInstanceName())
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return &activity.Activity{}, nil
+		}
+
+		return nil, errors.Wrap(err, "failed to inspect the sync instance")
+	}
+
+	if syncContainer.State == nil || syncContainer.State.Health == nil || syncContainer.State.Health.Status != types.Healthy {
+		return &activity.Activity{}, nil
+	}
+
+	output, err := r.runQuery(ctx, syncContainer.ID, r.options.Subscription.Database,
+		fmt.Sprintf(subscriptionActivityQuery, quoteLiteral(r.options.Subscription.Name)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the subscription activity")
+	}
+
+	return &activity.Activity{Target: parseSubscriptionActivity(output)}, nil
+}
+
+// parseSubscriptionActivity parses the output of subscriptionActivityQuery.
+func parseSubscriptionActivity(output string) []activity.PGEvent {
+	events := make([]activity.PGEvent, 0)
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) != subscriptionActivityFields {
+			continue
+		}
+
+		duration, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			log.Dbg("Cannot parse the time since the last message:", fields[1])
+		}
+
+		events = append(events, activity.PGEvent{
+			User:          fields[0],
+			Duration:      duration,
+			Query:         fields[2],
+			WaitEventType: fields[3],
+			WaitEvent:     fields[4],
+		})
+	}
+
+	return events
+}
+
+func (r *LogicalReplication) loadConfig(cfg map[string]interface{}) error {
+	var opts LogicalReplicationOptions
+
+	if err := options.Unmarshal(cfg, &opts); err != nil {
+		return errors.Wrap(err, "failed to unmarshal configuration options")
+	}
+
+	opts.Subscription.SetDefaults(r.globalCfg.Database.Name())
+
+	r.options = opts
+
+	return nil
+}
+
+func (r *LogicalReplication) validateConfig() error {
+	sub := r.options.Subscription
+
+	if sub.Publication == "" {
+		return errors.New("subscription.publication is required")
+	}
+
+	if !replicationNameRegexp.MatchString(sub.Name) {
+		return errors.Errorf("invalid subscription name %q: it may contain only lower case letters, digits and underscores", sub.Name)
+	}
+
+	if !replicationNameRegexp.MatchString(sub.SlotName) {
+		return errors.Errorf("invalid slot name %q: it may contain only lower case letters, digits and underscores", sub.SlotName)
+	}
+
+	if _, err := r.options.Source.connInfo(); err != nil {
+		return errors.Wrap(err, "invalid source")
+	}
+
+	if err := r.options.Masking.Validate(); err != nil {
+		return err
+	}
+
+	return r.options.Scheduler.validate()
+}
+
+// connInfo builds the libpq connection string used by the subscription. The password is never included,
+// the sync instance passes it to libpq via PGPASSWORD.
+func (s ReplicationSource) connInfo() (string, error) {
+	if s.ConnectionString != "" {
+		if _, err := probe.ParseConnectionString(s.ConnectionString); err != nil {
+			return "", err
+		}
+
+		return s.ConnectionString, nil
+	}
+
+	conn := s.Connection
+
+	if conn.Host == "" || conn.DBName == "" || conn.Username == "" {
+		return "", errors.New("connectionString or connection host, dbname and username are required")
+	}
+
+	params := []string{
+		"host=" + quoteConnValue(conn.Host),
+		"dbname=" + quoteConnValue(conn.DBName),
+		"user=" + quoteConnValue(conn.Username),
+	}
+
+	if conn.Port != 0 {
+		params = append(params, "port="+strconv.Itoa(conn.Port))
+	}
+
+	return strings.Join(params, " "), nil
+}
+
+// password returns the publisher password. PGPASSWORD takes priority over the configured value.
+func (s ReplicationSource) password() string {
+	if pwd := os.Getenv("PGPASSWORD"); pwd != "" {
+		return pwd
+	}
+
+	return s.Connection.Password
+}
+
+// quoteConnValue quotes a value of a keyword/value connection string.
+func quoteConnValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// quoteLiteral quotes a string literal of an SQL statement.
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func buildCreateSubscriptionQuery(sub Subscription, connInfo string) string {
+	return fmt.Sprintf("create subscription %s connection %s publication %s with (slot_name = %s, create_slot = false, copy_data = false)",
+		pgx.Identifier{sub.Name}.Sanitize(),
+		quoteLiteral(connInfo),
+		pgx.Identifier{sub.Publication}.Sanitize(),
+		quoteLiteral(sub.SlotName),
+	)
+}
+
+// buildDetachSubscriptionQueries returns statements dropping the subscription without dropping the replication slot
+// on the publisher, which is still used by the sync instance.
+func buildDetachSubscriptionQueries(sub Subscription) []string {
+	name := pgx.Identifier{sub.Name}.Sanitize()
+
+	return []string{
+		fmt.Sprintf("alter subscription %s disable", name),
+		fmt.Sprintf("alter subscription %s set (slot_name = none)", name),
+		fmt.Sprintf("drop subscription %s", name),
+	}
+}
+
+// Run starts the job.
+func (r *LogicalReplication) Run(ctx context.Context) error {
+	log.Msg("Run job: ", r.Name())
+
+	r.schedulerCtx = ctx
+
+	dataDir := r.fsPool.DataDir()
+
+	pgVersion, err := tools.DetectPGVersion(dataDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to detect the Postgres version")
+	}
+
+	if pgVersion < minLogicalReplicationVersion {
+		return errors.Errorf("logical replication requires Postgres %d or newer, got %g", minLogicalReplicationVersion, pgVersion)
+	}
+
+	containerID, err := r.runSyncInstance(ctx, pgVersion)
+	if err != nil {
+		return errors.Wrap(err, "failed to start the sync instance")
+	}
+
+	if err := r.ensureSubscription(ctx, containerID); err != nil {
+		return errors.Wrap(err, "failed to subscribe to the source")
+	}
+
+	r.startScheduler(ctx)
+
+	return nil
+}
+
+func (r *LogicalReplication) syncInstanceName() string {
+	return cont.SyncInstanceContainerPrefix + r.engineProps.InstanceID
+}
+
+func (r *LogicalReplication) snapshotContainerName() string {
+	return promoteContainerPrefix + r.engineProps.InstanceID
+}
+
+func (r *LogicalReplication) dockerImage(pgVersion float64) string {
+	if r.options.DockerImage != "" {
+		return r.options.DockerImage
+	}
+
+	return fmt.Sprintf("postgresai/extended-postgres:%g", pgVersion)
+}
+
+func (r *LogicalReplication) runSyncInstance(ctx context.Context, pgVersion float64) (containerID string, err error) {
+	syncContainer, err := r.dockerClient.ContainerInspect(ctx, r.syncInstanceName())
+	if err != nil && !errdefs.IsNotFound(err) {
+		return "", errors.Wrap(err, "failed to inspect sync container")
+	}
+
+	if syncContainer.ContainerJSONBase != nil {
+		if syncContainer.State.Running {
+			log.Msg("Sync instance is already running")
+			return syncContainer.ID, nil
+		}
+
+		log.Msg("Removing non-running sync instance")
+
+		tools.RemoveContainer(ctx, r.dockerClient, syncContainer.ID, cont.StopPhysicalTimeout)
+	}
+
+	dataDir := r.fsPool.DataDir()
+
+	defer func() {
+		if err != nil {
+			tools.PrintContainerLogs(ctx, r.dockerClient, r.syncInstanceName())
+			tools.PrintLastPostgresLogs(ctx, r.dockerClient, r.syncInstanceName(), dataDir)
+
+			filterArgs := filters.NewArgs(
+				filters.KeyValuePair{Key: "label",
+					Value: fmt.Sprintf("%s=%s", cont.DBLabControlLabel, cont.DBLabSyncLabel)})
+
+			if err := diagnostic.CollectDiagnostics(ctx, r.dockerClient, filterArgs, r.syncInstanceName(), dataDir); err != nil {
+				log.Err("failed to collect container diagnostics", err)
+			}
+		}
+	}()
+
+	image := r.dockerImage(pgVersion)
+
+	if err := tools.PullImage(ctx, r.dockerClient, image); err != nil {
+		return "", errors.Wrap(err, "failed to scan image pulling response")
+	}
+
+	pwd, err := tools.GeneratePassword()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate PostgreSQL password")
+	}
+
+	hostConfig, err := cont.BuildHostConfig(ctx, r.dockerClient, dataDir, r.options.ContainerConfig)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build container host config")
+	}
+
+	socketDir := filepath.Join(r.fsPool.SocketDir(), r.syncInstanceName())
+	if err := os.MkdirAll(socketDir, 0755); err != nil {
+		return "", errors.Wrap(err, "failed to make socket directory")
+	}
+
+	hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+		Type:   mount.TypeBind,
+		Source: socketDir,
+		Target: cont.DefaultPostgresSocket,
+	})
+
+	containerCfg := r.buildContainerConfig(cont.DBLabSyncLabel, dataDir, image, pwd)
+	containerCfg.Env = append(containerCfg.Env, "PGPASSWORD="+r.options.Source.password())
+
+	log.Msg("Starting sync instance: ", r.syncInstanceName())
+
+	containerID, err = tools.CreateContainerIfMissing(ctx, r.dockerClient, r.syncInstanceName(), containerCfg, hostConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container %w", err)
+	}
+
+	if err := r.dockerClient.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
+		return "", errors.Wrap(err, "failed to start container")
+	}
+
+	log.Msg("Starting PostgreSQL and waiting for readiness")
+	log.Msg(fmt.Sprintf("View logs using the command: %s %s", tools.ViewLogsCmd, r.syncInstanceName()))
+
+	if err := tools.CheckContainerReadiness(ctx, r.dockerClient, containerID); err != nil {
+		return "", errors.Wrap(err, "failed to readiness check")
+	}
+
+	log.Msg("Sync instance has been running")
+
+	return containerID, nil
+}
+
+func (r *LogicalReplication) buildContainerConfig(label, dataDir, image, password string) *container.Config {
+	hcInterval := health.DefaultRestoreInterval
+	hcRetries := health.DefaultRestoreRetries
+
+	if r.options.HealthCheck.Interval != 0 {
+		hcInterval = time.Duration(r.options.HealthCheck.Interval) * time.Second
+	}
+
+	if r.options.HealthCheck.MaxRetries != 0 {
+		hcRetries = r.options.HealthCheck.MaxRetries
+	}
+
+	return &container.Config{
+		Labels: map[string]string{
+			cont.DBLabControlLabel:    label,
+			cont.DBLabInstanceIDLabel: r.engineProps.InstanceID,
+			cont.DBLabEngineNameLabel: r.engineProps.ContainerName,
+		},
+		Env: []string{
+			"PGDATA=" + dataDir,
+			"POSTGRES_PASSWORD=" + password,
+		},
+		Image: image,
+		Healthcheck: health.GetConfig(
+			r.globalCfg.Database.User(),
+			r.options.Subscription.Database,
+			health.OptionInterval(hcInterval),
+			health.OptionRetries(hcRetries),
+		),
+	}
+}
+
+// ensureSubscription creates the subscription unless the pool data already has it.
+func (r *LogicalReplication) ensureSubscription(ctx context.Context, containerID string) error {
+	sub := r.options.Subscription
+
+	exists, err := r.runQuery(ctx, containerID, sub.Database,
+		fmt.Sprintf("select count(*) from pg_subscription where subname = %s", quoteLiteral(sub.Name)))
+	if err != nil {
+		return errors.Wrap(err, "failed to check the subscription")
+	}
+
+	if exists != "0" {
+		log.Msg(fmt.Sprintf("Subscription %q already exists", sub.Name))
+		return nil
+	}
+
+	connInfo, err := r.options.Source.connInfo()
+	if err != nil {
+		return err
+	}
+
+	// The slot is created by the logicalDump job, which dumps the data from the snapshot exported by the slot.
+	// The subscription neither creates it nor copies the data, so it starts exactly where the dump ends.
+	slotQuery := fmt.Sprintf("select count(*) from pg_replication_slots where slot_name = %s and not active", quoteLiteral(sub.SlotName))
+
+	slots, err := tools.ExecCommandWithOutput(ctx, r.dockerClient, containerID, container.ExecOptions{
+		Cmd: []string{"psql", connInfo, "-XAtc", slotQuery},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to check the replication slot on the source")
+	}
+
+	if strings.TrimSpace(slots) == "0" {
+		return errors.Errorf("inactive replication slot %q is not found on the source; "+
+			"set replicationSlot of the logicalDump job to create it before the dump", sub.SlotName)
+	}
+
+	log.Msg(fmt.Sprintf("Creating subscription %q to publication %q", sub.Name, sub.Publication))
+
+	if _, err := r.runQuery(ctx, containerID, sub.Database, buildCreateSubscriptionQuery(sub, connInfo)); err != nil {
+		return errors.Wrap(err, "failed to create subscription")
+	}
+
+	log.Msg("Subscription has been created")
+
+	return nil
+}
+
+func (r *LogicalReplication) runQuery(ctx context.Context, containerID, dbName, query string) (string, error) {
+	return tools.ExecCommandWithOutput(ctx, r.dockerClient, containerID, container.ExecOptions{
+		Cmd: []string{"psql", "-U", r.globalCfg.Database.User(), "-d", dbName, "-XAtc", query},
+	})
+}
+
+func (r *LogicalReplication) reloadScheduler() {
+	if r.scheduler == nil {
+		log.Msg("Skip schedule reloading because it has not been initialized")
+		return
+	}
+
+	r.scheduler.Stop()
+
+	for _, ent := range r.scheduler.Entries() {
+		r.scheduler.Remove(ent.ID)
+	}
+
+	r.startScheduler(r.schedulerCtx)
+}
+
+func (r *LogicalReplication) startScheduler(ctx context.Context) {
+	if r.scheduler == nil || ctx == nil || !r.options.Scheduler.hasTimetables() {
+		return
+	}
+
+	if r.options.Scheduler.Snapshot.Timetable != "" {
+		if _, err := r.scheduler.AddFunc(r.options.Scheduler.Snapshot.Timetable, r.runAutoSnapshot(ctx)); err != nil {
+			log.Err(errors.Wrap(err, "failed to schedule a new snapshot job"))
+			return
+		}
+	}
+
+	if r.options.Scheduler.Retention.Timetable != "" {
+		if _, err := r.scheduler.AddFunc(r.options.Scheduler.Retention.Timetable,
+			r.runAutoCleanup(r.options.Scheduler.Retention)); err != nil {
+			log.Err(errors.Wrap(err, "failed to schedule a new cleanup job"))
+			return
+		}
+	}
+
+	r.scheduler.Start()
+
+	log.Msg("Snapshot scheduler has been started")
+
+	go r.waitToStopScheduler()
+}
+
+func (r *LogicalReplication) waitToStopScheduler() {
+	if r.schedulerCtx != nil {
+		<-r.schedulerCtx.Done()
+	}
+
+	if r.scheduler != nil {
+		log.Msg("Stop snapshot scheduler")
+		r.scheduler.Stop()
+	}
+}
+
+func (r *LogicalReplication) runAutoSnapshot(ctx context.Context) func() {
+	return func() {
+		r.schedulerMutex.Lock()
+		defer r.schedulerMutex.Unlock()
+
+		if err := r.takeSnapshot(ctx); err != nil {
+			log.Err(errors.Wrap(err, "failed to take a snapshot automatically"))
+		}
+
+		if err := r.cloneManager.InitBranching(); err != nil {
+			log.Err(fmt.Errorf("failed to init branching after scheduled snapshot: %w", err))
+		}
+
+		if err := r.cloneManager.VerifyBranchMetadata(); err != nil {
+			log.Err(fmt.Errorf("failed to verify branch metadata after scheduled snapshot: %w", err))
+		}
+	}
+}
+
+func (r *LogicalReplication) runAutoCleanup(retention ScheduleSpec) func() {
+	return func() {
+		if !r.schedulerMutex.TryLock() {
+			log.Msg("skipping scheduled cleanup: snapshot operation in progress")
+			return
+		}
+		defer r.schedulerMutex.Unlock()
+
+		select {
+		case <-r.schedulerCtx.Done():
+			log.Msg("Stop automatic snapshot cleanup")
+			return
+		default:
+		}
+
+		// Replication snapshots are taken from "pre" clones the same way as physical ones.
+		if _, err := retention.cleanupSnapshots(r.cloneManager, models.Physical); err != nil {
+			log.Err(errors.Wrap(err, "failed to clean up snapshots automatically"))
+		}
+	}
+}
+
+// takeSnapshot snapshots the data of the sync instance and detaches the subscription in the snapshot,
+// so clones do not connect to the source.
+func (r *LogicalReplication) takeSnapshot(ctx context.Context) (err error) {
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	log.Msg("Take a snapshot of the replicated data")
+
+	dataStateAt, err := r.checkSyncInstance(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to check the sync instance")
+	}
+
+	if previous := extractDataStateAt(r.dbMarker); previous != "" && previous == dataStateAt {
+		log.Msg(fmt.Sprintf("The previous snapshot already contains the latest data: %s. Skip taking a new snapshot.", previous))
+		return nil
+	}
+
+	startedAt := time.Now()
+	preDataStateAt := startedAt.Format(tools.DataStateAtFormat)
+	cloneName := fmt.Sprintf("clone%s_%s", pre, preDataStateAt)
+
+	defer func() { observeSnapshot(startedAt, err) }()
+
+	snapshotName, err := r.cloneManager.CreateSnapshot("", preDataStateAt+pre)
+	if err != nil {
+		return errors.Wrap(err, "failed to create snapshot")
+	}
+
+	defer func() {
+		if err != nil {
+			if errDestroy := r.cloneManager.DestroySnapshot(snapshotName, thinclones.DestroyOptions{}); errDestroy != nil {
+				log.Err(fmt.Sprintf("failed to destroy %q snapshot: %v", snapshotName, errDestroy))
+			}
+		}
+	}()
+
+	if err := r.cloneManager.CreateClone(branching.DefaultBranch, cloneName, snapshotName, branching.DefaultRevision); err != nil {
+		return errors.Wrapf(err, "failed to create \"pre\" clone %s", cloneName)
+	}
+
+	defer func() {
+		if err != nil {
+			cloneDataset := branching.CloneDataset(r.fsPool.Name, branching.DefaultBranch, cloneName)
+			if errDestroy := r.cloneManager.DestroyDataset(cloneDataset); errDestroy != nil {
+				log.Err(fmt.Sprintf("failed to destroy clone %q: %v", cloneName, errDestroy))
+			}
+		}
+	}()
+
+	cloneDataDir := path.Join(r.fsPool.CloneLocation(branching.DefaultBranch, cloneName, branching.DefaultRevision), r.fsPool.DataSubDir)
+	if err := fs.CleanupLogsDir(cloneDataDir); err != nil {
+		log.Warn("Failed to clean up logs directory:", err.Error())
+	}
+
+	if err := r.detachSubscription(ctx, cloneDataDir); err != nil {
+		return errors.Wrap(err, "failed to detach the subscription")
+	}
+
+	r.dbMark.DataStateAt = dataStateAt
+
+	if err := r.markDatabaseData(); err != nil {
+		return errors.Wrap(err, "failed to mark the prepared data")
+	}
+
+	// normalize ownership of the prepared data so clones inherit it and skip the per-clone chown.
+	if err := r.cloneManager.EnsureDataOwnership(cloneDataDir); err != nil {
+		return errors.Wrap(err, "failed to ensure data ownership")
+	}
+
+	fullClonePath := path.Join(branching.BranchDir, branching.DefaultBranch, cloneName, branching.RevisionSegment(branching.DefaultRevision))
+	snapshotID, err := r.cloneManager.CreateSnapshot(fullClonePath, dataStateAt)
+	if err != nil {
+		return errors.Wrap(err, "failed to create snapshot")
+	}
+
+	if r.options.Masking.Enabled() {
+		recordMaskingReport(cloneDataDir, snapshotID)
+	}
+
+	if dsaTime, err := time.Parse(util.DataStateAtFormat, dataStateAt); err == nil {
+		r.fsPool.SetDSA(dsaTime)
+	}
+
+	notifySnapshotReady(r.snapshotReady)
+
+	r.tm.SendEvent(ctx, telemetry.SnapshotCreatedEvent, telemetry.SnapshotCreated{})
+
+	return nil
+}
+
+// checkSyncInstance makes a checkpoint in the sync instance and returns the time of the latest replicated changes.
+func (r *LogicalReplication) checkSyncInstance(ctx context.Context) (string, error) {
+	syncContainer, err := r.dockerClient.ContainerInspect(ctx, r.syncInstanceName())
+	if err != nil {
+		return "", err
+	}
+
+	if err := tools.CheckContainerReadiness(ctx, r.dockerClient, syncContainer.ID); err != nil {
+		return "", errors.Wrap(err, "failed to readiness check")
+	}
+
+	if err := tools.RunCheckpoint(ctx, r.dockerClient, syncContainer.ID,
+		r.globalCfg.Database.User(), r.options.Subscription.Database); err != nil {
+		return "", errors.Wrap(err, "failed to make a checkpoint for sync instance")
+	}
+
+	output, err := r.runQuery(ctx, syncContainer.ID, r.options.Subscription.Database, fmt.Sprintf(
+		"select coalesce(extract(epoch from max(latest_end_time))::int8, 0) from pg_stat_subscription where subname = %s",
+		quoteLiteral(r.options.Subscription.Name)))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get the latest replicated time")
+	}
+
+	return parseReplicatedAt(output, time.Now())
+}
+
+// parseReplicatedAt converts the epoch of the latest replicated changes to dataStateAt.
+// If the apply worker has not reported any position yet, the current time is used.
+func parseReplicatedAt(output string, now time.Time) (string, error) {
+	epoch, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid epoch %q", output)
+	}
+
+	if epoch == 0 {
+		return now.Format(util.DataStateAtFormat), nil
+	}
+
+	return time.Unix(epoch, 0).Format(util.DataStateAtFormat), nil
+}
+
+// detachSubscription drops the subscription in the cloned data with the apply workers disabled
+// and applies snapshot configs.
+func (r *LogicalReplication) detachSubscription(ctx context.Context, clonePath string) (err error) {
+	cfgManager, err := pgconfig.NewCorrector(clonePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to init configs manager")
+	}
+
+	if err := cfgManager.ApplySync(map[string]string{disabledReplicationWorkers: "0"}); err != nil {
+		return errors.Wrap(err, "failed to disable logical replication workers")
+	}
+
+	image := r.dockerImage(cfgManager.GetPgVersion())
+
+	if err := tools.PullImage(ctx, r.dockerClient, image); err != nil {
+		return errors.Wrap(err, "failed to scan image pulling response")
+	}
+
+	pwd, err := tools.GeneratePassword()
+	if err != nil {
+		return errors.Wrap(err, "failed to generate PostgreSQL password")
+	}
+
+	hostConfig, err := cont.BuildHostConfig(ctx, r.dockerClient, clonePath, r.options.ContainerConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to build container host config")
+	}
+
+	containerID, err := tools.CreateContainerIfMissing(ctx, r.dockerClient, r.snapshotContainerName(),
+		r.buildContainerConfig(cont.DBLabPromoteLabel, clonePath, image, pwd), hostConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create container %w", err)
+	}
+
+	defer tools.RemoveContainer(ctx, r.dockerClient, containerID, cont.StopPhysicalTimeout)
+
+	defer func() {
+		if err != nil {
+			tools.PrintContainerLogs(ctx, r.dockerClient, r.snapshotContainerName())
+			tools.PrintLastPostgresLogs(ctx, r.dockerClient, r.snapshotContainerName(), clonePath)
+		}
+	}()
+
+	log.Msg(fmt.Sprintf("Running container: %s. ID: %v", r.snapshotContainerName(), containerID))
+
+	if err := r.dockerClient.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
+		return errors.Wrap(err, "failed to start container")
+	}
+
+	if err := tools.CheckContainerReadiness(ctx, r.dockerClient, containerID); err != nil {
+		return errors.Wrap(err, "failed to readiness check")
+	}
+
+	for _, query := range buildDetachSubscriptionQueries(r.options.Subscription) {
+		if _, err := r.runQuery(ctx, containerID, r.options.Subscription.Database, query); err != nil {
+			return errors.Wrapf(err, "failed to run %q", query)
+		}
+	}
+
+	if r.options.Masking.Enabled() {
+		if err := runMasking(ctx, r.dockerClient, containerID, r.options.Masking, r.globalCfg, clonePath); err != nil {
+			return err
+		}
+	}
+
+	if err := tools.RunCheckpoint(ctx, r.dockerClient, containerID,
+		r.globalCfg.Database.User(), r.options.Subscription.Database); err != nil {
+		return errors.Wrap(err, "failed to run checkpoint")
+	}
+
+	if err := tools.StopPostgres(ctx, r.dockerClient, containerID, clonePath, tools.DefaultStopTimeout); err != nil {
+		log.Msg("Failed to stop Postgres", err)
+		tools.PrintContainerLogs(ctx, r.dockerClient, containerID)
+	}
+
+	if err := cfgManager.TruncateSyncConfig(); err != nil {
+		return errors.Wrap(err, "failed to truncate sync config file")
+	}
+
+	return cfgManager.ApplySnapshot(r.options.Configs)
+}
+
+func (r *LogicalReplication) markDatabaseData() error {
+	if err := r.dbMarker.CreateConfig(); err != nil {
+		return errors.Wrap(err, "failed to create a DBMarker config of the database")
+	}
+
+	return r.dbMarker.SaveConfig(r.dbMark)
+}
@@ -6,9 +6,13 @@
 package snapshot
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/opmetrics"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/runners"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/dbmarker"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 )
@@ -33,3 +37,22 @@ func runPreprocessingScript(preprocessingScript string) error {
 
 	return nil
 }
+
+// observeSnapshot records the duration and the outcome of taking a snapshot, whether it is requested through the API
+// or scheduled. Skipped snapshots are not recorded.
+func observeSnapshot(startedAt time.Time, err error) {
+	var existsErr *thinclones.SnapshotExistsError
+
+	if _, ok := errors.Cause(err).(*skipSnapshotErr); ok || errors.As(err, &existsErr) {
+		return
+	}
+
+	opmetrics.ObserveOperation(opmetrics.ResourceSnapshot, "create", startedAt, err)
+}
+
+// notifySnapshotReady reports that a new data state of the pool is available for cloning.
+func notifySnapshotReady(snapshotReady func()) {
+	if snapshotReady != nil {
+		snapshotReady()
+	}
+}
@@ -0,0 +1,57 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/opmetrics"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+)
+
+func snapshotsCreated(t *testing.T, reg *prometheus.Registry, outcome string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "dblab_operations_total" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["resource"] == opmetrics.ResourceSnapshot && labels["operation"] == "create" && labels["outcome"] == outcome {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestObserveSnapshot(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	require.NoError(t, opmetrics.Register(reg))
+
+	succeeded := snapshotsCreated(t, reg, opmetrics.OutcomeSuccess)
+	failed := snapshotsCreated(t, reg, opmetrics.OutcomeFailure)
+
+	observeSnapshot(time.Now(), nil)
+	observeSnapshot(time.Now(), errors.New("failed to create snapshot"))
+	observeSnapshot(time.Now(), errors.Wrap(newSkipSnapshotErr("no new data"), "skip"))
+	observeSnapshot(time.Now(), errors.Wrap(thinclones.NewSnapshotExistsError("pool@snapshot"), "skip"))
+
+	assert.Equal(t, succeeded+1, snapshotsCreated(t, reg, opmetrics.OutcomeSuccess))
+	assert.Equal(t, failed+1, snapshotsCreated(t, reg, opmetrics.OutcomeFailure), "skipped snapshots are not recorded")
+}
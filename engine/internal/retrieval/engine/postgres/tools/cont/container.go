@@ -60,6 +60,8 @@ const (
 	DBLabFoundationLabel = "dblab_foundation"
 	// DBLabRenameLabel defines a label value for database rename containers.
 	DBLabRenameLabel = "dblab_rename"
+	// DBLabSettingsLabel defines a label value for containers reading Postgres settings metadata.
+	DBLabSettingsLabel = "dblab_settings"
 
 	// DBLabRunner defines a label to mark runner containers.
 	DBLabRunner = "dblab_runner"
@@ -0,0 +1,199 @@
+/*
+2026 © Postgres.ai
+*/
+
+package masking
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+const (
+	// fieldSeparator separates fields of the psql output.
+	fieldSeparator = "\x1f"
+
+	// The maximum length of varchar(n) and char(n) columns is their type modifier minus the header size, 0 means unlimited.
+	columnsQuery = `select a.attname, format_type(a.atttypid, a.atttypmod), t.typcategory, a.attnotnull,
+case when a.atttypid in ('varchar'::regtype, 'bpchar'::regtype) and a.atttypmod > 0 then a.atttypmod - 4 else 0 end
+from pg_attribute a join pg_type t on t.oid = a.atttypid
+where a.attrelid = %s::regclass and a.attnum > 0 and not a.attisdropped`
+
+	updateTag = "UPDATE "
+)
+
+// Masker applies masking rules to the Postgres instance running in a container.
+type Masker struct {
+	docker   *client.Client
+	cfg      *Config
+	dbName   string
+	username string
+}
+
+// NewMasker creates a new Masker. If the config does not define a database, dbName is used.
+func NewMasker(docker *client.Client, cfg *Config, dbName, username string) *Masker {
+	if cfg.Database != "" {
+		dbName = cfg.Database
+	}
+
+	return &Masker{docker: docker, cfg: cfg, dbName: dbName, username: username}
+}
+
+// plannedStatement binds a compiled statement to the report of its table.
+type plannedStatement struct {
+	query string
+	table int
+}
+
+// Run checks that all listed tables and columns exist and masks them in a single transaction.
+func (m *Masker) Run(ctx context.Context, containerID string) (*Report, error) {
+	report := &Report{Database: m.dbName}
+
+	var statements []plannedStatement
+
+	for _, rule := range m.cfg.Rules {
+		relation, err := m.query(ctx, containerID, fmt.Sprintf("select to_regclass(%s)::text", quoteLiteral(rule.Table)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up table %q: %w", rule.Table, err)
+		}
+
+		if relation == "" {
+			return nil, fmt.Errorf("table %q does not exist", rule.Table)
+		}
+
+		columns, err := m.columns(ctx, containerID, relation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns of table %q: %w", rule.Table, err)
+		}
+
+		queries, err := Compile(relation, rule, columns, m.cfg.Salt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, query := range queries {
+			statements = append(statements, plannedStatement{query: query, table: len(report.Tables)})
+		}
+
+		report.Tables = append(report.Tables, TableReport{Table: relation, Columns: rule.Columns})
+	}
+
+	// Triggers and foreign key checks are skipped because masking rewrites the existing data.
+	cmd := []string{"psql", "-U", m.username, "-d", m.dbName, "-X", "-v", "ON_ERROR_STOP=1", "--single-transaction",
+		"-c", "set session_replication_role = replica"}
+
+	for _, statement := range statements {
+		cmd = append(cmd, "-c", statement.query)
+	}
+
+	log.Msg(fmt.Sprintf("Masking %d tables of the database %q", len(report.Tables), m.dbName))
+
+	output, err := tools.ExecCommandWithOutput(ctx, m.docker, containerID, container.ExecOptions{Cmd: cmd})
+	if err != nil {
+		return nil, fmt.Errorf("failed to mask data: %w", err)
+	}
+
+	counts, err := parseUpdateCounts(output)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(counts) != len(statements) {
+		return nil, fmt.Errorf("unexpected masking output: %d updates for %d statements", len(counts), len(statements))
+	}
+
+	for i, statement := range statements {
+		if table := &report.Tables[statement.table]; counts[i] > table.Rows {
+			table.Rows = counts[i]
+		}
+	}
+
+	report.MaskedAt = time.Now().Format(util.DataStateAtFormat)
+
+	for _, table := range report.Tables {
+		log.Msg(fmt.Sprintf("Masked table %s: %d rows", table.Table, table.Rows))
+	}
+
+	return report, nil
+}
+
+func (m *Masker) query(ctx context.Context, containerID, query string) (string, error) {
+	output, err := tools.ExecCommandWithOutput(ctx, m.docker, containerID, container.ExecOptions{
+		Cmd: []string{"psql", "-U", m.username, "-d", m.dbName, "-XAtF", fieldSeparator, "-c", query},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+func (m *Masker) columns(ctx context.Context, containerID, relation string) (map[string]Column, error) {
+	output, err := m.query(ctx, containerID, fmt.Sprintf(columnsQuery, quoteLiteral(relation)))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseColumns(output)
+}
+
+func parseColumns(output string) (map[string]Column, error) {
+	columns := make(map[string]Column)
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, fieldSeparator)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("unexpected column description: %q", line)
+		}
+
+		maxLength, err := strconv.Atoi(fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected length of column %q: %w", fields[0], err)
+		}
+
+		columns[fields[0]] = Column{
+			Name:      fields[0],
+			Type:      fields[1],
+			Category:  fields[2],
+			NotNull:   fields[3] == "t",
+			MaxLength: maxLength,
+		}
+	}
+
+	return columns, nil
+}
+
+func parseUpdateCounts(output string) ([]int64, error) {
+	var counts []int64
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		if !strings.HasPrefix(line, updateTag) {
+			continue
+		}
+
+		count, err := strconv.ParseInt(strings.TrimPrefix(line, updateTag), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected masking output %q: %w", line, err)
+		}
+
+		counts = append(counts, count)
+	}
+
+	return counts, nil
+}
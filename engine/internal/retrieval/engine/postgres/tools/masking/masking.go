@@ -0,0 +1,284 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package masking compiles declarative data masking rules to SQL and applies them before snapshots are taken.
+package masking
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Method defines how values of a column are masked.
+type Method string
+
+const (
+	// MethodHash replaces values with the salted MD5 hash.
+	MethodHash Method = "hash"
+	// MethodFakeEmail replaces values with fake emails unique for each original value.
+	MethodFakeEmail Method = "fake_email"
+	// MethodNullify replaces values with NULL.
+	MethodNullify Method = "nullify"
+	// MethodShuffle randomly redistributes values of the column between rows.
+	MethodShuffle Method = "shuffle"
+	// MethodKeepFormat substitutes letters and digits keeping the length, case and separators of values.
+	MethodKeepFormat Method = "keep_format"
+	// MethodTokenize replaces values with short deterministic tokens, so equal values get equal tokens in all tables.
+	MethodTokenize Method = "tokenize"
+
+	// ReportName defines the name of the masking report stored with the prepared data.
+	ReportName = "masking"
+
+	// stringCategory defines the category of string types in pg_type.
+	stringCategory = "S"
+
+	fakeEmailDomain = "example.com"
+	fakeEmailPrefix = "user_"
+	fakeEmailLength = 12
+	tokenPrefix     = "tok_"
+	tokenLength     = 16
+	md5Length       = 32
+
+	digits    = "0123456789"
+	lowercase = "abcdefghijklmnopqrstuvwxyz"
+	uppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+// stringMethods lists methods applicable only to columns of string types.
+var stringMethods = map[Method]struct{}{
+	MethodHash:       {},
+	MethodFakeEmail:  {},
+	MethodKeepFormat: {},
+	MethodTokenize:   {},
+}
+
+// Config describes masking rules.
+type Config struct {
+	// Database defines the database to mask. By default, the database of the engine configuration is used.
+	Database string `yaml:"database"`
+	// Salt is mixed into hashes, tokens and format-keeping substitutions. Keep it secret and stable
+	// to get the same masked values in all snapshots.
+	Salt  string `yaml:"salt" json:"-"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule describes masking methods of the columns of a table.
+type Rule struct {
+	Table   string            `yaml:"table"`
+	Columns map[string]Method `yaml:"columns"`
+}
+
+// Column describes a table column.
+type Column struct {
+	Name     string
+	Type     string
+	Category string
+	NotNull  bool
+	// MaxLength defines the maximum length of values of varchar(n) and char(n) columns, 0 means unlimited.
+	MaxLength int
+}
+
+// Report describes the masking applied to the data.
+type Report struct {
+	Database string        `yaml:"database" json:"database"`
+	MaskedAt string        `yaml:"maskedAt" json:"maskedAt"`
+	Tables   []TableReport `yaml:"tables" json:"tables"`
+}
+
+// TableReport describes the masking applied to a table.
+type TableReport struct {
+	Table   string            `yaml:"table" json:"table"`
+	Rows    int64             `yaml:"rows" json:"rows"`
+	Columns map[string]Method `yaml:"columns" json:"columns"`
+}
+
+// Enabled checks if any masking rules are configured.
+func (c *Config) Enabled() bool {
+	return c != nil && len(c.Rules) > 0
+}
+
+// Validate checks the rules without connecting to the database.
+func (c *Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	tables := make(map[string]struct{}, len(c.Rules))
+
+	for _, rule := range c.Rules {
+		if rule.Table == "" {
+			return fmt.Errorf("masking rule without a table")
+		}
+
+		if _, ok := tables[rule.Table]; ok {
+			return fmt.Errorf("duplicate masking rule for table %q", rule.Table)
+		}
+
+		tables[rule.Table] = struct{}{}
+
+		if len(rule.Columns) == 0 {
+			return fmt.Errorf("masking rule for table %q has no columns", rule.Table)
+		}
+
+		for column, method := range rule.Columns {
+			if !method.valid() {
+				return fmt.Errorf("unknown masking method %q for column %q of table %q", method, column, rule.Table)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m Method) valid() bool {
+	switch m {
+	case MethodHash, MethodFakeEmail, MethodNullify, MethodShuffle, MethodKeepFormat, MethodTokenize:
+		return true
+	}
+
+	return false
+}
+
+// Compile builds the statements masking the table. The relation name must be quoted, as returned by regclass.
+// The first statement updates all columns except shuffled ones, which get a statement each.
+func Compile(relation string, rule Rule, columns map[string]Column, salt string) ([]string, error) {
+	var (
+		assignments []string
+		statements  []string
+	)
+
+	for _, name := range sortedColumns(rule.Columns) {
+		method := rule.Columns[name]
+
+		column, ok := columns[name]
+		if !ok {
+			return nil, fmt.Errorf("column %q of table %q does not exist", name, rule.Table)
+		}
+
+		if _, ok := stringMethods[method]; ok && column.Category != stringCategory {
+			return nil, fmt.Errorf("masking method %q cannot be applied to column %q of table %q with type %s",
+				method, name, rule.Table, column.Type)
+		}
+
+		if length := maskedLength(method); column.MaxLength > 0 && length > column.MaxLength {
+			return nil, fmt.Errorf("masking method %q produces values of %d characters, "+
+				"which do not fit column %q of table %q with type %s", method, length, name, rule.Table, column.Type)
+		}
+
+		if method == MethodNullify && column.NotNull {
+			return nil, fmt.Errorf("column %q of table %q is NOT NULL and cannot be nullified", name, rule.Table)
+		}
+
+		if method == MethodShuffle {
+			statements = append(statements, shuffleStatement(relation, pgx.Identifier{name}.Sanitize()))
+			continue
+		}
+
+		assignments = append(assignments, fmt.Sprintf("%s = %s",
+			pgx.Identifier{name}.Sanitize(), maskExpression(method, column, salt)))
+	}
+
+	if len(assignments) > 0 {
+		statements = append([]string{fmt.Sprintf("update %s set %s", relation, strings.Join(assignments, ", "))}, statements...)
+	}
+
+	return statements, nil
+}
+
+func sortedColumns(columns map[string]Method) []string {
+	names := make([]string, 0, len(columns))
+
+	for name := range columns {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// maskedLength returns the length of values produced by the method or 0 if the method keeps the length of values.
+// Casting to varchar(n) silently truncates longer values, so masked values have to fit the column.
+func maskedLength(method Method) int {
+	switch method {
+	case MethodHash:
+		return md5Length
+
+	case MethodFakeEmail:
+		return len(fakeEmailPrefix) + fakeEmailLength + len("@"+fakeEmailDomain)
+
+	case MethodTokenize:
+		return len(tokenPrefix) + tokenLength
+	}
+
+	return 0
+}
+
+func maskExpression(method Method, column Column, salt string) string {
+	value := pgx.Identifier{column.Name}.Sanitize()
+	hashed := fmt.Sprintf("md5(%s || %s::text)", quoteLiteral(salt), value)
+
+	var expression string
+
+	switch method {
+	case MethodNullify:
+		return "null"
+
+	case MethodHash:
+		expression = hashed
+
+	case MethodFakeEmail:
+		expression = fmt.Sprintf("'%s' || left(%s, %d) || '@%s'", fakeEmailPrefix, hashed, fakeEmailLength, fakeEmailDomain)
+
+	case MethodTokenize:
+		expression = fmt.Sprintf("'%s' || left(%s, %d)", tokenPrefix, hashed, tokenLength)
+
+	case MethodKeepFormat:
+		from, to := substitutionAlphabet(salt)
+		expression = fmt.Sprintf("translate(%s::text, %s, %s)", value, quoteLiteral(from), quoteLiteral(to))
+	}
+
+	return fmt.Sprintf("(%s)::%s", expression, column.Type)
+}
+
+// shuffleStatement builds a statement redistributing values of the column between rows in random order.
+func shuffleStatement(relation, column string) string {
+	return fmt.Sprintf(`with shuffled_rows as (
+  select tableoid as rel, ctid as row_id, row_number() over (order by random()) as rn from %[1]s
+), shuffled_values as (
+  select %[2]s as val, row_number() over () as rn from %[1]s
+)
+update %[1]s t set %[2]s = shuffled_values.val
+from shuffled_rows join shuffled_values using (rn)
+where t.tableoid = shuffled_rows.rel and t.ctid = shuffled_rows.row_id`, relation, column)
+}
+
+// substitutionAlphabet derives the permutation of letters and digits used by the keep_format method from the salt.
+func substitutionAlphabet(salt string) (string, string) {
+	sum := sha256.Sum256([]byte(salt))
+	rnd := rand.New(rand.NewPCG(binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16])))
+
+	from := digits + lowercase + uppercase
+
+	var to strings.Builder
+
+	for _, alphabet := range []string{digits, lowercase, uppercase} {
+		permuted := []byte(alphabet)
+		rnd.Shuffle(len(permuted), func(i, j int) { permuted[i], permuted[j] = permuted[j], permuted[i] })
+		to.Write(permuted)
+	}
+
+	return from, to.String()
+}
+
+// quoteLiteral quotes a string literal of an SQL statement.
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
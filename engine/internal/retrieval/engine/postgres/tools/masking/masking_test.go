@@ -0,0 +1,174 @@
+package masking
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var disabled *Config
+	assert.NoError(t, disabled.Validate())
+	assert.False(t, disabled.Enabled())
+
+	testCases := []struct {
+		name    string
+		rules   []Rule
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			rules: []Rule{{Table: "public.users", Columns: map[string]Method{"email": MethodFakeEmail, "city": MethodShuffle}}},
+		},
+		{
+			name:    "missing table",
+			rules:   []Rule{{Columns: map[string]Method{"email": MethodHash}}},
+			wantErr: "without a table",
+		},
+		{
+			name:    "no columns",
+			rules:   []Rule{{Table: "users"}},
+			wantErr: "has no columns",
+		},
+		{
+			name:    "unknown method",
+			rules:   []Rule{{Table: "users", Columns: map[string]Method{"email": "encrypt"}}},
+			wantErr: `unknown masking method "encrypt"`,
+		},
+		{
+			name: "duplicate table",
+			rules: []Rule{
+				{Table: "users", Columns: map[string]Method{"email": MethodHash}},
+				{Table: "users", Columns: map[string]Method{"phone": MethodKeepFormat}},
+			},
+			wantErr: "duplicate masking rule",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := (&Config{Rules: tc.rules}).Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	columns := map[string]Column{
+		"email":   {Name: "email", Type: "character varying(255)", Category: "S", MaxLength: 255},
+		"code":    {Name: "code", Type: "character varying(20)", Category: "S", MaxLength: 20},
+		"Name":    {Name: "Name", Type: "text", Category: "S"},
+		"notes":   {Name: "notes", Type: "text", Category: "S"},
+		"city":    {Name: "city", Type: "text", Category: "S"},
+		"balance": {Name: "balance", Type: "numeric", Category: "N"},
+		"login":   {Name: "login", Type: "text", Category: "S", NotNull: true},
+	}
+
+	statements, err := Compile("public.users", Rule{Table: "users", Columns: map[string]Method{
+		"email": MethodFakeEmail,
+		"Name":  MethodHash,
+		"notes": MethodNullify,
+		"city":  MethodShuffle,
+		"login": MethodTokenize,
+	}}, columns, "s'alt")
+	require.NoError(t, err)
+	require.Len(t, statements, 2)
+
+	assert.Equal(t, `update public.users set "Name" = (md5('s''alt' || "Name"::text))::text, `+
+		`"email" = ('user_' || left(md5('s''alt' || "email"::text), 12) || '@example.com')::character varying(255), `+
+		`"login" = ('tok_' || left(md5('s''alt' || "login"::text), 16))::text, "notes" = null`, statements[0])
+	assert.Contains(t, statements[1], `update public.users t set "city" = shuffled_values.val`)
+
+	t.Run("missing column", func(t *testing.T) {
+		_, err := Compile("public.users", Rule{Table: "users", Columns: map[string]Method{"phone": MethodKeepFormat}}, columns, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `column "phone" of table "users" does not exist`)
+	})
+
+	t.Run("string method on numeric column", func(t *testing.T) {
+		_, err := Compile("public.users", Rule{Table: "users", Columns: map[string]Method{"balance": MethodHash}}, columns, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be applied")
+	})
+
+	t.Run("nullify NOT NULL column", func(t *testing.T) {
+		_, err := Compile("public.users", Rule{Table: "users", Columns: map[string]Method{"login": MethodNullify}}, columns, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be nullified")
+	})
+
+	t.Run("masked values longer than column", func(t *testing.T) {
+		_, err := Compile("public.users", Rule{Table: "users", Columns: map[string]Method{"code": MethodHash}}, columns, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `produces values of 32 characters, which do not fit column "code"`)
+
+		_, err = Compile("public.users", Rule{Table: "users", Columns: map[string]Method{"code": MethodFakeEmail}}, columns, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "produces values of 29 characters")
+	})
+
+	t.Run("masked values fitting column", func(t *testing.T) {
+		statements, err := Compile("public.users", Rule{Table: "users", Columns: map[string]Method{"code": MethodTokenize}}, columns, "")
+		require.NoError(t, err)
+		require.Len(t, statements, 1)
+
+		statements, err = Compile("public.users", Rule{Table: "users", Columns: map[string]Method{"code": MethodKeepFormat}}, columns, "")
+		require.NoError(t, err)
+		require.Len(t, statements, 1)
+	})
+
+	t.Run("only shuffle", func(t *testing.T) {
+		statements, err := Compile("public.users", Rule{Table: "users", Columns: map[string]Method{"balance": MethodShuffle}}, columns, "")
+		require.NoError(t, err)
+		require.Len(t, statements, 1)
+		assert.True(t, strings.HasPrefix(statements[0], "with shuffled_rows"))
+	})
+}
+
+func TestSubstitutionAlphabet(t *testing.T) {
+	from, to := substitutionAlphabet("salt")
+
+	assert.Equal(t, digits+lowercase+uppercase, from)
+	require.Len(t, to, len(from))
+	assert.ElementsMatch(t, []byte(digits), []byte(to[:10]))
+	assert.ElementsMatch(t, []byte(lowercase), []byte(to[10:36]))
+	assert.ElementsMatch(t, []byte(uppercase), []byte(to[36:]))
+
+	_, sameTo := substitutionAlphabet("salt")
+	assert.Equal(t, to, sameTo)
+
+	_, otherTo := substitutionAlphabet("other")
+	assert.NotEqual(t, to, otherTo)
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := parseColumns("id\x1finteger\x1fN\x1ft\x1f0\nemail\x1fcharacter varying(255)\x1fS\x1ff\x1f255\n")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Column{
+		"id":    {Name: "id", Type: "integer", Category: "N", NotNull: true},
+		"email": {Name: "email", Type: "character varying(255)", Category: "S", MaxLength: 255},
+	}, columns)
+
+	_, err = parseColumns("id|integer")
+	assert.Error(t, err)
+
+	_, err = parseColumns("id\x1finteger\x1fN\x1ft\x1fn/a\n")
+	assert.Error(t, err)
+}
+
+func TestParseUpdateCounts(t *testing.T) {
+	counts, err := parseUpdateCounts("SET\nUPDATE 42\nUPDATE 0\n")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{42, 0}, counts)
+
+	_, err = parseUpdateCounts("UPDATE many")
+	assert.Error(t, err)
+}
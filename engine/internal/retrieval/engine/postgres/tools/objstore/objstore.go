@@ -0,0 +1,236 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package objstore provides tools for transferring dumps to and from S3-compatible object storages.
+package objstore
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5" //nolint:gosec // MD5 is used only to compare with ETags of single-part uploads
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+const (
+	// ChecksumMetadataKey defines the user metadata key containing the SHA-256 checksum of an uploaded object.
+	ChecksumMetadataKey = "sha256"
+
+	// ChecksumSuffix defines the suffix of sidecar objects containing checksums in the sha256sum format.
+	ChecksumSuffix = ".sha256"
+
+	md5Length = 32
+)
+
+// ErrNotFound occurs when the requested object does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Config defines the connection to an S3-compatible object storage.
+type Config struct {
+	Bucket          string `yaml:"bucket"`
+	Prefix          string `yaml:"prefix"`
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	ForcePathStyle  bool   `yaml:"forcePathStyle"`
+	CredentialsFile string `yaml:"credentialsFile"`
+	Profile         string `yaml:"profile"`
+}
+
+// Enabled checks if the object storage is configured.
+func (c *Config) Enabled() bool {
+	return c != nil && c.Bucket != ""
+}
+
+// Key builds the object key from the configured prefix and the path elements.
+func (c *Config) Key(elem ...string) string {
+	key := path.Join(elem...)
+
+	if prefix := strings.TrimSuffix(c.Prefix, "/"); prefix != "" {
+		key = prefix + "/" + key
+	}
+
+	return key
+}
+
+// Object describes a stored object.
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	ETag         string
+	Metadata     map[string]string
+}
+
+// Storage describes an object storage.
+type Storage interface {
+	// List returns all objects with keys starting with the prefix.
+	List(ctx context.Context, prefix string) ([]Object, error)
+
+	// Get opens the object for reading. Returns ErrNotFound if the object does not exist.
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+
+	// Put stores the object with the user metadata.
+	Put(ctx context.Context, key string, body io.Reader, metadata map[string]string) error
+}
+
+// Download copies the object to the local file and verifies its checksum. The expected SHA-256 checksum
+// is taken from the argument, the object metadata or, for single-part uploads, from the ETag.
+func Download(ctx context.Context, s Storage, key, dst, checksum string) error {
+	body, obj, err := s.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to get object %q: %w", key, err)
+	}
+
+	defer func() { _ = body.Close() }()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", dst, err)
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %w", dst, err)
+	}
+
+	sha256Hash, md5Hash := sha256.New(), md5.New() //nolint:gosec
+
+	if _, err := io.Copy(io.MultiWriter(f, sha256Hash, md5Hash), body); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to download object %q: %w", key, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close file %q: %w", dst, err)
+	}
+
+	if err := verifyChecksum(obj, checksum, sha256Hash, md5Hash); err != nil {
+		if removeErr := os.Remove(dst); removeErr != nil {
+			log.Dbg("Cannot remove the corrupted file:", removeErr)
+		}
+
+		return fmt.Errorf("failed to verify object %q: %w", key, err)
+	}
+
+	return nil
+}
+
+func verifyChecksum(obj Object, checksum string, sha256Hash, md5Hash hash.Hash) error {
+	if checksum == "" {
+		checksum = obj.Metadata[ChecksumMetadataKey]
+	}
+
+	if checksum != "" {
+		if actual := hex.EncodeToString(sha256Hash.Sum(nil)); !strings.EqualFold(actual, checksum) {
+			return fmt.Errorf("SHA-256 checksum mismatch: expected %s, got %s", checksum, actual)
+		}
+
+		return nil
+	}
+
+	// ETags of multipart uploads are not MD5 checksums of the content.
+	etag := strings.Trim(obj.ETag, `"`)
+	if len(etag) == md5Length && !strings.Contains(etag, "-") {
+		if actual := hex.EncodeToString(md5Hash.Sum(nil)); !strings.EqualFold(actual, etag) {
+			return fmt.Errorf("MD5 checksum mismatch: expected %s, got %s", etag, actual)
+		}
+
+		return nil
+	}
+
+	log.Warn(fmt.Sprintf("No checksum available for object %q; skipping verification", obj.Key))
+
+	return nil
+}
+
+// ReadChecksum reads a SHA-256 checksum from the sidecar object in the sha256sum format.
+func ReadChecksum(ctx context.Context, s Storage, key string) (string, error) {
+	body, _, err := s.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = body.Close() }()
+
+	line, err := bufio.NewReader(io.LimitReader(body, 1024)).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read checksum %q: %w", key, err)
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("checksum object %q is empty", key)
+	}
+
+	return fields[0], nil
+}
+
+// Upload copies the local file to the object storage, recording its SHA-256 checksum in the object metadata.
+func Upload(ctx context.Context, s Storage, src, key string) error {
+	checksum, err := fileChecksum(src)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open file %q: %w", src, err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	if err := s.Put(ctx, key, f, map[string]string{ChecksumMetadataKey: checksum}); err != nil {
+		return fmt.Errorf("failed to upload %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// UploadDir copies all files of the local directory to the object storage under the key prefix.
+func UploadDir(ctx context.Context, s Storage, srcDir, keyPrefix string) error {
+	return filepath.WalkDir(srcDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(srcDir, filePath)
+		if err != nil {
+			return err
+		}
+
+		return Upload(ctx, s, filePath, path.Join(keyPrefix, filepath.ToSlash(relPath)))
+	})
+}
+
+func fileChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %q: %w", filePath, err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to calculate checksum of %q: %w", filePath, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
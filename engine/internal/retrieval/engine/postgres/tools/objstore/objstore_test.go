@@ -0,0 +1,205 @@
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStorage struct {
+	objects map[string]Object
+	data    map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{objects: make(map[string]Object), data: make(map[string][]byte)}
+}
+
+func (m *memoryStorage) List(_ context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+
+	for key, obj := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, obj)
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+
+	return objects, nil
+}
+
+func (m *memoryStorage) Get(_ context.Context, key string) (io.ReadCloser, Object, error) {
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, Object{}, ErrNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(m.data[key])), obj, nil
+}
+
+func (m *memoryStorage) Put(_ context.Context, key string, body io.Reader, metadata map[string]string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	sum := md5.Sum(data) //nolint:gosec
+
+	m.data[key] = data
+	m.objects[key] = Object{
+		Key:          key,
+		Size:         int64(len(data)),
+		LastModified: time.Now(),
+		ETag:         `"` + hex.EncodeToString(sum[:]) + `"`,
+		Metadata:     metadata,
+	}
+
+	return nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestConfigKey(t *testing.T) {
+	assert.Equal(t, "shop/20261017/shop.dump", (&Config{}).Key("shop", "20261017", "shop.dump"))
+	assert.Equal(t, "dumps/shop/shop.dump", (&Config{Prefix: "dumps/"}).Key("shop", "shop.dump"))
+	assert.Equal(t, "dumps/shop.dump", (&Config{Prefix: "dumps"}).Key("shop.dump"))
+}
+
+func TestConfigEnabled(t *testing.T) {
+	var cfg *Config
+	assert.False(t, cfg.Enabled())
+	assert.False(t, (&Config{Endpoint: "http://localhost:9000"}).Enabled())
+	assert.True(t, (&Config{Bucket: "dumps"}).Enabled())
+}
+
+func TestDownloadVerifiesChecksum(t *testing.T) {
+	ctx := context.Background()
+	content := []byte("PGDMP dump content")
+	contentMD5 := md5.Sum(content) //nolint:gosec
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name     string
+		object   Object
+		checksum string
+		wantErr  string
+	}{
+		{
+			name:   "metadata checksum",
+			object: Object{Key: "dump", Metadata: map[string]string{ChecksumMetadataKey: sha256Hex(content)}},
+		},
+		{
+			name:    "metadata checksum mismatch",
+			object:  Object{Key: "dump", Metadata: map[string]string{ChecksumMetadataKey: sha256Hex([]byte("other"))}},
+			wantErr: "SHA-256 checksum mismatch",
+		},
+		{
+			name:     "explicit checksum takes precedence",
+			object:   Object{Key: "dump", Metadata: map[string]string{ChecksumMetadataKey: sha256Hex([]byte("other"))}},
+			checksum: strings.ToUpper(sha256Hex(content)),
+		},
+		{
+			name:   "single-part ETag",
+			object: Object{Key: "dump", ETag: `"` + hex.EncodeToString(contentMD5[:]) + `"`},
+		},
+		{
+			name:    "single-part ETag mismatch",
+			object:  Object{Key: "dump", ETag: `"0123456789abcdef0123456789abcdef"`},
+			wantErr: "MD5 checksum mismatch",
+		},
+		{
+			name:   "multipart ETag is not verified",
+			object: Object{Key: "dump", ETag: `"0123456789abcdef0123456789abcdef-3"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			storage := newMemoryStorage()
+			storage.objects["dump"] = tc.object
+			storage.data["dump"] = content
+
+			dst := filepath.Join(dir, tc.name, "dump")
+
+			err := Download(ctx, storage, "dump", dst, tc.checksum)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				assert.NoFileExists(t, dst)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			downloaded, err := os.ReadFile(dst)
+			require.NoError(t, err)
+			assert.Equal(t, content, downloaded)
+		})
+	}
+}
+
+func TestDownloadNotFound(t *testing.T) {
+	err := Download(context.Background(), newMemoryStorage(), "missing", filepath.Join(t.TempDir(), "dump"), "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestReadChecksum(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemoryStorage()
+
+	require.NoError(t, storage.Put(ctx, "shop.dump.sha256", strings.NewReader("abc123  shop.dump\n"), nil))
+	require.NoError(t, storage.Put(ctx, "empty.sha256", strings.NewReader(""), nil))
+
+	checksum, err := ReadChecksum(ctx, storage, "shop.dump.sha256")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", checksum)
+
+	_, err = ReadChecksum(ctx, storage, "empty.sha256")
+	assert.Error(t, err)
+
+	_, err = ReadChecksum(ctx, storage, "missing.sha256")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUploadDirRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemoryStorage()
+
+	srcDir := filepath.Join(t.TempDir(), "shop")
+	require.NoError(t, os.MkdirAll(srcDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "toc.dat"), []byte("toc"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "3001.dat.gz"), []byte("table data"), 0600))
+
+	require.NoError(t, UploadDir(ctx, storage, srcDir, "dumps/shop/20261017/shop"))
+
+	objects, err := storage.List(ctx, "dumps/shop/")
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, "dumps/shop/20261017/shop/3001.dat.gz", objects[0].Key)
+	assert.Equal(t, sha256Hex([]byte("table data")), objects[0].Metadata[ChecksumMetadataKey])
+	assert.Equal(t, "dumps/shop/20261017/shop/toc.dat", objects[1].Key)
+
+	dst := filepath.Join(t.TempDir(), "toc.dat")
+	require.NoError(t, Download(ctx, storage, "dumps/shop/20261017/shop/toc.dat", dst, ""))
+
+	downloaded, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("toc"), downloaded)
+}
@@ -0,0 +1,145 @@
+/*
+2026 © Postgres.ai
+*/
+
+package objstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"                  //nolint:staticcheck // TODO: migrate to aws-sdk-go-v2
+	"github.com/aws/aws-sdk-go/aws/awserr"           //nolint:staticcheck // TODO: migrate to aws-sdk-go-v2
+	"github.com/aws/aws-sdk-go/aws/credentials"      //nolint:staticcheck // TODO: migrate to aws-sdk-go-v2
+	"github.com/aws/aws-sdk-go/aws/session"          //nolint:staticcheck // TODO: migrate to aws-sdk-go-v2
+	"github.com/aws/aws-sdk-go/service/s3"           //nolint:staticcheck // TODO: migrate to aws-sdk-go-v2
+	"github.com/aws/aws-sdk-go/service/s3/s3manager" //nolint:staticcheck // TODO: migrate to aws-sdk-go-v2
+)
+
+const defaultRegion = "us-east-1"
+
+// S3 provides access to a bucket of an S3-compatible object storage.
+type S3 struct {
+	bucket   string
+	client   *s3.S3
+	uploader *s3manager.Uploader
+}
+
+// NewS3 creates a new S3 storage. Credentials are read from the credentials file if it is configured,
+// otherwise from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables or other default sources.
+func NewS3(cfg Config) (*S3, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+
+	awsCfg := aws.NewConfig().WithS3ForcePathStyle(cfg.ForcePathStyle)
+
+	region := cfg.Region
+	if region == "" {
+		region = defaultRegion
+	}
+
+	awsCfg = awsCfg.WithRegion(region)
+
+	if cfg.Endpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(cfg.Endpoint)
+	}
+
+	if cfg.CredentialsFile != "" {
+		awsCfg = awsCfg.WithCredentials(credentials.NewSharedCredentials(cfg.CredentialsFile, cfg.Profile))
+	}
+
+	awsSession, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start AWS session: %w", err)
+	}
+
+	return &S3{
+		bucket:   cfg.Bucket,
+		client:   s3.New(awsSession),
+		uploader: s3manager.NewUploader(awsSession),
+	}, nil
+}
+
+// List returns all objects with keys starting with the prefix.
+func (s *S3) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, item := range page.Contents {
+			objects = append(objects, Object{
+				Key:          aws.StringValue(item.Key),
+				Size:         aws.Int64Value(item.Size),
+				LastModified: aws.TimeValue(item.LastModified),
+				ETag:         aws.StringValue(item.ETag),
+			})
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects of bucket %q: %w", s.bucket, err)
+	}
+
+	return objects, nil
+}
+
+// Get opens the object for reading.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	output, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, Object{}, ErrNotFound
+		}
+
+		return nil, Object{}, err
+	}
+
+	obj := Object{
+		Key:          key,
+		Size:         aws.Int64Value(output.ContentLength),
+		LastModified: aws.TimeValue(output.LastModified),
+		ETag:         aws.StringValue(output.ETag),
+		Metadata:     make(map[string]string, len(output.Metadata)),
+	}
+
+	// The SDK canonicalizes metadata keys as HTTP headers.
+	for name, value := range output.Metadata {
+		obj.Metadata[strings.ToLower(name)] = aws.StringValue(value)
+	}
+
+	return output.Body, obj, nil
+}
+
+// Put stores the object, using multipart uploads for large objects.
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, metadata map[string]string) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		Body:     body,
+		Metadata: aws.StringMap(metadata),
+	})
+
+	return err
+}
+
+func isNotFound(err error) bool {
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotFound {
+		return true
+	}
+
+	var awsErr awserr.Error
+
+	return errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey
+}
@@ -0,0 +1,97 @@
+//go:build integration
+// +build integration
+
+/*
+2026 © Postgres.ai
+*/
+
+package objstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"        //nolint:staticcheck
+	"github.com/aws/aws-sdk-go/service/s3" //nolint:staticcheck
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	minioImage     = "minio/minio:latest"
+	minioAccessKey = "dblab-test"
+	minioSecretKey = "dblab-test-secret"
+	minioBucket    = "dumps"
+)
+
+func startMinIO(t *testing.T) string {
+	t.Helper()
+
+	ctx := context.Background()
+
+	minio, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        minioImage,
+			ExposedPorts: []string{"9000/tcp"},
+			Env: map[string]string{
+				"MINIO_ROOT_USER":     minioAccessKey,
+				"MINIO_ROOT_PASSWORD": minioSecretKey,
+			},
+			Cmd:        []string{"server", "/data"},
+			WaitingFor: wait.ForHTTP("/minio/health/live").WithPort("9000/tcp").WithStartupTimeout(60 * time.Second),
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = minio.Terminate(ctx) })
+
+	host, err := minio.Host(ctx)
+	require.NoError(t, err)
+
+	port, err := minio.MappedPort(ctx, "9000/tcp")
+	require.NoError(t, err)
+
+	return fmt.Sprintf("http://%s:%s", host, port.Port())
+}
+
+func TestS3RoundTrip_Integration(t *testing.T) {
+	ctx := context.Background()
+	endpoint := startMinIO(t)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", minioAccessKey)
+	t.Setenv("AWS_SECRET_ACCESS_KEY", minioSecretKey)
+
+	storage, err := NewS3(Config{Bucket: minioBucket, Endpoint: endpoint, ForcePathStyle: true})
+	require.NoError(t, err)
+
+	_, err = storage.client.CreateBucketWithContext(ctx, &s3.CreateBucketInput{Bucket: aws.String(minioBucket)})
+	require.NoError(t, err)
+
+	srcDir := filepath.Join(t.TempDir(), "shop")
+	require.NoError(t, os.MkdirAll(srcDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "toc.dat"), []byte("toc"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "3001.dat"), []byte("table data"), 0600))
+
+	require.NoError(t, UploadDir(ctx, storage, srcDir, "dblab/shop/20261017000000/shop"))
+
+	objects, err := storage.List(ctx, "dblab/shop/")
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+
+	dst := filepath.Join(t.TempDir(), "3001.dat")
+	require.NoError(t, Download(ctx, storage, "dblab/shop/20261017000000/shop/3001.dat", dst, ""))
+
+	downloaded, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("table data"), downloaded)
+
+	_, _, err = storage.Get(ctx, "dblab/shop/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,403 @@
+/*
+2026 © Postgres.ai
+*/
+
+package subset
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+)
+
+const (
+	// minServerVersion defines the minimal source version: partitions and pg_sequences appeared in Postgres 10.
+	minServerVersion = 100000
+
+	// generatedColumnsVersion defines the version that introduced generated columns.
+	generatedColumnsVersion = 120000
+
+	// batchSize limits the number of rows passed to a single query.
+	batchSize = 10000
+
+	relationQuery = `select format('%%I.%%I', n.nspname, c.relname), c.relkind,
+  array(select a.attname from pg_attribute a
+    where a.attrelid = c.oid and a.attnum > 0 and not a.attisdropped%s order by a.attnum)
+from pg_class c join pg_namespace n on n.oid = c.relnamespace
+where c.oid = to_regclass($1)`
+
+	foreignKeysQuery = `select format('%I.%I', cn.nspname, cr.relname), format('%I.%I', fn.nspname, fr.relname),
+  array(select a.attname from unnest(c.conkey) with ordinality k(attnum, n)
+    join pg_attribute a on a.attrelid = c.conrelid and a.attnum = k.attnum order by k.n),
+  array(select a.attname from unnest(c.confkey) with ordinality k(attnum, n)
+    join pg_attribute a on a.attrelid = c.confrelid and a.attnum = k.attnum order by k.n)
+from pg_constraint c
+  join pg_class cr on cr.oid = c.conrelid join pg_namespace cn on cn.oid = cr.relnamespace
+  join pg_class fr on fr.oid = c.confrelid join pg_namespace fn on fn.oid = fr.relnamespace
+where c.contype = 'f' and not cr.relispartition and not fr.relispartition`
+
+	sequencesQuery = `select format('%I.%I', schemaname, sequencename), last_value
+from pg_sequences where last_value is not null order by 1`
+)
+
+// relation describes a table included in the subset.
+type relation struct {
+	name    string
+	only    bool
+	columns []string
+	rows    map[uint32]map[string]struct{}
+}
+
+// batch describes rows of a table stored in a single table or partition.
+type batch struct {
+	relation *relation
+	tableOID uint32
+	tids     []string
+}
+
+// Extractor walks foreign keys from the root tables and exports all rows of the subset.
+type Extractor struct {
+	tx          pgx.Tx
+	cfg         *Config
+	dir         string
+	version     int
+	relations   map[string]*relation
+	foreignKeys map[string][]ForeignKey
+	queue       []batch
+}
+
+// NewExtractor creates a new Extractor. The transaction must use the repeatable read isolation level,
+// so all tables are read from the same snapshot. Exported rows and the load script are written to dir.
+func NewExtractor(tx pgx.Tx, cfg *Config, dir string) *Extractor {
+	return &Extractor{
+		tx:          tx,
+		cfg:         cfg,
+		dir:         dir,
+		relations:   make(map[string]*relation),
+		foreignKeys: make(map[string][]ForeignKey),
+	}
+}
+
+// Run extracts the subset and writes the script loading it to a database with the same schema.
+func (e *Extractor) Run(ctx context.Context) (*Report, error) {
+	if err := e.tx.QueryRow(ctx, "select current_setting('server_version_num')::int").Scan(&e.version); err != nil {
+		return nil, fmt.Errorf("failed to detect the source version: %w", err)
+	}
+
+	if e.version < minServerVersion {
+		return nil, fmt.Errorf("subsetting requires Postgres 10 or newer, the source version is %d", e.version)
+	}
+
+	if err := e.loadForeignKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, root := range e.cfg.Roots {
+		if err := e.selectRoot(ctx, root); err != nil {
+			return nil, err
+		}
+	}
+
+	for len(e.queue) > 0 {
+		next := e.queue[0]
+		e.queue = e.queue[1:]
+
+		if err := e.followReferences(ctx, next); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.export(ctx)
+}
+
+func (e *Extractor) loadForeignKeys(ctx context.Context) error {
+	rows, err := e.tx.Query(ctx, foreignKeysQuery)
+	if err != nil {
+		return fmt.Errorf("failed to list foreign keys: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var fk ForeignKey
+
+		if err := rows.Scan(&fk.Table, &fk.RefTable, &fk.Columns, &fk.RefColumns); err != nil {
+			return fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+
+		e.foreignKeys[fk.Table] = append(e.foreignKeys[fk.Table], fk)
+	}
+
+	return rows.Err()
+}
+
+func (e *Extractor) relation(ctx context.Context, table string) (*relation, error) {
+	if rel, ok := e.relations[table]; ok {
+		return rel, nil
+	}
+
+	generatedFilter := ""
+	if e.version >= generatedColumnsVersion {
+		generatedFilter = " and a.attgenerated = ''"
+	}
+
+	var (
+		name    string
+		relKind string
+		columns []string
+	)
+
+	if err := e.tx.QueryRow(ctx, fmt.Sprintf(relationQuery, generatedFilter), table).Scan(&name, &relKind, &columns); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("table %q does not exist", table)
+		}
+
+		return nil, fmt.Errorf("failed to look up table %q: %w", table, err)
+	}
+
+	if relKind != "r" && relKind != "p" {
+		return nil, fmt.Errorf("relation %q is not a table", table)
+	}
+
+	if rel, ok := e.relations[name]; ok {
+		e.relations[table] = rel
+		return rel, nil
+	}
+
+	rel := &relation{
+		name:    name,
+		only:    relKind == "r",
+		columns: columns,
+		rows:    make(map[uint32]map[string]struct{}),
+	}
+
+	e.relations[name] = rel
+	e.relations[table] = rel
+
+	return rel, nil
+}
+
+func (e *Extractor) selectRoot(ctx context.Context, root Root) error {
+	rel, err := e.relation(ctx, root.Table)
+	if err != nil {
+		return err
+	}
+
+	if err := e.addRows(ctx, rel, rootQuery(rel.name, rel.only, root)); err != nil {
+		return fmt.Errorf("failed to select rows of the root table %q: %w", root.Table, err)
+	}
+
+	return nil
+}
+
+func (e *Extractor) followReferences(ctx context.Context, next batch) error {
+	for _, fk := range e.foreignKeys[next.relation.name] {
+		refRel, err := e.relation(ctx, fk.RefTable)
+		if err != nil {
+			return err
+		}
+
+		if err := e.addRows(ctx, refRel, referencedQuery(fk, next.relation.only, refRel.only),
+			next.tableOID, next.tids); err != nil {
+			return fmt.Errorf("failed to select rows of %s referenced by %s: %w", fk.RefTable, fk.Table, err)
+		}
+	}
+
+	return nil
+}
+
+// addRows runs the query returning table OIDs and CTIDs, adds new rows to the relation and queues them
+// to follow their references.
+func (e *Extractor) addRows(ctx context.Context, rel *relation, query string, args ...any) error {
+	rows, err := e.tx.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	added := make(map[uint32][]string)
+
+	for rows.Next() {
+		var (
+			tableOID uint32
+			tid      string
+		)
+
+		if err := rows.Scan(&tableOID, &tid); err != nil {
+			return err
+		}
+
+		tids, ok := rel.rows[tableOID]
+		if !ok {
+			tids = make(map[string]struct{})
+			rel.rows[tableOID] = tids
+		}
+
+		if _, ok := tids[tid]; ok {
+			continue
+		}
+
+		tids[tid] = struct{}{}
+		added[tableOID] = append(added[tableOID], tid)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for tableOID, tids := range added {
+		for _, chunk := range chunks(tids) {
+			e.queue = append(e.queue, batch{relation: rel, tableOID: tableOID, tids: chunk})
+		}
+	}
+
+	return nil
+}
+
+// export writes the rows of the subset and the values of sequences, and builds the script loading them.
+func (e *Extractor) export(ctx context.Context) (*Report, error) {
+	report := &Report{Roots: e.cfg.Roots}
+
+	var script strings.Builder
+
+	for i, rel := range e.sortedRelations() {
+		filename := path.Join(e.dir, fmt.Sprintf("%04d.copy", i+1))
+
+		count, err := e.exportRelation(ctx, rel, filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export rows of %s: %w", rel.name, err)
+		}
+
+		log.Msg(fmt.Sprintf("Subset table %s: %d rows", rel.name, count))
+
+		report.Tables = append(report.Tables, TableReport{Table: rel.name, Rows: count})
+		script.WriteString(copyCommand(rel.name, rel.columns, filename) + "\n")
+	}
+
+	if err := e.exportSequences(ctx, &script); err != nil {
+		return nil, fmt.Errorf("failed to export sequences: %w", err)
+	}
+
+	if err := os.WriteFile(path.Join(e.dir, LoadScriptName), []byte(script.String()), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write the load script: %w", err)
+	}
+
+	report.SubsetAt = time.Now().Format(util.DataStateAtFormat)
+
+	return report, nil
+}
+
+func (e *Extractor) sortedRelations() []*relation {
+	relations := make([]*relation, 0, len(e.relations))
+
+	for name, rel := range e.relations {
+		// Relations are also indexed by the names used in the configuration.
+		if name == rel.name {
+			relations = append(relations, rel)
+		}
+	}
+
+	sort.Slice(relations, func(i, j int) bool { return relations[i].name < relations[j].name })
+
+	return relations
+}
+
+func (e *Extractor) exportRelation(ctx context.Context, rel *relation, filename string) (int64, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	w := bufio.NewWriter(f)
+
+	var count int64
+
+	for _, tableOID := range sortedOIDs(rel.rows) {
+		tids := make([]string, 0, len(rel.rows[tableOID]))
+
+		for tid := range rel.rows[tableOID] {
+			tids = append(tids, tid)
+		}
+
+		sort.Strings(tids)
+
+		for _, chunk := range chunks(tids) {
+			tag, err := e.tx.Conn().PgConn().CopyTo(ctx, w, copyQuery(rel.name, rel.only, rel.columns, tableOID, chunk))
+			if err != nil {
+				return 0, err
+			}
+
+			count += tag.RowsAffected()
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+
+	return count, f.Close()
+}
+
+func (e *Extractor) exportSequences(ctx context.Context, script *strings.Builder) error {
+	rows, err := e.tx.Query(ctx, sequencesQuery)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			sequence string
+			value    int64
+		)
+
+		if err := rows.Scan(&sequence, &value); err != nil {
+			return err
+		}
+
+		script.WriteString(setvalStatement(sequence, value) + "\n")
+	}
+
+	return rows.Err()
+}
+
+func sortedOIDs(rows map[uint32]map[string]struct{}) []uint32 {
+	oids := make([]uint32, 0, len(rows))
+
+	for oid := range rows {
+		oids = append(oids, oid)
+	}
+
+	sort.Slice(oids, func(i, j int) bool { return oids[i] < oids[j] })
+
+	return oids
+}
+
+func chunks(tids []string) [][]string {
+	var result [][]string
+
+	for len(tids) > batchSize {
+		result = append(result, tids[:batchSize])
+		tids = tids[batchSize:]
+	}
+
+	if len(tids) > 0 {
+		result = append(result, tids)
+	}
+
+	return result
+}
@@ -0,0 +1,227 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package subset extracts referentially consistent subsets of databases for logical retrieval.
+package subset
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"gopkg.in/yaml.v2"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/dbmarker"
+)
+
+const (
+	// ReportName defines the name of the subsetting report stored with the restored data.
+	ReportName = "subset"
+
+	// ReportSuffix defines the suffix of the report file stored next to a dump of the subset.
+	ReportSuffix = ".subset.yml"
+
+	// LoadScriptName defines the name of the psql script loading the extracted rows.
+	LoadScriptName = "load.sql"
+)
+
+// Config describes the subset of a database.
+type Config struct {
+	// Roots lists the tables to start from. Rows referenced by the selected rows through foreign keys
+	// are added to the subset recursively.
+	Roots []Root `yaml:"roots"`
+}
+
+// Root describes a table to start the subset from.
+type Root struct {
+	Table string `yaml:"table"`
+	// Where filters the rows of the table. It is an SQL condition evaluated on the source.
+	Where string `yaml:"where,omitempty"`
+	// Percent defines the share of rows randomly sampled from the table.
+	Percent float64 `yaml:"percent,omitempty"`
+}
+
+// Report describes the extracted subset.
+type Report struct {
+	Database string        `yaml:"database"`
+	SubsetAt string        `yaml:"subsetAt"`
+	Roots    []Root        `yaml:"roots"`
+	Tables   []TableReport `yaml:"tables"`
+}
+
+// TableReport describes the rows of a table included in the subset.
+type TableReport struct {
+	Table string `yaml:"table"`
+	Rows  int64  `yaml:"rows"`
+}
+
+// ForeignKey describes a foreign key between two tables.
+type ForeignKey struct {
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// Enabled checks if the subset is configured.
+func (c *Config) Enabled() bool {
+	return c != nil && len(c.Roots) > 0
+}
+
+// Validate checks the subset configuration without connecting to the database.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if len(c.Roots) == 0 {
+		return fmt.Errorf("subset has no root tables")
+	}
+
+	tables := make(map[string]struct{}, len(c.Roots))
+
+	for _, root := range c.Roots {
+		if root.Table == "" {
+			return fmt.Errorf("subset root without a table")
+		}
+
+		if _, ok := tables[root.Table]; ok {
+			return fmt.Errorf("duplicate subset root %q", root.Table)
+		}
+
+		tables[root.Table] = struct{}{}
+
+		if root.Percent < 0 || root.Percent > 100 {
+			return fmt.Errorf("percent of subset root %q must be between 0 and 100", root.Table)
+		}
+	}
+
+	return nil
+}
+
+// SaveFile stores the report in a file.
+func SaveFile(filename string, report *Report) error {
+	reportData, err := yaml.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, reportData, 0644)
+}
+
+// LoadFile loads the report stored by SaveFile.
+func LoadFile(filename string) (*Report, error) {
+	reportData, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+
+	if err := yaml.Unmarshal(reportData, report); err != nil {
+		return nil, fmt.Errorf("failed to parse subset report %s: %w", filename, err)
+	}
+
+	return report, nil
+}
+
+// Record adds the report to the DBMarker metadata of the data, replacing the previous report of the same database.
+func Record(marker *dbmarker.Marker, report *Report) error {
+	var reports []Report
+
+	if err := marker.GetReport(ReportName, &reports); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to load subset reports: %w", err)
+	}
+
+	recorded := make([]Report, 0, len(reports)+1)
+
+	for _, stored := range reports {
+		if stored.Database != report.Database {
+			recorded = append(recorded, stored)
+		}
+	}
+
+	return marker.SaveReport(ReportName, append(recorded, *report))
+}
+
+// rootQuery builds the query selecting rows of the root table.
+func rootQuery(relation string, only bool, root Root) string {
+	query := fmt.Sprintf("select tableoid, ctid::text from %s%s", onlyClause(only), relation)
+
+	if root.Percent > 0 {
+		query += fmt.Sprintf(" tablesample bernoulli (%g)", root.Percent)
+	}
+
+	if root.Where != "" {
+		query += " where (" + root.Where + ")"
+	}
+
+	return query
+}
+
+// referencedQuery builds the query selecting rows of the referenced table for a batch of referencing rows.
+// The batch is passed as the table OID and the text array of row CTIDs.
+func referencedQuery(fk ForeignKey, only, refOnly bool) string {
+	return fmt.Sprintf("select p.tableoid, p.ctid::text from %s%s p where (%s) in "+
+		"(select %s from %s%s c where c.tableoid = $1 and c.ctid = any($2::text[]::tid[]))",
+		onlyClause(refOnly), fk.RefTable, qualifiedColumns("p", fk.RefColumns),
+		qualifiedColumns("c", fk.Columns), onlyClause(only), fk.Table)
+}
+
+// copyQuery builds the statement exporting a batch of rows of the table.
+// COPY does not accept parameters, so CTIDs are passed as an array literal.
+func copyQuery(relation string, only bool, columns []string, tableOID uint32, tids []string) string {
+	elements := make([]string, 0, len(tids))
+
+	for _, tid := range tids {
+		elements = append(elements, `"`+tid+`"`)
+	}
+
+	return fmt.Sprintf("copy (select %s from %s%s where tableoid = %d and ctid = any(%s::tid[])) to stdout",
+		columnList(columns), onlyClause(only), relation, tableOID, quoteLiteral("{"+strings.Join(elements, ",")+"}"))
+}
+
+// copyCommand builds the psql command loading the exported rows of the table.
+func copyCommand(relation string, columns []string, filename string) string {
+	return fmt.Sprintf(`\copy %s (%s) from %s`, relation, columnList(columns), quoteLiteral(filename))
+}
+
+// setvalStatement builds the statement restoring the value of the sequence.
+func setvalStatement(sequence string, value int64) string {
+	return fmt.Sprintf("select pg_catalog.setval(%s, %d, true);", quoteLiteral(sequence), value)
+}
+
+func onlyClause(only bool) string {
+	if only {
+		return "only "
+	}
+
+	return ""
+}
+
+func columnList(columns []string) string {
+	quoted := make([]string, 0, len(columns))
+
+	for _, column := range columns {
+		quoted = append(quoted, pgx.Identifier{column}.Sanitize())
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+func qualifiedColumns(alias string, columns []string) string {
+	qualified := make([]string, 0, len(columns))
+
+	for _, column := range columns {
+		qualified = append(qualified, alias+"."+pgx.Identifier{column}.Sanitize())
+	}
+
+	return strings.Join(qualified, ", ")
+}
+
+// quoteLiteral quotes a string literal of an SQL statement.
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
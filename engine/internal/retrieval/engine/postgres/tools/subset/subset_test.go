@@ -0,0 +1,130 @@
+package subset
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/dbmarker"
+)
+
+func TestConfigValidate(t *testing.T) {
+	var disabled *Config
+	assert.NoError(t, disabled.Validate())
+	assert.False(t, disabled.Enabled())
+
+	testCases := []struct {
+		name    string
+		roots   []Root
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			roots: []Root{{Table: "public.customers", Where: "country = 'NZ'"}, {Table: "orders", Percent: 1.5}},
+		},
+		{
+			name:    "no roots",
+			wantErr: "no root tables",
+		},
+		{
+			name:    "missing table",
+			roots:   []Root{{Where: "id < 100"}},
+			wantErr: "without a table",
+		},
+		{
+			name:    "duplicate table",
+			roots:   []Root{{Table: "orders"}, {Table: "orders", Percent: 10}},
+			wantErr: "duplicate subset root",
+		},
+		{
+			name:    "invalid percent",
+			roots:   []Root{{Table: "orders", Percent: -1}},
+			wantErr: "between 0 and 100",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := (&Config{Roots: tc.roots}).Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestRootQuery(t *testing.T) {
+	assert.Equal(t, "select tableoid, ctid::text from only public.customers",
+		rootQuery("public.customers", true, Root{}))
+	assert.Equal(t, "select tableoid, ctid::text from public.events tablesample bernoulli (0.5) where (created_at > now() - interval '1 day')",
+		rootQuery("public.events", false, Root{Percent: 0.5, Where: "created_at > now() - interval '1 day'"}))
+}
+
+func TestReferencedQuery(t *testing.T) {
+	fk := ForeignKey{
+		Table:      "public.order_items",
+		Columns:    []string{"order_id", "Shop"},
+		RefTable:   "public.orders",
+		RefColumns: []string{"id", "shop_id"},
+	}
+
+	assert.Equal(t, `select p.tableoid, p.ctid::text from public.orders p where (p."id", p."shop_id") in `+
+		`(select c."order_id", c."Shop" from only public.order_items c where c.tableoid = $1 and c.ctid = any($2::text[]::tid[]))`,
+		referencedQuery(fk, true, false))
+}
+
+func TestCopyStatements(t *testing.T) {
+	assert.Equal(t, `copy (select "id", "Email" from only public.customers where tableoid = 16384 `+
+		`and ctid = any('{"(0,1)","(12,7)"}'::tid[])) to stdout`,
+		copyQuery("public.customers", true, []string{"id", "Email"}, 16384, []string{"(0,1)", "(12,7)"}))
+	assert.Equal(t, `\copy public.customers ("id", "Email") from '/dump/shop''s.subset/0001.copy'`,
+		copyCommand("public.customers", []string{"id", "Email"}, "/dump/shop's.subset/0001.copy"))
+	assert.Equal(t, `select pg_catalog.setval('public."Orders_id_seq"', 42, true);`,
+		setvalStatement(`public."Orders_id_seq"`, 42))
+}
+
+func TestChunks(t *testing.T) {
+	assert.Empty(t, chunks(nil))
+
+	tids := make([]string, batchSize*2+1)
+	result := chunks(tids)
+
+	require.Len(t, result, 3)
+	assert.Len(t, result[0], batchSize)
+	assert.Len(t, result[2], 1)
+}
+
+func TestReports(t *testing.T) {
+	dir := t.TempDir()
+	report := &Report{
+		Database: "shop",
+		SubsetAt: "20261017120000",
+		Roots:    []Root{{Table: "public.customers", Where: "country = 'NZ'"}},
+		Tables:   []TableReport{{Table: "public.customers", Rows: 120}},
+	}
+
+	filename := filepath.Join(dir, "shop"+ReportSuffix)
+	require.NoError(t, SaveFile(filename, report))
+
+	loaded, err := LoadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, report, loaded)
+
+	marker := dbmarker.NewMarker(dir)
+	require.NoError(t, Record(marker, report))
+	require.NoError(t, Record(marker, &Report{Database: "analytics"}))
+	require.NoError(t, Record(marker, &Report{Database: "shop", SubsetAt: "20261018120000"}))
+
+	var recorded []Report
+
+	require.NoError(t, marker.GetReport(ReportName, &recorded))
+	require.Len(t, recorded, 2)
+	assert.Equal(t, "analytics", recorded[0].Database)
+	assert.Equal(t, "20261018120000", recorded[1].SubsetAt)
+}
@@ -0,0 +1,132 @@
+/*
+2026 © Postgres.ai
+*/
+
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/operations"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/opmetrics"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/components"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/tracing"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// webhookSendTimeout bounds waiting for the webhook service so that retrieval never stalls on it.
+const webhookSendTimeout = 10 * time.Second
+
+// emitEvent sends a webhook event, dropping it if the webhook service does not accept it in time.
+func (r *Retrieval) emitEvent(event webhooks.EventTyper) {
+	if r.webhookCh == nil {
+		return
+	}
+
+	select {
+	case r.webhookCh <- event:
+	case <-time.After(webhookSendTimeout):
+		log.Warn(fmt.Sprintf("webhook channel is busy, dropped %s event", event.GetType()))
+	}
+}
+
+// runJob runs a retrieval job and reports its outcome.
+func (r *Retrieval) runJob(ctx context.Context, j components.JobRunner, fsPool *resources.Pool) error {
+	poolName := fsPool.Name
+
+	r.State.CurrentJob = j
+	r.emitStageChange(poolName, j.Name())
+	operations.FromContext(ctx).SetProgress(fmt.Sprintf("running %s on pool %s", j.Name(), poolName))
+
+	ctx, span := tracing.Start(ctx, "retrieval."+j.Name(), attribute.String("pool", poolName))
+
+	startedAt := time.Now()
+	err := j.Run(ctx)
+
+	event := webhooks.RetrievalEvent{
+		BasicEvent: webhooks.BasicEvent{
+			EventType: webhooks.RetrievalJobFinishEvent,
+			EntityID:  poolName,
+			TraceID:   tracing.TraceID(ctx),
+		},
+		Job:             j.Name(),
+		Pool:            poolName,
+		DataStateAt:     formatDataStateAt(fsPool.DSA),
+		DurationSeconds: time.Since(startedAt).Round(time.Millisecond).Seconds(),
+	}
+
+	// An existing snapshot means the data state has already been captured, which is not a failure.
+	var existsErr *thinclones.SnapshotExistsError
+
+	failure := err
+	if errors.As(err, &existsErr) {
+		failure = nil
+	}
+
+	if failure != nil {
+		event.EventType = webhooks.RetrievalJobFailEvent
+		event.Error = failure.Error()
+	}
+
+	opmetrics.ObserveRetrievalJob(j.Name(), startedAt, failure)
+	tracing.End(span, failure)
+
+	r.emitEvent(event)
+
+	return err
+}
+
+// setStatus updates the retrieval status and reports the change.
+func (r *Retrieval) setStatus(status models.RetrievalStatus, poolName string) {
+	if previous := r.State.setStatus(status); previous != status {
+		r.emitStageChange(poolName, "")
+	}
+}
+
+// emitStageChange reports the retrieval status and the job that has started, if any.
+func (r *Retrieval) emitStageChange(poolName, job string) {
+	r.emitEvent(webhooks.RetrievalEvent{
+		BasicEvent: webhooks.BasicEvent{EventType: webhooks.RetrievalStageChangeEvent, EntityID: poolName},
+		Job:        job,
+		Pool:       poolName,
+		Status:     string(r.State.Status),
+	})
+}
+
+// emitDataStateReady reports that a new data state of the pool is available for cloning.
+func (r *Retrieval) emitDataStateReady(fsPool *resources.Pool) {
+	r.emitEvent(webhooks.RetrievalEvent{
+		BasicEvent:  webhooks.BasicEvent{EventType: webhooks.DataStateReadyEvent, EntityID: fsPool.Name},
+		Pool:        fsPool.Name,
+		DataStateAt: formatDataStateAt(fsPool.DSA),
+	})
+}
+
+// snapshotReady returns the function called by snapshot jobs when they take a snapshot, either during a refresh
+// or on their own schedule. Snapshots of an inactive pool are reported by the refresh once the pool becomes active.
+func (r *Retrieval) snapshotReady(fsPool *resources.Pool) func() {
+	return func() {
+		if fsPool.Status() != resources.ActivePool {
+			return
+		}
+
+		r.emitDataStateReady(fsPool)
+	}
+}
+
+func formatDataStateAt(dsa time.Time) string {
+	if dsa.IsZero() {
+		return ""
+	}
+
+	return dsa.Format(time.RFC3339)
+}
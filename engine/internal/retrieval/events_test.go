@@ -0,0 +1,115 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/activity"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+type testJob struct {
+	name string
+	err  error
+}
+
+func (j *testJob) Name() string                                               { return j.name }
+func (j *testJob) Reload(map[string]interface{}) error                        { return nil }
+func (j *testJob) Run(context.Context) error                                  { return j.err }
+func (j *testJob) ReportActivity(context.Context) (*activity.Activity, error) { return nil, nil }
+
+func TestRunJobEvents(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		eventType string
+	}{
+		{name: "finished job", eventType: webhooks.RetrievalJobFinishEvent},
+		{name: "failed job", err: errors.New("dump failed"), eventType: webhooks.RetrievalJobFailEvent},
+		{name: "existing snapshot", err: &thinclones.SnapshotExistsError{}, eventType: webhooks.RetrievalJobFinishEvent},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			webhookCh := make(chan webhooks.EventTyper, 2)
+			r := &Retrieval{webhookCh: webhookCh, State: State{Status: models.Refreshing}}
+
+			fsPool := &resources.Pool{Name: "dblab_pool", DSA: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)}
+
+			err := r.runJob(context.Background(), &testJob{name: "logicalDump", err: tc.err}, fsPool)
+			assert.Equal(t, tc.err, err)
+
+			require.Len(t, webhookCh, 2)
+
+			stage, ok := (<-webhookCh).(webhooks.RetrievalEvent)
+			require.True(t, ok)
+			assert.Equal(t, webhooks.RetrievalStageChangeEvent, stage.EventType)
+			assert.Equal(t, "logicalDump", stage.Job)
+			assert.Equal(t, string(models.Refreshing), stage.Status)
+
+			event, ok := (<-webhookCh).(webhooks.RetrievalEvent)
+			require.True(t, ok)
+			assert.Equal(t, tc.eventType, event.EventType)
+			assert.Equal(t, "dblab_pool", event.EntityID)
+			assert.Equal(t, "logicalDump", event.Job)
+			assert.Equal(t, "dblab_pool", event.Pool)
+			assert.Equal(t, "2026-03-01T10:00:00Z", event.DataStateAt)
+
+			if tc.eventType == webhooks.RetrievalJobFailEvent {
+				assert.Equal(t, tc.err.Error(), event.Error)
+			} else {
+				assert.Empty(t, event.Error)
+			}
+		})
+	}
+}
+
+func TestSetStatusEmitsStageChange(t *testing.T) {
+	webhookCh := make(chan webhooks.EventTyper, 2)
+	r := &Retrieval{webhookCh: webhookCh, State: State{Status: models.Finished}}
+
+	r.setStatus(models.Snapshotting, "dblab_pool")
+	r.setStatus(models.Snapshotting, "dblab_pool")
+
+	require.Len(t, webhookCh, 1)
+
+	event, ok := (<-webhookCh).(webhooks.RetrievalEvent)
+	require.True(t, ok)
+	assert.Equal(t, webhooks.RetrievalStageChangeEvent, event.EventType)
+	assert.Equal(t, "dblab_pool", event.Pool)
+	assert.Equal(t, string(models.Snapshotting), event.Status)
+	assert.Empty(t, event.Job)
+}
+
+func TestSnapshotReady(t *testing.T) {
+	webhookCh := make(chan webhooks.EventTyper, 2)
+	r := &Retrieval{webhookCh: webhookCh}
+
+	fsPool := resources.NewPool("dblab_pool")
+	fsPool.DSA = time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	fsPool.SetStatus(resources.RefreshingPool)
+
+	snapshotReady := r.snapshotReady(fsPool)
+
+	snapshotReady()
+	assert.Empty(t, webhookCh, "snapshots of a refreshing pool are reported once the pool becomes active")
+
+	fsPool.SetStatus(resources.ActivePool)
+	snapshotReady()
+
+	require.Len(t, webhookCh, 1)
+
+	event, ok := (<-webhookCh).(webhooks.RetrievalEvent)
+	require.True(t, ok)
+	assert.Equal(t, webhooks.DataStateReadyEvent, event.EventType)
+	assert.Equal(t, "dblab_pool", event.Pool)
+	assert.Equal(t, "2026-03-01T10:00:00Z", event.DataStateAt)
+}
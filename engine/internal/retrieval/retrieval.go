@@ -35,6 +35,7 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/options"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/status"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/telemetry"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
 
 	dblabCfg "gitlab.com/postgres-ai/database-lab/v3/pkg/config"
@@ -69,6 +70,7 @@ type Retrieval struct {
 	runner       runners.Runner
 	ctxCancel    context.CancelFunc
 	statefulJobs []components.JobRunner
+	webhookCh    chan webhooks.EventTyper
 }
 
 // Scheduler defines a refresh scheduler.
@@ -81,11 +83,14 @@ var (
 	ErrRefreshInProgress = errors.New("The data refresh/snapshot is currently in progress. Skip a new data refresh iteration")
 	ErrRefreshPending    = errors.New("Data retrieving suspended because Retrieval state is pending")
 	ErrNoAvailablePool   = errors.New("Pool to perform full refresh not found. Skip refreshing")
+
+	// ErrRefreshSkipped is returned by FullRefresh when the refresh does not run, e.g. because another one is in progress.
+	ErrRefreshSkipped = errors.New("full refresh skipped")
 )
 
 // New creates a new data retrieval.
 func New(cfg *dblabCfg.Config, engineProps *global.EngineProps, docker *client.Client, pm *pool.Manager, tm *telemetry.Agent,
-	runner runners.Runner) (*Retrieval, error) {
+	runner runners.Runner, webhookCh chan webhooks.EventTyper) (*Retrieval, error) {
 	r := &Retrieval{
 		global:      &cfg.Global,
 		engineProps: engineProps,
@@ -93,6 +98,7 @@ func New(cfg *dblabCfg.Config, engineProps *global.EngineProps, docker *client.C
 		poolManager: pm,
 		tm:          tm,
 		runner:      runner,
+		webhookCh:   webhookCh,
 		State: State{
 			Status: models.Inactive,
 			alerts: make(map[models.AlertType]models.Alert),
@@ -143,7 +149,7 @@ func checkPendingMarker(r *Retrieval) error {
 		return fmt.Errorf("failed to get pending file info: %w", err)
 	}
 
-	r.State.Status = models.Pending
+	r.setStatus(models.Pending, "")
 
 	return nil
 }
@@ -163,7 +169,7 @@ func (r *Retrieval) RemovePendingMarker() error {
 		return err
 	}
 
-	r.State.Status = models.Inactive
+	r.setStatus(models.Inactive, "")
 
 	return nil
 }
@@ -219,7 +225,7 @@ func (r *Retrieval) Run(ctx context.Context) error {
 	if err != nil {
 		var skipError *SkipRefreshingError
 		if errors.As(err, &skipError) {
-			r.State.Status = models.Finished
+			r.setStatus(models.Finished, "")
 
 			log.Msg("Continue without performing a full refresh:", skipError.Error())
 			r.setupScheduler(ctx)
@@ -231,7 +237,7 @@ func (r *Retrieval) Run(ctx context.Context) error {
 			Level:   models.RefreshFailed,
 			Message: "Pool to perform data refresh not found",
 		}
-		r.State.Status = models.Failed
+		r.setStatus(models.Failed, "")
 		r.State.addAlert(alert)
 		r.tm.SendEvent(ctx, telemetry.AlertEvent, alert)
 
@@ -375,8 +381,15 @@ func (r *Retrieval) run(ctx context.Context, fsm pool.FSManager) (err error) {
 	}
 
 	if r.State.Status == models.Finished {
+		// Snapshots of an inactive pool are reported once the pool becomes active.
+		activated := fsm.Pool().Status() != resources.ActivePool
+
 		r.poolManager.MakeActive(poolElement)
 		r.State.cleanAlerts()
+
+		if activated {
+			r.emitDataStateReady(fsm.Pool())
+		}
 	}
 
 	if err := fsm.InitBranching(); err != nil {
@@ -415,29 +428,29 @@ func (r *Retrieval) RefreshData(ctx context.Context, poolName string) error {
 
 	fsm.Pool().SetStatus(resources.RefreshingPool)
 
-	r.State.Status = models.Refreshing
 	r.State.LastRefresh = models.NewLocalTime(time.Now().Truncate(time.Second))
+	r.setStatus(models.Refreshing, poolName)
 
 	defer func() {
-		r.State.Status = models.Renewed
+		r.State.CurrentJob = nil
 
 		if err != nil {
-			r.State.Status = models.Failed
+			r.setStatus(models.Failed, poolName)
 			r.State.addAlert(telemetry.Alert{
 				Level:   models.RefreshFailed,
 				Message: err.Error(),
 			})
 
 			fsm.Pool().SetStatus(resources.EmptyPool)
+
+			return
 		}
 
-		r.State.CurrentJob = nil
+		r.setStatus(models.Renewed, poolName)
 	}()
 
 	for _, j := range jobs {
-		r.State.CurrentJob = j
-
-		if err = j.Run(ctx); err != nil {
+		if err = r.runJob(ctx, j, fsm.Pool()); err != nil {
 			return err
 		}
 	}
@@ -463,7 +476,7 @@ func (r *Retrieval) SnapshotData(ctx context.Context, poolName string) error {
 		return fmt.Errorf("failed to build snapshot jobs for %s: %w", poolName, err)
 	}
 
-	if r.State.Mode == models.Physical {
+	if r.State.Mode == models.Physical || r.replicates() {
 		r.statefulJobs = jobs
 	}
 
@@ -474,30 +487,30 @@ func (r *Retrieval) SnapshotData(ctx context.Context, poolName string) error {
 
 	log.Dbg("Taking a snapshot on the pool: ", fsm.Pool())
 
-	r.State.Status = models.Snapshotting
+	r.setStatus(models.Snapshotting, poolName)
 
 	defer func() {
-		r.State.Status = models.Finished
+		r.State.CurrentJob = nil
 
 		var existsErr *thinclones.SnapshotExistsError
 
 		if err != nil && !errors.As(err, &existsErr) {
-			r.State.Status = models.Failed
+			r.setStatus(models.Failed, poolName)
 			r.State.addAlert(telemetry.Alert{
 				Level:   models.RefreshFailed,
 				Message: err.Error(),
 			})
 
 			fsm.Pool().SetStatus(resources.EmptyPool)
+
+			return
 		}
 
-		r.State.CurrentJob = nil
+		r.setStatus(models.Finished, poolName)
 	}()
 
 	for _, j := range jobs {
-		r.State.CurrentJob = j
-
-		if err = j.Run(ctx); err != nil {
+		if err = r.runJob(ctx, j, fsm.Pool()); err != nil {
 			return err
 		}
 	}
@@ -505,6 +518,115 @@ func (r *Retrieval) SnapshotData(ctx context.Context, poolName string) error {
 	return nil
 }
 
+// RecoverSnapshot claims the pool for a point-in-time recovery and returns the function that creates a snapshot of the pool data
+// recovered to the target on a dedicated branch. The recovery replays WAL, so it is meant to run in the background;
+// the pool stays in the snapshotting status until it completes. It is available in the physical mode only.
+func (r *Retrieval) RecoverSnapshot(poolName string, target snapshot.RecoveryTarget) (func(ctx context.Context) (string, error), error) {
+	if r.State.Mode != models.Physical {
+		return nil, models.New(models.ErrCodeBadRequest, "point-in-time recovery is available in the physical mode only")
+	}
+
+	if err := target.Validate(); err != nil {
+		return nil, models.New(models.ErrCodeBadRequest, err.Error())
+	}
+
+	fsm, err := r.poolManager.GetFSManager(poolName)
+	if err != nil {
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("failed to get %q FSManager: %v", poolName, err))
+	}
+
+	physicalJob, err := r.physicalSnapshotJob(fsm)
+	if err != nil {
+		return nil, err
+	}
+
+	previousStatus, ok := r.State.claimStatus(models.Snapshotting, models.Inactive, models.Renewed, models.Finished)
+	if !ok {
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("pool is not ready to take a snapshot: %s", previousStatus))
+	}
+
+	r.emitStageChange(poolName, "")
+
+	return func(ctx context.Context) (string, error) {
+		defer r.setStatus(previousStatus, poolName)
+
+		return physicalJob.RecoverTo(ctx, target)
+	}, nil
+}
+
+// physicalSnapshotJob returns the physical snapshot job of the pool. The stateful job taking scheduled snapshots is preferred,
+// so the recovery is serialized with them.
+func (r *Retrieval) physicalSnapshotJob(fsm pool.FSManager) (*snapshot.PhysicalInitial, error) {
+	for _, job := range r.statefulJobs {
+		if physicalJob, ok := job.(*snapshot.PhysicalInitial); ok && physicalJob.Pool() == fsm.Pool() {
+			return physicalJob, nil
+		}
+	}
+
+	jobs, err := r.buildJobs(fsm, snapshotJobs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build snapshot jobs for %s: %w", fsm.Pool().Name, err)
+	}
+
+	for _, job := range jobs {
+		if physicalJob, ok := job.(*snapshot.PhysicalInitial); ok {
+			return physicalJob, nil
+		}
+	}
+
+	return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("the %s job is not configured", snapshot.PhysicalSnapshotType))
+}
+
+// RetentionPolicy returns the tiered retention policy configured for the snapshot job.
+// It returns nil if no policy is configured.
+func (r *Retrieval) RetentionPolicy() *thinclones.RetentionPolicy {
+	for _, stage := range []string{snapshot.PhysicalSnapshotType, snapshot.LogicalReplicationType} {
+		var jobCfg struct {
+			Scheduler *snapshot.Scheduler `yaml:"scheduler"`
+		}
+
+		if err := r.JobConfig(stage, &jobCfg); err != nil {
+			continue
+		}
+
+		if jobCfg.Scheduler != nil && jobCfg.Scheduler.Retention.Policy != nil {
+			return jobCfg.Scheduler.Retention.Policy
+		}
+	}
+
+	return nil
+}
+
+// PreviewRetention returns snapshots of the pool that the retention policy would destroy, without destroying them.
+// If policy is nil, the configured one is used.
+func (r *Retrieval) PreviewRetention(poolName string, policy *thinclones.RetentionPolicy) ([]string, error) {
+	if policy == nil {
+		policy = r.RetentionPolicy()
+	}
+
+	if policy == nil || !policy.Enabled() {
+		return nil, errors.New("retention policy is not configured")
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	fsm, err := r.poolManager.GetFSManager(poolName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %q FSManager: %w", poolName, err)
+	}
+
+	mode := r.State.Mode
+
+	// Replication snapshots are taken from "pre" clones the same way as physical ones.
+	if r.replicates() {
+		mode = models.Physical
+	}
+
+	return fsm.ApplyRetentionPolicy(*policy, mode, true)
+}
+
 // buildJobs processes the configuration spec to build data retrieval jobs.
 func (r *Retrieval) buildJobs(fsm pool.FSManager, groupName jobGroup) ([]components.JobRunner, error) {
 	retrievalRunner, err := engine.JobBuilder(r.global, r.engineProps, fsm, r.tm)
@@ -527,10 +649,11 @@ func (r *Retrieval) buildJobs(fsm pool.FSManager, groupName jobGroup) ([]compone
 		}
 
 		jobCfg := config.JobConfig{
-			Spec:   jobSpec,
-			Docker: r.docker,
-			Marker: dbMarker,
-			FSPool: fsm.Pool(),
+			Spec:          jobSpec,
+			Docker:        r.docker,
+			Marker:        dbMarker,
+			FSPool:        fsm.Pool(),
+			SnapshotReady: r.snapshotReady(fsm.Pool()),
 		}
 
 		job, err := retrievalRunner.BuildJob(jobCfg)
@@ -549,7 +672,7 @@ func getJobGroup(name string) jobGroup {
 	case logical.DumpJobType, logical.RestoreJobType, physical.RestoreJobType:
 		return refreshJobs
 
-	case snapshot.LogicalSnapshotType, snapshot.PhysicalSnapshotType:
+	case snapshot.LogicalSnapshotType, snapshot.PhysicalSnapshotType, snapshot.LogicalReplicationType:
 		return snapshotJobs
 	}
 
@@ -593,7 +716,7 @@ func (r *Retrieval) setupScheduler(ctx context.Context) {
 
 func (r *Retrieval) refreshFunc(ctx context.Context) func() {
 	return func() {
-		if err := r.FullRefresh(ctx); err != nil {
+		if err := r.FullRefresh(ctx); err != nil && !errors.Is(err, ErrRefreshSkipped) {
 			alert := telemetry.Alert{Level: models.RefreshFailed, Message: err.Error()}
 			r.State.addAlert(alert)
 			r.tm.SendEvent(ctx, telemetry.AlertEvent, telemetry.Alert{Level: models.RefreshFailed, Message: "Failed to run full-refresh"})
@@ -619,7 +742,7 @@ func (r *Retrieval) FullRefresh(ctx context.Context) error {
 			log.Msg(err.Error())
 		}
 
-		return nil
+		return fmt.Errorf("%w: %w", ErrRefreshSkipped, err)
 	}
 
 	// Stop previous runs and snapshot schedulers.
@@ -627,9 +750,14 @@ func (r *Retrieval) FullRefresh(ctx context.Context) error {
 		r.ctxCancel()
 	}
 
-	runCtx, cancel := context.WithCancel(ctx)
+	// Snapshot schedulers started by the refresh keep the run context, so it outlives the caller.
+	// Canceling the caller's context interrupts the run only until the refresh is complete.
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
 	r.ctxCancel = cancel
 
+	stopInterrupt := context.AfterFunc(ctx, cancel)
+	defer stopInterrupt()
+
 	if err := r.HasAvailablePool(); err != nil {
 		alert := telemetry.Alert{
 			Level:   models.RefreshSkipped,
@@ -640,7 +768,7 @@ func (r *Retrieval) FullRefresh(ctx context.Context) error {
 		log.Msg(err.Error() + ". Hint: Check that there is at least one pool that does not have clones running. " +
 			"Refresh can be performed only to a pool without clones.")
 
-		return nil
+		return fmt.Errorf("%w: %w", ErrRefreshSkipped, err)
 	}
 
 	elementToUpdate := r.poolManager.GetPoolToUpdate()
@@ -652,6 +780,16 @@ func (r *Retrieval) FullRefresh(ctx context.Context) error {
 
 	log.Msg("Pool selected to perform full refresh: ", poolToUpdate.Pool())
 
+	previousPool := ""
+	if active := r.poolManager.First(); active != nil {
+		previousPool = active.Pool().Name
+	}
+
+	r.emitEvent(webhooks.RetrievalEvent{
+		BasicEvent: webhooks.BasicEvent{EventType: webhooks.FullRefreshStartEvent, EntityID: poolToUpdate.Pool().Name},
+		Pool:       poolToUpdate.Pool().Name,
+	})
+
 	// Stop service containers: sync-instance, etc.
 	if cleanUpErr := cont.CleanUpControlContainers(runCtx, r.docker, r.engineProps.InstanceID); cleanUpErr != nil {
 		log.Err("failed to clean up service containers:", cleanUpErr)
@@ -666,6 +804,15 @@ func (r *Retrieval) FullRefresh(ctx context.Context) error {
 	r.poolManager.MakeActive(elementToUpdate)
 	r.State.cleanAlerts()
 
+	if activePool := poolToUpdate.Pool().Name; activePool != previousPool {
+		r.emitEvent(webhooks.RetrievalEvent{
+			BasicEvent:   webhooks.BasicEvent{EventType: webhooks.PoolRotateEvent, EntityID: activePool},
+			Pool:         activePool,
+			PreviousPool: previousPool,
+			DataStateAt:  formatDataStateAt(poolToUpdate.Pool().DSA),
+		})
+	}
+
 	return nil
 }
 
@@ -725,7 +872,7 @@ func (r *Retrieval) GetStageSpec(stage string) (config.JobSpec, error) {
 
 // ReportSyncStatus return status of sync containers.
 func (r *Retrieval) ReportSyncStatus(ctx context.Context) (*models.Sync, error) {
-	if r.State.Mode != models.Physical {
+	if r.State.Mode != models.Physical && !r.replicates() {
 		return &models.Sync{
 			Status: models.Status{Code: models.SyncStatusNotAvailable},
 		}, nil
@@ -797,7 +944,7 @@ func (r *Retrieval) reportContainerSyncStatus(ctx context.Context, containerID s
 	}
 
 	socketPath := filepath.Join(firstPool.Pool().SocketDir(), resp.Name)
-	value, err := status.FetchSyncMetrics(ctx, r.global, socketPath)
+	value, err := r.fetchSyncMetrics(ctx, socketPath)
 
 	if err != nil {
 		log.Warn("Failed to fetch synchronization metrics", err)
@@ -815,6 +962,29 @@ func (r *Retrieval) reportContainerSyncStatus(ctx context.Context, containerID s
 	return value, nil
 }
 
+// replicates checks if the logical data is kept up to date by the logicalReplication job.
+func (r *Retrieval) replicates() bool {
+	return r.cfg != nil && hasReplicationJob(r.cfg.JobsSpec)
+}
+
+// fetchSyncMetrics fetches replication metrics of the physical standby or of the subscription in logical mode.
+func (r *Retrieval) fetchSyncMetrics(ctx context.Context, socketPath string) (*models.Sync, error) {
+	if r.State.Mode == models.Physical {
+		return status.FetchSyncMetrics(ctx, r.global, socketPath)
+	}
+
+	var replicationCfg snapshot.LogicalReplicationOptions
+
+	if err := r.JobConfig(snapshot.LogicalReplicationType, &replicationCfg); err != nil {
+		return nil, fmt.Errorf("failed to read logical replication options: %w", err)
+	}
+
+	replicationCfg.Subscription.SetDefaults(r.global.Database.Name())
+
+	return status.FetchLogicalSyncMetrics(ctx, r.global, socketPath,
+		replicationCfg.Subscription.Database, replicationCfg.Subscription.Name)
+}
+
 func (r *Retrieval) CanStartRefresh() error {
 	if r.State.Status == models.Refreshing || r.State.Status == models.Snapshotting {
 		return ErrRefreshInProgress
@@ -40,6 +40,10 @@ func TestJobGroup(t *testing.T) {
 			jobName: "physicalSnapshot",
 			group:   snapshotJobs,
 		},
+		{
+			jobName: "logicalReplication",
+			group:   snapshotJobs,
+		},
 		{
 			jobName: "unknownDump",
 			group:   "",
@@ -237,6 +241,14 @@ func TestCanStartRefresh(t *testing.T) {
 	})
 }
 
+func TestFullRefreshSkipped(t *testing.T) {
+	r := &Retrieval{State: State{Status: models.Pending}}
+
+	err := r.FullRefresh(context.Background())
+	assert.ErrorIs(t, err, ErrRefreshSkipped)
+	assert.ErrorIs(t, err, ErrRefreshPending)
+}
+
 func TestReportState(t *testing.T) {
 	t.Run("with refresh timetable", func(t *testing.T) {
 		r := &Retrieval{
@@ -61,6 +61,35 @@ func (s *State) addAlert(telemetryAlert telemetry.Alert) {
 	s.alerts[telemetryAlert.Level] = alert
 }
 
+// setStatus sets the status and returns the previous one.
+func (s *State) setStatus(status models.RetrievalStatus) models.RetrievalStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.Status
+	s.Status = status
+
+	return previous
+}
+
+// claimStatus sets the status only if the current one is among the allowed ones, so concurrent callers cannot
+// both start an operation. It returns the previous status and whether the status has been set.
+func (s *State) claimStatus(status models.RetrievalStatus, allowed ...models.RetrievalStatus) (models.RetrievalStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.Status
+
+	for _, allowedStatus := range allowed {
+		if previous == allowedStatus {
+			s.Status = status
+			return previous, true
+		}
+	}
+
+	return previous, false
+}
+
 func (s *State) cleanAlerts() {
 	s.mu.Lock()
 	s.alerts = make(map[models.AlertType]models.Alert)
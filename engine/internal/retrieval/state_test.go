@@ -139,3 +139,36 @@ func TestState_AlertsGetReturnsCopy(t *testing.T) {
 	_, hasInjected := original[models.RefreshSkipped]
 	assert.False(t, hasInjected, "injected key must not appear in original")
 }
+
+func TestState_ClaimStatusConcurrent(t *testing.T) {
+	state := State{Status: models.Finished}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		claimed int
+	)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, ok := state.claimStatus(models.Snapshotting, models.Inactive, models.Renewed, models.Finished); ok {
+				mu.Lock()
+				claimed++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 1, claimed, "only one caller must claim the status")
+	assert.Equal(t, models.Snapshotting, state.Status)
+
+	previous, ok := state.claimStatus(models.Refreshing, models.Finished)
+	assert.False(t, ok)
+	assert.Equal(t, models.Snapshotting, previous)
+}
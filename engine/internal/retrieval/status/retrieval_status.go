@@ -8,6 +8,7 @@ package status
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
@@ -45,6 +46,19 @@ const (
 		 pg_stat_database
 		LIMIT 1;
 	`
+
+	subscriptionQuery = `
+		SELECT
+		  st.pid IS NOT NULL,
+		  coalesce(round(date_part('epoch', now() - st.latest_end_time))::int8, 0) lag_sec,
+		  st.latest_end_time,
+		  st.latest_end_lsn
+		FROM pg_subscription s
+		LEFT JOIN pg_stat_subscription st ON st.subid = s.oid AND st.relid IS NULL
+		WHERE s.subname = $1
+		ORDER BY st.latest_end_time DESC NULLS LAST
+		LIMIT 1;
+	`
 )
 
 // FetchSyncMetrics - fetch synchronization status.
@@ -95,6 +109,73 @@ func FetchSyncMetrics(ctx context.Context, config *global.Config, socketPath str
 	return &sync, nil
 }
 
+// FetchLogicalSyncMetrics fetches synchronization status of a sync instance subscribed to the source.
+func FetchLogicalSyncMetrics(ctx context.Context, config *global.Config, socketPath, dbName, subscription string) (
+	*models.Sync, error) {
+	conn, err := openConnection(ctx, config.Database.User(), dbName, socketPath)
+	if err != nil {
+		return &models.Sync{
+			Status: models.Status{Code: models.SyncStatusError},
+		}, err
+	}
+
+	defer func() {
+		if err := conn.Close(ctx); err != nil {
+			log.Dbg("Failed to close connection", err)
+		}
+	}()
+
+	sync, err := subscriptionStatus(ctx, conn, subscription)
+	if err != nil {
+		return &models.Sync{
+			Status: models.Status{Code: models.SyncStatusError, Message: err.Error()},
+		}, err
+	}
+
+	uptime, err := syncUptime(ctx, conn)
+	if err != nil {
+		log.Warn("Failed to fetch postgres sync uptime", err)
+	} else {
+		sync.ReplicationUptime = uptime
+	}
+
+	return sync, nil
+}
+
+func subscriptionStatus(ctx context.Context, conn *pgx.Conn, subscription string) (*models.Sync, error) {
+	var (
+		workerRunning       bool
+		lagSec              int
+		lastEndTime, endLsn sql.NullString
+	)
+
+	row := conn.QueryRow(ctx, subscriptionQuery, subscription)
+
+	if err := row.Scan(&workerRunning, &lagSec, &lastEndTime, &endLsn); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("subscription %q not found", subscription)
+		}
+
+		return nil, fmt.Errorf("failed to read subscription status: %w", err)
+	}
+
+	sync := &models.Sync{
+		Status:            models.Status{Code: models.SyncStatusOK},
+		ReplicationLag:    lagSec,
+		LastReplayedLsn:   endLsn.String,
+		LastReplayedLsnAt: lastEndTime.String,
+	}
+
+	if !workerRunning {
+		sync.Status = models.Status{
+			Code:    models.SyncStatusDown,
+			Message: fmt.Sprintf("apply worker of subscription %q is not running", subscription),
+		}
+	}
+
+	return sync, nil
+}
+
 func openConnection(ctx context.Context, username, dbname, socketPath string) (*pgx.Conn, error) {
 	connectionStr := fmt.Sprintf(`host=%s port=%d user=%s dbname=%s`,
 		socketPath,
@@ -11,6 +11,7 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/logical"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/physical"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/snapshot"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/options"
 )
 
 // ValidateConfig validates retrieval configuration.
@@ -66,6 +67,43 @@ func validateStructure(r *config.Config) error {
 		return errors.New("must not contain physical and logical jobs simultaneously")
 	}
 
+	return validateReplicationSlot(r.JobsSpec)
+}
+
+// validateReplicationSlot checks that the logical dump creates the replication slot used by the subscription,
+// so the subscription continues from the snapshot of the dump.
+func validateReplicationSlot(jobSpecs map[string]config.JobSpec) error {
+	replicationSpec, ok := jobSpecs[snapshot.LogicalReplicationType]
+	if !ok {
+		return nil
+	}
+
+	dumpSpec, ok := jobSpecs[logical.DumpJobType]
+	if !ok {
+		return fmt.Errorf("%s requires the %s job creating the replication slot", snapshot.LogicalReplicationType, logical.DumpJobType)
+	}
+
+	var (
+		replicationOpts snapshot.LogicalReplicationOptions
+		dumpOpts        logical.DumpOptions
+	)
+
+	if err := options.Unmarshal(replicationSpec.Options, &replicationOpts); err != nil {
+		return fmt.Errorf("failed to unmarshal %s options: %w", snapshot.LogicalReplicationType, err)
+	}
+
+	if err := options.Unmarshal(dumpSpec.Options, &dumpOpts); err != nil {
+		return fmt.Errorf("failed to unmarshal %s options: %w", logical.DumpJobType, err)
+	}
+
+	// The subscribed database does not affect the slot name.
+	replicationOpts.Subscription.SetDefaults("")
+
+	if dumpOpts.ReplicationSlot != replicationOpts.Subscription.SlotName {
+		return fmt.Errorf("replicationSlot of the %s job must be %q to match the slot of the subscription",
+			logical.DumpJobType, replicationOpts.Subscription.SlotName)
+	}
+
 	return nil
 }
 
@@ -101,7 +139,13 @@ func hasLogicalJob(jobSpecs map[string]config.JobSpec) bool {
 		return true
 	}
 
-	return false
+	return hasReplicationJob(jobSpecs)
+}
+
+func hasReplicationJob(jobSpecs map[string]config.JobSpec) bool {
+	_, hasLogicalReplication := jobSpecs[snapshot.LogicalReplicationType]
+
+	return hasLogicalReplication
 }
 
 func hasPhysicalJob(jobSpecs map[string]config.JobSpec) bool {
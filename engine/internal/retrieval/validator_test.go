@@ -62,6 +62,62 @@ func TestInvalidParallelJobSpecs(t *testing.T) {
 	}
 }
 
+func TestValidateReplicationSlot(t *testing.T) {
+	replicationSpec := config.JobSpec{Options: map[string]interface{}{
+		"subscription": map[string]interface{}{"publication": "dblab_publication", "slotName": "dblab_slot"},
+	}}
+
+	testCases := []struct {
+		name    string
+		spec    map[string]config.JobSpec
+		wantErr string
+	}{
+		{
+			name: "no replication",
+			spec: map[string]config.JobSpec{"logicalDump": {}},
+		},
+		{
+			name: "dump creates the subscription slot",
+			spec: map[string]config.JobSpec{
+				"logicalDump":        {Options: map[string]interface{}{"replicationSlot": "dblab_slot"}},
+				"logicalReplication": replicationSpec,
+			},
+		},
+		{
+			name: "default slot name",
+			spec: map[string]config.JobSpec{
+				"logicalDump":        {Options: map[string]interface{}{"replicationSlot": "dblab_subscription"}},
+				"logicalReplication": {},
+			},
+		},
+		{
+			name:    "no dump job",
+			spec:    map[string]config.JobSpec{"logicalRestore": {}, "logicalReplication": replicationSpec},
+			wantErr: "requires the logicalDump job",
+		},
+		{
+			name: "dump does not create the slot",
+			spec: map[string]config.JobSpec{
+				"logicalDump":        {},
+				"logicalReplication": replicationSpec,
+			},
+			wantErr: `replicationSlot of the logicalDump job must be "dblab_slot"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateStructure(&config.Config{JobsSpec: tc.spec})
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
 func TestPhysicalJobs(t *testing.T) {
 	testCases := []struct {
 		spec        map[string]config.JobSpec
@@ -122,6 +178,10 @@ func TestLogicalJobs(t *testing.T) {
 			spec:       map[string]config.JobSpec{"logicalDump": {}},
 			hasLogical: true,
 		},
+		{
+			spec:       map[string]config.JobSpec{"logicalReplication": {}},
+			hasLogical: true,
+		},
 		{
 			spec: map[string]config.JobSpec{
 				"logicalDump":     {},
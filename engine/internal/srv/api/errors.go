@@ -51,6 +51,16 @@ func SendUnauthorizedError(w http.ResponseWriter, r *http.Request) {
 	SendError(w, r, errorUnauthorized)
 }
 
+// SendForbiddenError sends an error for an authenticated request lacking the required permissions.
+func SendForbiddenError(w http.ResponseWriter, r *http.Request, message string) {
+	errorForbidden := models.Error{
+		Code:    models.ErrCodeForbidden,
+		Message: message,
+	}
+
+	SendError(w, r, errorForbidden)
+}
+
 // SendNotFoundError sends a not found error.
 func SendNotFoundError(w http.ResponseWriter, r *http.Request) {
 	errorNotFound := models.Error{
@@ -80,6 +90,12 @@ func toStatusCode(err models.Error) int {
 	case models.ErrCodeUnauthorized:
 		return http.StatusUnauthorized
 
+	case models.ErrCodeForbidden:
+		return http.StatusForbidden
+
+	case models.ErrCodeQuotaExceeded:
+		return http.StatusTooManyRequests
+
 	case models.ErrCodeNotFound:
 		return http.StatusNotFound
 
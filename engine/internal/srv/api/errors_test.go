@@ -31,6 +31,14 @@ func TestErrorCodeStatuses(t *testing.T) {
 			error: "UNAUTHORIZED",
 			code:  401,
 		},
+		{
+			error: "FORBIDDEN",
+			code:  403,
+		},
+		{
+			error: "QUOTA_EXCEEDED",
+			code:  429,
+		},
 		{
 			error: "NOT_FOUND",
 			code:  404,
@@ -110,6 +118,20 @@ func TestSendUnauthorizedError(t *testing.T) {
 	assert.Contains(t, errResp.Message, "verification token")
 }
 
+func TestSendForbiddenError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodDelete, "/branch/dev", nil)
+	rec := httptest.NewRecorder()
+
+	SendForbiddenError(rec, req, "admin role required")
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	var errResp models.Error
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+	assert.Equal(t, models.ErrCodeForbidden, errResp.Code)
+	assert.Equal(t, "admin role required", errResp.Message)
+}
+
 func TestSendNotFoundError(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/clone/missing", nil)
 	rec := httptest.NewRecorder()
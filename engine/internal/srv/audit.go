@@ -0,0 +1,280 @@
+/*
+2026 © Postgres.ai
+*/
+
+package srv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/audit"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/operations"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/mw"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/tracing"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	// maxAuditedBodyBytes limits request bodies recorded as parameters; larger bodies are not recorded.
+	maxAuditedBodyBytes = 64 << 10
+	// maxAuditedErrorBytes limits error responses read to record the error message.
+	maxAuditedErrorBytes = 4 << 10
+	maxAuditListLimit    = 1000
+)
+
+// routeTargetVars are route variables that identify the object of an action.
+var routeTargetVars = []string{"id", "branchName", "clone_id"}
+
+// SetAuditLog attaches the log of actions performed through the API.
+func (s *Server) SetAuditLog(auditLog *audit.Log) {
+	s.auditLog = auditLog
+}
+
+// ReloadAudit applies new settings of the audit log.
+func (s *Server) ReloadAudit(cfg audit.Config) {
+	if s.auditLog != nil {
+		s.auditLog.Reload(cfg)
+	}
+}
+
+// audited records the action performed by the handler in the audit log: the caller, the object of the action,
+// request parameters without credentials, and the outcome. It must be wrapped by the authorization middleware,
+// which identifies the caller.
+func (s *Server) audited(action string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.auditLog == nil {
+			next(w, r)
+			return
+		}
+
+		startedAt := time.Now()
+		params := auditParams(r)
+
+		ctx, annotation := audit.WithAnnotation(r.Context())
+		recorder := &auditRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next(recorder, r.WithContext(ctx))
+
+		entry := models.AuditEntry{
+			Time:        startedAt,
+			Actor:       mw.ActorFromContext(ctx),
+			Role:        string(mw.RoleFromContext(ctx)),
+			Action:      action,
+			Target:      routeTarget(r),
+			Params:      params,
+			Outcome:     models.AuditOutcomeSuccess,
+			StatusCode:  recorder.status,
+			RemoteAddr:  r.RemoteAddr,
+			TraceID:     tracing.TraceID(ctx),
+			OperationID: operations.FromContext(ctx).ID(),
+		}
+
+		if annotation.Action != "" {
+			entry.Action = annotation.Action
+		}
+
+		if annotation.Target != "" {
+			entry.Target = annotation.Target
+		}
+
+		if recorder.status >= http.StatusBadRequest {
+			entry.Outcome = models.AuditOutcomeFailure
+			entry.Error = recorder.errorMessage()
+			s.recordAudit(entry)
+
+			return
+		}
+
+		// An action that continues in the background is recorded with the outcome of its operation.
+		if op := operations.FromContext(ctx); op.IsDetached() {
+			op.OnFinish(func(state models.Operation) {
+				if state.State != models.OperationSucceeded {
+					entry.Outcome = models.AuditOutcomeFailure
+					entry.Error = state.Error
+				}
+
+				s.recordAudit(entry)
+			})
+
+			return
+		}
+
+		s.recordAudit(entry)
+	}
+}
+
+func (s *Server) recordAudit(entry models.AuditEntry) {
+	if err := s.auditLog.Record(entry); err != nil {
+		log.Err("failed to record audit entry:", err)
+	}
+}
+
+// protectionAction returns the audited action of a protection change of a clone, snapshot, or branch.
+func protectionAction(resource string, protected bool) string {
+	if protected {
+		return resource + ".protect"
+	}
+
+	return resource + ".unprotect"
+}
+
+// auditParams returns the JSON body and the query parameters of the request with credentials redacted.
+// The body is restored for the handler.
+func auditParams(r *http.Request) map[string]any {
+	params := make(map[string]any)
+
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err := io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if err == nil && len(body) <= maxAuditedBodyBytes {
+			// Bodies other than JSON objects are not recorded.
+			_ = json.Unmarshal(body, &params)
+		}
+	}
+
+	for key, values := range r.URL.Query() {
+		if _, ok := params[key]; !ok {
+			params[key] = strings.Join(values, ",")
+		}
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+
+	return audit.Redact(params)
+}
+
+// routeTarget returns the object of the action identified by the route, e.g. the clone ID.
+func routeTarget(r *http.Request) string {
+	vars := mux.Vars(r)
+
+	for _, name := range routeTargetVars {
+		if value, ok := vars[name]; ok && value != "" {
+			if unescaped, err := url.PathUnescape(value); err == nil {
+				return unescaped
+			}
+
+			return value
+		}
+	}
+
+	return ""
+}
+
+// auditRecorder captures the status code of a response and the beginning of error responses.
+type auditRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	errorBody   bytes.Buffer
+}
+
+// WriteHeader records the status code and sends it.
+func (r *auditRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write sends the response body, keeping the beginning of error responses.
+func (r *auditRecorder) Write(body []byte) (int, error) {
+	r.wroteHeader = true
+
+	if r.status >= http.StatusBadRequest && r.errorBody.Len() < maxAuditedErrorBytes {
+		r.errorBody.Write(body[:min(len(body), maxAuditedErrorBytes-r.errorBody.Len())])
+	}
+
+	return r.ResponseWriter.Write(body)
+}
+
+// Unwrap returns the original response writer for http.ResponseController.
+func (r *auditRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *auditRecorder) errorMessage() string {
+	var apiErr models.Error
+
+	if err := json.Unmarshal(r.errorBody.Bytes(), &apiErr); err == nil && apiErr.Message != "" {
+		return apiErr.Message
+	}
+
+	if message := strings.TrimSpace(r.errorBody.String()); message != "" {
+		return message
+	}
+
+	return http.StatusText(r.status)
+}
+
+func (s *Server) auditEntries(w http.ResponseWriter, r *http.Request) {
+	if s.auditLog == nil {
+		api.SendBadRequestError(w, r, "audit log is not available")
+		return
+	}
+
+	filter, err := auditFilter(r.URL.Query())
+	if err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	entries, err := s.auditLog.List(filter)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, entries); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+// auditFilter parses the filter of audit entries: the "since" and "until" times in RFC 3339 format,
+// the "actor", the "action" or its resource, e.g. "clone", and the "limit" on the number of entries.
+func auditFilter(query url.Values) (audit.Filter, error) {
+	filter := audit.Filter{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+	}
+
+	for name, value := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if raw := query.Get(name); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return audit.Filter{}, fmt.Errorf("%s must be a time in RFC 3339 format, e.g. 2026-01-02T15:04:05Z", name)
+			}
+
+			*value = parsed
+		}
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxAuditListLimit {
+			return audit.Filter{}, fmt.Errorf("limit must be a number from 1 to %d", maxAuditListLimit)
+		}
+
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
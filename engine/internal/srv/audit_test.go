@@ -0,0 +1,151 @@
+package srv
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/audit"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/platform"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/mw"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func newAuditedServer(t *testing.T) *Server {
+	t.Helper()
+
+	auditLog, err := audit.Open(filepath.Join(t.TempDir(), "audit.log"), audit.Config{})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = auditLog.Close() })
+
+	s := &Server{}
+	s.SetAuditLog(auditLog)
+
+	return s
+}
+
+func serveAudited(t *testing.T, handler http.HandlerFunc, route, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := mux.NewRouter()
+	router.HandleFunc(route, handler)
+
+	req := httptest.NewRequest(http.MethodPost, target, bytes.NewBufferString(body))
+	req = req.WithContext(mw.WithUserIdentity(context.Background(), platform.UserIdentity{Email: "alice@example.com"}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestAuditedRecordsSuccess(t *testing.T) {
+	s := newAuditedServer(t)
+
+	var handlerBody []byte
+
+	handler := s.audited("clone.reset", func(w http.ResponseWriter, r *http.Request) {
+		var err error
+
+		handlerBody, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	body := `{"latest":true,"db":{"username":"john","password":"secret"}}`
+	rec := serveAudited(t, handler, "/clone/{id}/reset", "/clone/clone1/reset?force=true", body)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, body, string(handlerBody), "the handler must receive the original body")
+
+	entries, err := s.auditLog.List(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	assert.Equal(t, "alice@example.com", entry.Actor)
+	assert.Equal(t, "clone.reset", entry.Action)
+	assert.Equal(t, "clone1", entry.Target)
+	assert.Equal(t, models.AuditOutcomeSuccess, entry.Outcome)
+	assert.Equal(t, http.StatusOK, entry.StatusCode)
+	assert.Empty(t, entry.Error)
+	assert.Equal(t, true, entry.Params["latest"])
+	assert.Equal(t, "true", entry.Params["force"])
+	assert.Equal(t, map[string]any{"username": "john", "password": "[REDACTED]"}, entry.Params["db"])
+	assert.WithinDuration(t, time.Now(), entry.Time, time.Minute)
+}
+
+func TestAuditedRecordsFailure(t *testing.T) {
+	s := newAuditedServer(t)
+
+	handler := s.audited("clone.destroy", func(w http.ResponseWriter, r *http.Request) {
+		api.SendBadRequestError(w, r, "clone is protected")
+	})
+
+	rec := serveAudited(t, handler, "/clone/{id}", "/clone/clone1", "")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	entries, err := s.auditLog.List(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.AuditOutcomeFailure, entries[0].Outcome)
+	assert.Equal(t, http.StatusBadRequest, entries[0].StatusCode)
+	assert.Equal(t, "clone is protected", entries[0].Error)
+	assert.Nil(t, entries[0].Params)
+}
+
+func TestAuditedAnnotation(t *testing.T) {
+	s := newAuditedServer(t)
+
+	handler := s.audited("clone.update", func(w http.ResponseWriter, r *http.Request) {
+		audit.SetAction(r.Context(), protectionAction("clone", true))
+		audit.SetTarget(r.Context(), "clone2")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serveAudited(t, handler, "/clone/{id}", "/clone/clone1", `{"protected":true}`)
+
+	entries, err := s.auditLog.List(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "clone.protect", entries[0].Action)
+	assert.Equal(t, "clone2", entries[0].Target)
+}
+
+func TestAuditFilter(t *testing.T) {
+	filter, err := auditFilter(url.Values{
+		"since":  {"2026-03-01T10:00:00Z"},
+		"actor":  {"alice@example.com"},
+		"action": {"clone"},
+		"limit":  {"20"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, audit.Filter{
+		Since:  time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		Actor:  "alice@example.com",
+		Action: "clone",
+		Limit:  20,
+	}, filter)
+
+	for _, query := range []url.Values{
+		{"until": {"yesterday"}},
+		{"limit": {"0"}},
+		{"limit": {"1001"}},
+		{"limit": {"ten"}},
+	} {
+		_, err := auditFilter(query)
+		assert.Error(t, err, query.Encode())
+	}
+}
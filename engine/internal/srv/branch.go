@@ -2,6 +2,7 @@ package srv
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -11,8 +12,11 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/audit"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/pool"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/masking"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/telemetry"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
@@ -293,6 +297,7 @@ func (s *Server) createBranch(w http.ResponseWriter, r *http.Request) {
 	fsm.RefreshSnapshotList()
 
 	branch := models.Branch{Name: createRequest.BranchName}
+	setTarget(r.Context(), branch.Name)
 
 	s.webhookCh <- webhooks.BasicEvent{
 		EventType: webhooks.BranchCreateEvent,
@@ -338,6 +343,44 @@ func (s *Server) getSnapshot(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// getMaskingReport returns the report of the masking applied to the data of the snapshot.
+func (s *Server) getMaskingReport(w http.ResponseWriter, r *http.Request) {
+	snapshotID := mux.Vars(r)["id"]
+
+	if snapshotID == "" {
+		api.SendBadRequestError(w, r, "snapshotID must not be empty")
+		return
+	}
+
+	if _, err := s.Cloning.GetSnapshotByID(snapshotID); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	data, err := s.snapshotMetadata.Load(thinclones.MaskingReportMetadata, snapshotID)
+	if err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+
+	if data == nil {
+		api.SendNotFoundError(w, r)
+		return
+	}
+
+	report := &masking.Report{}
+
+	if err := json.Unmarshal(data, report); err != nil {
+		api.SendError(w, r, fmt.Errorf("failed to parse masking report of snapshot %s: %w", snapshotID, err))
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, report); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
 func (s *Server) getCommit(w http.ResponseWriter, r *http.Request) {
 	snapshotID := mux.Vars(r)["id"]
 
@@ -463,6 +506,13 @@ func (s *Server) snapshot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if snapshotRequest.Migration != "" {
+		if err := s.snapshotMetadata.Save(thinclones.MigrationMetadata, snapshotName, []byte(snapshotRequest.Migration)); err != nil {
+			api.SendBadRequestError(w, r, err.Error())
+			return
+		}
+	}
+
 	fsm.RefreshSnapshotList()
 
 	if err := s.Cloning.ReloadSnapshots(); err != nil {
@@ -472,6 +522,8 @@ func (s *Server) snapshot(w http.ResponseWriter, r *http.Request) {
 
 	s.tm.SendEvent(context.Background(), telemetry.SnapshotCreatedEvent, telemetry.SnapshotCreated{})
 
+	setTarget(r.Context(), snapshotName)
+
 	if err := api.WriteJSON(w, http.StatusOK, types.SnapshotResponse{SnapshotID: snapshotName}); err != nil {
 		api.SendError(w, r, err)
 		return
@@ -653,6 +705,9 @@ func (s *Server) destroyBranchOnPool(fsm pool.FSManager, branchName string) erro
 		return destroyErr
 	}
 
+	// The branch dataset is destroyed recursively, so its snapshots are not reported one by one.
+	thinclones.DeleteSnapshotMetadata(toRemove...)
+
 	return s.cleanupAfterBranchDeletion(fsm, branchName)
 }
 
@@ -779,6 +834,10 @@ func (s *Server) patchBranch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Protected != nil {
+		audit.SetAction(r.Context(), protectionAction("branch", *req.Protected))
+	}
+
 	datasets := s.branchDatasets(branchName)
 	if len(datasets) == 0 {
 		api.SendBadRequestError(w, r, "branch not found: "+branchName)
@@ -806,6 +865,11 @@ func (s *Server) patchBranch(w http.ResponseWriter, r *http.Request) {
 	protected, till, deleteAt := readBranchProtection(datasets)
 	view := models.BranchView{Name: branchName, Protected: protected, ProtectedTill: till, DeleteAt: deleteAt}
 
+	s.webhookCh <- webhooks.BasicEvent{
+		EventType: webhooks.BranchUpdateEvent,
+		EntityID:  branchName,
+	}
+
 	s.tm.SendEvent(context.Background(), telemetry.BranchUpdatedEvent, telemetry.BranchUpdated{
 		Name:      branchName,
 		Protected: protected,
@@ -425,3 +425,21 @@ func TestFilterSnapshotsByBranch_EdgeCases(t *testing.T) {
 		assert.Empty(t, result)
 	})
 }
+
+func TestBranchCommits(t *testing.T) {
+	repo := &models.Repo{
+		Snapshots: map[string]models.SnapshotDetails{
+			"s1": {ID: "s1", Parent: "-", Root: []string{"dev"}},
+			"s2": {ID: "s2", Parent: "s1"},
+			"s3": {ID: "s3", Parent: "s2"},
+		},
+	}
+
+	t.Run("returns commits since the fork point oldest first", func(t *testing.T) {
+		assert.Equal(t, []string{"s2", "s3"}, branchCommits(repo, "s3", "dev"))
+	})
+
+	t.Run("returns nothing when the head is the fork point", func(t *testing.T) {
+		assert.Empty(t, branchCommits(repo, "s1", "dev"))
+	})
+}
@@ -11,6 +11,18 @@ type Config struct {
 	Host                      string `yaml:"host"`
 	Port                      uint   `yaml:"port"`
 	DisableConfigModification bool   `yaml:"disableConfigModification" json:"-"`
+	Access                    Access `yaml:"access" json:"-"`
+}
+
+// Access configures role-based access control of the HTTP API. Roles are "viewer", "developer"
+// and "admin"; the shared verification token always grants the admin role.
+type Access struct {
+	// DefaultRole is granted to personal-token users not listed in Users; empty means "developer".
+	DefaultRole string `yaml:"defaultRole"`
+	// Users maps user emails resolved from personal tokens to roles.
+	Users map[string]string `yaml:"users"`
+	// Tokens maps additional static API tokens to roles, e.g. a read-only token for dashboards.
+	Tokens map[string]string `yaml:"tokens"`
 }
 
 // Retention configures background auto-deletion of unused branches and snapshots.
@@ -0,0 +1,186 @@
+/*
+2026 © Postgres.ai
+*/
+
+package srv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/diff"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	// diffTimeout bounds starting the temporary clones and reading their catalogs,
+	// so a diff does not hold the request open for longer than a client is likely to wait.
+	diffTimeout = 5 * time.Minute
+
+	diffFormatJSON = "json"
+	diffFormatSQL  = "sql"
+
+	textContentType = "text/plain; charset=utf-8"
+)
+
+// snapshotDiff compares the schema and table statistics of a snapshot against another snapshot or a clone.
+// Snapshots are read in temporary clones; a clone is read as is, in its own database, and the snapshot is read in the same one.
+func (s *Server) snapshotDiff(w http.ResponseWriter, r *http.Request) {
+	snapshotID := mux.Vars(r)["id"]
+	query := r.URL.Query()
+	against := query.Get("against")
+
+	if snapshotID == "" || against == "" {
+		api.SendBadRequestError(w, r, "snapshot ID and the 'against' parameter must not be empty")
+		return
+	}
+
+	if snapshotID == against {
+		api.SendBadRequestError(w, r, "cannot compare a snapshot with itself")
+		return
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = diffFormatJSON
+	}
+
+	if format != diffFormatJSON && format != diffFormatSQL {
+		api.SendBadRequestError(w, r, "format must be either json or sql")
+		return
+	}
+
+	exactCounts := false
+
+	if value := query.Get("exactCounts"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			api.SendBadRequestError(w, r, "exactCounts must be a boolean")
+			return
+		}
+
+		exactCounts = parsed
+	}
+
+	if _, err := s.Cloning.GetSnapshotByID(snapshotID); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	var againstClone *models.Clone
+
+	if clone, err := s.Cloning.GetClone(against); err == nil {
+		if !s.authorizeCloneAction(w, r, against, "diff against") {
+			return
+		}
+
+		againstClone = clone
+	}
+
+	dbName, err := diffDBName(query.Get("dbName"), againstClone)
+	if err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	if dbName == "" {
+		dbName = s.Global.Database.Name()
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), diffTimeout)
+	defer cancel()
+
+	base, err := s.collectCatalog(ctx, against, dbName, exactCounts)
+	if err != nil {
+		sendRequestError(w, r, err)
+		return
+	}
+
+	target, err := s.collectCatalog(ctx, snapshotID, dbName, exactCounts)
+	if err != nil {
+		sendRequestError(w, r, err)
+		return
+	}
+
+	changes, tables := diff.Compare(base, target)
+
+	result := &models.SnapshotDiff{
+		Snapshot:    snapshotID,
+		Against:     against,
+		DBName:      dbName,
+		ExactCounts: exactCounts,
+		Changes:     changes,
+		Tables:      tables,
+	}
+
+	if format == diffFormatSQL {
+		if err := api.WriteDataTyped(w, http.StatusOK, textContentType, []byte(diff.FormatSQL(result))); err != nil {
+			api.SendError(w, r, err)
+		}
+
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, result); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+// diffDBName returns the database to compare. A clone is read in its own database,
+// so the snapshot is read in the same one, and a different requested database is rejected.
+func diffDBName(requested string, clone *models.Clone) (string, error) {
+	if clone == nil {
+		return requested, nil
+	}
+
+	if requested != "" && requested != clone.DB.DBName {
+		return "", fmt.Errorf("clone %s can only be compared in its database %q", clone.ID, clone.DB.DBName)
+	}
+
+	return clone.DB.DBName, nil
+}
+
+// collectCatalog reads the catalog of an existing clone, or of a snapshot in a temporary clone.
+func (s *Server) collectCatalog(ctx context.Context, id, dbName string, exactCounts bool) (*diff.Catalog, error) {
+	if clone, err := s.Cloning.GetClone(id); err == nil {
+		if clone.Status.Code != models.StatusOK {
+			return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("clone %s is not ready: %s", id, clone.Status.Code))
+		}
+
+		return s.readCloneCatalog(ctx, clone.ID, exactCounts)
+	}
+
+	if _, err := s.Cloning.GetSnapshotByID(id); err != nil {
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("neither snapshot nor clone %s found", id))
+	}
+
+	log.Dbg(fmt.Sprintf("Starting a temporary clone of %s to read its catalog", id))
+
+	clone, err := s.startTemporaryClone(ctx, "", id, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer s.destroyTemporaryClone(clone.ID)
+
+	return s.readCloneCatalog(ctx, clone.ID, exactCounts)
+}
+
+func (s *Server) readCloneCatalog(ctx context.Context, cloneID string, exactCounts bool) (*diff.Catalog, error) {
+	conn, err := s.Cloning.ConnectToClone(ctx, cloneID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clone %s: %w", cloneID, err)
+	}
+
+	defer func() { _ = conn.Close(ctx) }()
+
+	return diff.Collect(ctx, conn, exactCounts)
+}
@@ -0,0 +1,41 @@
+package srv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestDiffDBName(t *testing.T) {
+	clone := &models.Clone{ID: "clone1", DB: models.Database{DBName: "app"}}
+
+	testCases := []struct {
+		name      string
+		requested string
+		clone     *models.Clone
+		expected  string
+		wantErr   bool
+	}{
+		{name: "snapshot with the default database", requested: "", expected: ""},
+		{name: "snapshot with a requested database", requested: "app", expected: "app"},
+		{name: "clone with the default database", requested: "", clone: clone, expected: "app"},
+		{name: "clone with its own database", requested: "app", clone: clone, expected: "app"},
+		{name: "clone with another database", requested: "postgres", clone: clone, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dbName, err := diffDBName(tc.requested, tc.clone)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, dbName)
+		})
+	}
+}
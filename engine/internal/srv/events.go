@@ -0,0 +1,131 @@
+/*
+2026 © Postgres.ai
+*/
+
+package srv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/events"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	// eventStreamHeartbeat keeps idle event streams open behind proxies.
+	eventStreamHeartbeat = 15 * time.Second
+
+	lastEventIDHeader = "Last-Event-ID"
+	lastEventIDKey    = "lastEventId"
+)
+
+// SetEvents attaches the broker of engine events.
+func (s *Server) SetEvents(broker *events.Broker) {
+	s.events = broker
+}
+
+// eventStream sends engine events as server-sent events. A client resumes from the last received event
+// with the Last-Event-ID header or the lastEventId query parameter.
+func (s *Server) eventStream(w http.ResponseWriter, r *http.Request) {
+	if s.events == nil {
+		api.SendBadRequestError(w, r, "event stream is not available")
+		return
+	}
+
+	lastEventID, err := parseLastEventID(r)
+	if err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	controller := http.NewResponseController(w)
+
+	// The stream outlives any write timeout of the server.
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		log.Dbg("failed to reset write deadline of event stream:", err)
+	}
+
+	missed, eventCh, unsubscribe := s.events.Subscribe(lastEventID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range missed {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+	}
+
+	if err := controller.Flush(); err != nil {
+		log.Err("failed to flush event stream:", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case event, ok := <-eventCh:
+			if !ok {
+				// The client has fallen behind and has to reconnect.
+				return
+			}
+
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func parseLastEventID(r *http.Request) (uint64, error) {
+	raw := r.Header.Get(lastEventIDHeader)
+	if raw == "" {
+		raw = r.URL.Query().Get(lastEventIDKey)
+	}
+
+	if raw == "" {
+		return 0, nil
+	}
+
+	lastEventID, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid last event ID: %q", raw)
+	}
+
+	return lastEventID, nil
+}
+
+func writeEvent(w io.Writer, event models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+
+	return err
+}
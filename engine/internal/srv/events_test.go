@@ -0,0 +1,105 @@
+package srv
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/events"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
+)
+
+func TestEventStream(t *testing.T) {
+	broker := events.NewBroker()
+	s := &Server{}
+	s.SetEvents(broker)
+
+	srv := httptest.NewServer(http.HandlerFunc(s.eventStream))
+	defer srv.Close()
+
+	broker.Publish(webhooks.BasicEvent{EventType: webhooks.SnapshotCreateEvent, EntityID: "snapshot1"})
+
+	// A resync event carries the ID of the last published event.
+	missed, _, unsubscribe := broker.Subscribe(1)
+	unsubscribe()
+	require.Len(t, missed, 1)
+
+	lastEventID := missed[0].ID
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(lastEventIDHeader, strconv.FormatUint(lastEventID-1, 10))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+
+	readEvent := func() []string {
+		var lines []string
+
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				return lines
+			}
+
+			lines = append(lines, line)
+		}
+	}
+
+	replayed := readEvent()
+	require.Len(t, replayed, 3)
+	assert.Equal(t, "id: "+strconv.FormatUint(lastEventID, 10), replayed[0])
+	assert.Equal(t, "event: snapshot_create", replayed[1])
+	assert.Contains(t, replayed[2], `"entityId":"snapshot1"`)
+
+	broker.Publish(webhooks.CloneStatusEvent{
+		BasicEvent: webhooks.BasicEvent{EventType: webhooks.CloneStatusChangeEvent, EntityID: "clone1"},
+		Status:     "OK",
+	})
+
+	live := readEvent()
+	require.Len(t, live, 3)
+	assert.Equal(t, "id: "+strconv.FormatUint(lastEventID+1, 10), live[0])
+	assert.Equal(t, "event: clone_status_change", live[1])
+	assert.Contains(t, live[2], `"status":"OK"`)
+}
+
+func TestParseLastEventID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/events?lastEventId=42", nil)
+
+	lastEventID, err := parseLastEventID(req)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), lastEventID)
+
+	req.Header.Set(lastEventIDHeader, "43")
+
+	lastEventID, err = parseLastEventID(req)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(43), lastEventID, "the header takes precedence")
+
+	req = httptest.NewRequest(http.MethodGet, "/events?lastEventId=abc", nil)
+
+	_, err = parseLastEventID(req)
+	assert.Error(t, err)
+}
@@ -571,7 +571,8 @@ func (c *Collector) collectBranchMetrics() {
 }
 
 func (c *Collector) collectSyncMetrics(ctx context.Context) {
-	if c.retrieval.GetRetrievalMode() != models.Physical {
+	// logical mode reports the status of a subscription if the logicalReplication job is configured.
+	if mode := c.retrieval.GetRetrievalMode(); mode != models.Physical && mode != models.Logical {
 		return
 	}
 
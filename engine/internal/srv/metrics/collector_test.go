@@ -468,9 +468,9 @@ func (m *configMockRetrievalService) ReportSyncStatus(_ context.Context) (*model
 }
 
 func TestCollectSyncMetrics(t *testing.T) {
-	t.Run("non-physical mode skips collection", func(t *testing.T) {
+	t.Run("unknown mode skips collection", func(t *testing.T) {
 		m := NewMetrics()
-		retrieval := &configMockRetrievalService{mode: models.Logical}
+		retrieval := &configMockRetrievalService{mode: models.Unknown}
 		c := &Collector{metrics: m, retrieval: retrieval, prevCPUStats: make(map[string]containerCPUState)}
 
 		c.collectSyncMetrics(context.Background())
@@ -479,6 +479,23 @@ func TestCollectSyncMetrics(t *testing.T) {
 		assert.Equal(t, float64(0), getGaugeValue(m.SyncUptimeSeconds))
 	})
 
+	t.Run("logical mode collects subscription status", func(t *testing.T) {
+		m := NewMetrics()
+		syncStatus := &models.Sync{
+			Status:            models.Status{Code: models.SyncStatusDown},
+			ReplicationLag:    15,
+			ReplicationUptime: 600,
+		}
+		retrieval := &configMockRetrievalService{mode: models.Logical, syncStatus: syncStatus}
+		c := &Collector{metrics: m, retrieval: retrieval, prevCPUStats: make(map[string]containerCPUState)}
+
+		c.collectSyncMetrics(context.Background())
+
+		assert.Equal(t, float64(15), getGaugeValue(m.SyncWALLagSeconds))
+		assert.Equal(t, float64(600), getGaugeValue(m.SyncUptimeSeconds))
+		assert.Equal(t, float64(1), getGaugeVecValue(m.SyncStatus, string(models.SyncStatusDown)))
+	})
+
 	t.Run("error from ReportSyncStatus sets not available status", func(t *testing.T) {
 		m := NewMetrics()
 		retrieval := &configMockRetrievalService{mode: models.Physical, syncErr: fmt.Errorf("connection failed")}
@@ -7,12 +7,19 @@ package mw
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 
 	"gitlab.com/postgres-ai/database-lab/v3/internal/platform"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	srvCfg "gitlab.com/postgres-ai/database-lab/v3/internal/srv/config"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/ws"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 )
 
 // VerificationTokenHeader defines the verification token name that should be passed in request headers.
@@ -32,10 +39,34 @@ type ctxKey string
 // userIdentityKey is the context key carrying the authenticated user identity.
 const userIdentityKey ctxKey = "dblab_user_identity"
 
+// actorKey is the context key carrying the caller name of requests without a user identity.
+const actorKey ctxKey = "dblab_actor"
+
+// tokenOwnerKey is the context key carrying the owner name of an additional static token.
+const tokenOwnerKey ctxKey = "dblab_token_owner"
+
+// Names of callers without a user identity, as recorded in the audit log.
+const (
+	anonymousActor         = "anonymous"
+	verificationTokenActor = "verification-token"
+	personalTokenActor     = "personal-token"
+	unknownActor           = "unknown"
+)
+
+const (
+	// tokenOwnerPrefix prefixes the owner names of additional static tokens.
+	tokenOwnerPrefix = "token-"
+
+	// tokenFingerprintLength defines the number of hex characters of the token hash used in its owner name.
+	tokenFingerprintLength = 12
+)
+
 // Auth defines an authorization middleware of the Database Lab HTTP server.
 type Auth struct {
 	verificationToken     string
 	personalTokenVerifier platform.PersonalTokenVerifier
+	accessMu              sync.RWMutex
+	access                srvCfg.Access
 }
 
 // NewAuth creates a new Auth middleware.
@@ -43,9 +74,22 @@ func NewAuth(verificationToken string, personalTokenVerifier platform.PersonalTo
 	return &Auth{verificationToken: verificationToken, personalTokenVerifier: personalTokenVerifier}
 }
 
+// SetAccess replaces the role mapping; called on start and on config reload.
+func (a *Auth) SetAccess(access srvCfg.Access) {
+	a.accessMu.Lock()
+	a.access = access
+	a.accessMu.Unlock()
+}
+
 // Authorized checks if the user has permission to access and attaches the
-// resolved user identity (for personal tokens) to the request context.
+// resolved user identity (for personal tokens) and role to the request context.
+// Any role is enough to pass; use Require to restrict a route further.
 func (a *Auth) Authorized(h http.HandlerFunc) http.HandlerFunc {
+	return a.Require(RoleViewer, h)
+}
+
+// Require checks that the caller is authenticated and holds at least the given role.
+func (a *Auth) Require(role Role, h http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, ok := a.authenticate(r.Context(), r.Header.Get(VerificationTokenHeader), r.Header.Get(ForwardedUserEmailHeader))
 		if !ok {
@@ -53,22 +97,18 @@ func (a *Auth) Authorized(h http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		if !RoleFromContext(ctx).Allows(role) {
+			api.SendForbiddenError(w, r, fmt.Sprintf("%s role required", role))
+			return
+		}
+
 		h(w, r.WithContext(ctx))
 	}
 }
 
 // AdminMW checks if the user has permission to access to admin sub-route.
-// TODO: check admin permissions.
 func (a *Auth) AdminMW(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token := r.Header.Get(VerificationTokenHeader)
-		if !a.isAccessAllowed(r.Context(), token) {
-			api.SendUnauthorizedError(w, r)
-			return
-		}
-
-		h.ServeHTTP(w, r)
-	})
+	return a.Require(RoleAdmin, h.ServeHTTP)
 }
 
 func (a *Auth) isAccessAllowed(ctx context.Context, token string) bool {
@@ -77,29 +117,98 @@ func (a *Auth) isAccessAllowed(ctx context.Context, token string) bool {
 	return ok
 }
 
-// authenticate validates the token and, for a valid personal token, returns a
-// context carrying the resolved user identity. The shared verification token
-// carries no identity of its own, but a shared-token caller may assert the
-// acting user via forwardedEmail; the assertion is trusted because the shared
-// token already grants full instance access.
+// authenticate validates the token and returns a context carrying the caller
+// role and, for a valid personal token, the resolved user identity. The shared
+// verification token carries no identity of its own, but a shared-token caller
+// may assert the acting user via forwardedEmail; the assertion is trusted
+// because the shared token already grants full instance access.
 func (a *Auth) authenticate(ctx context.Context, token, forwardedEmail string) (context.Context, bool) {
 	if a.verificationToken == "" {
-		return ctx, true
+		return WithRole(withActor(ctx, anonymousActor), RoleAdmin), true
 	}
 
 	if subtle.ConstantTimeCompare([]byte(a.verificationToken), []byte(token)) == 1 {
-		return withForwardedIdentity(ctx, forwardedEmail), true
+		return WithRole(withForwardedIdentity(withActor(ctx, verificationTokenActor), forwardedEmail), RoleAdmin), true
+	}
+
+	a.accessMu.RLock()
+	access := a.access
+	a.accessMu.RUnlock()
+
+	if roleName, ok := tokenRole(access, token); ok {
+		owner := tokenOwner(token)
+
+		return withConfiguredRole(withActor(context.WithValue(ctx, tokenOwnerKey, owner), owner), roleName)
 	}
 
 	if a.personalTokenVerifier != nil && a.personalTokenVerifier.IsPersonalTokenEnabled() {
 		if identity, ok := a.personalTokenVerifier.AuthenticatePersonalToken(ctx, token); ok {
-			return WithUserIdentity(ctx, identity), true
+			ctx = WithUserIdentity(withActor(ctx, personalTokenActor), identity)
+
+			return withConfiguredRole(ctx, userRole(access, identity.Email))
 		}
 	}
 
 	return ctx, false
 }
 
+// tokenRole looks up the role of an additional static token.
+func tokenRole(access srvCfg.Access, token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+
+	for accessToken, roleName := range access.Tokens {
+		if subtle.ConstantTimeCompare([]byte(accessToken), []byte(token)) == 1 {
+			return roleName, true
+		}
+	}
+
+	return "", false
+}
+
+// tokenOwner returns a stable name of an additional static token, derived from its hash so that the token itself
+// never appears in clone labels or the audit log. Clones created with the token are bound to this name.
+func tokenOwner(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return tokenOwnerPrefix + hex.EncodeToString(sum[:])[:tokenFingerprintLength]
+}
+
+// TokenOwnerFromContext returns the owner name of the additional static token the request is authenticated with.
+func TokenOwnerFromContext(ctx context.Context) (string, bool) {
+	owner, ok := ctx.Value(tokenOwnerKey).(string)
+
+	return owner, ok && owner != ""
+}
+
+// userRole returns the role configured for the user email, falling back to the default role.
+func userRole(access srvCfg.Access, email string) string {
+	for userEmail, roleName := range access.Users {
+		if email != "" && strings.EqualFold(userEmail, email) {
+			return roleName
+		}
+	}
+
+	if access.DefaultRole != "" {
+		return access.DefaultRole
+	}
+
+	return string(RoleDeveloper)
+}
+
+// withConfiguredRole attaches a role taken from the configuration. A misspelled role
+// denies access rather than granting an unexpected level.
+func withConfiguredRole(ctx context.Context, roleName string) (context.Context, bool) {
+	role, err := ParseRole(roleName)
+	if err != nil {
+		log.Warn("access denied:", err)
+		return ctx, false
+	}
+
+	return WithRole(ctx, role), true
+}
+
 // withForwardedIdentity attaches the identity asserted by a shared-token caller.
 func withForwardedIdentity(ctx context.Context, email string) context.Context {
 	if email == "" {
@@ -123,6 +232,24 @@ func UserIdentityFromContext(ctx context.Context) (platform.UserIdentity, bool)
 	return identity, ok
 }
 
+// ActorFromContext returns the name of the caller for the audit log: the user email if the identity is known,
+// otherwise the kind of credentials, e.g. "verification-token", or the token owner name for additional static tokens.
+func ActorFromContext(ctx context.Context) string {
+	if identity, ok := UserIdentityFromContext(ctx); ok && identity.Email != "" {
+		return identity.Email
+	}
+
+	if actor, ok := ctx.Value(actorKey).(string); ok {
+		return actor
+	}
+
+	return unknownActor
+}
+
+func withActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
 // WebSocketsMW checks if the user has a token to access to web-socket handlers.
 func (a *Auth) WebSocketsMW(holder *ws.TokenKeeper, h http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
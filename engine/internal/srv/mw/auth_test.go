@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"gitlab.com/postgres-ai/database-lab/v3/internal/platform"
+	srvCfg "gitlab.com/postgres-ai/database-lab/v3/internal/srv/config"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/ws"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
@@ -251,16 +252,171 @@ func TestAdminMW(t *testing.T) {
 }
 
 func TestAdminMW_WithPersonalToken(t *testing.T) {
-	auth := NewAuth(testVerificationToken, MockPersonalTokenVerifier{isPersonalTokenEnabled: true})
-	handler := auth.AdminMW(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	testCases := []struct {
+		name       string
+		access     srvCfg.Access
+		wantStatus int
+	}{
+		{name: "personal token defaults to developer", wantStatus: http.StatusForbidden},
+		{name: "admin user passes through", access: srvCfg.Access{Users: map[string]string{"u@acme.io": "admin"}},
+			wantStatus: http.StatusOK},
+		{name: "admin default role passes through", access: srvCfg.Access{DefaultRole: "admin"}, wantStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := NewAuth(testVerificationToken, MockPersonalTokenVerifier{isPersonalTokenEnabled: true, email: "u@acme.io"})
+			auth.SetAccess(tc.access)
+
+			handler := auth.AdminMW(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/test", nil)
+			req.Header.Set(VerificationTokenHeader, testPlatformAccessToken)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tc.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	access := srvCfg.Access{
+		DefaultRole: "viewer",
+		Users:       map[string]string{"Dev@Acme.io": "developer", "typo@acme.io": "superuser"},
+		Tokens:      map[string]string{"dashboard-token": "viewer", "ci-token": "developer"},
+	}
+
+	testCases := []struct {
+		name       string
+		token      string
+		email      string
+		required   Role
+		wantStatus int
+		wantRole   Role
+	}{
+		{name: "shared token is admin", token: testVerificationToken, required: RoleAdmin,
+			wantStatus: http.StatusOK, wantRole: RoleAdmin},
+		{name: "viewer token reads", token: "dashboard-token", required: RoleViewer,
+			wantStatus: http.StatusOK, wantRole: RoleViewer},
+		{name: "viewer token cannot write", token: "dashboard-token", required: RoleDeveloper,
+			wantStatus: http.StatusForbidden},
+		{name: "developer token creates", token: "ci-token", required: RoleDeveloper,
+			wantStatus: http.StatusOK, wantRole: RoleDeveloper},
+		{name: "developer token cannot administer", token: "ci-token", required: RoleAdmin,
+			wantStatus: http.StatusForbidden},
+		{name: "mapped user ignores email case", token: testPlatformAccessToken, email: "dev@acme.io",
+			required: RoleDeveloper, wantStatus: http.StatusOK, wantRole: RoleDeveloper},
+		{name: "unmapped user gets default role", token: testPlatformAccessToken, email: "other@acme.io",
+			required: RoleDeveloper, wantStatus: http.StatusForbidden},
+		{name: "unknown configured role denies access", token: testPlatformAccessToken, email: "typo@acme.io",
+			required: RoleViewer, wantStatus: http.StatusUnauthorized},
+		{name: "wrong token is unauthorized", token: "wrong", required: RoleViewer, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotRole Role
+
+			auth := NewAuth(testVerificationToken, MockPersonalTokenVerifier{isPersonalTokenEnabled: true, email: tc.email})
+			auth.SetAccess(access)
+
+			handler := auth.Require(tc.required, func(w http.ResponseWriter, r *http.Request) {
+				gotRole = RoleFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/clone", nil)
+			req.Header.Set(VerificationTokenHeader, tc.token)
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantRole, gotRole)
+		})
+	}
+}
+
+func TestActorFromContext(t *testing.T) {
+	testCases := []struct {
+		name              string
+		verificationToken string
+		token             string
+		forwardedEmail    string
+		wantActor         string
+	}{
+		{name: "personal token", verificationToken: testVerificationToken, token: testPlatformAccessToken,
+			wantActor: "u@acme.io"},
+		{name: "shared token", verificationToken: testVerificationToken, token: testVerificationToken,
+			wantActor: "verification-token"},
+		{name: "shared token with forwarded email", verificationToken: testVerificationToken, token: testVerificationToken,
+			forwardedEmail: "console@acme.io", wantActor: "console@acme.io"},
+		{name: "additional static token", verificationToken: testVerificationToken, token: "ci-token",
+			wantActor: "token-948b8c2427cd"},
+		{name: "disabled authorization", wantActor: "anonymous"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotActor string
+
+			auth := NewAuth(tc.verificationToken, MockPersonalTokenVerifier{isPersonalTokenEnabled: true, email: "u@acme.io"})
+			auth.SetAccess(srvCfg.Access{Tokens: map[string]string{"ci-token": "developer"}})
+
+			handler := auth.Authorized(func(w http.ResponseWriter, r *http.Request) {
+				gotActor = ActorFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/clone", nil)
+			req.Header.Set(VerificationTokenHeader, tc.token)
+
+			if tc.forwardedEmail != "" {
+				req.Header.Set(ForwardedUserEmailHeader, tc.forwardedEmail)
+			}
+
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.wantActor, gotActor)
+		})
+	}
+
+	assert.Equal(t, "unknown", ActorFromContext(context.Background()))
+}
+
+func TestTokenOwnerFromContext(t *testing.T) {
+	auth := NewAuth(testVerificationToken, MockPersonalTokenVerifier{isPersonalTokenEnabled: true, email: "u@acme.io"})
+	auth.SetAccess(srvCfg.Access{Tokens: map[string]string{"ci-token": "developer", "other-token": "developer"}})
+
+	owners := make(map[string]string)
+
+	for _, token := range []string{"ci-token", "other-token", testPlatformAccessToken, testVerificationToken} {
+		ctx, ok := auth.authenticate(context.Background(), token, "")
+		require.True(t, ok)
+
+		owner, _ := TokenOwnerFromContext(ctx)
+		owners[token] = owner
+	}
+
+	assert.Equal(t, "token-948b8c2427cd", owners["ci-token"])
+	assert.NotEqual(t, owners["ci-token"], owners["other-token"])
+	assert.Empty(t, owners[testPlatformAccessToken])
+	assert.Empty(t, owners[testVerificationToken])
+}
+
+func TestRequire_DisabledAuthorization(t *testing.T) {
+	auth := NewAuth("", nil)
+	handler := auth.Require(RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, IsAdmin(r.Context()))
 		w.WriteHeader(http.StatusOK)
-	}))
+	})
 
-	req := httptest.NewRequest(http.MethodGet, "/admin/test", nil)
-	req.Header.Set(VerificationTokenHeader, testPlatformAccessToken)
 	rec := httptest.NewRecorder()
-
-	handler.ServeHTTP(rec, req)
+	handler(rec, httptest.NewRequest(http.MethodPost, "/full-refresh", nil))
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
 
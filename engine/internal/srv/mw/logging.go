@@ -10,10 +10,10 @@ import (
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
 )
 
-// Logging logs the incoming request.
+// Logging logs the incoming request with the ID of its trace.
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Msg("-> ", r.Method, r.RequestURI)
+		log.MsgCtx(r.Context(), "-> ", r.Method, r.RequestURI)
 		next.ServeHTTP(w, r)
 	})
 }
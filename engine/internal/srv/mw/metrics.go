@@ -0,0 +1,116 @@
+/*
+2026 © Postgres.ai
+*/
+
+package mw
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/opmetrics"
+)
+
+// minErrorStatus is the lowest status code of a failed request.
+const minErrorStatus = http.StatusBadRequest
+
+var errOperationFailed = errors.New("operation failed")
+
+// Metrics records the rate, latency, and status codes of API requests per route.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startedAt := time.Now()
+		recorder := newStatusRecorder(w)
+
+		next.ServeHTTP(recorder, r)
+
+		opmetrics.ObserveHTTPRequest(routeTemplate(r), r.Method, recorder.status, time.Since(startedAt))
+	})
+}
+
+// routeTemplate returns the path template of the matched route, so that requests to the same endpoint are grouped.
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+
+	return "unknown"
+}
+
+// ObserveOperation records the duration and the outcome of an operation performed by a synchronous handler.
+// Responses with error status codes are counted as failures.
+func ObserveOperation(resource, operation string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		startedAt := time.Now()
+		recorder := newStatusRecorder(w)
+
+		next(recorder, r)
+
+		var err error
+		if recorder.status >= minErrorStatus {
+			err = errOperationFailed
+		}
+
+		opmetrics.ObserveOperation(resource, operation, startedAt, err)
+	}
+}
+
+// statusRecorder captures the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func newStatusRecorder(w http.ResponseWriter) *statusRecorder {
+	return &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// WriteHeader records the status code and sends it.
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write sends the response body.
+func (r *statusRecorder) Write(body []byte) (int, error) {
+	r.wroteHeader = true
+
+	return r.ResponseWriter.Write(body)
+}
+
+// Flush sends buffered data to the client, e.g. for streaming responses.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets WebSocket handlers take over the connection.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+
+	r.status = http.StatusSwitchingProtocols
+	r.wroteHeader = true
+
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the original response writer for http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
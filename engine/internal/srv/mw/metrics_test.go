@@ -0,0 +1,46 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusRecorder(t *testing.T) {
+	rec := httptest.NewRecorder()
+	recorder := newStatusRecorder(rec)
+
+	recorder.WriteHeader(http.StatusNotFound)
+	recorder.WriteHeader(http.StatusOK)
+
+	assert.Equal(t, http.StatusNotFound, recorder.status)
+	assert.Equal(t, rec, recorder.Unwrap())
+
+	recorder = newStatusRecorder(httptest.NewRecorder())
+	_, _ = recorder.Write([]byte("ok"))
+	recorder.WriteHeader(http.StatusTeapot)
+
+	assert.Equal(t, http.StatusOK, recorder.status, "status must not change after the body is written")
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	r := mux.NewRouter()
+	r.Use(Metrics)
+
+	observed := false
+
+	r.HandleFunc("/clone/{id}", ObserveOperation("clone", "get", func(w http.ResponseWriter, _ *http.Request) {
+		observed = true
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/clone/test", nil))
+
+	assert.True(t, observed)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
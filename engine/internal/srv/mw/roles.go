@@ -0,0 +1,73 @@
+/*
+2026 © Postgres.ai
+*/
+
+package mw
+
+import (
+	"context"
+	"fmt"
+)
+
+// Role defines the access level granted to an authenticated API caller.
+type Role string
+
+const (
+	// RoleViewer allows read-only access to the API.
+	RoleViewer Role = "viewer"
+
+	// RoleDeveloper additionally allows creating clones, branches and snapshots, and managing own clones.
+	RoleDeveloper Role = "developer"
+
+	// RoleAdmin allows full access, including deletion of any entity and the admin endpoints.
+	RoleAdmin Role = "admin"
+)
+
+// roleKey is the context key carrying the resolved caller role.
+const roleKey ctxKey = "dblab_role"
+
+// roleLevels orders roles so that every role includes the permissions of the lower ones.
+var roleLevels = map[Role]int{
+	RoleViewer:    1,
+	RoleDeveloper: 2,
+	RoleAdmin:     3,
+}
+
+// ParseRole converts a configured role name into a Role.
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+
+	if _, ok := roleLevels[role]; !ok {
+		return "", fmt.Errorf("unknown role %q: must be one of viewer, developer, admin", name)
+	}
+
+	return role, nil
+}
+
+// Allows reports whether the role grants at least the permissions of the required role.
+func (r Role) Allows(required Role) bool {
+	level, ok := roleLevels[r]
+
+	return ok && level >= roleLevels[required]
+}
+
+// WithRole returns a context carrying the given caller role.
+func WithRole(ctx context.Context, role Role) context.Context {
+	return context.WithValue(ctx, roleKey, role)
+}
+
+// RoleFromContext returns the caller role attached to the context by Authorized.
+// A context without a role is treated as the least privileged one.
+func RoleFromContext(ctx context.Context) Role {
+	role, ok := ctx.Value(roleKey).(Role)
+	if !ok {
+		return RoleViewer
+	}
+
+	return role
+}
+
+// IsAdmin reports whether the caller attached to the context has the admin role.
+func IsAdmin(ctx context.Context) bool {
+	return RoleFromContext(ctx).Allows(RoleAdmin)
+}
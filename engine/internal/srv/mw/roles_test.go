@@ -0,0 +1,42 @@
+/*
+2026 © Postgres.ai
+*/
+
+package mw
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRole(t *testing.T) {
+	for _, name := range []string{"viewer", "developer", "admin"} {
+		role, err := ParseRole(name)
+		require.NoError(t, err)
+		assert.Equal(t, Role(name), role)
+	}
+
+	_, err := ParseRole("owner")
+	require.Error(t, err)
+}
+
+func TestRoleAllows(t *testing.T) {
+	assert.True(t, RoleAdmin.Allows(RoleDeveloper))
+	assert.True(t, RoleDeveloper.Allows(RoleDeveloper))
+	assert.True(t, RoleDeveloper.Allows(RoleViewer))
+	assert.False(t, RoleDeveloper.Allows(RoleAdmin))
+	assert.False(t, RoleViewer.Allows(RoleDeveloper))
+	assert.False(t, Role("").Allows(RoleViewer))
+}
+
+func TestRoleFromContext(t *testing.T) {
+	assert.Equal(t, RoleViewer, RoleFromContext(context.Background()))
+	assert.False(t, IsAdmin(context.Background()))
+
+	ctx := WithRole(context.Background(), RoleAdmin)
+	assert.Equal(t, RoleAdmin, RoleFromContext(ctx))
+	assert.True(t, IsAdmin(ctx))
+}
@@ -0,0 +1,32 @@
+/*
+2026 © Postgres.ai
+*/
+
+package mw
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a span for every API request. The trace of the caller is continued
+// if the request carries W3C trace context.
+func Tracing(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "API request")
+}
+
+// NameSpan names the span of the request after the matched route, e.g. "POST /clone/{id}/reset".
+func NameSpan(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(attribute.String("http.route", route))
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,137 @@
+/*
+2026 © Postgres.ai
+*/
+
+package srv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/audit"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/operations"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+// operationIDHeader carries the ID of the operation started by a request.
+const operationIDHeader = "Operation-ID"
+
+// SetOperations attaches the registry of operations started through the API.
+func (s *Server) SetOperations(registry *operations.Registry) {
+	s.operations = registry
+}
+
+// ReloadOperations applies new settings of the operations registry.
+func (s *Server) ReloadOperations(cfg operations.Config) {
+	if s.operations != nil {
+		s.operations.Reload(cfg)
+	}
+}
+
+// tracked records the action in the audit log and tracks it as an operation.
+func (s *Server) tracked(action string, next http.HandlerFunc) http.HandlerFunc {
+	return s.withOperation(action, s.audited(action, next))
+}
+
+// withOperation starts an operation for the request and returns its ID in the Operation-ID header.
+// The operation is finished with the response unless the handler hands it over to background work.
+func (s *Server) withOperation(action string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.operations == nil {
+			next(w, r)
+			return
+		}
+
+		op := s.operations.Start(action, routeTarget(r))
+		w.Header().Set(operationIDHeader, op.ID())
+
+		recorder := &auditRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next(recorder, r.WithContext(operations.WithOperation(r.Context(), op)))
+
+		switch {
+		case recorder.status >= http.StatusBadRequest:
+			op.Finish(errors.New(recorder.errorMessage()))
+
+		case !op.IsDetached():
+			op.Finish(nil)
+		}
+	}
+}
+
+// runInBackground hands the operation of the request over to the work and responds with the state of the operation,
+// so that long-running actions do not hold the request open. The work can be interrupted by canceling the operation;
+// its result, e.g. the ID of a created snapshot, is recorded in the operation.
+func runInBackground(w http.ResponseWriter, r *http.Request, work func(ctx context.Context) (string, error)) {
+	op := operations.FromContext(r.Context())
+	ctx := op.WithCancel(context.WithoutCancel(r.Context()))
+	op.Detach()
+
+	go func() {
+		result, err := work(ctx)
+		if err != nil {
+			log.Err(fmt.Sprintf("operation %s failed: %v", op.ID(), err))
+		}
+
+		op.SetResult(result)
+		op.Finish(err)
+	}()
+
+	if err := api.WriteJSON(w, http.StatusAccepted, op.State()); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+// setTarget records the object of the action in the audit log and in the operation, e.g. the ID of a created snapshot.
+func setTarget(ctx context.Context, target string) {
+	audit.SetTarget(ctx, target)
+	operations.FromContext(ctx).SetTarget(target)
+}
+
+func (s *Server) getOperation(w http.ResponseWriter, r *http.Request) {
+	if s.operations == nil {
+		api.SendNotFoundError(w, r)
+		return
+	}
+
+	op, err := s.operations.Get(mux.Vars(r)["id"])
+	if err != nil {
+		api.SendNotFoundError(w, r)
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, op); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+func (s *Server) cancelOperation(w http.ResponseWriter, r *http.Request) {
+	if s.operations == nil {
+		api.SendNotFoundError(w, r)
+		return
+	}
+
+	op, err := s.operations.Cancel(mux.Vars(r)["id"])
+
+	switch {
+	case errors.Is(err, operations.ErrNotFound):
+		api.SendNotFoundError(w, r)
+		return
+
+	case err != nil:
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, op); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,172 @@
+package srv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/audit"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/operations"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func newTrackedServer(t *testing.T) *Server {
+	t.Helper()
+
+	s := newAuditedServer(t)
+	s.SetOperations(operations.NewRegistry(operations.Config{}))
+
+	return s
+}
+
+func TestTrackedFinishesSynchronousOperation(t *testing.T) {
+	s := newTrackedServer(t)
+
+	handler := s.tracked("snapshot.create", func(w http.ResponseWriter, r *http.Request) {
+		setTarget(r.Context(), "snapshot1")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rec := serveAudited(t, handler, "/snapshot", "/snapshot", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	operationID := rec.Header().Get(operationIDHeader)
+	require.NotEmpty(t, operationID)
+
+	op, err := s.operations.Get(operationID)
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot.create", op.Type)
+	assert.Equal(t, "snapshot1", op.Target)
+	assert.Equal(t, models.OperationSucceeded, op.State)
+
+	entries, err := s.auditLog.List(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, operationID, entries[0].OperationID)
+	assert.Equal(t, "snapshot1", entries[0].Target)
+}
+
+func TestTrackedRecordsFailure(t *testing.T) {
+	s := newTrackedServer(t)
+
+	handler := s.tracked("clone.destroy", func(w http.ResponseWriter, r *http.Request) {
+		operations.FromContext(r.Context()).Detach()
+		api.SendBadRequestError(w, r, "clone is protected")
+	})
+
+	rec := serveAudited(t, handler, "/clone/{id}", "/clone/clone1", "")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	op, err := s.operations.Get(rec.Header().Get(operationIDHeader))
+	require.NoError(t, err)
+	assert.Equal(t, "clone1", op.Target)
+	assert.Equal(t, models.OperationFailed, op.State)
+	assert.Equal(t, "clone is protected", op.Error)
+}
+
+func TestTrackedKeepsDetachedOperationRunning(t *testing.T) {
+	s := newTrackedServer(t)
+
+	var detached *operations.Operation
+
+	handler := s.tracked("clone.reset", func(w http.ResponseWriter, r *http.Request) {
+		detached = operations.FromContext(r.Context())
+		detached.Detach()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rec := serveAudited(t, handler, "/clone/{id}/reset", "/clone/clone1/reset", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	operationID := rec.Header().Get(operationIDHeader)
+
+	op, err := s.operations.Get(operationID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OperationRunning, op.State)
+
+	detached.Finish(nil)
+
+	op, err = s.operations.Get(operationID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OperationSucceeded, op.State)
+}
+
+func TestTrackedAuditsOutcomeOfBackgroundWork(t *testing.T) {
+	s := newTrackedServer(t)
+
+	var detached *operations.Operation
+
+	handler := s.tracked("clone.create", func(w http.ResponseWriter, r *http.Request) {
+		detached = operations.FromContext(r.Context())
+		detached.Detach()
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	rec := serveAudited(t, handler, "/clone", "/clone", "")
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	entries, err := s.auditLog.List(audit.Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the action is recorded when the background work finishes")
+
+	detached.Finish(errors.New("failed to start container"))
+
+	entries, err = s.auditLog.List(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.AuditOutcomeFailure, entries[0].Outcome)
+	assert.Equal(t, "failed to start container", entries[0].Error)
+	assert.Equal(t, http.StatusCreated, entries[0].StatusCode)
+}
+
+func serveOperation(t *testing.T, s *Server, method, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/operations/{id}", s.getOperation).Methods(http.MethodGet)
+	router.HandleFunc("/operations/{id}/cancel", s.cancelOperation).Methods(http.MethodPost)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+
+	return rec
+}
+
+func TestOperationHandlers(t *testing.T) {
+	s := newTrackedServer(t)
+
+	op := s.operations.Start("clone.create", "clone1")
+	ctx := op.WithCancel(t.Context())
+
+	rec := serveOperation(t, s, http.MethodGet, "/operations/"+op.ID())
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var state models.Operation
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+	assert.Equal(t, op.ID(), state.ID)
+	assert.Equal(t, models.OperationRunning, state.State)
+	assert.True(t, state.Cancelable)
+
+	rec = serveOperation(t, s, http.MethodPost, "/operations/"+op.ID()+"/cancel")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	op.Finish(ctx.Err())
+
+	rec = serveOperation(t, s, http.MethodPost, "/operations/"+op.ID()+"/cancel")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serveOperation(t, s, http.MethodGet, "/operations/unknown")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serveOperation(t, s, http.MethodPost, "/operations/unknown/cancel")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,315 @@
+/*
+2026 © Postgres.ai
+*/
+
+package srv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/opmetrics"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/pool"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util/branching"
+)
+
+// rebaseTimeout bounds the whole rebase: starting a clone, replaying migrations and committing.
+const rebaseTimeout = 30 * time.Minute
+
+var errRebaseInProgress = errors.New("another rebase is in progress; try again later")
+
+// rebasePlan describes how a branch is replayed on top of another branch.
+type rebasePlan struct {
+	branch     string
+	head       string
+	forkPoint  string
+	ontoHead   string
+	commits    []string
+	migrations []string
+}
+
+func (s *Server) rebaseBranch(w http.ResponseWriter, r *http.Request) {
+	branchName := mux.Vars(r)["branchName"]
+
+	if !isValidBranchName(branchName) {
+		api.SendBadRequestError(w, r, "invalid branch name")
+		return
+	}
+
+	var rebaseRequest types.BranchRebaseRequest
+	if r.Body != http.NoBody {
+		if err := api.ReadJSON(r, &rebaseRequest); err != nil {
+			api.SendBadRequestError(w, r, err.Error())
+			return
+		}
+	}
+
+	if rebaseRequest.Onto == "" {
+		rebaseRequest.Onto = branching.DefaultBranch
+	}
+
+	if !isValidBranchName(rebaseRequest.Onto) {
+		api.SendBadRequestError(w, r, "invalid onto branch name")
+		return
+	}
+
+	if rebaseRequest.Onto == branchName {
+		api.SendBadRequestError(w, r, "cannot rebase a branch onto itself")
+		return
+	}
+
+	if !s.rebaseMu.TryLock() {
+		api.SendBadRequestError(w, r, errRebaseInProgress.Error())
+		return
+	}
+
+	fsm, err := s.getFSManagerForBranch(branchName)
+	if err != nil {
+		s.rebaseMu.Unlock()
+		api.SendBadRequestError(w, r, err.Error())
+
+		return
+	}
+
+	plan, err := s.planRebase(fsm, branchName, rebaseRequest.Onto)
+	if err != nil {
+		s.rebaseMu.Unlock()
+		sendRequestError(w, r, err)
+
+		return
+	}
+
+	// Replaying migrations can take long, so the rebase continues in the background and is followed by the operation.
+	runInBackground(w, r, func(ctx context.Context) (string, error) {
+		defer s.rebaseMu.Unlock()
+
+		startedAt := time.Now()
+
+		snapshotID, err := s.rebase(ctx, fsm, plan, rebaseRequest)
+		opmetrics.ObserveOperation(opmetrics.ResourceBranch, "rebase", startedAt, err)
+
+		if err != nil {
+			return "", err
+		}
+
+		log.Msg(fmt.Sprintf("Branch %s has been rebased onto %s: %s", branchName, rebaseRequest.Onto, snapshotID))
+
+		return snapshotID, nil
+	})
+}
+
+// rebase replays the migrations recorded in the commits of the branch on top of the head of the onto
+// branch in a temporary clone and commits the result as the new head of the branch.
+func (s *Server) rebase(ctx context.Context, fsm pool.FSManager, plan *rebasePlan, req types.BranchRebaseRequest) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, rebaseTimeout)
+	defer cancel()
+
+	clone, err := s.startTemporaryClone(ctx, plan.branch, plan.ontoHead, req.DBName)
+	if err != nil {
+		return "", err
+	}
+
+	// The clone dataset stays because the new commit depends on it; only the container goes away.
+	defer s.destroyTemporaryClone(clone.ID)
+
+	if err := s.replayMigrations(ctx, clone.ID, plan); err != nil {
+		return "", err
+	}
+
+	message := req.Message
+	if message == "" {
+		message = fmt.Sprintf("Rebase %s onto %s", plan.branch, req.Onto)
+	}
+
+	snapshotID, err := s.commitRebase(fsm, clone, plan, message)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.Cloning.ReloadSnapshots(); err != nil {
+		log.Err("failed to reload snapshots:", err)
+	}
+
+	return snapshotID, nil
+}
+
+// planRebase collects the commits of the branch since it forked and their recorded migrations.
+// Every commit must have a migration: changes made in a clone are not captured otherwise, so replaying
+// the rest would silently drop them. Problems of the branch itself are reported as bad requests.
+func (s *Server) planRebase(fsm pool.FSManager, branchName, onto string) (*rebasePlan, error) {
+	repo, err := fsm.GetRepo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the branches of pool %s: %w", fsm.Pool().Name, err)
+	}
+
+	head, ok := repo.Branches[branchName]
+	if !ok {
+		return nil, models.New(models.ErrCodeBadRequest, "branch not found: "+branchName)
+	}
+
+	ontoHead, ok := repo.Branches[onto]
+	if !ok {
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("branch %s not found in pool %s", onto, fsm.Pool().Name))
+	}
+
+	commits := branchCommits(repo, head, branchName)
+	if len(commits) == 0 {
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("branch %s has no commits to rebase", branchName))
+	}
+
+	forkPoint := repo.Snapshots[commits[0]].Parent
+	if forkPoint == ontoHead {
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("branch %s is already based on the head of %s", branchName, onto))
+	}
+
+	plan := &rebasePlan{
+		branch:    branchName,
+		head:      head,
+		forkPoint: forkPoint,
+		ontoHead:  ontoHead,
+		commits:   commits,
+	}
+
+	var unrecorded []string
+
+	for _, commit := range commits {
+		migration, err := s.snapshotMetadata.Load(thinclones.MigrationMetadata, commit)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.TrimSpace(string(migration)) == "" {
+			unrecorded = append(unrecorded, commit)
+			continue
+		}
+
+		plan.migrations = append(plan.migrations, string(migration))
+	}
+
+	if len(unrecorded) > 0 {
+		return nil, models.New(models.ErrCodeBadRequest, fmt.Sprintf("cannot rebase branch %s: commits %s have no recorded "+
+			"migration, so their changes cannot be replayed; pass a migration when committing clones",
+			branchName, strings.Join(unrecorded, ", ")))
+	}
+
+	return plan, nil
+}
+
+// branchCommits returns the snapshots made on the branch since it forked, oldest first.
+func branchCommits(repo *models.Repo, head, branchName string) []string {
+	commits := traverseUp(repo, head, branchName)
+	slices.Reverse(commits)
+
+	return commits
+}
+
+// replayMigrations applies the recorded migrations in the order they were committed.
+func (s *Server) replayMigrations(ctx context.Context, cloneID string, plan *rebasePlan) error {
+	conn, err := s.Cloning.ConnectToClone(ctx, cloneID)
+	if err != nil {
+		return fmt.Errorf("failed to connect to rebase clone: %w", err)
+	}
+
+	defer func() { _ = conn.Close(ctx) }()
+
+	for i, migration := range plan.migrations {
+		if _, err := conn.Exec(ctx, migration); err != nil {
+			return fmt.Errorf("failed to replay migration %d of %d: %w", i+1, len(plan.migrations), err)
+		}
+	}
+
+	if _, err := conn.Exec(ctx, "checkpoint"); err != nil {
+		return fmt.Errorf("failed to run checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// rebaseStep is a change of branch metadata made when committing a rebase and the change that reverts it.
+type rebaseStep struct {
+	apply  func() error
+	revert func() error
+}
+
+// commitRebase snapshots the rebase clone, moves the branch head to it and re-roots the branch on the onto head.
+// If any step fails, the completed steps are reverted and the snapshot is destroyed, so the branch keeps its head.
+func (s *Server) commitRebase(fsm pool.FSManager, clone *models.Clone, plan *rebasePlan, message string) (string, error) {
+	dataStateAt := time.Now().Format(util.DataStateAtFormat)
+	snapshotName := fmt.Sprintf("%s@%s", fsm.Pool().CloneName(clone.Branch, clone.ID, clone.Revision), dataStateAt)
+
+	if err := fsm.Snapshot(snapshotName); err != nil {
+		return "", err
+	}
+
+	steps := []rebaseStep{
+		{apply: func() error { return fsm.SetDSA(dataStateAt, snapshotName) }},
+		{
+			apply:  func() error { return fsm.AddBranchProp(plan.branch, snapshotName) },
+			revert: func() error { return fsm.DeleteBranchProp(plan.branch, snapshotName) },
+		},
+		{
+			apply:  func() error { return fsm.DeleteBranchProp(plan.branch, plan.head) },
+			revert: func() error { return fsm.AddBranchProp(plan.branch, plan.head) },
+		},
+		{
+			apply:  func() error { return fsm.SetRelation(plan.ontoHead, snapshotName) },
+			revert: func() error { return fsm.DeleteChildProp(snapshotName, plan.ontoHead) },
+		},
+		{
+			apply:  func() error { return fsm.DeleteRootProp(plan.branch, plan.forkPoint) },
+			revert: func() error { return fsm.SetRoot(plan.branch, plan.forkPoint) },
+		},
+		{
+			apply:  func() error { return fsm.SetRoot(plan.branch, plan.ontoHead) },
+			revert: func() error { return fsm.DeleteRootProp(plan.branch, plan.ontoHead) },
+		},
+		{apply: func() error { return fsm.SetMessage(message, snapshotName) }},
+		// The combined migration lets the branch be rebased again later.
+		{apply: func() error {
+			return s.snapshotMetadata.Save(thinclones.MigrationMetadata, snapshotName, []byte(strings.Join(plan.migrations, ";\n")))
+		}},
+	}
+
+	for i, step := range steps {
+		if err := step.apply(); err != nil {
+			revertRebase(fsm, snapshotName, steps[:i])
+			return "", fmt.Errorf("failed to commit the rebase of branch %s: %w", plan.branch, err)
+		}
+	}
+
+	fsm.RefreshSnapshotList()
+
+	return snapshotName, nil
+}
+
+// revertRebase undoes the completed steps of a failed commit in reverse order and destroys the uncommitted snapshot.
+func revertRebase(fsm pool.FSManager, snapshotName string, completed []rebaseStep) {
+	for i := len(completed) - 1; i >= 0; i-- {
+		if completed[i].revert == nil {
+			continue
+		}
+
+		if err := completed[i].revert(); err != nil {
+			log.Err(fmt.Sprintf("failed to revert the rebase metadata of %s: %v", snapshotName, err))
+		}
+	}
+
+	if err := fsm.DestroySnapshot(snapshotName, thinclones.DestroyOptions{}); err != nil {
+		log.Err(fmt.Sprintf("failed to destroy the snapshot %s of a failed rebase: %v", snapshotName, err))
+	}
+
+	fsm.RefreshSnapshotList()
+}
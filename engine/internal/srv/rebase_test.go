@@ -0,0 +1,136 @@
+package srv
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/pool"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/resources"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// rebaseFSM records the branch metadata changes made by a rebase commit.
+// The name of the created snapshot is recorded as "new".
+type rebaseFSM struct {
+	pool.FSManager
+	repo     *models.Repo
+	snapshot string
+	calls    []string
+}
+
+func (m *rebaseFSM) record(format string, args ...any) error {
+	m.calls = append(m.calls, strings.ReplaceAll(fmt.Sprintf(format, args...), m.snapshot, "new"))
+	return nil
+}
+
+func (m *rebaseFSM) Pool() *resources.Pool           { return &resources.Pool{Name: "dblab_pool"} }
+func (m *rebaseFSM) SetDSA(string, string) error     { return m.record("dsa") }
+func (m *rebaseFSM) SetMessage(string, string) error { return m.record("message") }
+func (m *rebaseFSM) RefreshSnapshotList()            {}
+func (m *rebaseFSM) GetRepo() (*models.Repo, error)  { return m.repo, nil }
+
+func (m *rebaseFSM) Snapshot(name string) error {
+	m.snapshot = name
+	return m.record("snapshot")
+}
+
+func (m *rebaseFSM) AddBranchProp(branch, snapshot string) error {
+	return m.record("add branch %s %s", branch, snapshot)
+}
+
+func (m *rebaseFSM) DeleteBranchProp(branch, snapshot string) error {
+	return m.record("delete branch %s %s", branch, snapshot)
+}
+
+func (m *rebaseFSM) SetRelation(parent, snapshot string) error {
+	return m.record("relation %s %s", parent, snapshot)
+}
+
+func (m *rebaseFSM) DeleteChildProp(child, snapshot string) error {
+	return m.record("delete child %s %s", child, snapshot)
+}
+
+func (m *rebaseFSM) SetRoot(branch, snapshot string) error {
+	return m.record("root %s %s", branch, snapshot)
+}
+
+func (m *rebaseFSM) DeleteRootProp(branch, snapshot string) error {
+	return m.record("delete root %s %s", branch, snapshot)
+}
+
+func (m *rebaseFSM) DestroySnapshot(string, thinclones.DestroyOptions) error {
+	return m.record("destroy")
+}
+
+func TestPlanRebase(t *testing.T) {
+	fsm := &rebaseFSM{repo: &models.Repo{
+		Snapshots: map[string]models.SnapshotDetails{
+			"s1": {ID: "s1", Parent: "-", Root: []string{"dev"}},
+			"s2": {ID: "s2", Parent: "s1"},
+			"s3": {ID: "s3", Parent: "s2"},
+			"m2": {ID: "m2", Parent: "s1"},
+		},
+		Branches: map[string]string{"main": "m2", "dev": "s3"},
+	}}
+
+	s := &Server{snapshotMetadata: thinclones.NewMetadataStore(t.TempDir())}
+	require.NoError(t, s.snapshotMetadata.Save(thinclones.MigrationMetadata, "s3", []byte("create table t1 (id int)")))
+
+	_, err := s.planRebase(fsm, "dev", "main")
+
+	var reqErr *models.Error
+	require.ErrorAs(t, err, &reqErr)
+	assert.Equal(t, models.ErrCodeBadRequest, reqErr.Code)
+	assert.Contains(t, reqErr.Message, "commits s2 have no recorded migration")
+
+	require.NoError(t, s.snapshotMetadata.Save(thinclones.MigrationMetadata, "s2", []byte("alter table t0 add column c1 int")))
+
+	plan, err := s.planRebase(fsm, "dev", "main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s2", "s3"}, plan.commits)
+	assert.Equal(t, "s1", plan.forkPoint)
+	assert.Equal(t, []string{"alter table t0 add column c1 int", "create table t1 (id int)"}, plan.migrations)
+}
+
+func TestCommitRebaseRevertsMetadataOnFailure(t *testing.T) {
+	// The migration directory cannot be created under a regular file, so the last step of the commit fails.
+	blocker := path.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(blocker, nil, 0600))
+
+	s := &Server{snapshotMetadata: thinclones.NewMetadataStore(blocker)}
+	fsm := &rebaseFSM{}
+
+	plan := &rebasePlan{branch: "feature", head: "head", forkPoint: "fork", ontoHead: "onto", migrations: []string{"select 1"}}
+	clone := &models.Clone{ID: "rebase1", Branch: "feature"}
+
+	_, err := s.commitRebase(fsm, clone, plan, "Rebase feature onto main")
+	require.Error(t, err)
+
+	applied := []string{
+		"snapshot",
+		"dsa",
+		"add branch feature new",
+		"delete branch feature head",
+		"relation onto new",
+		"delete root feature fork",
+		"root feature onto",
+		"message",
+	}
+	reverted := []string{
+		"delete root feature onto",
+		"root feature fork",
+		"delete child new onto",
+		"add branch feature head",
+		"delete branch feature new",
+		"destroy",
+	}
+
+	assert.Equal(t, append(applied, reverted...), fsm.calls)
+}
@@ -0,0 +1,31 @@
+/*
+2026 © Postgres.ai
+*/
+
+package srv
+
+import (
+	"net/http"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/pgrouter"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// SetRouter attaches the Postgres router whose connection statistics are exposed by the API.
+func (s *Server) SetRouter(router *pgrouter.Router) {
+	s.router = router
+}
+
+func (s *Server) routerConnections(w http.ResponseWriter, r *http.Request) {
+	connections := []models.CloneConnections{}
+
+	if s.router != nil {
+		connections = s.router.Connections()
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, connections); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
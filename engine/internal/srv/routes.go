@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/mail"
+	"net/url"
 	"os"
 	"path"
 	"regexp"
@@ -17,10 +18,13 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/audit"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/operations"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/pool"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/runners"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/snapshot"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/engine/postgres/tools/activity"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/mw"
@@ -146,19 +150,108 @@ func (s *Server) getSnapshots(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *Server) previewRetention(w http.ResponseWriter, r *http.Request) {
+	poolName := r.URL.Query().Get("pool")
+
+	if poolName == "" {
+		firstFSM := s.pm.First()
+
+		if firstFSM == nil || firstFSM.Pool() == nil {
+			api.SendBadRequestError(w, r, pool.ErrNoPools.Error())
+			return
+		}
+
+		poolName = firstFSM.Pool().Name
+	}
+
+	policy, err := retentionPolicyFromQuery(r.URL.Query())
+	if err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	if policy == nil {
+		policy = s.Retrieval.RetentionPolicy()
+	}
+
+	if policy == nil {
+		api.SendBadRequestError(w, r, "retention policy is not configured")
+		return
+	}
+
+	snapshots, err := s.Retrieval.PreviewRetention(poolName, policy)
+	if err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	preview := models.RetentionPreview{
+		Pool:      poolName,
+		Policy:    policy.String(),
+		Snapshots: snapshots,
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, preview); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+// retentionPolicyFromQuery builds a retention policy from the hourly, daily, weekly and monthly query parameters.
+// It returns nil if none of them is set.
+func retentionPolicyFromQuery(query url.Values) (*thinclones.RetentionPolicy, error) {
+	var policy thinclones.RetentionPolicy
+
+	tiers := []struct {
+		name  string
+		value *int
+	}{
+		{"hourly", &policy.Hourly},
+		{"daily", &policy.Daily},
+		{"weekly", &policy.Weekly},
+		{"monthly", &policy.Monthly},
+	}
+
+	found := false
+
+	for _, tier := range tiers {
+		value := query.Get(tier.name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer", tier.name)
+		}
+
+		*tier.value = parsed
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
 func (s *Server) createSnapshot(w http.ResponseWriter, r *http.Request) {
-	var poolName string
+	var createRequest types.SnapshotCreateRequest
 
 	if r.Body != http.NoBody {
-		var createRequest types.SnapshotCreateRequest
 		if err := api.ReadJSON(r, &createRequest); err != nil {
 			api.SendBadRequestError(w, r, err.Error())
 			return
 		}
-
-		poolName = createRequest.PoolName
 	}
 
+	poolName := createRequest.PoolName
+
 	if poolName == "" {
 		firstFSM := s.pm.First()
 
@@ -170,6 +263,11 @@ func (s *Server) createSnapshot(w http.ResponseWriter, r *http.Request) {
 		poolName = firstFSM.Pool().Name
 	}
 
+	if createRequest.IsPointInTime() {
+		s.createPointInTimeSnapshot(w, r, poolName, createRequest)
+		return
+	}
+
 	if err := s.Retrieval.SnapshotData(context.Background(), poolName); err != nil {
 		api.SendBadRequestError(w, r, err.Error())
 		return
@@ -204,6 +302,7 @@ func (s *Server) createSnapshot(w http.ResponseWriter, r *http.Request) {
 	}
 
 	latestSnapshot := snapshotList[0]
+	setTarget(r.Context(), latestSnapshot.ID)
 
 	s.webhookCh <- webhooks.BasicEvent{
 		EventType: webhooks.SnapshotCreateEvent,
@@ -216,6 +315,73 @@ func (s *Server) createSnapshot(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// createPointInTimeSnapshot recovers the pool data to the requested target and commits it to a dedicated branch.
+// Replaying WAL can take long, so the recovery continues in the background and is followed by the operation.
+func (s *Server) createPointInTimeSnapshot(w http.ResponseWriter, r *http.Request, poolName string,
+	createRequest types.SnapshotCreateRequest) {
+	target, err := recoveryTarget(createRequest)
+	if err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	recoverData, err := s.Retrieval.RecoverSnapshot(poolName, target)
+	if err != nil {
+		sendRequestError(w, r, err)
+		return
+	}
+
+	runInBackground(w, r, func(ctx context.Context) (string, error) {
+		snapshotID, err := recoverData(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		setTarget(ctx, snapshotID)
+
+		if err := s.Cloning.ReloadSnapshots(); err != nil {
+			log.Dbg("Failed to reload snapshots", err.Error())
+		}
+
+		s.webhookCh <- webhooks.BasicEvent{
+			EventType: webhooks.SnapshotCreateEvent,
+			EntityID:  snapshotID,
+		}
+
+		s.tm.SendEvent(context.Background(), telemetry.SnapshotCreatedEvent, telemetry.SnapshotCreated{})
+
+		return snapshotID, nil
+	})
+}
+
+// recoveryTarget builds the recovery target of a point-in-time snapshot request.
+func recoveryTarget(createRequest types.SnapshotCreateRequest) (snapshot.RecoveryTarget, error) {
+	target := snapshot.RecoveryTarget{
+		LSN:    createRequest.RecoveryTargetLSN,
+		Branch: createRequest.Branch,
+	}
+
+	if createRequest.RecoveryTargetTime != "" {
+		targetTime, err := time.Parse(time.RFC3339, createRequest.RecoveryTargetTime)
+		if err != nil {
+			return snapshot.RecoveryTarget{}, fmt.Errorf("invalid recoveryTargetTime, use the RFC 3339 format: %w", err)
+		}
+
+		target.Time = targetTime
+	}
+
+	if err := target.Validate(); err != nil {
+		return snapshot.RecoveryTarget{}, err
+	}
+
+	if !isValidBranchName(target.BranchName()) {
+		return snapshot.RecoveryTarget{}, errors.New("the branch name must start with a letter, number, or underscore, " +
+			"and contain only letters, numbers, underscores, and hyphens")
+	}
+
+	return target, nil
+}
+
 func (s *Server) deleteSnapshot(w http.ResponseWriter, r *http.Request) {
 	snapshotID := mux.Vars(r)["id"]
 	if snapshotID == "" {
@@ -586,6 +752,8 @@ func (s *Server) createSnapshotClone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setTarget(r.Context(), snapshotID)
+
 	if err := s.Cloning.ReloadSnapshots(); err != nil {
 		log.Dbg("Failed to reload snapshots", err.Error())
 	}
@@ -603,9 +771,23 @@ func (s *Server) createSnapshotClone(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) clones(w http.ResponseWriter, r *http.Request) {
-	cloningState := s.Cloning.GetCloningState()
+	owner := r.URL.Query().Get("owner")
+
+	if mine, _ := strconv.ParseBool(r.URL.Query().Get("mine")); mine {
+		owner = ownerFromContext(r.Context())
+		if owner == "" {
+			api.SendBadRequestError(w, r, "cannot filter own clones: the request token is not bound to a user")
+			return
+		}
+	}
 
-	if err := api.WriteJSON(w, http.StatusOK, cloningState.Clones); err != nil {
+	clones := s.Cloning.GetCloningState().Clones
+
+	if owner != "" {
+		clones = filterClonesByOwner(clones, owner)
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, clones); err != nil {
 		api.SendError(w, r, err)
 		return
 	}
@@ -630,7 +812,8 @@ func (s *Server) createClone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if s.Platform != nil && s.Platform.BindClonesToUser() {
+	// Role-restricted callers can only manage their own clones, so their clones are always bound.
+	if (s.Platform != nil && s.Platform.BindClonesToUser()) || !mw.IsAdmin(r.Context()) {
 		cloneRequest.DB.OwnerUser = ownerFromContext(r.Context())
 	}
 
@@ -695,8 +878,13 @@ func (s *Server) createClone(w http.ResponseWriter, r *http.Request) {
 		cloneRequest.Revision = findMaxCloneRevision(fsm.Pool().CloneRevisionLocation(cloneRequest.Branch, cloneRequest.ID))
 	}
 
-	newClone, err := s.Cloning.CreateClone(cloneRequest)
+	newClone, err := s.Cloning.CreateClone(r.Context(), cloneRequest)
 	if err != nil {
+		if quotaErr, ok := asQuotaError(err); ok {
+			api.SendError(w, r, quotaErr)
+			return
+		}
+
 		var reqErr *models.Error
 		if errors.As(err, &reqErr) {
 			api.SendBadRequestError(w, r, reqErr.Error())
@@ -708,6 +896,8 @@ func (s *Server) createClone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setTarget(r.Context(), newClone.ID)
+
 	if err := api.WriteJSON(w, http.StatusCreated, newClone); err != nil {
 		api.SendError(w, r, err)
 		return
@@ -732,7 +922,8 @@ const maxOwnerLabelLength = 254
 var safeOwnerLabel = regexp.MustCompile(`^[a-zA-Z0-9._@-]+$`)
 
 // ownerFromContext resolves the clone owner label from the authenticated user
-// identity on the context. It returns an empty string when no identity is
+// identity on the context, or from the owner name of an additional static token
+// for callers without an identity. It returns an empty string when neither is
 // present, when the identity has no email, or when the email cannot be
 // represented as a valid owner label — such requests create unlabeled clones
 // instead of failing; the fallback is logged as a warning so a misconfiguration
@@ -741,11 +932,13 @@ var safeOwnerLabel = regexp.MustCompile(`^[a-zA-Z0-9._@-]+$`)
 func ownerFromContext(ctx context.Context) string {
 	identity, ok := mw.UserIdentityFromContext(ctx)
 	if !ok {
-		return ""
+		owner, _ := mw.TokenOwnerFromContext(ctx)
+
+		return owner
 	}
 
 	if identity.Email == "" {
-		log.Warn("clone-to-user binding is enabled but the authenticated identity has no email; " +
+		log.Warn("cannot bind the clone to its creator: the authenticated identity has no email; " +
 			"creating an unlabeled clone (check that the Platform returns the user email)")
 
 		return ""
@@ -753,7 +946,7 @@ func ownerFromContext(ctx context.Context) string {
 
 	owner, err := ownerFromEmail(identity.Email)
 	if err != nil {
-		log.Warn(fmt.Sprintf("clone-to-user binding is enabled but no owner label can be derived: %v; "+
+		log.Warn(fmt.Sprintf("cannot bind the clone to its creator: no owner label can be derived: %v; "+
 			"creating an unlabeled clone", err))
 
 		return ""
@@ -788,6 +981,80 @@ func isValidOwnerLabel(name string) bool {
 	return name != "" && len(name) <= maxOwnerLabelLength && safeOwnerLabel.MatchString(name)
 }
 
+// filterClonesByOwner returns the clones bound to the given owner.
+func filterClonesByOwner(clones []*models.Clone, owner string) []*models.Clone {
+	filtered := make([]*models.Clone, 0, len(clones))
+
+	for _, clone := range clones {
+		if clone.DB.OwnerUser == owner {
+			filtered = append(filtered, clone)
+		}
+	}
+
+	return filtered
+}
+
+// isCloneOwner reports whether the caller is the user or the static token the clone is bound to.
+// Unbound clones have no owner, so only admins can manage them.
+func isCloneOwner(ctx context.Context, clone *models.Clone) bool {
+	if clone.DB.OwnerUser == "" {
+		return false
+	}
+
+	identity, ok := mw.UserIdentityFromContext(ctx)
+	if !ok {
+		owner, ok := mw.TokenOwnerFromContext(ctx)
+
+		return ok && owner == clone.DB.OwnerUser
+	}
+
+	owner, err := ownerFromEmail(identity.Email)
+
+	return err == nil && owner == clone.DB.OwnerUser
+}
+
+// authorizeCloneAction lets admins manage any clone and other callers only their own ones.
+// It sends an error response and returns false when the action is not allowed.
+func (s *Server) authorizeCloneAction(w http.ResponseWriter, r *http.Request, cloneID, action string) bool {
+	if mw.IsAdmin(r.Context()) {
+		return true
+	}
+
+	clone, err := s.Cloning.GetClone(cloneID)
+	if err != nil {
+		api.SendNotFoundError(w, r)
+		return false
+	}
+
+	if !isCloneOwner(r.Context(), clone) {
+		api.SendForbiddenError(w, r, fmt.Sprintf("only the clone owner or an admin can %s clone %s", action, cloneID))
+		return false
+	}
+
+	return true
+}
+
+// sendRequestError responds with the status of a request error, e.g. a bad request, or with an internal error otherwise.
+func sendRequestError(w http.ResponseWriter, r *http.Request, err error) {
+	var reqErr *models.Error
+	if errors.As(err, &reqErr) {
+		api.SendError(w, r, *reqErr)
+		return
+	}
+
+	api.SendError(w, r, err)
+}
+
+// asQuotaError extracts a per-owner quota violation from the error.
+func asQuotaError(err error) (models.Error, bool) {
+	var reqErr *models.Error
+	if errors.As(err, &reqErr) && reqErr.Code == models.ErrCodeQuotaExceeded {
+		return *reqErr, true
+	}
+
+	return models.Error{}, false
+}
+
 func findMaxCloneRevision(path string) int {
 	files, err := os.ReadDir(path)
 	if err != nil {
@@ -829,7 +1096,11 @@ func (s *Server) destroyClone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.Cloning.DestroyClone(cloneID); err != nil {
+	if !s.authorizeCloneAction(w, r, cloneID, "destroy") {
+		return
+	}
+
+	if err := s.Cloning.DestroyClone(r.Context(), cloneID); err != nil {
 		api.SendError(w, r, errors.Wrap(err, "failed to destroy clone"))
 		return
 	}
@@ -896,6 +1167,10 @@ func (s *Server) patchSnapshot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Protected != nil {
+		audit.SetAction(r.Context(), protectionAction("snapshot", *req.Protected))
+	}
+
 	poolName, err := s.detectPoolName(snapshotID)
 	if err != nil {
 		api.SendBadRequestError(w, r, err.Error())
@@ -944,6 +1219,11 @@ func (s *Server) patchSnapshot(w http.ResponseWriter, r *http.Request) {
 
 	snapshot.Protected, snapshot.ProtectedTill, snapshot.DeleteAt = readProtection(fsm, snapshotID)
 
+	s.webhookCh <- webhooks.BasicEvent{
+		EventType: webhooks.SnapshotUpdateEvent,
+		EntityID:  snapshotID,
+	}
+
 	s.tm.SendEvent(context.Background(), telemetry.SnapshotUpdatedEvent, telemetry.SnapshotUpdated{
 		ID:        snapshotID,
 		Protected: snapshot.Protected,
@@ -970,9 +1250,21 @@ func (s *Server) patchClone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	audit.SetAction(r.Context(), protectionAction("clone", patchClone.Protected))
+
+	if !s.authorizeCloneAction(w, r, cloneID, "update") {
+		return
+	}
+
 	updatedClone, err := s.Cloning.UpdateClone(cloneID, patchClone)
 	if err != nil {
+		if quotaErr, ok := asQuotaError(err); ok {
+			api.SendError(w, r, quotaErr)
+			return
+		}
+
 		api.SendError(w, r, errors.Wrap(err, "failed to update clone"))
+
 		return
 	}
 
@@ -987,6 +1279,48 @@ func (s *Server) patchClone(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *Server) patchCloneConfig(w http.ResponseWriter, r *http.Request) {
+	cloneID := mux.Vars(r)["id"]
+
+	if cloneID == "" {
+		api.SendBadRequestError(w, r, "ID must not be empty")
+		return
+	}
+
+	var configRequest types.CloneConfigUpdateRequest
+	if err := api.ReadJSON(r, &configRequest); err != nil {
+		api.SendBadRequestError(w, r, err.Error())
+		return
+	}
+
+	if len(configRequest.PostgresConfig) == 0 {
+		api.SendBadRequestError(w, r, "postgresConfig must not be empty")
+		return
+	}
+
+	if !s.authorizeCloneAction(w, r, cloneID, "update the configuration of") {
+		return
+	}
+
+	update, err := s.Cloning.UpdateCloneConfig(r.Context(), cloneID, configRequest.PostgresConfig)
+	if err != nil {
+		var reqErr *models.Error
+		if errors.As(err, &reqErr) {
+			api.SendError(w, r, *reqErr)
+			return
+		}
+
+		api.SendError(w, r, errors.Wrap(err, "failed to update clone configuration"))
+
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, update); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
 func (s *Server) getClone(w http.ResponseWriter, r *http.Request) {
 	cloneID := mux.Vars(r)["id"]
 
@@ -1029,7 +1363,11 @@ func (s *Server) resetClone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.Cloning.ResetClone(cloneID, resetOptions); err != nil {
+	if !s.authorizeCloneAction(w, r, cloneID, "reset") {
+		return
+	}
+
+	if err := s.Cloning.ResetClone(r.Context(), cloneID, resetOptions); err != nil {
 		api.SendError(w, r, errors.Wrap(err, "failed to reset clone"))
 		return
 	}
@@ -1037,6 +1375,47 @@ func (s *Server) resetClone(w http.ResponseWriter, r *http.Request) {
 	log.Dbg(fmt.Sprintf("Clone ID=%s is being reset", cloneID))
 }
 
+func (s *Server) hibernateClone(w http.ResponseWriter, r *http.Request) {
+	s.changeCloneHibernation(w, r, "hibernate", s.Cloning.HibernateClone)
+}
+
+func (s *Server) wakeClone(w http.ResponseWriter, r *http.Request) {
+	s.changeCloneHibernation(w, r, "wake up", s.Cloning.WakeClone)
+}
+
+func (s *Server) changeCloneHibernation(w http.ResponseWriter, r *http.Request, action string,
+	change func(ctx context.Context, cloneID string) (*models.Clone, error)) {
+	cloneID := mux.Vars(r)["id"]
+
+	if cloneID == "" {
+		api.SendBadRequestError(w, r, "ID must not be empty")
+		return
+	}
+
+	if !s.authorizeCloneAction(w, r, cloneID, action) {
+		return
+	}
+
+	// Do not leave the container half-started or half-stopped if the client disconnects.
+	clone, err := change(context.WithoutCancel(r.Context()), cloneID)
+	if err != nil {
+		var reqErr *models.Error
+		if errors.As(err, &reqErr) {
+			api.SendError(w, r, *reqErr)
+			return
+		}
+
+		api.SendError(w, r, errors.Wrapf(err, "failed to %s clone", action))
+
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, clone); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
 func (s *Server) startObservation(w http.ResponseWriter, r *http.Request) {
 	if s.Platform.Client == nil {
 		api.SendBadRequestError(w, r, "cannot start the session observation because a Platform client is not configured")
@@ -1049,6 +1428,12 @@ func (s *Server) startObservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setTarget(r.Context(), observationRequest.CloneID)
+
+	if !s.authorizeCloneAction(w, r, observationRequest.CloneID, "observe") {
+		return
+	}
+
 	clone, err := s.Cloning.GetClone(observationRequest.CloneID)
 	if err != nil {
 		api.SendNotFoundError(w, r)
@@ -1124,6 +1509,12 @@ func (s *Server) stopObservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setTarget(r.Context(), observationRequest.CloneID)
+
+	if !s.authorizeCloneAction(w, r, observationRequest.CloneID, "stop the observation of") {
+		return
+	}
+
 	observingClone, err := s.Observer.GetObservingClone(observationRequest.CloneID)
 	if err != nil {
 		api.SendNotFoundError(w, r)
@@ -1291,10 +1682,18 @@ func (s *Server) refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The operation of the request ends with the refresh, which can be canceled until then.
+	op := operations.FromContext(r.Context())
+	ctx := op.WithCancel(context.WithoutCancel(r.Context()))
+	op.Detach()
+
 	go func() {
-		if err := s.Retrieval.FullRefresh(context.Background()); err != nil {
+		err := s.Retrieval.FullRefresh(ctx)
+		if err != nil {
 			log.Err("failed to initiate full refresh", err)
 		}
+
+		op.Finish(err)
 	}()
 
 	if err := api.WriteJSON(w, http.StatusOK, models.Response{
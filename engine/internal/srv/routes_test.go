@@ -2,14 +2,29 @@ package srv
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/cloning"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/platform"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
+	srvCfg "gitlab.com/postgres-ai/database-lab/v3/internal/srv/config"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/mw"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/telemetry"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
 )
 
 func TestOwnerFromEmail(t *testing.T) {
@@ -52,6 +67,16 @@ func TestOwnerFromContext_NoIdentity(t *testing.T) {
 	assert.Empty(t, ownerFromContext(context.Background()))
 }
 
+func TestOwnerFromContext_StaticToken(t *testing.T) {
+	ctx := tokenContext(t, "ci-token")
+
+	owner := ownerFromContext(ctx)
+	assert.True(t, strings.HasPrefix(owner, "token-"))
+	assert.True(t, isValidOwnerLabel(owner))
+	assert.Equal(t, owner, ownerFromContext(tokenContext(t, "ci-token")))
+	assert.NotEqual(t, owner, ownerFromContext(tokenContext(t, "other-token")))
+}
+
 func TestOwnerFromContext_Identity(t *testing.T) {
 	testCases := []struct {
 		name  string
@@ -70,3 +95,195 @@ func TestOwnerFromContext_Identity(t *testing.T) {
 		})
 	}
 }
+
+func TestIsCloneOwner(t *testing.T) {
+	testCases := []struct {
+		name  string
+		email string
+		owner string
+		want  bool
+	}{
+		{name: "creator owns the clone", email: "jsmith@acme.io", owner: "jsmith@acme.io", want: true},
+		{name: "another user does not own the clone", email: "jdoe@acme.io", owner: "jsmith@acme.io", want: false},
+		{name: "unbound clone has no owner", email: "jsmith@acme.io", owner: "", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := mw.WithUserIdentity(context.Background(), platform.UserIdentity{Email: tc.email})
+			clone := &models.Clone{DB: models.Database{OwnerUser: tc.owner}}
+			assert.Equal(t, tc.want, isCloneOwner(ctx, clone))
+		})
+	}
+
+	assert.False(t, isCloneOwner(context.Background(), &models.Clone{DB: models.Database{OwnerUser: "jsmith@acme.io"}}))
+
+	tokenCtx := tokenContext(t, "ci-token")
+	assert.True(t, isCloneOwner(tokenCtx, &models.Clone{DB: models.Database{OwnerUser: ownerFromContext(tokenCtx)}}))
+	assert.False(t, isCloneOwner(tokenCtx, &models.Clone{DB: models.Database{OwnerUser: "jsmith@acme.io"}}))
+	assert.False(t, isCloneOwner(tokenCtx, &models.Clone{}))
+}
+
+// testTokens defines additional static tokens of the developer role used by route tests.
+var testTokens = map[string]string{"ci-token": "developer", "other-token": "developer"}
+
+func newTokenAuth() *mw.Auth {
+	auth := mw.NewAuth("shared-token", nil)
+	auth.SetAccess(srvCfg.Access{Tokens: testTokens})
+
+	return auth
+}
+
+// tokenContext returns the context that the middleware attaches to requests authenticated with the token.
+func tokenContext(t *testing.T, token string) context.Context {
+	t.Helper()
+
+	var ctx context.Context
+
+	handler := newTokenAuth().Authorized(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/clones", nil)
+	req.Header.Set(mw.VerificationTokenHeader, token)
+	handler(httptest.NewRecorder(), req)
+
+	require.NotNil(t, ctx)
+
+	return ctx
+}
+
+// newTokenCloneServer returns a server whose clones are restored from the sessions file of the working directory.
+func newTokenCloneServer(t *testing.T, quotas cloning.Quotas, clones ...*models.Clone) *Server {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+
+	sessions := make(map[string]*cloning.CloneWrapper, len(clones))
+	for _, clone := range clones {
+		sessions[clone.ID] = &cloning.CloneWrapper{Clone: clone}
+	}
+
+	data, err := json.Marshal(sessions)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll("meta", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("meta", "sessions.json"), data, 0600))
+
+	base := cloning.NewBase(&cloning.Config{Quotas: quotas}, &global.Config{}, nil, nil, nil, nil)
+	require.NoError(t, base.RestoreClonesState())
+
+	return &Server{Cloning: base, tm: telemetry.New(&platform.Service{}, "")}
+}
+
+func tokenTestClone(id, owner string, protected bool) *models.Clone {
+	return &models.Clone{
+		ID:        id,
+		Snapshot:  &models.Snapshot{ID: "dblab_pool@snapshot_20260101000000", Pool: "dblab_pool"},
+		Protected: protected,
+		DB:        models.Database{OwnerUser: owner},
+	}
+}
+
+func serveWithToken(handler http.HandlerFunc, method, target, token, body string) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/clone/{id}", newTokenAuth().Require(mw.RoleDeveloper, handler)).Methods(method)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(mw.VerificationTokenHeader, token)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestDestroyClone_StaticToken(t *testing.T) {
+	owner := ownerFromContext(tokenContext(t, "ci-token"))
+
+	s := newTokenCloneServer(t, cloning.Quotas{},
+		tokenTestClone("own", owner, false),
+		tokenTestClone("foreign", "jsmith@acme.io", false),
+	)
+
+	rec := serveWithToken(s.destroyClone, http.MethodDelete, "/clone/own", "other-token", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.True(t, s.Cloning.HasClone("own"))
+
+	rec = serveWithToken(s.destroyClone, http.MethodDelete, "/clone/foreign", "ci-token", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.True(t, s.Cloning.HasClone("foreign"))
+
+	rec = serveWithToken(s.destroyClone, http.MethodDelete, "/clone/own", "ci-token", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, s.Cloning.HasClone("own"))
+}
+
+func TestPatchClone_StaticTokenQuota(t *testing.T) {
+	owner := ownerFromContext(tokenContext(t, "ci-token"))
+
+	s := newTokenCloneServer(t, cloning.Quotas{MaxProtectedClones: 1},
+		tokenTestClone("protected", owner, true),
+		tokenTestClone("own", owner, false),
+		tokenTestClone("other", ownerFromContext(tokenContext(t, "other-token")), false),
+	)
+
+	rec := serveWithToken(s.patchClone, http.MethodPatch, "/clone/own", "ci-token", `{"protected": true}`)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), "quota exceeded for "+owner)
+
+	rec = serveWithToken(s.patchClone, http.MethodPatch, "/clone/other", "other-token", `{"protected": true}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestFilterClonesByOwner(t *testing.T) {
+	clones := []*models.Clone{
+		{ID: "c1", DB: models.Database{OwnerUser: "jsmith@acme.io"}},
+		{ID: "c2", DB: models.Database{OwnerUser: "jdoe@acme.io"}},
+		{ID: "c3"},
+		{ID: "c4", DB: models.Database{OwnerUser: "jsmith@acme.io"}},
+	}
+
+	filtered := filterClonesByOwner(clones, "jsmith@acme.io")
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "c1", filtered[0].ID)
+	assert.Equal(t, "c4", filtered[1].ID)
+
+	assert.Empty(t, filterClonesByOwner(clones, "nobody@acme.io"))
+}
+
+func TestRecoveryTarget(t *testing.T) {
+	target, err := recoveryTarget(types.SnapshotCreateRequest{RecoveryTargetTime: "2026-10-16T16:32:10+02:00"})
+	require.NoError(t, err)
+	assert.True(t, target.Time.Equal(time.Date(2026, 10, 16, 14, 32, 10, 0, time.UTC)))
+	assert.Equal(t, "pitr_20261016143210", target.BranchName())
+
+	target, err = recoveryTarget(types.SnapshotCreateRequest{RecoveryTargetLSN: "16/B374D848", Branch: "incident-42"})
+	require.NoError(t, err)
+	assert.Equal(t, "16/B374D848", target.LSN)
+	assert.Equal(t, "incident-42", target.BranchName())
+
+	_, err = recoveryTarget(types.SnapshotCreateRequest{RecoveryTargetTime: "yesterday 14:32"})
+	assert.ErrorContains(t, err, "RFC 3339")
+
+	_, err = recoveryTarget(types.SnapshotCreateRequest{RecoveryTargetTime: "2026-10-16T14:32:10Z", RecoveryTargetLSN: "16/B374D848"})
+	assert.Error(t, err)
+
+	_, err = recoveryTarget(types.SnapshotCreateRequest{RecoveryTargetLSN: "16/B374D848", Branch: "feature/pitr"})
+	assert.ErrorContains(t, err, "branch name")
+}
+
+func TestRetentionPolicyFromQuery(t *testing.T) {
+	policy, err := retentionPolicyFromQuery(url.Values{})
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	policy, err = retentionPolicyFromQuery(url.Values{"daily": {"7"}, "monthly": {"6"}})
+	require.NoError(t, err)
+	assert.Equal(t, &thinclones.RetentionPolicy{Daily: 7, Monthly: 6}, policy)
+
+	_, err = retentionPolicyFromQuery(url.Values{"hourly": {"many"}})
+	assert.ErrorContains(t, err, "hourly must be an integer")
+
+	_, err = retentionPolicyFromQuery(url.Values{"weekly": {"-1"}})
+	assert.Error(t, err)
+}
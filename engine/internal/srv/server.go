@@ -21,13 +21,19 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"gitlab.com/postgres-ai/database-lab/v3/internal/audit"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/billing"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/cloning"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/embeddedui"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/events"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/operations"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/opmetrics"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/pgrouter"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/platform"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/pool"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/thinclones"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/probe"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
@@ -54,6 +60,7 @@ type Server struct {
 	provisioner      *provision.Provisioner
 	Config           *srvCfg.Config
 	configMu         sync.RWMutex
+	auth             *mw.Auth
 	retention        srvCfg.Retention
 	retentionMu      sync.RWMutex
 	Global           *global.Config
@@ -71,10 +78,17 @@ type Server struct {
 	filtering        *log.Filtering
 	reloadFn         func(server *Server) error
 	webhookCh        chan webhooks.EventTyper
+	webhookSvc       *webhooks.Service
+	router           *pgrouter.Router
+	auditLog         *audit.Log
+	events           *events.Broker
+	operations       *operations.Registry
 	metricsRegistry  *prometheus.Registry
 	metricsCollector *metrics.Collector
 	metricsCancel    context.CancelFunc
 	imageRegistry    *probe.Registry
+	snapshotMetadata *thinclones.MetadataStore
+	rebaseMu         sync.Mutex
 }
 
 // WSService defines a service to manage web-sockets.
@@ -100,6 +114,10 @@ func NewServer(cfg *srvCfg.Config, globalCfg *global.Config, engineProps *global
 		log.Err("failed to register prometheus metrics:", err)
 	}
 
+	if err := opmetrics.Register(metricsRegistry); err != nil {
+		log.Err("failed to register prometheus operation metrics:", err)
+	}
+
 	server := &Server{
 		Config:      cfg,
 		Global:      globalCfg,
@@ -124,8 +142,18 @@ func NewServer(cfg *srvCfg.Config, globalCfg *global.Config, engineProps *global
 		webhookCh:       webhookCh,
 		metricsRegistry: metricsRegistry,
 		imageRegistry:   probe.NewRegistry(),
+		auth:            mw.NewAuth(cfg.VerificationToken, platform),
 	}
 
+	server.auth.SetAccess(cfg.Access)
+
+	snapshotMetadata, err := thinclones.DefaultMetadataStore()
+	if err != nil {
+		log.Err(err)
+	}
+
+	server.snapshotMetadata = snapshotMetadata
+
 	collector, err := metrics.NewCollector(m, cloning, retrievalSvc, pm, engineProps, dockerClient, startedAt)
 	if err != nil {
 		log.Err("failed to create metrics collector:", err)
@@ -228,6 +256,8 @@ func (s *Server) Reload(cfg srvCfg.Config) {
 	s.configMu.Lock()
 	*s.Config = cfg
 	s.configMu.Unlock()
+
+	s.auth.SetAccess(cfg.Access)
 }
 
 // configModificationDisabled reports whether the config-modification endpoints are disabled,
@@ -261,34 +291,64 @@ func (s *Server) SetRetention(r srvCfg.Retention) {
 func (s *Server) InitHandlers() {
 	r := mux.NewRouter().StrictSlash(true).UseEncodedPath()
 
-	authMW := mw.NewAuth(s.Config.VerificationToken, s.Platform)
+	authMW := s.auth
 
+	// Read-only routes are open to every role; developers can create clones and branches, snapshot clones
+	// and branches, and manage their own clones; snapshotting the pool and deleting or changing shared entities
+	// require the admin role.
 	r.HandleFunc("/status", authMW.Authorized(s.getInstanceStatus)).Methods(http.MethodGet)
 	r.HandleFunc("/snapshots", authMW.Authorized(s.getSnapshots)).Methods(http.MethodGet)
+	r.HandleFunc("/snapshots/retention", authMW.Require(mw.RoleAdmin, s.previewRetention)).Methods(http.MethodGet)
+	r.HandleFunc("/snapshot/{id:.*}/masking", authMW.Authorized(s.getMaskingReport)).Methods(http.MethodGet)
+	r.HandleFunc("/snapshot/{id:.*}/diff", authMW.Require(mw.RoleDeveloper, s.snapshotDiff)).Methods(http.MethodGet)
 	r.HandleFunc("/snapshot/{id:.*}", authMW.Authorized(s.getSnapshot)).Methods(http.MethodGet)
-	r.HandleFunc("/snapshot", authMW.Authorized(s.createSnapshot)).Methods(http.MethodPost)
-	r.HandleFunc("/snapshot/{id:.*}", authMW.Authorized(s.deleteSnapshot)).Methods(http.MethodDelete)
-	r.HandleFunc("/snapshot/{id:.*}", authMW.Authorized(s.patchSnapshot)).Methods(http.MethodPatch)
-	r.HandleFunc("/snapshot/clone", authMW.Authorized(s.createSnapshotClone)).Methods(http.MethodPost)
+	r.HandleFunc("/snapshot", authMW.Require(mw.RoleAdmin, s.tracked("snapshot.create", s.createSnapshot))).Methods(http.MethodPost)
+	r.HandleFunc("/snapshot/{id:.*}", authMW.Require(mw.RoleAdmin, s.tracked("snapshot.destroy",
+		mw.ObserveOperation(opmetrics.ResourceSnapshot, "destroy", s.deleteSnapshot)))).Methods(http.MethodDelete)
+	r.HandleFunc("/snapshot/{id:.*}", authMW.Require(mw.RoleAdmin, s.tracked("snapshot.update", s.patchSnapshot))).
+		Methods(http.MethodPatch)
+	r.HandleFunc("/snapshot/clone", authMW.Require(mw.RoleDeveloper, s.tracked("snapshot.create_from_clone",
+		mw.ObserveOperation(opmetrics.ResourceSnapshot, "create_from_clone", s.createSnapshotClone)))).Methods(http.MethodPost)
 	r.HandleFunc("/clones", authMW.Authorized(s.clones)).Methods(http.MethodGet)
-	r.HandleFunc("/clone", authMW.Authorized(s.createClone)).Methods(http.MethodPost)
-	r.HandleFunc("/clone/{id}", authMW.Authorized(s.destroyClone)).Methods(http.MethodDelete)
-	r.HandleFunc("/clone/{id}", authMW.Authorized(s.patchClone)).Methods(http.MethodPatch)
+	r.HandleFunc("/clone", authMW.Require(mw.RoleDeveloper, s.tracked("clone.create", s.createClone))).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}", authMW.Require(mw.RoleDeveloper, s.tracked("clone.destroy", s.destroyClone))).
+		Methods(http.MethodDelete)
+	r.HandleFunc("/clone/{id}", authMW.Require(mw.RoleDeveloper, s.tracked("clone.update", s.patchClone))).
+		Methods(http.MethodPatch)
 	r.HandleFunc("/clone/{id}", authMW.Authorized(s.getClone)).Methods(http.MethodGet)
-	r.HandleFunc("/clone/{id}/reset", authMW.Authorized(s.resetClone)).Methods(http.MethodPost)
-	r.HandleFunc("/observation/start", authMW.Authorized(s.startObservation)).Methods(http.MethodPost)
-	r.HandleFunc("/observation/stop", authMW.Authorized(s.stopObservation)).Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/reset", authMW.Require(mw.RoleDeveloper, s.tracked("clone.reset", s.resetClone))).
+		Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/hibernate", authMW.Require(mw.RoleDeveloper, s.tracked("clone.hibernate", s.hibernateClone))).
+		Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/wake", authMW.Require(mw.RoleDeveloper, s.tracked("clone.wake", s.wakeClone))).
+		Methods(http.MethodPost)
+	r.HandleFunc("/clone/{id}/config", authMW.Require(mw.RoleDeveloper, s.tracked("clone.configure", s.patchCloneConfig))).
+		Methods(http.MethodPatch)
+	r.HandleFunc("/router/connections", authMW.Authorized(s.routerConnections)).Methods(http.MethodGet)
+	r.HandleFunc("/observation/start", authMW.Require(mw.RoleDeveloper, s.tracked("observation.start", s.startObservation))).
+		Methods(http.MethodPost)
+	r.HandleFunc("/observation/stop", authMW.Require(mw.RoleDeveloper, s.tracked("observation.stop", s.stopObservation))).
+		Methods(http.MethodPost)
 	r.HandleFunc("/observation/summary/{clone_id}/{session_id}", authMW.Authorized(s.sessionSummaryObservation)).Methods(http.MethodGet)
 	r.HandleFunc("/observation/download", authMW.Authorized(s.downloadArtifact)).Methods(http.MethodGet)
 	r.HandleFunc("/instance/retrieval", authMW.Authorized(s.retrievalState)).Methods(http.MethodGet)
+	r.HandleFunc("/operations/{id}", authMW.Authorized(s.getOperation)).Methods(http.MethodGet)
+	r.HandleFunc("/operations/{id}/cancel", authMW.Require(mw.RoleDeveloper, s.audited("operation.cancel", s.cancelOperation))).
+		Methods(http.MethodPost)
 
 	r.HandleFunc("/branches", authMW.Authorized(s.listBranches)).Methods(http.MethodGet)
 	r.HandleFunc("/branch/snapshot/{id:.*}", authMW.Authorized(s.getCommit)).Methods(http.MethodGet)
-	r.HandleFunc("/branch", authMW.Authorized(s.createBranch)).Methods(http.MethodPost)
-	r.HandleFunc("/branch/snapshot", authMW.Authorized(s.snapshot)).Methods(http.MethodPost)
+	r.HandleFunc("/branch", authMW.Require(mw.RoleDeveloper, s.tracked("branch.create",
+		mw.ObserveOperation(opmetrics.ResourceBranch, "create", s.createBranch)))).Methods(http.MethodPost)
+	r.HandleFunc("/branch/snapshot", authMW.Require(mw.RoleDeveloper, s.tracked("branch.snapshot",
+		mw.ObserveOperation(opmetrics.ResourceBranch, "snapshot", s.snapshot)))).Methods(http.MethodPost)
 	r.HandleFunc("/branch/{branchName}/log", authMW.Authorized(s.log)).Methods(http.MethodGet)
-	r.HandleFunc("/branch/{branchName}", authMW.Authorized(s.deleteBranch)).Methods(http.MethodDelete)
-	r.HandleFunc("/branch/{branchName}", authMW.Authorized(s.patchBranch)).Methods(http.MethodPatch)
+	r.HandleFunc("/branch/{branchName}/rebase", authMW.Require(mw.RoleAdmin, s.tracked("branch.rebase", s.rebaseBranch))).
+		Methods(http.MethodPost)
+	r.HandleFunc("/branch/{branchName}", authMW.Require(mw.RoleAdmin, s.tracked("branch.delete",
+		mw.ObserveOperation(opmetrics.ResourceBranch, "delete", s.deleteBranch)))).Methods(http.MethodDelete)
+	r.HandleFunc("/branch/{branchName}", authMW.Require(mw.RoleAdmin, s.tracked("branch.update", s.patchBranch))).
+		Methods(http.MethodPatch)
 
 	// Sub-route /admin
 	adminR := r.PathPrefix("/admin").Subrouter()
@@ -296,13 +356,18 @@ func (s *Server) InitHandlers() {
 	adminR.HandleFunc("/ws-auth", s.websocketAuth).Methods(http.MethodGet)
 	adminR.HandleFunc("/config", s.getProjectedAdminConfig).Methods(http.MethodGet)
 	adminR.HandleFunc("/config.yaml", s.getAdminConfigYaml).Methods(http.MethodGet)
-	adminR.HandleFunc("/config", s.setProjectedAdminConfig).Methods(http.MethodPost)
+	adminR.HandleFunc("/config", s.tracked("config.update", s.setProjectedAdminConfig)).Methods(http.MethodPost)
 	adminR.HandleFunc("/test-db-source", s.testDBSource).Methods(http.MethodPost)
 	adminR.HandleFunc("/probe-source", s.probeSource).Methods(http.MethodPost)
 	adminR.HandleFunc("/billing-status", s.billingStatus).Methods(http.MethodGet)
-	adminR.HandleFunc("/activate", s.activate).Methods(http.MethodPost)
+	adminR.HandleFunc("/activate", s.tracked("instance.activate", s.activate)).Methods(http.MethodPost)
+	adminR.HandleFunc("/webhooks/deliveries", s.webhookDeliveries).Methods(http.MethodGet)
+	adminR.HandleFunc("/webhooks/deliveries/{id}/replay", s.tracked("webhook.replay", s.replayWebhookDelivery)).
+		Methods(http.MethodPost)
+	adminR.HandleFunc("/audit", s.auditEntries).Methods(http.MethodGet)
 
 	r.HandleFunc("/instance/logs", authMW.WebSocketsMW(s.wsService.tokenKeeper, s.instanceLogs))
+	r.HandleFunc("/events", authMW.WebSocketsMW(s.wsService.tokenKeeper, s.eventStream)).Methods(http.MethodGet)
 
 	// Health check.
 	r.HandleFunc("/healthz", s.healthCheck).Methods(http.MethodGet, http.MethodPost)
@@ -311,7 +376,8 @@ func (s *Server) InitHandlers() {
 	r.Handle("/metrics", s.metricsHandler()).Methods(http.MethodGet)
 
 	// Full refresh
-	r.HandleFunc("/full-refresh", authMW.Authorized(s.refresh)).Methods(http.MethodPost)
+	r.HandleFunc("/full-refresh", authMW.Require(mw.RoleAdmin, s.tracked("instance.full_refresh", s.refresh))).
+		Methods(http.MethodPost)
 
 	// Show Swagger UI on index page.
 	if err := attachAPI(r); err != nil {
@@ -326,7 +392,9 @@ func (s *Server) InitHandlers() {
 	// Show not found error for all other possible routes.
 	r.NotFoundHandler = http.HandlerFunc(api.SendNotFoundError)
 
-	s.httpSrv = &http.Server{Addr: fmt.Sprintf("%s:%d", s.Config.Host, s.Config.Port), Handler: mw.Logging(r)}
+	r.Use(mw.NameSpan, mw.Metrics)
+
+	s.httpSrv = &http.Server{Addr: fmt.Sprintf("%s:%d", s.Config.Host, s.Config.Port), Handler: mw.Tracing(mw.Logging(r))}
 }
 
 // Run starts HTTP server on specified port in configuration. The provided context governs the
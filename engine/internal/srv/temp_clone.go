@@ -0,0 +1,94 @@
+/*
+2026 © Postgres.ai
+*/
+
+package srv
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/operations"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	temporaryClonePollInterval = time.Second
+	temporaryCloneUser         = "dblab_service"
+	temporaryPasswordBytes     = 16
+)
+
+// startTemporaryClone creates a service clone of the snapshot and waits until it is ready.
+// An empty branch means the branch of the snapshot; an empty dbName means the configured database.
+// The caller must destroy the clone with destroyTemporaryClone.
+func (s *Server) startTemporaryClone(ctx context.Context, branch, snapshotID, dbName string) (*models.Clone, error) {
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName == "" {
+		dbName = s.Global.Database.Name()
+	}
+
+	// The temporary clone is a step of the operation of the caller, so it must not finish that operation.
+	clone, err := s.Cloning.CreateClone(operations.WithOperation(ctx, nil), &types.CloneCreateRequest{
+		Branch:   branch,
+		Snapshot: &types.SnapshotCloneFieldRequest{ID: snapshotID},
+		DB: &types.DatabaseRequest{
+			Username: temporaryCloneUser,
+			Password: password,
+			DBName:   dbName,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary clone of %s: %w", snapshotID, err)
+	}
+
+	ticker := time.NewTicker(temporaryClonePollInterval)
+	defer ticker.Stop()
+
+	for {
+		current, err := s.Cloning.GetClone(clone.ID)
+		if err != nil {
+			return nil, fmt.Errorf("temporary clone %s disappeared: %w", clone.ID, err)
+		}
+
+		switch current.Status.Code {
+		case models.StatusOK:
+			return current, nil
+
+		case models.StatusFatal:
+			s.destroyTemporaryClone(clone.ID)
+			return nil, fmt.Errorf("failed to start temporary clone of %s: %s", snapshotID, current.Status.Message)
+		}
+
+		select {
+		case <-ctx.Done():
+			s.destroyTemporaryClone(clone.ID)
+			return nil, fmt.Errorf("temporary clone %s is not ready: %w", clone.ID, ctx.Err())
+
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) destroyTemporaryClone(cloneID string) {
+	if err := s.Cloning.DestroyCloneSync(cloneID); err != nil {
+		log.Err(fmt.Sprintf("failed to destroy temporary clone %s: %v", cloneID, err))
+	}
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, temporaryPasswordBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,70 @@
+/*
+2026 © Postgres.ai
+*/
+
+package srv
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"gitlab.com/postgres-ai/database-lab/v3/internal/srv/api"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+)
+
+// SetWebhooks attaches the webhook service whose deliveries are exposed by the admin API.
+func (s *Server) SetWebhooks(whs *webhooks.Service) {
+	s.webhookSvc = whs
+}
+
+func (s *Server) webhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if s.webhookSvc == nil {
+		api.SendBadRequestError(w, r, "webhook service is not available")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+
+	switch status {
+	case "", webhooks.DeliveryPending, webhooks.DeliveryDelivered, webhooks.DeliveryFailed:
+	default:
+		api.SendBadRequestError(w, r, "status must be one of pending, delivered, failed")
+		return
+	}
+
+	if err := api.WriteJSON(w, http.StatusOK, s.webhookSvc.Deliveries(status)); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
+
+func (s *Server) replayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	if s.webhookSvc == nil {
+		api.SendBadRequestError(w, r, "webhook service is not available")
+		return
+	}
+
+	deliveryID := mux.Vars(r)["id"]
+
+	delivery, err := s.webhookSvc.Replay(deliveryID)
+	if err != nil {
+		if errors.Is(err, webhooks.ErrDeliveryNotFound) {
+			api.SendNotFoundError(w, r)
+			return
+		}
+
+		api.SendBadRequestError(w, r, err.Error())
+
+		return
+	}
+
+	log.Msg("Webhook delivery has been scheduled for replay:", deliveryID)
+
+	if err := api.WriteJSON(w, http.StatusOK, delivery); err != nil {
+		api.SendError(w, r, err)
+		return
+	}
+}
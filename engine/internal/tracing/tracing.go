@@ -0,0 +1,160 @@
+/*
+2026 © Postgres.ai
+*/
+
+// Package tracing provides OpenTelemetry tracing of engine operations exported over OTLP/HTTP.
+//
+// Spans are created with the global tracer provider, which is a no-op until Init installs an exporting one,
+// so instrumented code does not need to check whether tracing is enabled.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"gitlab.com/postgres-ai/database-lab/v3/version"
+)
+
+const (
+	tracerName         = "gitlab.com/postgres-ai/database-lab"
+	defaultServiceName = "dblab-engine"
+)
+
+// Config defines the export of traces.
+type Config struct {
+	Enabled bool `yaml:"enabled"`
+	// Endpoint is the host and port of the OTLP/HTTP receiver, e.g. "localhost:4318".
+	// When empty, the standard OTEL_EXPORTER_OTLP_* environment variables are used.
+	Endpoint string `yaml:"endpoint"`
+	// Insecure sends traces over plain HTTP instead of HTTPS.
+	Insecure bool `yaml:"insecure"`
+	// Headers are added to export requests, e.g. to authenticate with a tracing backend.
+	Headers     map[string]string `yaml:"headers"`
+	ServiceName string            `yaml:"serviceName"`
+	// SampleRatio is the fraction of traces to record, from 0 to 1. Zero means all traces.
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+// Validate checks the tracing configuration.
+func (c Config) Validate() error {
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1, got %v", c.SampleRatio)
+	}
+
+	return nil
+}
+
+func (c Config) serviceName() string {
+	if c.ServiceName == "" {
+		return defaultServiceName
+	}
+
+	return c.ServiceName
+}
+
+func (c Config) sampler() sdktrace.Sampler {
+	if c.SampleRatio == 0 || c.SampleRatio == 1 {
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	}
+
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRatio))
+}
+
+// Init installs the global tracer provider that exports spans to the configured OTLP endpoint.
+// The returned function flushes pending spans and stops the exporter. If tracing is disabled, nothing
+// is exported, but W3C trace context is still propagated, so that incoming trace IDs reach logs and webhooks.
+func Init(ctx context.Context, cfg Config, instanceID string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	options := []otlptracehttp.Option{}
+
+	if cfg.Endpoint != "" {
+		options = append(options, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+
+	if cfg.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	if len(cfg.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.serviceName()),
+		semconv.ServiceVersion(version.GetVersion()),
+		semconv.ServiceInstanceID(instanceID),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(cfg.sampler()),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span of an engine operation. The span must be finished with End.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End finishes the span and marks it as failed if the operation returned an error.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// Step runs a step of an operation in its own span.
+func Step(ctx context.Context, name string, step func(context.Context) error) error {
+	ctx, span := Start(ctx, name)
+
+	err := step(ctx)
+
+	End(span, err)
+
+	return err
+}
+
+// TraceID returns the ID of the trace carried by the context or an empty string if there is none.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
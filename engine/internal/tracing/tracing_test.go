@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestStep(t *testing.T) {
+	recorder := recordSpans(t)
+
+	ctx, span := Start(context.Background(), "cloning.CreateClone")
+	traceID := TraceID(ctx)
+
+	require.NotEmpty(t, traceID)
+
+	err := Step(ctx, "fs.CreateClone", func(ctx context.Context) error {
+		assert.Equal(t, traceID, TraceID(ctx), "steps must belong to the trace of the operation")
+		return errors.New("dataset already exists")
+	})
+	require.Error(t, err)
+
+	End(span, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "fs.CreateClone", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, span.SpanContext().SpanID(), spans[0].Parent().SpanID())
+
+	assert.Equal(t, "cloning.CreateClone", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestTraceIDWithoutSpan(t *testing.T) {
+	assert.Empty(t, TraceID(context.Background()))
+}
+
+func TestInitDisabled(t *testing.T) {
+	shutdown, err := Init(context.Background(), Config{Enabled: false, SampleRatio: 2}, "instance")
+	require.NoError(t, err, "the configuration of disabled tracing is not used")
+	require.NoError(t, shutdown(context.Background()))
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{SampleRatio: 0.25}.Validate())
+	assert.Error(t, Config{SampleRatio: -0.1}.Validate())
+	assert.Error(t, Config{SampleRatio: 1.5}.Validate())
+}
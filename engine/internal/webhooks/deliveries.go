@@ -0,0 +1,270 @@
+/*
+2026 © Postgres.ai
+*/
+
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+var (
+	// ErrDeliveryNotFound is returned when no delivery with the given ID is recorded.
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+	// ErrDeliveryNotFailed is returned when replaying a delivery that has not failed.
+	ErrDeliveryNotFailed = errors.New("only failed webhook deliveries can be replayed")
+
+	errQueueFull = errors.New("webhook delivery queue is full")
+)
+
+// Delivery describes a webhook request for one event and one hook, and the outcome of its attempts.
+type Delivery struct {
+	ID            string          `json:"id"`
+	EventType     string          `json:"eventType"`
+	URL           string          `json:"url"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	StatusCode    int             `json:"statusCode,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	LastAttemptAt *time.Time      `json:"lastAttemptAt,omitempty"`
+	NextAttemptAt *time.Time      `json:"nextAttemptAt,omitempty"`
+}
+
+// deliveryStore keeps pending deliveries and the history of finished ones. It is persisted to a file
+// after every change so that pending deliveries survive engine restarts.
+type deliveryStore struct {
+	mu          sync.Mutex
+	filePath    string
+	deliveries  []*Delivery
+	inFlight    map[string]struct{}
+	queueSize   int
+	historySize int
+}
+
+func newDeliveryStore(filePath string, queueSize, historySize int) *deliveryStore {
+	return &deliveryStore{
+		filePath:    filePath,
+		inFlight:    make(map[string]struct{}),
+		queueSize:   queueSize,
+		historySize: historySize,
+	}
+}
+
+// load restores deliveries saved by a previous run.
+func (st *deliveryStore) load() error {
+	if st.filePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(st.filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read webhook deliveries: %w", err)
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if err := json.Unmarshal(data, &st.deliveries); err != nil {
+		return fmt.Errorf("failed to parse webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+func (st *deliveryStore) setLimits(queueSize, historySize int) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.queueSize = queueSize
+	st.historySize = historySize
+}
+
+// enqueue adds a pending delivery unless the queue is full.
+func (st *deliveryStore) enqueue(d *Delivery) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.countLocked(DeliveryPending) >= st.queueSize {
+		return errQueueFull
+	}
+
+	st.deliveries = append(st.deliveries, d)
+
+	return st.saveLocked()
+}
+
+// due returns copies of pending deliveries to attempt now and marks them in flight until updated.
+func (st *deliveryStore) due(now time.Time) []Delivery {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var due []Delivery
+
+	for _, d := range st.deliveries {
+		if d.Status != DeliveryPending {
+			continue
+		}
+
+		if _, ok := st.inFlight[d.ID]; ok {
+			continue
+		}
+
+		if d.NextAttemptAt != nil && d.NextAttemptAt.After(now) {
+			continue
+		}
+
+		st.inFlight[d.ID] = struct{}{}
+		due = append(due, *d)
+	}
+
+	return due
+}
+
+// update records the outcome of an attempt.
+func (st *deliveryStore) update(updated Delivery) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	delete(st.inFlight, updated.ID)
+
+	for i, d := range st.deliveries {
+		if d.ID == updated.ID {
+			st.deliveries[i] = &updated
+			break
+		}
+	}
+
+	st.trimHistoryLocked()
+
+	return st.saveLocked()
+}
+
+// replay schedules a failed delivery to be attempted again from scratch.
+func (st *deliveryStore) replay(id string, now time.Time) (Delivery, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for _, d := range st.deliveries {
+		if d.ID != id {
+			continue
+		}
+
+		if d.Status != DeliveryFailed {
+			return Delivery{}, ErrDeliveryNotFailed
+		}
+
+		if st.countLocked(DeliveryPending) >= st.queueSize {
+			return Delivery{}, errQueueFull
+		}
+
+		d.Status = DeliveryPending
+		d.Attempts = 0
+		d.NextAttemptAt = &now
+
+		return *d, st.saveLocked()
+	}
+
+	return Delivery{}, ErrDeliveryNotFound
+}
+
+// list returns deliveries with the given status, or all of them for an empty status, newest first.
+func (st *deliveryStore) list(status string) []Delivery {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	deliveries := []Delivery{}
+
+	for _, d := range st.deliveries {
+		if status == "" || d.Status == status {
+			deliveries = append(deliveries, *d)
+		}
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+
+	return deliveries
+}
+
+func (st *deliveryStore) countLocked(status string) int {
+	count := 0
+
+	for _, d := range st.deliveries {
+		if d.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+// trimHistoryLocked drops the oldest finished deliveries above the history limit.
+func (st *deliveryStore) trimHistoryLocked() {
+	excess := len(st.deliveries) - st.countLocked(DeliveryPending) - st.historySize
+	if excess <= 0 {
+		return
+	}
+
+	kept := st.deliveries[:0]
+
+	for _, d := range st.deliveries {
+		if excess > 0 && d.Status != DeliveryPending {
+			excess--
+			continue
+		}
+
+		kept = append(kept, d)
+	}
+
+	st.deliveries = kept
+}
+
+func (st *deliveryStore) saveLocked() error {
+	if st.filePath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(st.deliveries)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook deliveries: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(st.filePath), 0700); err != nil {
+		return fmt.Errorf("failed to create directory for webhook deliveries: %w", err)
+	}
+
+	tmpFile := st.filePath + ".tmp"
+
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to save webhook deliveries: %w", err)
+	}
+
+	if err := os.Rename(tmpFile, st.filePath); err != nil {
+		return fmt.Errorf("failed to save webhook deliveries: %w", err)
+	}
+
+	return nil
+}
@@ -11,6 +11,8 @@ const (
 	CloneProtectionExpiringEvent = "clone_protection_expiring"
 	// CloneProtectionExpiredEvent defines the clone protection expired event type.
 	CloneProtectionExpiredEvent = "clone_protection_expired"
+	// CloneStatusChangeEvent defines the event type sent when the status of a clone changes.
+	CloneStatusChangeEvent = "clone_status_change"
 
 	// SnapshotCreateEvent defines the snapshot create event type.
 	SnapshotCreateEvent = "snapshot_create"
@@ -18,11 +20,36 @@ const (
 	// SnapshotDeleteEvent defines the snapshot delete event type.
 	SnapshotDeleteEvent = "snapshot_delete"
 
+	// SnapshotUpdateEvent defines the event type sent when the protection or deletion time of a snapshot changes.
+	SnapshotUpdateEvent = "snapshot_update"
+
 	// BranchCreateEvent defines the branch create event type.
 	BranchCreateEvent = "branch_create"
 
 	// BranchDeleteEvent defines the branch delete event type.
 	BranchDeleteEvent = "branch_delete"
+
+	// BranchUpdateEvent defines the event type sent when the protection or deletion time of a branch changes.
+	BranchUpdateEvent = "branch_update"
+
+	// FullRefreshStartEvent defines the event type sent when a full refresh of a pool starts.
+	FullRefreshStartEvent = "full_refresh_start"
+
+	// RetrievalJobFinishEvent defines the event type sent when a retrieval job (logicalDump, logicalRestore,
+	// physicalRestore, logicalSnapshot, physicalSnapshot) finishes successfully.
+	RetrievalJobFinishEvent = "retrieval_job_finish"
+
+	// RetrievalJobFailEvent defines the event type sent when a retrieval job fails.
+	RetrievalJobFailEvent = "retrieval_job_fail"
+
+	// DataStateReadyEvent defines the event type sent when a new data state is available for cloning.
+	DataStateReadyEvent = "data_state_ready"
+
+	// PoolRotateEvent defines the event type sent when another pool becomes the active one after a full refresh.
+	PoolRotateEvent = "pool_rotate"
+
+	// RetrievalStageChangeEvent defines the event type sent when the retrieval status changes or a retrieval job starts.
+	RetrievalStageChangeEvent = "retrieval_stage_change"
 )
 
 // EventTyper unifies webhook events.
@@ -34,6 +61,8 @@ type EventTyper interface {
 type BasicEvent struct {
 	EventType string `json:"event_type"`
 	EntityID  string `json:"entity_id"`
+	// TraceID links the event to the trace of the operation that caused it, if tracing is enabled.
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 // GetType returns type of the event.
@@ -41,6 +70,11 @@ func (e BasicEvent) GetType() string {
 	return e.EventType
 }
 
+// GetEntityID returns the ID of the object of the event.
+func (e BasicEvent) GetEntityID() string {
+	return e.EntityID
+}
+
 // CloneEvent defines clone webhook events payload.
 type CloneEvent struct {
 	BasicEvent
@@ -52,6 +86,14 @@ type CloneEvent struct {
 	ContainerName string `json:"container_name,omitempty"`
 }
 
+// CloneStatusEvent defines clone status change events payload.
+type CloneStatusEvent struct {
+	BasicEvent
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+	Message        string `json:"message,omitempty"`
+}
+
 // CloneProtectionEvent defines clone protection-related webhook events payload.
 type CloneProtectionEvent struct {
 	BasicEvent
@@ -63,3 +105,16 @@ type CloneProtectionEvent struct {
 	ProtectedTill  string `json:"protected_till,omitempty"`
 	ExpiresInHours int    `json:"expires_in_hours,omitempty"`
 }
+
+// RetrievalEvent defines data retrieval webhook events payload. EntityID holds the pool name.
+// For retrieval stage changes, Status holds the retrieval status and Job the running job, if any.
+type RetrievalEvent struct {
+	BasicEvent
+	Job             string  `json:"job,omitempty"`
+	Pool            string  `json:"pool"`
+	Status          string  `json:"status,omitempty"`
+	PreviousPool    string  `json:"previous_pool,omitempty"`
+	DataStateAt     string  `json:"data_state_at,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	Error           string  `json:"error,omitempty"`
+}
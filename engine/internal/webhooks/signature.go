@@ -0,0 +1,57 @@
+/*
+2026 © Postgres.ai
+*/
+
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DLEWebhookSignatureHeader defines the HTTP header carrying the HMAC-SHA256 signature of the request.
+	DLEWebhookSignatureHeader = "DBLab-Webhook-Signature"
+
+	// DLEWebhookTimestampHeader defines the HTTP header carrying the Unix time the request was signed at.
+	DLEWebhookTimestampHeader = "DBLab-Webhook-Timestamp"
+
+	// DLEWebhookDeliveryHeader defines the HTTP header carrying the delivery ID, which stays the same across retries.
+	DLEWebhookDeliveryHeader = "DBLab-Webhook-Delivery"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign computes the signature sent in the DBLab-Webhook-Signature header: the hex-encoded HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the webhook secret, prefixed with "sha256=".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks the signature and timestamp headers of a received webhook request.
+// Requests signed more than tolerance ago are rejected to prevent replays; zero tolerance disables the check.
+func VerifySignature(secret, timestamp, signature string, body []byte, tolerance time.Duration) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	if tolerance > 0 && time.Since(time.Unix(ts, 0)).Abs() > tolerance {
+		return false
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
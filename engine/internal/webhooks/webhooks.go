@@ -9,18 +9,55 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
 
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/log"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/util"
 )
 
 const (
 	// DLEWebhookTokenHeader defines the HTTP header name to send secret with the webhook request.
+	//
+	// Deprecated: the secret is sent in plain text only for hooks with sendToken enabled;
+	// verify the DBLab-Webhook-Signature header instead.
 	DLEWebhookTokenHeader = "DBLab-Webhook-Token"
+
+	deliveriesFile        = "webhook_deliveries.json"
+	dispatchInterval      = time.Second
+	maxLoggedBodyBytes    = 4096
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = 10 * time.Second
+	defaultMaxBackoff     = 10 * time.Minute
+	defaultQueueSize      = 1000
+	defaultHistorySize    = 200
+	defaultTimeout        = 10 * time.Second
 )
 
 // Config defines webhooks configuration.
 type Config struct {
 	Hooks []Hook `yaml:"hooks"`
+	Retry Retry  `yaml:"delivery"`
+}
+
+// Retry defines how webhook deliveries are retried and how many of them are kept.
+// Zero values fall back to defaults.
+type Retry struct {
+	// MaxAttempts caps the number of attempts per delivery, including the first one.
+	MaxAttempts uint `yaml:"maxAttempts"`
+	// InitialBackoffSeconds is the delay before the first retry; it doubles with every further retry.
+	InitialBackoffSeconds uint `yaml:"initialBackoffSeconds"`
+	// MaxBackoffSeconds caps the delay between retries.
+	MaxBackoffSeconds uint `yaml:"maxBackoffSeconds"`
+	// TimeoutSeconds bounds a single webhook request.
+	TimeoutSeconds uint `yaml:"timeoutSeconds"`
+	// QueueSize caps the number of pending deliveries; new events are dropped when the queue is full.
+	QueueSize uint `yaml:"queueSize"`
+	// HistorySize caps the number of finished deliveries kept for inspection and replay.
+	HistorySize uint `yaml:"historySize"`
 }
 
 // Hook defines structure of the webhook configuration.
@@ -28,23 +65,108 @@ type Hook struct {
 	URL     string   `yaml:"url"`
 	Secret  string   `yaml:"secret"`
 	Trigger []string `yaml:"trigger"`
+	// SendToken additionally sends the secret in the deprecated DBLab-Webhook-Token header
+	// for receivers that do not verify signatures yet.
+	SendToken bool `yaml:"sendToken"`
 }
 
-// Service listens events and performs webhooks requests.
+// retrySettings holds the effective retry configuration.
+type retrySettings struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	timeout        time.Duration
+	queueSize      int
+	historySize    int
+}
+
+func newRetrySettings(cfg Retry) retrySettings {
+	settings := retrySettings{
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		timeout:        defaultTimeout,
+		queueSize:      defaultQueueSize,
+		historySize:    defaultHistorySize,
+	}
+
+	if cfg.MaxAttempts > 0 {
+		settings.maxAttempts = int(cfg.MaxAttempts)
+	}
+
+	if cfg.InitialBackoffSeconds > 0 {
+		settings.initialBackoff = time.Duration(cfg.InitialBackoffSeconds) * time.Second
+	}
+
+	if cfg.MaxBackoffSeconds > 0 {
+		settings.maxBackoff = time.Duration(cfg.MaxBackoffSeconds) * time.Second
+	}
+
+	if cfg.TimeoutSeconds > 0 {
+		settings.timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+
+	if cfg.QueueSize > 0 {
+		settings.queueSize = int(cfg.QueueSize)
+	}
+
+	if cfg.HistorySize > 0 {
+		settings.historySize = int(cfg.HistorySize)
+	}
+
+	return settings
+}
+
+// backoff returns the delay before the next attempt after the given number of failed attempts.
+func (rs retrySettings) backoff(attempts int) time.Duration {
+	delay := rs.initialBackoff
+
+	for i := 1; i < attempts && delay < rs.maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, rs.maxBackoff)
+}
+
+// Service listens events and performs webhooks requests. Deliveries are queued persistently,
+// signed with the hook secret, and retried with exponential backoff.
 type Service struct {
 	client        *http.Client
+	mu            sync.RWMutex
 	hooksRegistry map[string][]Hook
+	settings      retrySettings
+	store         *deliveryStore
 	eventCh       <-chan EventTyper
+	wakeCh        chan struct{}
 }
 
 // NewService creates a new Webhook Service.
 func NewService(cfg *Config, eventCh <-chan EventTyper) *Service {
+	filePath, err := util.GetMetaPath(deliveriesFile)
+	if err != nil {
+		log.Err("failed to get path of webhook deliveries; deliveries will not survive restarts:", err)
+
+		filePath = ""
+	}
+
+	return newService(cfg, eventCh, filePath)
+}
+
+func newService(cfg *Config, eventCh <-chan EventTyper, filePath string) *Service {
+	settings := newRetrySettings(cfg.Retry)
+
 	whs := &Service{
 		client: &http.Client{
 			Transport: &http.Transport{},
 		},
 		hooksRegistry: make(map[string][]Hook),
+		store:         newDeliveryStore(filePath, settings.queueSize, settings.historySize),
 		eventCh:       eventCh,
+		wakeCh:        make(chan struct{}, 1),
+	}
+
+	if err := whs.store.load(); err != nil {
+		log.Err(err)
 	}
 
 	whs.Reload(cfg)
@@ -54,7 +176,7 @@ func NewService(cfg *Config, eventCh <-chan EventTyper) *Service {
 
 // Reload reloads Webhook Service configuration.
 func (s *Service) Reload(cfg *Config) {
-	s.hooksRegistry = make(map[string][]Hook)
+	hooksRegistry := make(map[string][]Hook)
 
 	for _, hook := range cfg.Hooks {
 		if err := validateURL(hook.URL); err != nil {
@@ -63,11 +185,20 @@ func (s *Service) Reload(cfg *Config) {
 		}
 
 		for _, event := range hook.Trigger {
-			s.hooksRegistry[event] = append(s.hooksRegistry[event], hook)
+			hooksRegistry[event] = append(hooksRegistry[event], hook)
 		}
 	}
 
-	log.Dbg("Registered webhooks", s.hooksRegistry)
+	settings := newRetrySettings(cfg.Retry)
+
+	s.mu.Lock()
+	s.hooksRegistry = hooksRegistry
+	s.settings = settings
+	s.mu.Unlock()
+
+	s.store.setLimits(settings.queueSize, settings.historySize)
+
+	log.Dbg("Registered webhooks", hooksRegistry)
 }
 
 func validateURL(hookURL string) error {
@@ -89,8 +220,13 @@ func validateURL(hookURL string) error {
 
 // Run starts webhook listener.
 func (s *Service) Run(ctx context.Context) {
+	go s.dispatch(ctx)
+
 	for whEvent := range s.eventCh {
+		s.mu.RLock()
 		hooks, ok := s.hooksRegistry[whEvent.GetType()]
+		s.mu.RUnlock()
+
 		if !ok {
 			log.Dbg("Skipped unknown hook: ", whEvent.GetType())
 
@@ -99,51 +235,195 @@ func (s *Service) Run(ctx context.Context) {
 
 		log.Dbg("Trigger event:", whEvent)
 
-		for _, hook := range hooks {
-			go s.triggerWebhook(ctx, hook, whEvent)
-		}
+		s.enqueue(whEvent, hooks)
 	}
 }
 
-func (s *Service) triggerWebhook(ctx context.Context, hook Hook, whEvent EventTyper) {
-	log.Msg("Webhook request: ", hook.URL)
+// Deliveries returns recorded deliveries with the given status, or all of them for an empty status, newest first.
+func (s *Service) Deliveries(status string) []Delivery {
+	return s.store.list(status)
+}
+
+// Replay schedules a failed delivery to be sent again.
+func (s *Service) Replay(deliveryID string) (*Delivery, error) {
+	delivery, err := s.store.replay(deliveryID, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := s.makeRequest(ctx, hook, whEvent)
+	s.wake()
 
+	return &delivery, nil
+}
+
+func (s *Service) enqueue(whEvent EventTyper, hooks []Hook) {
+	payload, err := json.Marshal(whEvent)
 	if err != nil {
-		log.Err("webhook error:", err)
+		log.Err("failed to encode webhook payload:", err)
 		return
 	}
 
+	log.Dbg("Webhook payload: ", string(payload))
+
+	for _, hook := range hooks {
+		delivery := &Delivery{
+			ID:        xid.New().String(),
+			EventType: whEvent.GetType(),
+			URL:       hook.URL,
+			Payload:   payload,
+			Status:    DeliveryPending,
+			CreatedAt: time.Now(),
+		}
+
+		if err := s.store.enqueue(delivery); err != nil {
+			log.Err(fmt.Sprintf("failed to queue webhook %s for %s: %v", delivery.EventType, hook.URL, err))
+		}
+	}
+
+	s.wake()
+}
+
+func (s *Service) wake() {
+	select {
+	case s.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch sends due deliveries until the context is canceled.
+func (s *Service) dispatch(ctx context.Context) {
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, delivery := range s.store.due(time.Now()) {
+			go s.deliver(ctx, delivery)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+
+		case <-s.wakeCh:
+		}
+	}
+}
+
+// deliver makes one attempt of the delivery and records its outcome.
+func (s *Service) deliver(ctx context.Context, delivery Delivery) {
+	s.mu.RLock()
+	settings := s.settings
+	hook, ok := s.findHookLocked(delivery.EventType, delivery.URL)
+	s.mu.RUnlock()
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.NextAttemptAt = nil
+
+	var retryable bool
+
+	if ok {
+		log.Msg("Webhook request: ", hook.URL)
+
+		requestCtx, cancel := context.WithTimeout(ctx, settings.timeout)
+		delivery.StatusCode, delivery.Error = s.send(requestCtx, hook, delivery)
+
+		cancel()
+
+		retryable = delivery.Error != "" && isRetryable(delivery.StatusCode)
+	} else {
+		delivery.StatusCode = 0
+		delivery.Error = "webhook is no longer configured"
+	}
+
+	switch {
+	case delivery.Error == "":
+		delivery.Status = DeliveryDelivered
+
+	case retryable && delivery.Attempts < settings.maxAttempts:
+		next := now.Add(settings.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+
+		log.Warn(fmt.Sprintf("webhook %s to %s failed (attempt %d of %d), retrying at %s: %s", delivery.ID,
+			delivery.URL, delivery.Attempts, settings.maxAttempts, next.Format(time.RFC3339), delivery.Error))
+
+	default:
+		delivery.Status = DeliveryFailed
+
+		log.Err(fmt.Sprintf("webhook %s to %s failed after %d attempt(s): %s",
+			delivery.ID, delivery.URL, delivery.Attempts, delivery.Error))
+	}
+
+	if err := s.store.update(delivery); err != nil {
+		log.Err(err)
+	}
+}
+
+func (s *Service) findHookLocked(eventType, hookURL string) (Hook, bool) {
+	for _, hook := range s.hooksRegistry[eventType] {
+		if hook.URL == hookURL {
+			return hook, true
+		}
+	}
+
+	return Hook{}, false
+}
+
+// send makes the webhook request and returns the response status code and an error message
+// when the request failed or the receiver did not respond with a 2xx status.
+func (s *Service) send(ctx context.Context, hook Hook, delivery Delivery) (int, string) {
+	resp, err := s.makeRequest(ctx, hook, delivery)
+	if err != nil {
+		return 0, err.Error()
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
 	log.Dbg("Webhook status code: ", resp.StatusCode)
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBodyBytes))
 	if err != nil {
-		log.Err("webhook error:", err)
-		return
+		log.Dbg("failed to read webhook response:", err)
 	}
 
 	log.Dbg("Webhook response: ", string(body))
-}
 
-func (s *Service) makeRequest(ctx context.Context, hook Hook, whEvent EventTyper) (*http.Response, error) {
-	payload, err := json.Marshal(whEvent)
-	if err != nil {
-		return nil, err
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Sprintf("unexpected status code %d", resp.StatusCode)
 	}
 
-	log.Dbg("Webhook payload: ", string(payload))
+	return resp.StatusCode, ""
+}
+
+// isRetryable reports whether a failed request may succeed later: network errors, timeouts,
+// throttling, and server errors are retried, while other client errors are not.
+func isRetryable(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+func (s *Service) makeRequest(ctx context.Context, hook Hook, delivery Delivery) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
 	if err != nil {
 		return nil, err
 	}
 
-	if hook.Secret != "" {
-		req.Header.Add(DLEWebhookTokenHeader, hook.Secret)
-	}
+	timestamp := time.Now().Unix()
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DLEWebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(DLEWebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+
+	if hook.Secret != "" {
+		req.Header.Set(DLEWebhookSignatureHeader, Sign(hook.Secret, timestamp, delivery.Payload))
+
+		if hook.SendToken {
+			req.Header.Add(DLEWebhookTokenHeader, hook.Secret)
+		}
+	}
 
 	return s.client.Do(req)
 }
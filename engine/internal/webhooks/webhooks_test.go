@@ -0,0 +1,167 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"event_type":"clone_create"}`)
+	now := time.Now().Unix()
+	signature := Sign("secret", now, body)
+
+	assert.True(t, VerifySignature("secret", itoa(now), signature, body, time.Minute))
+	assert.False(t, VerifySignature("other", itoa(now), signature, body, time.Minute))
+	assert.False(t, VerifySignature("secret", itoa(now), signature, []byte(`{}`), time.Minute))
+	assert.False(t, VerifySignature("secret", itoa(now-3600), Sign("secret", now-3600, body), body, time.Minute))
+	assert.True(t, VerifySignature("secret", itoa(now-3600), Sign("secret", now-3600, body), body, 0))
+}
+
+func TestBackoff(t *testing.T) {
+	settings := retrySettings{initialBackoff: time.Second, maxBackoff: 5 * time.Second}
+
+	assert.Equal(t, time.Second, settings.backoff(1))
+	assert.Equal(t, 2*time.Second, settings.backoff(2))
+	assert.Equal(t, 4*time.Second, settings.backoff(3))
+	assert.Equal(t, 5*time.Second, settings.backoff(4))
+	assert.Equal(t, 5*time.Second, settings.backoff(40))
+}
+
+func TestDeliveryRetries(t *testing.T) {
+	var requests atomic.Int32
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.True(t, VerifySignature("secret", r.Header.Get(DLEWebhookTimestampHeader),
+			r.Header.Get(DLEWebhookSignatureHeader), body, time.Minute))
+		assert.NotEmpty(t, r.Header.Get(DLEWebhookDeliveryHeader))
+		assert.Empty(t, r.Header.Get(DLEWebhookTokenHeader))
+
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	s := newTestService(t, receiver.URL, path.Join(t.TempDir(), deliveriesFile))
+	s.enqueue(BasicEvent{EventType: CloneCreatedEvent, EntityID: "clone1"}, s.hooksRegistry[CloneCreatedEvent])
+
+	deliverDue(t, s)
+
+	pending := s.Deliveries(DeliveryPending)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 1, pending[0].Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, pending[0].StatusCode)
+	require.NotNil(t, pending[0].NextAttemptAt)
+
+	assert.Empty(t, s.store.due(time.Now()), "the retry must wait for the backoff")
+
+	deliverDueAt(t, s, *pending[0].NextAttemptAt)
+
+	delivered := s.Deliveries(DeliveryDelivered)
+	require.Len(t, delivered, 1)
+	assert.Equal(t, 2, delivered[0].Attempts)
+	assert.Equal(t, http.StatusOK, delivered[0].StatusCode)
+	assert.Empty(t, delivered[0].Error)
+}
+
+func TestDeliveryFailureAndReplay(t *testing.T) {
+	var status atomic.Int32
+
+	status.Store(http.StatusBadRequest)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer receiver.Close()
+
+	filePath := path.Join(t.TempDir(), deliveriesFile)
+
+	s := newTestService(t, receiver.URL, filePath)
+	s.enqueue(BasicEvent{EventType: CloneCreatedEvent, EntityID: "clone1"}, s.hooksRegistry[CloneCreatedEvent])
+
+	deliverDue(t, s)
+
+	failed := s.Deliveries(DeliveryFailed)
+	require.Len(t, failed, 1, "client errors must not be retried")
+	assert.Equal(t, 1, failed[0].Attempts)
+
+	// Deliveries survive a restart.
+	restarted := newTestService(t, receiver.URL, filePath)
+	require.Len(t, restarted.Deliveries(DeliveryFailed), 1)
+
+	_, err := restarted.Replay("unknown")
+	assert.ErrorIs(t, err, ErrDeliveryNotFound)
+
+	replayed, err := restarted.Replay(failed[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, DeliveryPending, replayed.Status)
+
+	_, err = restarted.Replay(failed[0].ID)
+	assert.ErrorIs(t, err, ErrDeliveryNotFailed)
+
+	status.Store(http.StatusNoContent)
+	deliverDue(t, restarted)
+
+	assert.Len(t, restarted.Deliveries(DeliveryDelivered), 1)
+}
+
+func TestDeliveryQueueLimits(t *testing.T) {
+	store := newDeliveryStore("", 1, 1)
+
+	require.NoError(t, store.enqueue(&Delivery{ID: "1", Status: DeliveryPending}))
+	assert.ErrorIs(t, store.enqueue(&Delivery{ID: "2", Status: DeliveryPending}), errQueueFull)
+
+	require.NoError(t, store.update(Delivery{ID: "1", Status: DeliveryDelivered}))
+	require.NoError(t, store.enqueue(&Delivery{ID: "2", Status: DeliveryPending}))
+	require.NoError(t, store.update(Delivery{ID: "2", Status: DeliveryFailed}))
+
+	deliveries := store.list("")
+	require.Len(t, deliveries, 1, "history must be trimmed to its limit")
+	assert.Equal(t, "2", deliveries[0].ID)
+}
+
+func newTestService(t *testing.T, hookURL, filePath string) *Service {
+	t.Helper()
+
+	return newService(&Config{
+		Hooks: []Hook{{URL: hookURL, Secret: "secret", Trigger: []string{CloneCreatedEvent}}},
+		Retry: Retry{MaxAttempts: 3, InitialBackoffSeconds: 60},
+	}, nil, filePath)
+}
+
+func deliverDue(t *testing.T, s *Service) {
+	t.Helper()
+
+	deliverDueAt(t, s, time.Now())
+}
+
+func deliverDueAt(t *testing.T, s *Service, now time.Time) {
+	t.Helper()
+
+	due := s.store.due(now)
+	require.NotEmpty(t, due)
+
+	for _, delivery := range due {
+		s.deliver(context.Background(), delivery)
+	}
+}
+
+func itoa(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
@@ -0,0 +1,68 @@
+/*
+2026 © Postgres.ai
+*/
+
+package dblabapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// ListAuditEntries returns entries of the audit log (GET /admin/audit) matching the filter,
+// the most recent first.
+func (c *Client) ListAuditEntries(ctx context.Context, listRequest types.AuditListRequest) ([]models.AuditEntry, error) {
+	u := c.URL("/admin/audit")
+
+	values := url.Values{}
+
+	if !listRequest.Since.IsZero() {
+		values.Set("since", listRequest.Since.Format(time.RFC3339))
+	}
+
+	if !listRequest.Until.IsZero() {
+		values.Set("until", listRequest.Until.Format(time.RFC3339))
+	}
+
+	if listRequest.Actor != "" {
+		values.Set("actor", listRequest.Actor)
+	}
+
+	if listRequest.Action != "" {
+		values.Set("action", listRequest.Action)
+	}
+
+	if listRequest.Limit > 0 {
+		values.Set("limit", strconv.Itoa(listRequest.Limit))
+	}
+
+	u.RawQuery = values.Encode()
+
+	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make a request: %w", err)
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get response: %w", err)
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	var entries []models.AuditEntry
+
+	if err := json.NewDecoder(response.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return entries, nil
+}
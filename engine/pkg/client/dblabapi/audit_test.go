@@ -0,0 +1,59 @@
+package dblabapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/client/dblabapi/types"
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestClientListAuditEntries(t *testing.T) {
+	expected := []models.AuditEntry{
+		{
+			Time:       time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+			Actor:      "alice@example.com",
+			Action:     "clone.reset",
+			Target:     "clone1",
+			Outcome:    models.AuditOutcomeSuccess,
+			StatusCode: http.StatusOK,
+		},
+	}
+
+	c := newConfigTestClient(t, func(req *http.Request) *http.Response {
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, "/admin/audit", req.URL.Path)
+		assert.Equal(t, "2026-03-01T00:00:00Z", req.URL.Query().Get("since"))
+		assert.Empty(t, req.URL.Query().Get("until"))
+		assert.Equal(t, "alice@example.com", req.URL.Query().Get("actor"))
+		assert.Equal(t, "clone", req.URL.Query().Get("action"))
+		assert.Equal(t, "10", req.URL.Query().Get("limit"))
+		assert.Equal(t, "testVerify", req.Header.Get(verificationHeader))
+
+		return jsonResponse(t, http.StatusOK, expected)
+	})
+
+	entries, err := c.ListAuditEntries(context.Background(), types.AuditListRequest{
+		Since:  time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Actor:  "alice@example.com",
+		Action: "clone",
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, entries)
+}
+
+func TestClientListAuditEntriesError(t *testing.T) {
+	c := newConfigTestClient(t, func(*http.Request) *http.Response {
+		return jsonResponse(t, http.StatusBadRequest, models.Error{Code: "BAD_REQUEST", Message: "audit log is not available"})
+	})
+
+	_, err := c.ListAuditEntries(context.Background(), types.AuditListRequest{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "audit log is not available")
+}
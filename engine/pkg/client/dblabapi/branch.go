@@ -127,6 +127,54 @@ func (c *Client) CreateSnapshotForBranch(
 	return snapshot, nil
 }
 
+// RebaseBranch replays the recorded migrations of the branch on top of another branch and waits until
+// the rebase is committed.
+func (c *Client) RebaseBranch(ctx context.Context, branchName string,
+	rebaseRequest types.BranchRebaseRequest) (*types.SnapshotResponse, error) {
+	operation, err := c.RebaseBranchAsync(ctx, branchName, rebaseRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, err = c.WaitOperation(ctx, operation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebase branch %s: %w", branchName, err)
+	}
+
+	return &types.SnapshotResponse{SnapshotID: operation.Result}, nil
+}
+
+// RebaseBranchAsync starts the rebase of the branch and returns the operation that follows it.
+func (c *Client) RebaseBranchAsync(ctx context.Context, branchName string,
+	rebaseRequest types.BranchRebaseRequest) (*models.Operation, error) {
+	u := c.URL(fmt.Sprintf("/branch/%s/rebase", branchName))
+
+	body := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(body).Encode(rebaseRequest); err != nil {
+		return nil, fmt.Errorf("failed to encode BranchRebaseRequest: %w", err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make a request: %w", err)
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get response: %w", err)
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	var operation models.Operation
+
+	if err := json.NewDecoder(response.Body).Decode(&operation); err != nil {
+		return nil, fmt.Errorf("failed to get response: %w", err)
+	}
+
+	return &operation, nil
+}
+
 // BranchLog provides snapshot list for branch.
 func (c *Client) BranchLog(ctx context.Context, logRequest types.LogRequest) ([]models.SnapshotDetails, error) {
 	u := c.URL(fmt.Sprintf("/branch/%s/log", logRequest.BranchName))
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -52,3 +53,33 @@ func TestClientUpdateBranch(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, updated.Protected)
 }
+
+func TestClientRebaseBranch(t *testing.T) {
+	c := newConfigTestClient(t, func(r *http.Request) *http.Response {
+		if r.Method == http.MethodGet {
+			assert.Equal(t, "/operations/op1", r.URL.Path)
+
+			return jsonResponse(t, http.StatusOK, models.Operation{
+				ID: "op1", State: models.OperationSucceeded, Result: "pool/branch/feature/c1/r0@20260101000000",
+			})
+		}
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Contains(t, r.URL.String(), "/branch/feature/rebase")
+
+		requestBody, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		defer func() { _ = r.Body.Close() }()
+
+		rebaseRequest := types.BranchRebaseRequest{}
+		require.NoError(t, json.Unmarshal(requestBody, &rebaseRequest))
+		assert.Equal(t, "main", rebaseRequest.Onto)
+
+		return jsonResponse(t, http.StatusAccepted, models.Operation{ID: "op1", State: models.OperationRunning})
+	})
+	c.pollingInterval = time.Millisecond
+
+	snapshot, err := c.RebaseBranch(context.Background(), "feature", types.BranchRebaseRequest{Onto: "main"})
+	require.NoError(t, err)
+	assert.Equal(t, "pool/branch/feature/c1/r0@20260101000000", snapshot.SnapshotID)
+}
@@ -56,6 +56,45 @@ func (c *Client) ListClonesRaw(ctx context.Context) (io.ReadCloser, error) {
 	return response.Body, nil
 }
 
+// ListOwnClones provides a list of Database Lab clones bound to the user of the client token.
+func (c *Client) ListOwnClones(ctx context.Context) ([]*models.Clone, error) {
+	body, err := c.ListOwnClonesRaw(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = body.Close() }()
+
+	var clones []*models.Clone
+
+	if err := json.NewDecoder(body).Decode(&clones); err != nil {
+		return nil, errors.Wrap(err, "failed to decode a response body")
+	}
+
+	return clones, nil
+}
+
+// ListOwnClonesRaw provides a raw list of Database Lab clones bound to the user of the client token.
+func (c *Client) ListOwnClonesRaw(ctx context.Context) (io.ReadCloser, error) {
+	u := c.URL("/clones")
+
+	values := url.Values{}
+	values.Add("mine", "true")
+	u.RawQuery = values.Encode()
+
+	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	return response.Body, nil
+}
+
 // GetClone returns info about a Database Lab clone.
 func (c *Client) GetClone(ctx context.Context, cloneID string) (*models.Clone, error) {
 	body, err := c.GetCloneRaw(ctx, cloneID)
@@ -190,6 +229,12 @@ func (c *Client) UpdateClone(ctx context.Context, cloneID string, updateRequest
 	return patchJSON[models.Clone](ctx, c, fmt.Sprintf("/clone/%s", cloneID), updateRequest)
 }
 
+// UpdateCloneConfig updates user-defined Postgres settings of a running clone.
+func (c *Client) UpdateCloneConfig(ctx context.Context, cloneID string,
+	configRequest types.CloneConfigUpdateRequest) (*models.CloneConfigUpdate, error) {
+	return patchJSON[models.CloneConfigUpdate](ctx, c, fmt.Sprintf("/clone/%s/config", cloneID), configRequest)
+}
+
 // patchJSON encodes payload, sends it as a PATCH request to path, and decodes the
 // response body into a new value of T. Shared by the Update* client methods.
 func patchJSON[T any](ctx context.Context, c *Client, path string, payload any) (*T, error) {
@@ -219,6 +264,40 @@ func patchJSON[T any](ctx context.Context, c *Client, path string, payload any)
 	return &result, nil
 }
 
+// HibernateClone stops the container of a Database Lab clone, keeping its data and port.
+func (c *Client) HibernateClone(ctx context.Context, cloneID string) (*models.Clone, error) {
+	return c.postCloneAction(ctx, cloneID, "hibernate")
+}
+
+// WakeClone starts the container of a hibernated Database Lab clone and waits until it accepts connections.
+func (c *Client) WakeClone(ctx context.Context, cloneID string) (*models.Clone, error) {
+	return c.postCloneAction(ctx, cloneID, "wake")
+}
+
+func (c *Client) postCloneAction(ctx context.Context, cloneID, action string) (*models.Clone, error) {
+	u := c.URL(fmt.Sprintf("/clone/%s/%s", cloneID, action))
+
+	request, err := http.NewRequest(http.MethodPost, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	var clone models.Clone
+
+	if err := json.NewDecoder(response.Body).Decode(&clone); err != nil {
+		return nil, errors.Wrap(err, "failed to decode a response body")
+	}
+
+	return &clone, nil
+}
+
 // ResetClone resets a Database Lab clone session.
 func (c *Client) ResetClone(ctx context.Context, cloneID string, params types.ResetCloneRequest) error {
 	u := c.URL(fmt.Sprintf("/clone/%s/reset", cloneID))
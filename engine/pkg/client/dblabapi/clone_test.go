@@ -67,6 +67,38 @@ func TestClientListClones(t *testing.T) {
 	require.EqualValues(t, expectedClones, cloneList)
 }
 
+func TestClientListOwnClones(t *testing.T) {
+	expectedClones := []*models.Clone{{
+		ID: "testCloneID",
+		DB: models.Database{OwnerUser: "jsmith@acme.io"},
+	}}
+
+	mockClient := NewTestClient(func(req *http.Request) *http.Response {
+		assert.Equal(t, "https://example.com/clones?mine=true", req.URL.String())
+
+		body, err := json.Marshal(expectedClones)
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBuffer(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "token",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	cloneList, err := c.ListOwnClones(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, expectedClones, cloneList)
+}
+
 func TestClientListClonesWithFailedRequest(t *testing.T) {
 	mockClient := NewTestClient(func(r *http.Request) *http.Response {
 		return &http.Response{
@@ -396,6 +428,93 @@ func TestClientUpdateClone(t *testing.T) {
 	assert.EqualValues(t, cloneModel, newClone)
 }
 
+func TestClientUpdateCloneConfig(t *testing.T) {
+	mockClient := NewTestClient(func(r *http.Request) *http.Response {
+		assert.Equal(t, "https://example.com/clone/testCloneID/config", r.URL.String())
+		assert.Equal(t, http.MethodPatch, r.Method)
+
+		requestBody, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		defer func() { _ = r.Body.Close() }()
+
+		assert.JSONEq(t, `{"postgresConfig":{"shared_buffers":"1GB","work_mem":null}}`, string(requestBody))
+
+		responseBody, err := json.Marshal(models.CloneConfigUpdate{
+			Applied:           models.ConfigApplyRestart,
+			RestartParameters: []string{"shared_buffers"},
+			PostgresConfig:    map[string]string{"shared_buffers": "1GB"},
+		})
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBuffer(responseBody)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "token",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	sharedBuffers := "1GB"
+
+	update, err := c.UpdateCloneConfig(context.Background(), "testCloneID", types.CloneConfigUpdateRequest{
+		PostgresConfig: map[string]*string{"shared_buffers": &sharedBuffers, "work_mem": nil},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.ConfigApplyRestart, update.Applied)
+	assert.Equal(t, []string{"shared_buffers"}, update.RestartParameters)
+	assert.Equal(t, map[string]string{"shared_buffers": "1GB"}, update.PostgresConfig)
+}
+
+func TestClientHibernateAndWakeClone(t *testing.T) {
+	status := models.StatusHibernated
+
+	mockClient := NewTestClient(func(r *http.Request) *http.Response {
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		switch r.URL.String() {
+		case "https://example.com/clone/testCloneID/hibernate":
+			status = models.StatusHibernated
+		case "https://example.com/clone/testCloneID/wake":
+			status = models.StatusOK
+		default:
+			t.Errorf("unexpected URL: %s", r.URL)
+		}
+
+		responseBody, err := json.Marshal(models.Clone{ID: "testCloneID", Status: models.Status{Code: status}})
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBuffer(responseBody)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{
+		Host:              "https://example.com/",
+		VerificationToken: "token",
+	})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	clone, err := c.HibernateClone(context.Background(), "testCloneID")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusHibernated, clone.Status.Code)
+
+	clone, err = c.WakeClone(context.Background(), "testCloneID")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusOK, clone.Status.Code)
+}
+
 func TestClientUpdateCloneWithFailedRequest(t *testing.T) {
 	mockClient := NewTestClient(func(req *http.Request) *http.Response {
 		errorBadRequest := models.Error{
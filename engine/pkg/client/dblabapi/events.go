@@ -0,0 +1,317 @@
+/*
+2026 © Postgres.ai
+*/
+
+package dblabapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	maxEventBytes          = 1 << 20
+	maxReconnectAttempts   = 5
+	reconnectDelay         = time.Second
+
+	// cloneStatusChangeEvent is the type of events sent when the status of a clone changes.
+	cloneStatusChangeEvent = "clone_status_change"
+)
+
+var errEventStreamClosed = errors.New("event stream closed by the server")
+
+// Subscription is a stream of engine events.
+type Subscription struct {
+	events chan models.Event
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+	err    error
+}
+
+// Events returns the channel of events. It is closed when the subscription is closed or the stream
+// cannot be resumed; Err reports the reason.
+func (s *Subscription) Events() <-chan models.Event {
+	return s.events
+}
+
+// Err returns the error that ended the stream, if any.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *Subscription) setErr(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// Subscribe opens the stream of engine events (GET /events). Events published after lastEventID are replayed
+// if the engine still keeps them, otherwise a resync event is sent first; zero means only new events.
+// The stream is resumed automatically if the connection fails.
+func (c *Client) Subscribe(ctx context.Context, lastEventID uint64) (*Subscription, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	body, err := c.openEventStream(ctx, lastEventID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	sub := &Subscription{
+		events: make(chan models.Event),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go c.streamEvents(ctx, sub, body, lastEventID)
+
+	return sub, nil
+}
+
+func (c *Client) streamEvents(ctx context.Context, sub *Subscription, body io.ReadCloser, lastEventID uint64) {
+	defer close(sub.done)
+	defer close(sub.events)
+
+	for {
+		err := readEventStream(body, func(event models.Event) bool {
+			lastEventID = event.ID
+
+			select {
+			case sub.events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+
+		_ = body.Close()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		body, err = c.reopenEventStream(ctx, lastEventID, err)
+		if err != nil {
+			if ctx.Err() == nil {
+				sub.setErr(err)
+			}
+
+			return
+		}
+	}
+}
+
+func (c *Client) reopenEventStream(ctx context.Context, lastEventID uint64, streamErr error) (io.ReadCloser, error) {
+	err := streamErr
+
+	for range maxReconnectAttempts {
+		timer := time.NewTimer(reconnectDelay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		body, openErr := c.openEventStream(ctx, lastEventID)
+		if openErr == nil {
+			return body, nil
+		}
+
+		err = openErr
+	}
+
+	return nil, fmt.Errorf("failed to resume event stream: %w", err)
+}
+
+// openEventStream connects to the event stream with a one-time token issued by the engine.
+func (c *Client) openEventStream(ctx context.Context, lastEventID uint64) (io.ReadCloser, error) {
+	token, err := c.webSocketsToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.URL("/events")
+
+	values := url.Values{}
+	values.Set("token", token)
+
+	if lastEventID != 0 {
+		values.Set("lastEventId", strconv.FormatUint(lastEventID, 10))
+	}
+
+	u.RawQuery = values.Encode()
+
+	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make a request: %w", err)
+	}
+
+	request.Header.Set("Accept", eventStreamContentType)
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get response: %w", err)
+	}
+
+	if !strings.HasPrefix(response.Header.Get("Content-Type"), eventStreamContentType) {
+		_ = response.Body.Close()
+		return nil, errors.New("event stream is not supported by the engine")
+	}
+
+	return response.Body, nil
+}
+
+func (c *Client) webSocketsToken(ctx context.Context) (string, error) {
+	request, err := http.NewRequest(http.MethodGet, c.URL("/admin/ws-auth").String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to make a request: %w", err)
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("failed to get access token of event stream: %w", err)
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	var token models.WSToken
+
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode access token of event stream: %w", err)
+	}
+
+	if token.Token == "" {
+		return "", errors.New("empty access token of event stream")
+	}
+
+	return token.Token, nil
+}
+
+// readEventStream parses server-sent events and passes them to the handler until it returns false
+// or the stream ends.
+func readEventStream(body io.Reader, handle func(models.Event) bool) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxEventBytes)
+
+	var data strings.Builder
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+
+			var event models.Event
+
+			if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+				return fmt.Errorf("failed to decode event: %w", err)
+			}
+
+			data.Reset()
+
+			if !handle(event) {
+				return nil
+			}
+
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// Comments, such as heartbeats, and the id and event fields, which are repeated in the data, are skipped.
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return errEventStreamClosed
+}
+
+// WaitCloneStatus waits on the event stream until the clone leaves the initial status and returns the clone.
+// If the stream ends, the clone status is polled instead.
+func (c *Client) WaitCloneStatus(ctx context.Context, sub *Subscription, cloneID string,
+	initialStatusCode models.StatusCode) (*models.Clone, error) {
+	var cancel context.CancelFunc
+
+	if _, ok := ctx.Deadline(); !ok {
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
+	}
+
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return c.watchCloneStatus(ctx, cloneID, initialStatusCode)
+			}
+
+			if !isCloneStatusChange(event, cloneID, initialStatusCode) {
+				continue
+			}
+
+			clone, err := c.GetClone(ctx, cloneID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get clone info: %w", err)
+			}
+
+			if clone.Status.Code != initialStatusCode {
+				return clone, nil
+			}
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// isCloneStatusChange checks if the event may report that the clone has left the initial status.
+func isCloneStatusChange(event models.Event, cloneID string, initialStatusCode models.StatusCode) bool {
+	if event.Type == models.EventResync {
+		return true
+	}
+
+	if event.Type != cloneStatusChangeEvent || event.EntityID != cloneID {
+		return false
+	}
+
+	var change struct {
+		Status models.StatusCode `json:"status"`
+	}
+
+	if err := json.Unmarshal(event.Data, &change); err != nil {
+		return true
+	}
+
+	return change.Status != initialStatusCode
+}
@@ -0,0 +1,173 @@
+package dblabapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// eventServer emulates the event stream of the engine. Each connection sends the given events and closes.
+type eventServer struct {
+	mu           sync.Mutex
+	connections  [][]models.Event
+	lastEventIDs []string
+	clone        models.Clone
+}
+
+func (s *eventServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/admin/ws-auth":
+		_ = json.NewEncoder(w).Encode(models.WSToken{Token: "wsToken"})
+
+	case "/events":
+		if r.URL.Query().Get("token") != "wsToken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(models.Error{Code: "UNAUTHORIZED"})
+
+			return
+		}
+
+		s.mu.Lock()
+		s.lastEventIDs = append(s.lastEventIDs, r.URL.Query().Get("lastEventId"))
+
+		var events []models.Event
+		if len(s.connections) > 0 {
+			events, s.connections = s.connections[0], s.connections[1:]
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, ": ping\n\n")
+
+		for _, event := range events {
+			data, _ := json.Marshal(event)
+			_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		}
+
+		if len(events) == 0 {
+			<-r.Context().Done()
+		}
+
+	case "/clone/clone1":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(s.clone)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func statusEvent(id uint64, cloneID string, status models.StatusCode) models.Event {
+	return models.Event{
+		ID:       id,
+		Type:     cloneStatusChangeEvent,
+		EntityID: cloneID,
+		Data:     json.RawMessage(fmt.Sprintf(`{"status":%q}`, status)),
+	}
+}
+
+func newEventTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(Options{Host: srv.URL, VerificationToken: "testVerify"})
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestClientSubscribeResumes(t *testing.T) {
+	server := &eventServer{connections: [][]models.Event{
+		{statusEvent(10, "clone1", models.StatusCreating), statusEvent(11, "clone1", models.StatusOK)},
+		{statusEvent(12, "clone2", models.StatusCreating)},
+	}}
+
+	c := newEventTestClient(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sub, err := c.Subscribe(ctx, 5)
+	require.NoError(t, err)
+
+	defer sub.Close()
+
+	var ids []uint64
+
+	for range 3 {
+		event := <-sub.Events()
+		ids = append(ids, event.ID)
+	}
+
+	assert.Equal(t, []uint64{10, 11, 12}, ids)
+
+	server.mu.Lock()
+	assert.Equal(t, []string{"5", "11"}, server.lastEventIDs)
+	server.mu.Unlock()
+}
+
+func TestClientSubscribeUnsupported(t *testing.T) {
+	c := newEventTestClient(t, http.NotFoundHandler())
+
+	_, err := c.Subscribe(context.Background(), 0)
+	require.Error(t, err)
+}
+
+func TestClientWaitCloneStatus(t *testing.T) {
+	server := &eventServer{
+		connections: [][]models.Event{{
+			statusEvent(1, "clone2", models.StatusOK),
+			statusEvent(2, "clone1", models.StatusCreating),
+			statusEvent(3, "clone1", models.StatusOK),
+		}},
+		clone: models.Clone{ID: "clone1", Status: models.Status{Code: models.StatusOK}},
+	}
+
+	c := newEventTestClient(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sub, err := c.Subscribe(ctx, 0)
+	require.NoError(t, err)
+
+	defer sub.Close()
+
+	clone, err := c.WaitCloneStatus(ctx, sub, "clone1", models.StatusCreating)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusOK, clone.Status.Code)
+}
+
+func TestReadEventStream(t *testing.T) {
+	stream := ": ping\n\n" +
+		"id: 1\nevent: snapshot_create\ndata: {\"id\":1,\"type\":\"snapshot_create\",\"entityId\":\"snapshot1\"}\n\n" +
+		"id: 2\nevent: resync\ndata: {\"id\":2,\n" +
+		"data: \"type\":\"resync\"}\n\n"
+
+	var events []models.Event
+
+	err := readEventStream(strings.NewReader(stream), func(event models.Event) bool {
+		events = append(events, event)
+		return true
+	})
+	assert.ErrorIs(t, err, errEventStreamClosed)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "snapshot1", events[0].EntityID)
+	assert.Equal(t, models.EventResync, events[1].Type)
+}
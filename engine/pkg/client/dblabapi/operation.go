@@ -0,0 +1,79 @@
+/*
+2026 © Postgres.ai
+*/
+
+package dblabapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+// GetOperation returns the state of an operation started by a mutating request.
+func (c *Client) GetOperation(ctx context.Context, operationID string) (*models.Operation, error) {
+	return c.requestOperation(ctx, http.MethodGet, fmt.Sprintf("/operations/%s", operationID))
+}
+
+// CancelOperation requests the cancellation of a running operation and returns its state.
+func (c *Client) CancelOperation(ctx context.Context, operationID string) (*models.Operation, error) {
+	return c.requestOperation(ctx, http.MethodPost, fmt.Sprintf("/operations/%s/cancel", operationID))
+}
+
+// WaitOperation polls the operation until it finishes and returns its final state.
+// An operation that has failed or has been canceled is reported as an error.
+func (c *Client) WaitOperation(ctx context.Context, operationID string) (*models.Operation, error) {
+	pollingTimer := time.NewTimer(c.pollingInterval)
+	defer pollingTimer.Stop()
+
+	for {
+		select {
+		case <-pollingTimer.C:
+			operation, err := c.GetOperation(ctx, operationID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get operation %s: %w", operationID, err)
+			}
+
+			if operation.IsFinished() {
+				if operation.State != models.OperationSucceeded {
+					return operation, fmt.Errorf("operation %s %s: %s", operationID, operation.State, operation.Error)
+				}
+
+				return operation, nil
+			}
+
+			pollingTimer.Reset(c.pollingInterval)
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) requestOperation(ctx context.Context, method, path string) (*models.Operation, error) {
+	u := c.URL(path)
+
+	request, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make a request: %w", err)
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get response: %w", err)
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	var operation models.Operation
+
+	if err := json.NewDecoder(response.Body).Decode(&operation); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &operation, nil
+}
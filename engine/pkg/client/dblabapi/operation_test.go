@@ -0,0 +1,79 @@
+package dblabapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.com/postgres-ai/database-lab/v3/pkg/models"
+)
+
+func TestClientGetOperation(t *testing.T) {
+	expected := models.Operation{
+		ID:         "op1",
+		Type:       "clone.create",
+		Target:     "clone1",
+		State:      models.OperationRunning,
+		Progress:   "starting Postgres",
+		Cancelable: true,
+		CreatedAt:  time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		UpdatedAt:  time.Date(2026, 3, 1, 10, 0, 5, 0, time.UTC),
+	}
+
+	c := newConfigTestClient(t, func(req *http.Request) *http.Response {
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, "/operations/op1", req.URL.Path)
+		assert.Equal(t, "testVerify", req.Header.Get(verificationHeader))
+
+		return jsonResponse(t, http.StatusOK, expected)
+	})
+
+	operation, err := c.GetOperation(context.Background(), "op1")
+	require.NoError(t, err)
+	assert.Equal(t, expected, *operation)
+}
+
+func TestClientCancelOperation(t *testing.T) {
+	c := newConfigTestClient(t, func(req *http.Request) *http.Response {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "/operations/op1/cancel", req.URL.Path)
+
+		return jsonResponse(t, http.StatusOK, models.Operation{ID: "op1", State: models.OperationRunning, Progress: "canceling"})
+	})
+
+	operation, err := c.CancelOperation(context.Background(), "op1")
+	require.NoError(t, err)
+	assert.Equal(t, "canceling", operation.Progress)
+}
+
+func TestClientCancelOperationError(t *testing.T) {
+	c := newConfigTestClient(t, func(*http.Request) *http.Response {
+		return jsonResponse(t, http.StatusBadRequest, models.Error{Code: "BAD_REQUEST", Message: "operation cannot be canceled"})
+	})
+
+	_, err := c.CancelOperation(context.Background(), "op1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "operation cannot be canceled")
+}
+
+func TestClientWaitOperation(t *testing.T) {
+	states := []models.OperationState{models.OperationRunning, models.OperationFailed}
+
+	c := newConfigTestClient(t, func(*http.Request) *http.Response {
+		state := states[0]
+		states = states[1:]
+
+		return jsonResponse(t, http.StatusOK, models.Operation{ID: "op1", State: state, Error: "failed to replay migration 1 of 1"})
+	})
+	c.pollingInterval = time.Millisecond
+
+	operation, err := c.WaitOperation(context.Background(), "op1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to replay migration 1 of 1")
+	assert.Equal(t, models.OperationFailed, operation.State)
+	assert.Empty(t, states)
+}
@@ -54,13 +54,128 @@ func (c *Client) ListSnapshotsRaw(ctx context.Context) (io.ReadCloser, error) {
 	return response.Body, nil
 }
 
+// SnapshotDiff compares the snapshot against another snapshot or clone.
+func (c *Client) SnapshotDiff(ctx context.Context, snapshotID string,
+	diffRequest types.SnapshotDiffRequest) (*models.SnapshotDiff, error) {
+	body, err := c.SnapshotDiffRaw(ctx, snapshotID, diffRequest, "json")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = body.Close() }()
+
+	var snapshotDiff *models.SnapshotDiff
+
+	if err := json.NewDecoder(body).Decode(&snapshotDiff); err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	return snapshotDiff, nil
+}
+
+// SnapshotDiffRaw compares the snapshot against another snapshot or clone and returns the diff
+// in the given format: "json" or "sql".
+func (c *Client) SnapshotDiffRaw(ctx context.Context, snapshotID string,
+	diffRequest types.SnapshotDiffRequest, format string) (io.ReadCloser, error) {
+	u := c.URL(fmt.Sprintf("/snapshot/%s/diff", snapshotID))
+
+	values := url.Values{}
+	values.Set("against", diffRequest.Against)
+	values.Set("format", format)
+
+	if diffRequest.DBName != "" {
+		values.Set("dbName", diffRequest.DBName)
+	}
+
+	if diffRequest.ExactCounts {
+		values.Set("exactCounts", "true")
+	}
+
+	u.RawQuery = values.Encode()
+
+	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	return response.Body, nil
+}
+
 // CreateSnapshot creates a new snapshot.
 func (c *Client) CreateSnapshot(ctx context.Context, snapshotRequest types.SnapshotCreateRequest) (*models.Snapshot, error) {
 	u := c.URL("/snapshot")
 
+	if snapshotRequest.IsPointInTime() {
+		return c.createPointInTimeSnapshot(ctx, snapshotRequest, u)
+	}
+
 	return c.createRequest(ctx, snapshotRequest, u)
 }
 
+// createPointInTimeSnapshot starts the recovery, which runs in the background, and waits until the recovered snapshot is committed.
+func (c *Client) createPointInTimeSnapshot(ctx context.Context, snapshotRequest types.SnapshotCreateRequest,
+	u *url.URL) (*models.Snapshot, error) {
+	body := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(body).Encode(snapshotRequest); err != nil {
+		return nil, errors.Wrap(err, "failed to encode SnapshotCreateRequest")
+	}
+
+	request, err := http.NewRequest(http.MethodPost, u.String(), body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	var operation *models.Operation
+
+	if err := json.NewDecoder(response.Body).Decode(&operation); err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	operation, err = c.WaitOperation(ctx, operation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover the data: %w", err)
+	}
+
+	return c.GetSnapshot(ctx, operation.Result)
+}
+
+// GetSnapshot returns the snapshot.
+func (c *Client) GetSnapshot(ctx context.Context, snapshotID string) (*models.Snapshot, error) {
+	u := c.URL(fmt.Sprintf("/snapshot/%s", snapshotID))
+
+	request, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make a request")
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	var snapshot *models.Snapshot
+
+	if err := json.NewDecoder(response.Body).Decode(&snapshot); err != nil {
+		return nil, errors.Wrap(err, "failed to get response")
+	}
+
+	return snapshot, nil
+}
+
 // CreateSnapshotFromClone creates a new snapshot from clone.
 func (c *Client) CreateSnapshotFromClone(
 	ctx context.Context,
@@ -115,3 +115,72 @@ func TestClientUpdateSnapshot(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, updated.Protected)
 }
+
+func TestClientCreatePointInTimeSnapshot(t *testing.T) {
+	snapshotID := "dblab/branch/pitr_16_B374D848/clone_pre_pitr_20261016150000/r0@20261016150000"
+
+	c := newConfigTestClient(t, func(req *http.Request) *http.Response {
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/snapshot":
+			createRequest := types.SnapshotCreateRequest{}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&createRequest))
+			assert.Equal(t, "16/B374D848", createRequest.RecoveryTargetLSN)
+
+			return jsonResponse(t, http.StatusAccepted, models.Operation{ID: "op1", State: models.OperationRunning})
+
+		case req.URL.Path == "/operations/op1":
+			return jsonResponse(t, http.StatusOK, models.Operation{ID: "op1", State: models.OperationSucceeded, Result: snapshotID})
+
+		case req.URL.Path == "/snapshot/"+snapshotID:
+			return jsonResponse(t, http.StatusOK, models.Snapshot{ID: snapshotID, Branch: "pitr_16_B374D848"})
+		}
+
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+
+		return nil
+	})
+	c.pollingInterval = time.Millisecond
+
+	snapshot, err := c.CreateSnapshot(context.Background(), types.SnapshotCreateRequest{RecoveryTargetLSN: "16/B374D848"})
+	require.NoError(t, err)
+	assert.Equal(t, snapshotID, snapshot.ID)
+}
+
+func TestClientSnapshotDiff(t *testing.T) {
+	expectedDiff := &models.SnapshotDiff{
+		Snapshot: "pool/branch/dev@20260101000000",
+		Against:  "pool@20251201000000",
+		DBName:   "postgres",
+		Changes: []models.SchemaChange{
+			{Kind: models.ObjectTable, Name: "public.t2", Action: models.ChangeAdded, After: "create table public.t2"},
+		},
+		Tables: []models.TableStatsDiff{},
+	}
+
+	mockClient := NewTestClient(func(req *http.Request) *http.Response {
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, "/snapshot/pool/branch/dev@20260101000000/diff", req.URL.Path)
+		assert.Equal(t, "pool@20251201000000", req.URL.Query().Get("against"))
+		assert.Equal(t, "json", req.URL.Query().Get("format"))
+		assert.Equal(t, "true", req.URL.Query().Get("exactCounts"))
+
+		body, err := json.Marshal(expectedDiff)
+		require.NoError(t, err)
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewBuffer(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	c, err := NewClient(Options{Host: "https://example.com/", VerificationToken: "testVerify"})
+	require.NoError(t, err)
+
+	c.client = mockClient
+
+	snapshotDiff, err := c.SnapshotDiff(context.Background(), expectedDiff.Snapshot,
+		types.SnapshotDiffRequest{Against: expectedDiff.Against, ExactCounts: true})
+	require.NoError(t, err)
+	assert.Equal(t, expectedDiff, snapshotDiff)
+}
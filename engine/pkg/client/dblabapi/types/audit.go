@@ -0,0 +1,17 @@
+/*
+2026 © Postgres.ai
+*/
+
+package types
+
+import "time"
+
+// AuditListRequest describes a filter of audit log entries.
+type AuditListRequest struct {
+	Since time.Time
+	Until time.Time
+	Actor string
+	// Action is an action, e.g. "clone.reset", or a resource whose actions are listed, e.g. "clone".
+	Action string
+	Limit  int
+}
@@ -16,6 +16,7 @@ type CloneCreateRequest struct {
 	Snapshot                  *SnapshotCloneFieldRequest `json:"snapshot"`
 	ExtraConf                 map[string]string          `json:"extra_conf"`
 	Branch                    string                     `json:"branch"`
+	Template                  string                     `json:"template,omitempty"`
 	Revision                  int                        `json:"-"`
 }
 
@@ -25,6 +26,12 @@ type CloneUpdateRequest struct {
 	ProtectionDurationMinutes *uint `json:"protectionDurationMinutes,omitempty"`
 }
 
+// CloneConfigUpdateRequest represents params of a request to update Postgres settings of a clone.
+// A null value resets the parameter.
+type CloneConfigUpdateRequest struct {
+	PostgresConfig map[string]*string `json:"postgresConfig"`
+}
+
 // SnapshotUpdateRequest represents params of a snapshot update request. Pointer fields
 // distinguish "unset" from a zero value; protection and scheduled deletion are mutually
 // exclusive (setting one clears the other).
@@ -68,6 +75,17 @@ type ResetCloneRequest struct {
 // SnapshotCreateRequest describes params for creating snapshot request.
 type SnapshotCreateRequest struct {
 	PoolName string `json:"poolName"`
+	// RecoveryTargetTime requests a point-in-time snapshot as of the time in the RFC 3339 format. Physical mode only.
+	RecoveryTargetTime string `json:"recoveryTargetTime,omitempty"`
+	// RecoveryTargetLSN requests a point-in-time snapshot as of the WAL location. Physical mode only.
+	RecoveryTargetLSN string `json:"recoveryTargetLSN,omitempty"`
+	// Branch defines the branch of the point-in-time snapshot. It is derived from the recovery target if empty.
+	Branch string `json:"branch,omitempty"`
+}
+
+// IsPointInTime checks if the request asks for a point-in-time snapshot.
+func (r SnapshotCreateRequest) IsPointInTime() bool {
+	return r.RecoveryTargetTime != "" || r.RecoveryTargetLSN != ""
 }
 
 // SnapshotDestroyRequest describes params for destroying snapshot request.
@@ -76,10 +94,31 @@ type SnapshotDestroyRequest struct {
 	Force      bool   `json:"force"`
 }
 
+// SnapshotDiffRequest describes params of a snapshot diff request.
+type SnapshotDiffRequest struct {
+	// Against is the ID of the snapshot or clone to compare with.
+	Against     string
+	DBName      string
+	ExactCounts bool
+}
+
 // SnapshotCloneCreateRequest describes params for creating snapshot request from clone.
 type SnapshotCloneCreateRequest struct {
 	CloneID string `json:"cloneID"`
 	Message string `json:"message"`
+	// Migration is the DDL/SQL applied on the clone since the previous commit. It is recorded
+	// with the snapshot so that a branch rebase can replay it on top of another branch;
+	// a branch with a commit lacking it cannot be rebased.
+	Migration string `json:"migration,omitempty"`
+}
+
+// BranchRebaseRequest describes params for rebasing a branch onto the head of another branch.
+type BranchRebaseRequest struct {
+	// Onto is the branch whose head becomes the new base; defaults to the main branch.
+	Onto string `json:"onto"`
+	// DBName is the database in which migrations are replayed; defaults to the management database.
+	DBName  string `json:"dbName"`
+	Message string `json:"message"`
 }
 
 // BranchCreateRequest describes params for creating branch request.
@@ -6,15 +6,19 @@
 package config
 
 import (
+	"gitlab.com/postgres-ai/database-lab/v3/internal/audit"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/cloning"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/diagnostic"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/embeddedui"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/observer"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/operations"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/pgrouter"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/platform"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/provision/pool"
 	retConfig "gitlab.com/postgres-ai/database-lab/v3/internal/retrieval/config"
 	srvCfg "gitlab.com/postgres-ai/database-lab/v3/internal/srv/config"
+	"gitlab.com/postgres-ai/database-lab/v3/internal/tracing"
 	"gitlab.com/postgres-ai/database-lab/v3/internal/webhooks"
 	"gitlab.com/postgres-ai/database-lab/v3/pkg/config/global"
 )
@@ -38,4 +42,8 @@ type Config struct {
 	EmbeddedUI  embeddedui.Config `yaml:"embeddedUI"`
 	Diagnostic  diagnostic.Config `yaml:"diagnostic"`
 	Webhooks    webhooks.Config   `yaml:"webhooks"`
+	Router      pgrouter.Config   `yaml:"router"`
+	Tracing     tracing.Config    `yaml:"tracing"`
+	Audit       audit.Config      `yaml:"audit"`
+	Operations  operations.Config `yaml:"operations"`
 }
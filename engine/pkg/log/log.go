@@ -6,11 +6,14 @@
 package log
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 var debugMode = true
@@ -122,6 +125,38 @@ func Errf(format string, v ...interface{}) {
 	printf("[ERROR] "+format, v...)
 }
 
+// MsgCtx outputs message with the ID of the trace carried by the context.
+func MsgCtx(ctx context.Context, v ...interface{}) {
+	printLine("[INFO]  " + traceField(ctx) + prepareMessage(v...))
+}
+
+// WarnCtx outputs a warning message with the ID of the trace carried by the context.
+func WarnCtx(ctx context.Context, v ...interface{}) {
+	printLine("[WARNING]  " + traceField(ctx) + prepareMessage(v...))
+}
+
+// DbgCtx outputs debug message with the ID of the trace carried by the context.
+func DbgCtx(ctx context.Context, v ...interface{}) {
+	if debugMode {
+		printLine("[DEBUG] " + traceField(ctx) + prepareMessage(v...))
+	}
+}
+
+// ErrCtx outputs error message with the ID of the trace carried by the context.
+func ErrCtx(ctx context.Context, v ...interface{}) {
+	printLine("[ERROR] " + traceField(ctx) + prepareMessage(v...))
+}
+
+// traceField formats the trace ID for log lines; it is empty if the context carries no trace.
+func traceField(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return "trace_id=" + spanContext.TraceID().String()
+}
+
 // Audit outputs messages for security audit.
 func Audit(v ...interface{}) {
 	printLine("[AUDIT] " + prepareMessage(v...))
@@ -0,0 +1,29 @@
+/*
+2026 © Postgres.ai
+*/
+
+package models
+
+import "time"
+
+// Outcomes of audited actions.
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEntry describes an action performed through the API: who did what to which object and how it ended.
+type AuditEntry struct {
+	Time        time.Time      `json:"time"`
+	Actor       string         `json:"actor"`
+	Role        string         `json:"role,omitempty"`
+	Action      string         `json:"action"`
+	Target      string         `json:"target,omitempty"`
+	Params      map[string]any `json:"params,omitempty"`
+	Outcome     string         `json:"outcome"`
+	StatusCode  int            `json:"statusCode"`
+	Error       string         `json:"error,omitempty"`
+	RemoteAddr  string         `json:"remoteAddr,omitempty"`
+	TraceID     string         `json:"traceId,omitempty"`
+	OperationID string         `json:"operationId,omitempty"`
+}
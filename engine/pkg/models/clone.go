@@ -8,18 +8,21 @@ import "time"
 
 // Clone defines a clone model.
 type Clone struct {
-	ID                    string        `json:"id"`
-	Snapshot              *Snapshot     `json:"snapshot"`
-	Branch                string        `json:"branch"`
-	Revision              int           `json:"revision"`
-	Protected             bool          `json:"protected"`
-	ProtectedTill         *LocalTime    `json:"protectedTill,omitempty"`
-	ProtectionWarningSent bool          `json:"-"`
-	DeleteAt              *LocalTime    `json:"deleteAt"`
-	CreatedAt             *LocalTime    `json:"createdAt"`
-	Status                Status        `json:"status"`
-	DB                    Database      `json:"db"`
-	Metadata              CloneMetadata `json:"metadata"`
+	ID                    string            `json:"id"`
+	Snapshot              *Snapshot         `json:"snapshot"`
+	Branch                string            `json:"branch"`
+	Revision              int               `json:"revision"`
+	Template              string            `json:"template,omitempty"`
+	PostgresConfig        map[string]string `json:"postgresConfig,omitempty"`
+	Protected             bool              `json:"protected"`
+	ProtectedTill         *LocalTime        `json:"protectedTill,omitempty"`
+	ProtectionWarningSent bool              `json:"-"`
+	DeleteAt              *LocalTime        `json:"deleteAt"`
+	HibernatedAt          *LocalTime        `json:"hibernatedAt,omitempty"`
+	CreatedAt             *LocalTime        `json:"createdAt"`
+	Status                Status            `json:"status"`
+	DB                    Database          `json:"db"`
+	Metadata              CloneMetadata     `json:"metadata"`
 }
 
 // IsProtected returns true if the clone is currently protected.
@@ -0,0 +1,24 @@
+/*
+2026 © Postgres.ai
+*/
+
+package models
+
+// Ways Postgres settings of a clone are applied.
+const (
+	ConfigApplyNone    = "none"
+	ConfigApplyReload  = "reload"
+	ConfigApplyRestart = "restart"
+)
+
+// CloneConfigUpdate describes the result of updating Postgres settings of a running clone.
+type CloneConfigUpdate struct {
+	// Applied tells whether the change was applied with a configuration reload or a restart; "none" if nothing changed.
+	Applied string `json:"applied"`
+
+	// RestartParameters lists the changed parameters that required a restart.
+	RestartParameters []string `json:"restartParameters,omitempty"`
+
+	// PostgresConfig contains the resulting user-defined settings of the clone.
+	PostgresConfig map[string]string `json:"postgresConfig"`
+}
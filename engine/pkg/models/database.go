@@ -14,7 +14,8 @@ type Database struct {
 	DBName   string `json:"dbName"`
 	// OwnerUser is the authenticated user the clone is bound to (the full email
 	// address). It is set only from the authenticated identity when clone binding
-	// is enabled and is used as the trusted Teleport dblab_user label; it is
-	// empty when binding is off or the creator used the shared token.
+	// is enabled or the creator is not an admin, and is used as the trusted
+	// Teleport dblab_user label and for ownership checks; it is empty when the
+	// creator used the shared token with binding off.
 	OwnerUser string `json:"ownerUser,omitempty"`
 }
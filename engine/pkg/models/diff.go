@@ -0,0 +1,52 @@
+/*
+2026 © Postgres.ai
+*/
+
+package models
+
+// Schema object kinds compared by a snapshot diff.
+const (
+	ObjectTable      = "table"
+	ObjectColumn     = "column"
+	ObjectIndex      = "index"
+	ObjectConstraint = "constraint"
+	ObjectFunction   = "function"
+	ObjectExtension  = "extension"
+)
+
+// Change actions reported by a snapshot diff.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// SnapshotDiff describes how the database of a snapshot differs from the database it is compared against.
+type SnapshotDiff struct {
+	Snapshot    string           `json:"snapshot"`
+	Against     string           `json:"against"`
+	DBName      string           `json:"dbName"`
+	ExactCounts bool             `json:"exactCounts"`
+	Changes     []SchemaChange   `json:"changes"`
+	Tables      []TableStatsDiff `json:"tables"`
+}
+
+// SchemaChange describes a schema object that was added, removed, or changed.
+type SchemaChange struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// TableStatsDiff describes the row count and size delta of a table. A table missing on one side counts as empty.
+type TableStatsDiff struct {
+	Table      string `json:"table"`
+	RowsBefore int64  `json:"rowsBefore"`
+	RowsAfter  int64  `json:"rowsAfter"`
+	RowsDelta  int64  `json:"rowsDelta"`
+	SizeBefore int64  `json:"sizeBefore"`
+	SizeAfter  int64  `json:"sizeAfter"`
+	SizeDelta  int64  `json:"sizeDelta"`
+}
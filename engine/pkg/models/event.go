@@ -0,0 +1,24 @@
+/*
+2026 © Postgres.ai
+*/
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventResync is the type of the event sent instead of missed events that can no longer be replayed,
+// e.g. after an engine restart. Clients should fetch the current state again.
+const EventResync = "resync"
+
+// Event describes a change of the engine state delivered by the event stream.
+type Event struct {
+	ID       uint64    `json:"id"`
+	Type     string    `json:"type"`
+	Time     time.Time `json:"time"`
+	EntityID string    `json:"entityId,omitempty"`
+	// Data holds the payload of the webhook event of the same type.
+	Data json.RawMessage `json:"data,omitempty"`
+}
@@ -0,0 +1,41 @@
+/*
+2026 © Postgres.ai
+*/
+
+package models
+
+import "time"
+
+// OperationState defines the state of an operation.
+type OperationState string
+
+// States of operations.
+const (
+	OperationRunning   OperationState = "running"
+	OperationSucceeded OperationState = "succeeded"
+	OperationFailed    OperationState = "failed"
+	OperationCanceled  OperationState = "canceled"
+)
+
+// Operation describes an action started through the API, such as creating a clone, and its outcome.
+type Operation struct {
+	ID string `json:"id"`
+	// Type is the action, e.g. "clone.create".
+	Type   string         `json:"type"`
+	Target string         `json:"target,omitempty"`
+	State  OperationState `json:"state"`
+	// Progress describes the current step of a running operation.
+	Progress   string     `json:"progress,omitempty"`
+	Cancelable bool       `json:"cancelable"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	// Result identifies what a succeeded operation running in the background has produced, e.g. the ID of a snapshot.
+	Result string `json:"result,omitempty"`
+}
+
+// IsFinished reports whether the operation has ended.
+func (o Operation) IsFinished() bool {
+	return o.State != OperationRunning
+}
@@ -0,0 +1,13 @@
+/*
+2026 © Postgres.ai
+*/
+
+package models
+
+// CloneConnections describes connections routed to a clone by the Postgres router.
+type CloneConnections struct {
+	CloneID         string     `json:"cloneId"`
+	Active          int        `json:"active"`
+	Total           uint64     `json:"total"`
+	LastConnectedAt *LocalTime `json:"lastConnectedAt,omitempty"`
+}
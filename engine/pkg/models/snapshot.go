@@ -40,3 +40,10 @@ type SnapshotView struct {
 	PhysicalSize Size `json:"physicalSize"`
 	LogicalSize  Size `json:"logicalSize"`
 }
+
+// RetentionPreview describes snapshots that a retention policy would destroy.
+type RetentionPreview struct {
+	Pool      string   `json:"pool"`
+	Policy    string   `json:"policy"`
+	Snapshots []string `json:"snapshots"`
+}
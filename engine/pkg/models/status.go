@@ -21,19 +21,23 @@ type StatusCode string
 
 // Constants declares available status codes and messages.
 const (
-	StatusOK        StatusCode = "OK"
-	StatusCreating  StatusCode = "CREATING"
-	StatusResetting StatusCode = "RESETTING"
-	StatusDeleting  StatusCode = "DELETING"
-	StatusExporting StatusCode = "EXPORTING"
-	StatusFatal     StatusCode = "FATAL"
-	StatusWarning   StatusCode = "WARNING"
-
-	CloneMessageOK        = "Clone is ready to accept Postgres connections."
-	CloneMessageCreating  = "Clone is being created."
-	CloneMessageResetting = "Clone is being reset."
-	CloneMessageDeleting  = "Clone is being deleted."
-	CloneMessageFatal     = "Cloning failure."
+	StatusOK         StatusCode = "OK"
+	StatusCreating   StatusCode = "CREATING"
+	StatusResetting  StatusCode = "RESETTING"
+	StatusDeleting   StatusCode = "DELETING"
+	StatusExporting  StatusCode = "EXPORTING"
+	StatusFatal      StatusCode = "FATAL"
+	StatusWarning    StatusCode = "WARNING"
+	StatusHibernated StatusCode = "HIBERNATED"
+	StatusWaking     StatusCode = "WAKING"
+
+	CloneMessageOK         = "Clone is ready to accept Postgres connections."
+	CloneMessageCreating   = "Clone is being created."
+	CloneMessageResetting  = "Clone is being reset."
+	CloneMessageDeleting   = "Clone is being deleted."
+	CloneMessageFatal      = "Cloning failure."
+	CloneMessageHibernated = "Clone is hibernated: its container is stopped until the clone is woken up."
+	CloneMessageWaking     = "Clone is waking up."
 
 	InstanceMessageOK      = "Instance is ready"
 	InstanceMessageWarning = "Subsystems that need attention"